@@ -0,0 +1,113 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditEvent is the kind of action an audit record describes.
+type AuditEvent string
+
+const (
+	AuditAuthenticate     AuditEvent = "authenticate"
+	AuditAuthorize        AuditEvent = "authorize"
+	AuditChangeIdentities AuditEvent = "change-identities"
+	AuditAddLayer         AuditEvent = "add-layer"
+	AuditExec             AuditEvent = "exec"
+	AuditWriteFile        AuditEvent = "write-file"
+)
+
+// AuditOutcome is the result of the action an audit record describes.
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditFailure AuditOutcome = "failure"
+)
+
+// AuditRecord is a single entry in the daemon's audit log.
+type AuditRecord struct {
+	Time      time.Time    `json:"time"`
+	Event     AuditEvent   `json:"event"`
+	User      string       `json:"user"`
+	Transport string       `json:"transport,omitempty"`
+	Outcome   AuditOutcome `json:"outcome"`
+	Message   string       `json:"message,omitempty"`
+}
+
+type AuditOptions struct {
+	// Events, if not empty, includes only records with one of these events.
+	Events []AuditEvent
+
+	// Users, if not empty, includes only records for one of these users.
+	Users []string
+
+	// Outcome, if set, includes only records with this outcome.
+	Outcome AuditOutcome
+
+	// After, if set, includes only records after this time.
+	After time.Time
+
+	// N, if positive, limits the result to the N most recent records.
+	N int
+}
+
+// Audit returns the audit records that match the filters given in opts,
+// oldest first. Only admin users may read the audit log.
+func (client *Client) Audit(opts *AuditOptions) ([]*AuditRecord, error) {
+	query := url.Values{}
+	if opts != nil {
+		if len(opts.Events) > 0 {
+			events := make([]string, len(opts.Events))
+			for i, event := range opts.Events {
+				events[i] = string(event)
+			}
+			query.Set("events", strings.Join(events, ","))
+		}
+		if len(opts.Users) > 0 {
+			query.Set("users", strings.Join(opts.Users, ","))
+		}
+		if opts.Outcome != "" {
+			query.Set("outcome", string(opts.Outcome))
+		}
+		if !opts.After.IsZero() {
+			query.Set("after", opts.After.Format(time.RFC3339Nano))
+		}
+		if opts.N > 0 {
+			query.Set("n", strconv.Itoa(opts.N))
+		}
+	}
+
+	resp, err := client.Requester().Do(context.Background(), &RequestOptions{
+		Type:   SyncRequest,
+		Method: "GET",
+		Path:   "/v1/audit",
+		Query:  query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var records []*AuditRecord
+	err = resp.DecodeResult(&records)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
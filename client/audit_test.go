@@ -0,0 +1,81 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"net/url"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/canonical/pebble/client"
+)
+
+func (cs *clientSuite) TestAudit(c *C) {
+	cs.rsp = `{"type": "sync", "result": [{
+		"time": "2026-01-02T03:04:05Z",
+		"event": "authenticate",
+		"user": "bob",
+		"transport": "https",
+		"outcome": "failure",
+		"message": "Invalid basic credentials"
+	}, {
+		"time": "2026-01-02T03:05:05Z",
+		"event": "exec",
+		"user": "0",
+		"transport": "http+unix",
+		"outcome": "success"
+	}]}`
+	records, err := cs.cli.Audit(nil)
+	c.Assert(err, IsNil)
+	c.Check(cs.req.Method, Equals, "GET")
+	c.Check(cs.req.URL.Path, Equals, "/v1/audit")
+	c.Check(cs.req.URL.Query(), DeepEquals, url.Values{})
+	c.Check(records, DeepEquals, []*client.AuditRecord{{
+		Time:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Event:     client.AuditAuthenticate,
+		User:      "bob",
+		Transport: "https",
+		Outcome:   client.AuditFailure,
+		Message:   "Invalid basic credentials",
+	}, {
+		Time:      time.Date(2026, 1, 2, 3, 5, 5, 0, time.UTC),
+		Event:     client.AuditExec,
+		User:      "0",
+		Transport: "http+unix",
+		Outcome:   client.AuditSuccess,
+	}})
+}
+
+func (cs *clientSuite) TestAuditOptions(c *C) {
+	cs.rsp = `{"type": "sync", "result": []}`
+	records, err := cs.cli.Audit(&client.AuditOptions{
+		Events:  []client.AuditEvent{client.AuditAuthenticate, client.AuditAuthorize},
+		Users:   []string{"bob", "alice"},
+		Outcome: client.AuditFailure,
+		After:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		N:       10,
+	})
+	c.Assert(err, IsNil)
+	c.Check(records, HasLen, 0)
+	c.Check(cs.req.URL.Path, Equals, "/v1/audit")
+	c.Check(cs.req.URL.Query(), DeepEquals, url.Values{
+		"events":  {"authenticate,authorize"},
+		"users":   {"bob,alice"},
+		"outcome": {"failure"},
+		"after":   {"2026-01-02T03:04:05Z"},
+		"n":       {"10"},
+	})
+}
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -177,6 +179,27 @@ const (
 type ChangesOptions struct {
 	ServiceName string // if empty, no filtering by service is done
 	Selector    ChangeSelector
+
+	// Kinds, if not empty, includes only changes whose kind is one of these.
+	Kinds []string
+
+	// Statuses, if not empty, includes only changes whose status is one of
+	// these, for example "Doing" or "Error".
+	Statuses []string
+
+	// Since, if set, includes only changes spawned at or after this time.
+	Since time.Time
+
+	// Until, if set, includes only changes spawned before this time.
+	Until time.Time
+
+	// Limit, if not zero, is the maximum number of changes to return.
+	// Changes are ordered by spawn time, so together with Offset this can
+	// be used to page through the changes.
+	Limit int
+
+	// Offset is the number of matching changes to skip.
+	Offset int
 }
 
 // Changes fetches information for the changes specified.
@@ -189,6 +212,24 @@ func (client *Client) Changes(opts *ChangesOptions) ([]*Change, error) {
 		if opts.ServiceName != "" {
 			query.Set("for", opts.ServiceName)
 		}
+		if len(opts.Kinds) > 0 {
+			query.Set("kinds", strings.Join(opts.Kinds, ","))
+		}
+		if len(opts.Statuses) > 0 {
+			query.Set("statuses", strings.Join(opts.Statuses, ","))
+		}
+		if !opts.Since.IsZero() {
+			query.Set("since", opts.Since.Format(time.RFC3339Nano))
+		}
+		if !opts.Until.IsZero() {
+			query.Set("until", opts.Until.Format(time.RFC3339Nano))
+		}
+		if opts.Limit != 0 {
+			query.Set("limit", strconv.Itoa(opts.Limit))
+		}
+		if opts.Offset != 0 {
+			query.Set("offset", strconv.Itoa(opts.Offset))
+		}
 	}
 
 	var chgds []changeAndData
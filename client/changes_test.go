@@ -17,6 +17,7 @@ package client_test
 import (
 	"fmt"
 	"io"
+	"net/url"
 	"time"
 
 	"gopkg.in/check.v1"
@@ -218,6 +219,31 @@ func (cs *clientSuite) TestClientChanges(c *check.C) {
 
 }
 
+func (cs *clientSuite) TestClientChangesFilters(c *check.C) {
+	cs.rsp = `{"type": "sync", "result": []}`
+	chgs, err := cs.cli.Changes(&client.ChangesOptions{
+		Selector: client.ChangesAll,
+		Kinds:    []string{"replan", "start"},
+		Statuses: []string{"Error"},
+		Since:    time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC),
+		Until:    time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC),
+		Limit:    10,
+		Offset:   20,
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(chgs, check.HasLen, 0)
+	c.Check(cs.req.URL.Path, check.Equals, "/v1/changes")
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"select":   {"all"},
+		"kinds":    {"replan,start"},
+		"statuses": {"Error"},
+		"since":    {"2024-01-02T03:04:05.123456789Z"},
+		"until":    {"2024-02-03T04:05:06Z"},
+		"limit":    {"10"},
+		"offset":   {"20"},
+	})
+}
+
 func (cs *clientSuite) TestClientChangesData(c *check.C) {
 	cs.rsp = `{"type": "sync", "result": [{
   "id":   "uno",
@@ -20,6 +20,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strconv"
+	"time"
 )
 
 type ChecksOptions struct {
@@ -32,6 +34,10 @@ type ChecksOptions struct {
 	// the results if this field is nil or empty slice, or if one of the
 	// values in the slice is equal to the check's name.
 	Names []string
+
+	// History is the number of most recent results to include in each
+	// check's History field. No history is included if this is zero.
+	History int
 }
 
 type ChecksActionOptions struct {
@@ -48,9 +54,10 @@ type ChecksActionResult struct {
 type CheckLevel string
 
 const (
-	UnsetLevel CheckLevel = ""
-	AliveLevel CheckLevel = "alive"
-	ReadyLevel CheckLevel = "ready"
+	UnsetLevel   CheckLevel = ""
+	AliveLevel   CheckLevel = "alive"
+	ReadyLevel   CheckLevel = "ready"
+	StartupLevel CheckLevel = "startup"
 )
 
 // CheckStatus represents the status of a health check.
@@ -109,6 +116,46 @@ type CheckInfo struct {
 	// The change will be of kind "perform-check" if the check is up, or
 	// "recover-check" if it's down.
 	ChangeID string `json:"change-id"`
+
+	// History holds the most recent results of this check, oldest first.
+	// It's only set if ChecksOptions.History was set.
+	History []CheckResult `json:"history,omitempty"`
+}
+
+// CheckResult holds the result of a single run of a health check.
+type CheckResult struct {
+	// Time is when the check run started.
+	Time time.Time `json:"time"`
+
+	// Duration is how long the check run took.
+	Duration time.Duration `json:"duration"`
+
+	// Error is the error message if the check failed, or empty on success.
+	Error string `json:"error,omitempty"`
+
+	// Details holds additional details about the error, if any.
+	Details string `json:"details,omitempty"`
+}
+
+func (r *CheckResult) UnmarshalJSON(data []byte) error {
+	// Use a type without the UnmarshalJSON method to avoid recursion.
+	type checkResult CheckResult
+	var jr struct {
+		checkResult
+		Duration string `json:"duration"`
+	}
+	err := json.Unmarshal(data, &jr)
+	if err != nil {
+		return err
+	}
+	*r = CheckResult(jr.checkResult)
+	if jr.Duration != "" {
+		r.Duration, err = time.ParseDuration(jr.Duration)
+		if err != nil {
+			return fmt.Errorf("cannot parse check result duration: %w", err)
+		}
+	}
+	return nil
 }
 
 // Checks fetches information about specific health checks (or all of them),
@@ -121,6 +168,9 @@ func (client *Client) Checks(opts *ChecksOptions) ([]*CheckInfo, error) {
 	if len(opts.Names) > 0 {
 		query["names"] = opts.Names
 	}
+	if opts.History > 0 {
+		query.Set("history", strconv.Itoa(opts.History))
+	}
 	var checks []*CheckInfo
 	resp, err := client.Requester().Do(context.Background(), &RequestOptions{
 		Type:   SyncRequest,
@@ -17,6 +17,7 @@ package client_test
 import (
 	"encoding/json"
 	"net/url"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -61,6 +62,44 @@ func (cs *clientSuite) TestChecksGet(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestChecksGetHistory(c *check.C) {
+	cs.rsp = `{
+		"result": [
+			{"name": "chk1", "status": "up", "history": [
+				{"time": "2025-03-13T10:04:40Z", "duration": "2.5ms", "error": "non-2xx status code 500", "details": "oops"},
+				{"time": "2025-03-13T10:04:50Z", "duration": "1s"}
+			]}
+		],
+		"status": "OK",
+		"status-code": 200,
+		"type": "sync"
+	}`
+
+	opts := client.ChecksOptions{
+		Names:   []string{"chk1"},
+		History: 2,
+	}
+	checks, err := cs.cli.Checks(&opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(checks, check.DeepEquals, []*client.CheckInfo{{
+		Name:   "chk1",
+		Status: client.CheckStatusUp,
+		History: []client.CheckResult{{
+			Time:     time.Date(2025, 3, 13, 10, 4, 40, 0, time.UTC),
+			Duration: 2500 * time.Microsecond,
+			Error:    "non-2xx status code 500",
+			Details:  "oops",
+		}, {
+			Time:     time.Date(2025, 3, 13, 10, 4, 50, 0, time.UTC),
+			Duration: time.Second,
+		}},
+	}})
+	c.Assert(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"names":   {"chk1"},
+		"history": {"2"},
+	})
+}
+
 func (cs *clientSuite) TestStartChecks(c *check.C) {
 	cs.rsp = `{
 		"result": {"changed": ["chk1", "chk2"]},
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -27,6 +28,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -138,6 +140,19 @@ type Config struct {
 	// should be configured, and how to verify TLS server certificates.
 	VerifyTLSConnection func(tls.ConnectionState) error
 
+	// KnownHostsFile is the path of a known hosts file (see KnownHosts).
+	// If set, and VerifyTLSConnection and RootCAs are nil, the server's
+	// fingerprint is checked against the fingerprint trusted for the
+	// BaseURL's address.
+	KnownHostsFile string
+
+	// RootCAs holds the certificate authorities used to verify a server
+	// that uses a certificate supplied by the user. If set, and
+	// VerifyTLSConnection is nil, the server's certificate chain must be
+	// signed by one of them and be valid for the BaseURL's host (see
+	// CAVerifier). Use x509.SystemCertPool for the system's authorities.
+	RootCAs *x509.CertPool
+
 	// Optional HTTP Basic Authentication details. If supplied this will
 	// add an HTTP basic authentication header entry.
 	// RFC 7617 (HTTP Authentication: Basic and Digest) support a user without
@@ -146,6 +161,15 @@ type Config struct {
 	BasicUsername string
 	BasicPassword string
 
+	// Optional bearer token for a "token" type identity. If supplied this
+	// will add an "Authorization: Bearer" header entry. It cannot be used
+	// together with HTTP basic authentication.
+	BearerToken string
+
+	// Optional TLS client certificates to present to the server over HTTPS,
+	// for authenticating as a "cert" type identity.
+	TLSClientCertificates []tls.Certificate
+
 	// Socket is the path to the unix socket to use.
 	Socket string
 
@@ -193,6 +217,28 @@ func New(config *Config) (*Client, error) {
 		localConfig = *config
 	}
 
+	if localConfig.VerifyTLSConnection == nil && localConfig.RootCAs != nil &&
+		strings.HasPrefix(localConfig.BaseURL, "https://") {
+		u, err := url.Parse(localConfig.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse server address: %w", err)
+		}
+		localConfig.VerifyTLSConnection = CAVerifier(u.Hostname(), localConfig.RootCAs)
+	}
+
+	if localConfig.VerifyTLSConnection == nil && localConfig.KnownHostsFile != "" &&
+		strings.HasPrefix(localConfig.BaseURL, "https://") {
+		knownHosts, err := LoadKnownHosts(localConfig.KnownHostsFile)
+		if err != nil {
+			return nil, err
+		}
+		address, err := ServerAddress(localConfig.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		localConfig.VerifyTLSConnection = knownHosts.Verifier(address)
+	}
+
 	// The default verifier never trusts any server TLS certificates.
 	if localConfig.VerifyTLSConnection == nil {
 		localConfig.VerifyTLSConnection = defaultTLSVerifier
@@ -274,6 +320,9 @@ func (rq *defaultRequester) dispatch(ctx context.Context, method, urlpath string
 	if rq.basicUsername != "" && rq.basicPassword != "" {
 		req.SetBasicAuth(rq.basicUsername, rq.basicPassword)
 	}
+	if rq.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+rq.bearerToken)
+	}
 
 	for key, value := range headers {
 		req.Header.Set(key, value)
@@ -314,7 +363,7 @@ func (rq *defaultRequester) retry(ctx context.Context, method, urlpath string, q
 	var err error
 	for {
 		rsp, err = rq.dispatch(ctx, method, urlpath, query, headers, body)
-		if err == nil || method != "GET" {
+		if err == nil || method != "GET" || isTrustError(err) {
 			break
 		}
 		select {
@@ -492,6 +541,22 @@ type SysInfo struct {
 
 	// BootID is a unique string that represents this boot of the server.
 	BootID string `json:"boot-id,omitempty"`
+
+	// TLSCertificate describes the certificate the server presents over
+	// HTTPS. It's nil if HTTPS isn't enabled.
+	TLSCertificate *TLSCertificateInfo `json:"tls-certificate,omitempty"`
+}
+
+// TLSCertificateInfo describes a server's TLS certificate.
+type TLSCertificateInfo struct {
+	// Source is "user" for a certificate supplied in the server's TLS
+	// directory, or "generated" for one generated by the server.
+	Source string `json:"source"`
+
+	Subject   string    `json:"subject"`
+	SANs      []string  `json:"sans,omitempty"`
+	NotBefore time.Time `json:"not-before"`
+	NotAfter  time.Time `json:"not-after"`
 }
 
 // SysInfo gets system information from the remote API.
@@ -569,6 +634,7 @@ type defaultRequester struct {
 	userAgent     string
 	basicUsername string
 	basicPassword string
+	bearerToken   string
 	transport     *http.Transport
 	client        *Client
 }
@@ -583,6 +649,9 @@ func newDefaultRequester(client *Client, opts *Config) (*defaultRequester, error
 		(opts.BasicPassword != "" && opts.BasicUsername == "") {
 		return nil, errors.New("cannot use incomplete basic auth credentials")
 	}
+	if opts.BearerToken != "" && opts.BasicUsername != "" {
+		return nil, errors.New("cannot use both basic auth credentials and a bearer token")
+	}
 
 	var requester *defaultRequester
 
@@ -613,6 +682,7 @@ func newDefaultRequester(client *Client, opts *Config) (*defaultRequester, error
 				// TLS certificates.
 				InsecureSkipVerify: true,
 				VerifyConnection:   opts.VerifyTLSConnection,
+				Certificates:       opts.TLSClientCertificates,
 			},
 		}
 		requester = &defaultRequester{
@@ -625,6 +695,7 @@ func newDefaultRequester(client *Client, opts *Config) (*defaultRequester, error
 
 	requester.doer = &http.Client{Transport: requester.transport}
 	requester.userAgent = opts.UserAgent
+	requester.bearerToken = opts.BearerToken
 	requester.client = client
 
 	return requester, nil
@@ -652,7 +723,10 @@ func (rq *defaultRequester) getWebsocket(urlPath string) (clientWebsocket, error
 	if rq.basicUsername != "" && rq.basicPassword != "" {
 		r.SetBasicAuth(rq.basicUsername, rq.basicPassword)
 	}
-	conn, resp, err := dialer.Dial(url, nil)
+	if rq.bearerToken != "" {
+		r.Header.Set("Authorization", "Bearer "+rq.bearerToken)
+	}
+	conn, resp, err := dialer.Dial(url, r.Header)
 	if errors.Is(err, websocket.ErrBadHandshake) {
 		// FIXME: gorilla truncates the response body to 1024 characters.
 		// If parsing fails, the real error should appear in the server logs.
@@ -31,6 +31,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
 	. "gopkg.in/check.v1"
 
 	"github.com/canonical/pebble/client"
@@ -171,6 +172,28 @@ func (cs *clientSuite) TestClientSysInfo(c *C) {
 	c.Check(sysInfo, DeepEquals, &client.SysInfo{Version: "1"})
 }
 
+func (cs *clientSuite) TestClientSysInfoTLSCertificate(c *C) {
+	cs.rsp = `{"type": "sync", "result": {
+		"version": "1",
+		"tls-certificate": {
+			"source": "user",
+			"subject": "CN=pebble.example.com",
+			"sans": ["pebble.example.com", "10.0.0.1"],
+			"not-before": "2026-01-01T00:00:00Z",
+			"not-after": "2026-04-01T00:00:00Z"
+		}
+	}}`
+	sysInfo, err := cs.cli.SysInfo()
+	c.Assert(err, IsNil)
+	c.Check(sysInfo.TLSCertificate, DeepEquals, &client.TLSCertificateInfo{
+		Source:    "user",
+		Subject:   "CN=pebble.example.com",
+		SANs:      []string{"pebble.example.com", "10.0.0.1"},
+		NotBefore: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	})
+}
+
 func (cs *clientSuite) TestClientReportsOpError(c *C) {
 	cs.rsp = `{"type": "error", "status": "potatoes"}`
 	_, err := cs.cli.SysInfo()
@@ -462,6 +485,83 @@ func (cs *clientSuite) TestClientIntegrationHTTP(c *C) {
 	c.Check(si.Version, Equals, "1")
 }
 
+func (cs *clientSuite) TestClientIntegrationBearerToken(c *C) {
+	listener, err := net.Listen("unix", cs.socketPath)
+	if err != nil {
+		c.Fatalf("unable to listen on %q: %v", cs.socketPath, err)
+	}
+	defer listener.Close()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v1/system-info")
+		c.Check(r.Header.Get("Authorization"), Equals, "Bearer secret-token")
+		fmt.Fprintln(w, `{"type":"sync", "result":{"version":"1"}}`)
+	}
+
+	srv := &httptest.Server{
+		Listener: listener,
+		Config:   &http.Server{Handler: http.HandlerFunc(handler)},
+	}
+	srv.Start()
+	defer srv.Close()
+
+	cli, err := client.New(&client.Config{
+		Socket:      cs.socketPath,
+		BearerToken: "secret-token",
+	})
+	c.Assert(err, IsNil)
+	si, err := cli.SysInfo()
+	c.Check(err, IsNil)
+	c.Check(si.Version, Equals, "1")
+}
+
+func (cs *clientSuite) TestWebsocketAuthorization(c *C) {
+	var authorization string
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v1/tasks/T1/websocket/control")
+		authorization = r.Header.Get("Authorization")
+		conn, err := upgrader.Upgrade(w, r, nil)
+		c.Assert(err, IsNil)
+		conn.Close()
+	}))
+	defer srv.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	tests := []struct {
+		config        client.Config
+		authorization string
+	}{{
+		config:        client.Config{BearerToken: "secret-token"},
+		authorization: "Bearer secret-token",
+	}, {
+		config:        client.Config{BasicUsername: "foo", BasicPassword: "bar"},
+		authorization: "Basic Zm9vOmJhcg==",
+	}}
+	for _, test := range tests {
+		authorization = ""
+		config := test.config
+		config.BaseURL = srv.URL
+		config.RootCAs = roots
+		cli, err := client.New(&config)
+		c.Assert(err, IsNil)
+		ws, err := cli.GetWebsocket("/v1/tasks/T1/websocket/control")
+		c.Assert(err, IsNil)
+		ws.Close()
+		c.Check(authorization, Equals, test.authorization)
+	}
+}
+
+func (cs *clientSuite) TestClientBearerTokenWithBasicAuth(c *C) {
+	_, err := client.New(&client.Config{
+		BasicUsername: "foo",
+		BasicPassword: "bar",
+		BearerToken:   "secret-token",
+	})
+	c.Assert(err, ErrorMatches, "cannot use both basic auth credentials and a bearer token")
+}
+
 func (cs *clientSuite) TestClientIntegrationHTTPS(c *C) {
 	testUsername := "foo"
 	testPassword := "bar"
@@ -19,6 +19,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Identity holds the configuration of a single identity.
@@ -29,6 +30,16 @@ type Identity struct {
 	// non-nil.
 	Local *LocalIdentity `json:"local,omitempty" yaml:"local,omitempty"`
 	Basic *BasicIdentity `json:"basic,omitempty" yaml:"basic,omitempty"`
+	Token *TokenIdentity `json:"token,omitempty" yaml:"token,omitempty"`
+	Cert  *CertIdentity  `json:"cert,omitempty" yaml:"cert,omitempty"`
+
+	// Permissions optionally restricts (or extends) the identity's access to
+	// the listed actions on matching resources.
+	Permissions []Permission `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+
+	// ExpiresAt is the time the identity expires and is removed, or nil if
+	// it doesn't expire.
+	ExpiresAt *time.Time `json:"expires-at,omitempty" yaml:"expires-at,omitempty"`
 }
 
 // IdentityAccess defines the access level for an identity.
@@ -53,8 +64,55 @@ type LocalIdentity struct {
 type BasicIdentity struct {
 	// Password holds the user's sha512-crypt-hashed password.
 	Password string `json:"password" yaml:"password"`
+
+	// PreviousExpiresAt is set (in responses only) if the password was
+	// rotated and the previous password is still valid until this time.
+	PreviousExpiresAt *time.Time `json:"previous-expires-at,omitempty" yaml:"previous-expires-at,omitempty"`
+}
+
+// TokenIdentity holds identity configuration specific to the "token" type
+// (for HTTP bearer token authentication).
+type TokenIdentity struct {
+	// Hash holds the hex-encoded SHA-256 hash of the user's token.
+	Hash string `json:"hash" yaml:"hash"`
+
+	// PreviousExpiresAt is set (in responses only) if the token was rotated
+	// and the previous token is still valid until this time.
+	PreviousExpiresAt *time.Time `json:"previous-expires-at,omitempty" yaml:"previous-expires-at,omitempty"`
+}
+
+// CertIdentity holds identity configuration specific to the "cert" type
+// (for mutual TLS client certificate authentication).
+type CertIdentity struct {
+	// PEM holds the PEM-encoded X.509 client certificate.
+	PEM string `json:"pem" yaml:"pem"`
+}
+
+// Permission grants an identity access to perform an action on the
+// resources matching any of the given patterns.
+type Permission struct {
+	Action PermissionAction `json:"action" yaml:"action"`
+
+	// Resources holds service or check name patterns, or for the file and
+	// exec actions, absolute path patterns (a trailing "/**" matches a
+	// directory and everything under it).
+	Resources []string `json:"resources" yaml:"resources"`
 }
 
+// PermissionAction defines an action that a Permission can grant.
+type PermissionAction string
+
+const (
+	ReadServicesPermission   PermissionAction = "read-services"
+	ManageServicesPermission PermissionAction = "manage-services"
+	ReadLogsPermission       PermissionAction = "read-logs"
+	ReadChecksPermission     PermissionAction = "read-checks"
+	ManageChecksPermission   PermissionAction = "manage-checks"
+	ReadFilesPermission      PermissionAction = "read-files"
+	WriteFilesPermission     PermissionAction = "write-files"
+	ExecPermission           PermissionAction = "exec"
+)
+
 // For future extension.
 type IdentitiesOptions struct{}
 
@@ -115,6 +173,54 @@ func (client *Client) RemoveIdentities(identityNames map[string]struct{}) error
 	return client.postIdentities("remove", identities)
 }
 
+// RotateIdentitiesOptions holds the options for a call to RotateIdentities.
+type RotateIdentitiesOptions struct {
+	// GracePeriod is how long the previous password or token remains valid
+	// after rotation. If zero, it's invalid immediately.
+	GracePeriod time.Duration
+}
+
+type rotateIdentitiesPayload struct {
+	Action      string                     `json:"action"`
+	GracePeriod string                     `json:"grace-period,omitempty"`
+	Identities  map[string]*rotateIdentity `json:"identities"`
+}
+
+type rotateIdentity struct {
+	Basic *BasicIdentity `json:"basic,omitempty"`
+	Token *TokenIdentity `json:"token,omitempty"`
+}
+
+// RotateIdentities replaces the basic password hash and/or token hash of the
+// named identities with those in the given identities (other fields are
+// ignored). It's an error if any of the named identities do not exist.
+func (client *Client) RotateIdentities(identities map[string]*Identity, opts *RotateIdentitiesOptions) error {
+	payload := rotateIdentitiesPayload{
+		Action:     "rotate",
+		Identities: make(map[string]*rotateIdentity, len(identities)),
+	}
+	if opts != nil && opts.GracePeriod != 0 {
+		payload.GracePeriod = opts.GracePeriod.String()
+	}
+	for name, identity := range identities {
+		if identity == nil {
+			return fmt.Errorf("identity %q must not be nil", name)
+		}
+		payload.Identities[name] = &rotateIdentity{Basic: identity.Basic, Token: identity.Token}
+	}
+	body, err := json.Marshal(&payload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal identities payload: %w", err)
+	}
+	_, err = client.Requester().Do(context.Background(), &RequestOptions{
+		Type:   SyncRequest,
+		Method: "POST",
+		Path:   "/v1/identities",
+		Body:   bytes.NewReader(body),
+	})
+	return err
+}
+
 func (client *Client) postIdentities(action string, identities map[string]*Identity) error {
 	payload := identitiesPayload{
 		Action:     action,
@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/url"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -30,13 +31,31 @@ func (cs *clientSuite) TestIdentities(c *C) {
 			"access": "read",
 			"local": {
 				"user-id": 42
-			}
+			},
+			"permissions": [
+				{"action": "manage-services", "resources": ["web-*"]},
+				{"action": "read-files", "resources": ["/srv/**"]}
+			]
 		},
 		"mary": {
 			"access": "admin",
 			"local": {
 				"user-id": 1000
 			}
+		},
+		"olivia": {
+			"access": "read",
+			"token": {
+				"hash": "*****",
+				"previous-expires-at": "2026-01-02T04:04:05Z"
+			},
+			"expires-at": "2026-02-01T00:00:00Z"
+		},
+		"controller": {
+			"access": "admin",
+			"cert": {
+				"pem": "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+			}
 		}
 	}}`
 	identities, err := cs.cli.Identities(nil)
@@ -48,11 +67,27 @@ func (cs *clientSuite) TestIdentities(c *C) {
 		"bob": {
 			Access: client.ReadAccess,
 			Local:  &client.LocalIdentity{UserID: ptr(uint32(42))},
+			Permissions: []client.Permission{
+				{Action: client.ManageServicesPermission, Resources: []string{"web-*"}},
+				{Action: client.ReadFilesPermission, Resources: []string{"/srv/**"}},
+			},
 		},
 		"mary": {
 			Access: client.AdminAccess,
 			Local:  &client.LocalIdentity{UserID: ptr(uint32(1000))},
 		},
+		"olivia": {
+			Access: client.ReadAccess,
+			Token: &client.TokenIdentity{
+				Hash:              "*****",
+				PreviousExpiresAt: ptr(time.Date(2026, 1, 2, 4, 4, 5, 0, time.UTC)),
+			},
+			ExpiresAt: ptr(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)),
+		},
+		"controller": {
+			Access: client.AdminAccess,
+			Cert:   &client.CertIdentity{PEM: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"},
+		},
 	})
 }
 
@@ -104,17 +139,58 @@ func (cs *clientSuite) TestRemoveIdentities(c *C) {
 	})
 }
 
+func (cs *clientSuite) TestRotateIdentities(c *C) {
+	cs.rsp = `{"type": "sync", "result": null}`
+	err := cs.cli.RotateIdentities(map[string]*client.Identity{
+		"nancy":  {Basic: &client.BasicIdentity{Password: "hash"}},
+		"olivia": {Token: &client.TokenIdentity{Hash: "0123abcd"}},
+	}, &client.RotateIdentitiesOptions{GracePeriod: time.Hour})
+	c.Assert(err, IsNil)
+	c.Assert(cs.req.Method, Equals, "POST")
+	c.Assert(cs.req.URL.Path, Equals, "/v1/identities")
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	var m map[string]any
+	err = json.Unmarshal(body, &m)
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, map[string]any{
+		"action":       "rotate",
+		"grace-period": "1h0m0s",
+		"identities": map[string]any{
+			"nancy": map[string]any{
+				"basic": map[string]any{"password": "hash"},
+			},
+			"olivia": map[string]any{
+				"token": map[string]any{"hash": "0123abcd"},
+			},
+		},
+	})
+}
+
 func (cs *clientSuite) testPostIdentities(c *C, action string, clientFunc func(map[string]*client.Identity) error) {
 	cs.rsp = `{"type": "sync", "result": null}`
 	err := clientFunc(map[string]*client.Identity{
 		"bob": {
 			Access: client.ReadAccess,
 			Local:  &client.LocalIdentity{UserID: ptr(uint32(42))},
+			Permissions: []client.Permission{
+				{Action: client.ManageServicesPermission, Resources: []string{"web-*"}},
+				{Action: client.ReadFilesPermission, Resources: []string{"/srv/**"}},
+			},
 		},
 		"mary": {
 			Access: client.AdminAccess,
 			Local:  &client.LocalIdentity{UserID: ptr(uint32(1000))},
 		},
+		"olivia": {
+			Access: client.ReadAccess,
+			Token:  &client.TokenIdentity{Hash: "0123abcd"},
+		},
+		"controller": {
+			Access: client.AdminAccess,
+			Cert:   &client.CertIdentity{PEM: "PEM"},
+		},
 	})
 	c.Assert(err, IsNil)
 	c.Assert(cs.req.Method, Equals, "POST")
@@ -133,6 +209,10 @@ func (cs *clientSuite) testPostIdentities(c *C, action string, clientFunc func(m
 				"local": map[string]any{
 					"user-id": 42.0,
 				},
+				"permissions": []any{
+					map[string]any{"action": "manage-services", "resources": []any{"web-*"}},
+					map[string]any{"action": "read-files", "resources": []any{"/srv/**"}},
+				},
 			},
 			"mary": map[string]any{
 				"access": "admin",
@@ -140,6 +220,18 @@ func (cs *clientSuite) testPostIdentities(c *C, action string, clientFunc func(m
 					"user-id": 1000.0,
 				},
 			},
+			"olivia": map[string]any{
+				"access": "read",
+				"token": map[string]any{
+					"hash": "0123abcd",
+				},
+			},
+			"controller": map[string]any{
+				"access": "admin",
+				"cert": map[string]any{
+					"pem": "PEM",
+				},
+			},
 		},
 	})
 }
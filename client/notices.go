@@ -15,16 +15,25 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// maxNoticeEventSize is the maximum size of a line in a notice stream, which
+// must be large enough to hold a notice's JSON, including its data.
+const maxNoticeEventSize = 64 * 1024
+
 type NotifyOptions struct {
 	// Type is the notice's type. Currently only notices of type CustomNotice
 	// can be added.
@@ -85,6 +94,94 @@ func (client *Client) Notify(opts *NotifyOptions) (string, error) {
 	return result.ID, err
 }
 
+type AcknowledgeNoticesOptions struct {
+	// Types, if not empty, acknowledges only notices whose type is one of these.
+	Types []NoticeType
+
+	// Keys, if not empty, acknowledges only notices whose key is one of these.
+	Keys []string
+
+	// Before, if set, acknowledges only notices that were last repeated
+	// before this time.
+	Before time.Time
+}
+
+// AcknowledgeNotices acknowledges the notices visible to the current user
+// that match the filters given in opts, returning the number of notices
+// acknowledged. Acknowledgements are per user, and a notice becomes
+// unacknowledged again when it's next repeated.
+func (client *Client) AcknowledgeNotices(opts *AcknowledgeNoticesOptions) (int, error) {
+	var payload = struct {
+		Action string   `json:"action"`
+		Types  []string `json:"types,omitempty"`
+		Keys   []string `json:"keys,omitempty"`
+		Before string   `json:"before,omitempty"`
+	}{
+		Action: "acknowledge",
+	}
+	if opts != nil {
+		for _, t := range opts.Types {
+			payload.Types = append(payload.Types, string(t))
+		}
+		payload.Keys = opts.Keys
+		if !opts.Before.IsZero() {
+			payload.Before = opts.Before.Format(time.RFC3339Nano)
+		}
+	}
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(&payload); err != nil {
+		return 0, err
+	}
+
+	result := struct {
+		Count int `json:"count"`
+	}{}
+	resp, err := client.Requester().Do(context.Background(), &RequestOptions{
+		Type:   SyncRequest,
+		Method: "POST",
+		Path:   "/v1/notices",
+		Body:   &body,
+	})
+	if err != nil {
+		return 0, err
+	}
+	err = resp.DecodeResult(&result)
+	if err != nil {
+		return 0, err
+	}
+	return result.Count, nil
+}
+
+// DeleteNotice deletes the custom notice with the given ID. Only the user who
+// added the notice, or an admin, may delete it.
+func (client *Client) DeleteNotice(id string) error {
+	if !noticeIDRegexp.MatchString(id) {
+		return fmt.Errorf("invalid notice ID %q", id)
+	}
+	var payload = struct {
+		Action string `json:"action"`
+		ID     string `json:"id"`
+	}{
+		Action: "delete",
+		ID:     id,
+	}
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(&payload); err != nil {
+		return err
+	}
+
+	resp, err := client.Requester().Do(context.Background(), &RequestOptions{
+		Type:   SyncRequest,
+		Method: "POST",
+		Path:   "/v1/notices",
+		Body:   &body,
+	})
+	if err != nil {
+		return err
+	}
+	return resp.DecodeResult(nil)
+}
+
 type NoticesOptions struct {
 	// Users allows returning notices for all users.
 	Users NoticesUsers
@@ -100,6 +197,13 @@ type NoticesOptions struct {
 
 	// After, if set, includes only notices that were last repeated after this time.
 	After time.Time
+
+	// Before, if set, includes only notices that were last repeated before this time.
+	Before time.Time
+
+	// Unacknowledged, if true, includes only notices that the current user
+	// hasn't acknowledged since they were last repeated.
+	Unacknowledged bool
 }
 
 type NoticesUsers string
@@ -143,6 +247,15 @@ const (
 	// Warnings are a subset of notices where the key is a human-readable
 	// warning message.
 	WarningNotice NoticeType = "warning"
+
+	// Recorded whenever a service's status changes, for example when it
+	// becomes active, enters backoff, or exits. The key for service-status
+	// notices is the service name.
+	ServiceStatusNotice NoticeType = "service-status"
+
+	// Recorded whenever a check goes up or down. The key for check-status
+	// notices is the check name.
+	CheckStatusNotice NoticeType = "check-status"
 )
 
 type jsonNotice struct {
@@ -227,6 +340,85 @@ func (client *Client) WaitNotices(ctx context.Context, serverTimeout time.Durati
 	return jsonNoticesToNotices(jns), err
 }
 
+// StreamNotices streams notices that match the filters given in opts as they
+// occur, calling f for each notice, until ctx is cancelled or f returns an
+// error. Notices are streamed in last-repeated order, so to resume a stream,
+// set opts.After to the LastRepeated time of the last notice processed.
+//
+// Cancelling ctx is not considered an error: StreamNotices returns nil.
+func (client *Client) StreamNotices(ctx context.Context, opts *NoticesOptions, f func(notice *Notice) error) error {
+	resp, err := client.Requester().Do(ctx, &RequestOptions{
+		Type:   RawRequest,
+		Method: "GET",
+		Path:   "/v1/notices/stream",
+		Query:  makeNoticesQuery(opts),
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var serverResp response
+		err := decodeInto(resp.Body, &serverResp)
+		if err != nil {
+			return fmt.Errorf("cannot stream notices: server returned status %d", resp.StatusCode)
+		}
+		err = serverResp.err()
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("cannot stream notices: server returned status %d", resp.StatusCode)
+	}
+
+	err = decodeNoticeEvents(resp.Body, f)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// decodeNoticeEvents reads server-sent events from reader, calling f with the
+// notice of each "notice" event, until EOF or an error occurs.
+func decodeNoticeEvents(reader io.Reader, f func(notice *Notice) error) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, maxNoticeEventSize)
+	var event, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event = value
+			case "data":
+				data += value
+			}
+			continue
+		}
+
+		// A blank line dispatches the event.
+		if event == "notice" {
+			var jn *jsonNotice
+			err := json.Unmarshal([]byte(data), &jn)
+			if err != nil {
+				return fmt.Errorf("cannot unmarshal notice: %w", err)
+			}
+			err = f(jsonNoticeToNotice(jn))
+			if err != nil {
+				return err
+			}
+		}
+		event, data = "", ""
+	}
+	err := scanner.Err()
+	if err != nil {
+		return fmt.Errorf("cannot read notice stream: %w", err)
+	}
+	return nil
+}
+
 func makeNoticesQuery(opts *NoticesOptions) url.Values {
 	query := make(url.Values)
 	if opts == nil {
@@ -247,6 +439,12 @@ func makeNoticesQuery(opts *NoticesOptions) url.Values {
 	if !opts.After.IsZero() {
 		query.Set("after", opts.After.Format(time.RFC3339Nano))
 	}
+	if !opts.Before.IsZero() {
+		query.Set("before", opts.Before.Format(time.RFC3339Nano))
+	}
+	if opts.Unacknowledged {
+		query.Set("unacknowledged", "true")
+	}
 	return query
 }
 
@@ -17,7 +17,9 @@ package client_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"net/http"
 	"net/url"
 	"time"
 
@@ -127,16 +129,21 @@ func (cs *clientSuite) TestNoticesFilters(c *C) {
 		Types:  []client.NoticeType{client.CustomNotice},
 		Keys:   []string{"foo.com/bar", "example.com/x"},
 		After:  time.Date(2023, 9, 5, 16, 43, 32, 123_456_789, time.UTC),
+		Before: time.Date(2023, 9, 6, 10, 0, 0, 0, time.UTC),
+
+		Unacknowledged: true,
 	})
 	c.Assert(err, IsNil)
 	c.Assert(cs.req.Method, Equals, "GET")
 	c.Assert(cs.req.URL.Path, Equals, "/v1/notices")
 	c.Assert(cs.req.URL.Query(), DeepEquals, url.Values{
-		"users":   {"all"},
-		"user-id": {"1000"},
-		"types":   {"custom"},
-		"keys":    {"foo.com/bar", "example.com/x"},
-		"after":   {"2023-09-05T16:43:32.123456789Z"},
+		"users":          {"all"},
+		"user-id":        {"1000"},
+		"types":          {"custom"},
+		"keys":           {"foo.com/bar", "example.com/x"},
+		"after":          {"2023-09-05T16:43:32.123456789Z"},
+		"before":         {"2023-09-06T10:00:00Z"},
+		"unacknowledged": {"true"},
 	})
 	c.Assert(notices, DeepEquals, []*client.Notice{})
 }
@@ -191,6 +198,76 @@ func (cs *clientSuite) TestNotifyMinimal(c *C) {
 	})
 }
 
+func (cs *clientSuite) TestAcknowledgeNotices(c *C) {
+	cs.rsp = `{"type": "sync", "result": {"count": 3}}`
+	count, err := cs.cli.AcknowledgeNotices(&client.AcknowledgeNoticesOptions{
+		Types:  []client.NoticeType{client.CustomNotice, client.WarningNotice},
+		Keys:   []string{"a.b/c"},
+		Before: time.Date(2023, 9, 5, 16, 43, 32, 123_456_789, time.UTC),
+	})
+	c.Assert(err, IsNil)
+	c.Check(count, Equals, 3)
+	c.Assert(cs.req.Method, Equals, "POST")
+	c.Assert(cs.req.URL.Path, Equals, "/v1/notices")
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	var m map[string]any
+	err = json.Unmarshal(body, &m)
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, map[string]any{
+		"action": "acknowledge",
+		"types":  []any{"custom", "warning"},
+		"keys":   []any{"a.b/c"},
+		"before": "2023-09-05T16:43:32.123456789Z",
+	})
+}
+
+func (cs *clientSuite) TestAcknowledgeNoticesMinimal(c *C) {
+	cs.rsp = `{"type": "sync", "result": {"count": 0}}`
+	count, err := cs.cli.AcknowledgeNotices(nil)
+	c.Assert(err, IsNil)
+	c.Check(count, Equals, 0)
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	var m map[string]any
+	err = json.Unmarshal(body, &m)
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, map[string]any{
+		"action": "acknowledge",
+	})
+}
+
+func (cs *clientSuite) TestDeleteNotice(c *C) {
+	cs.rsp = `{"type": "sync", "result": null}`
+	err := cs.cli.DeleteNotice("42")
+	c.Assert(err, IsNil)
+	c.Assert(cs.req.Method, Equals, "POST")
+	c.Assert(cs.req.URL.Path, Equals, "/v1/notices")
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	var m map[string]any
+	err = json.Unmarshal(body, &m)
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, map[string]any{
+		"action": "delete",
+		"id":     "42",
+	})
+}
+
+func (cs *clientSuite) TestDeleteNoticeError(c *C) {
+	cs.rsp = `{"type": "error", "status-code": 404, "result": {"message": "cannot find notice with ID \"42\""}}`
+	err := cs.cli.DeleteNotice("42")
+	c.Assert(err, ErrorMatches, `cannot find notice with ID "42"`)
+}
+
+func (cs *clientSuite) TestDeleteNoticeInvalidID(c *C) {
+	err := cs.cli.DeleteNotice("<bad>")
+	c.Assert(err, ErrorMatches, `invalid notice ID "<bad>"`)
+}
+
 func (cs *clientSuite) TestWaitNotices(c *C) {
 	cs.rsp = `{"type": "sync", "result": [{
 		"id":   "1",
@@ -228,3 +305,80 @@ func (cs *clientSuite) TestWaitNoticesTimeout(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(notices, HasLen, 0)
 }
+
+func (cs *clientSuite) TestStreamNotices(c *C) {
+	readsChan := make(chan string)
+	cli, err := client.New(nil)
+	c.Assert(err, IsNil)
+	cli.SetDoer(doerFunc(func(req *http.Request) (*http.Response, error) {
+		c.Check(req.Method, Equals, "GET")
+		c.Check(req.URL.Path, Equals, "/v1/notices/stream")
+		c.Check(req.URL.Query(), DeepEquals, url.Values{
+			"types": {"custom"},
+			"after": {"2023-09-06T15:00:00Z"},
+		})
+		rsp := &http.Response{
+			Body:       &followReader{readsChan},
+			Header:     make(http.Header),
+			StatusCode: http.StatusOK,
+		}
+		return rsp, nil
+	}))
+
+	go func() {
+		readsChan <- "id: 2023-09-06T16:43:00Z\nevent: notice\n"
+		readsChan <- `data: {"id":"1","user-id":null,"type":"custom","key":"a.b/1","first-occurred":"2023-09-06T15:43:00Z","last-occurred":"2023-09-06T16:43:00Z","last-repeated":"2023-09-06T16:43:00Z","occurrences":2,"repeat-after":"1h0m0s"}` + "\n\n"
+		readsChan <- ": keepalive\n\n"
+		readsChan <- "id: 2023-09-06T17:00:00Z\nevent: notice\n" +
+			`data: {"id":"2","user-id":1000,"type":"custom","key":"a.b/2","first-occurred":"2023-09-06T17:00:00Z","last-occurred":"2023-09-06T17:00:00Z","last-repeated":"2023-09-06T17:00:00Z","occurrences":1}` + "\n\n"
+		readsChan <- ""
+	}()
+
+	var notices []*client.Notice
+	err = cli.StreamNotices(context.Background(), &client.NoticesOptions{
+		Types: []client.NoticeType{client.CustomNotice},
+		After: time.Date(2023, 9, 6, 15, 0, 0, 0, time.UTC),
+	}, func(notice *client.Notice) error {
+		notices = append(notices, notice)
+		return nil
+	})
+	c.Assert(err, IsNil)
+	uid := uint32(1000)
+	c.Assert(notices, DeepEquals, []*client.Notice{{
+		ID:            "1",
+		Type:          "custom",
+		Key:           "a.b/1",
+		FirstOccurred: time.Date(2023, 9, 6, 15, 43, 0, 0, time.UTC),
+		LastOccurred:  time.Date(2023, 9, 6, 16, 43, 0, 0, time.UTC),
+		LastRepeated:  time.Date(2023, 9, 6, 16, 43, 0, 0, time.UTC),
+		Occurrences:   2,
+		RepeatAfter:   time.Hour,
+	}, {
+		ID:            "2",
+		UserID:        &uid,
+		Type:          "custom",
+		Key:           "a.b/2",
+		FirstOccurred: time.Date(2023, 9, 6, 17, 0, 0, 0, time.UTC),
+		LastOccurred:  time.Date(2023, 9, 6, 17, 0, 0, 0, time.UTC),
+		LastRepeated:  time.Date(2023, 9, 6, 17, 0, 0, 0, time.UTC),
+		Occurrences:   1,
+	}})
+}
+
+func (cs *clientSuite) TestStreamNoticesCallbackError(c *C) {
+	cs.rsp = "event: notice\n" + `data: {"id":"1","type":"custom","key":"a.b/1"}` + "\n\n"
+	err := cs.cli.StreamNotices(context.Background(), nil, func(notice *client.Notice) error {
+		return errors.New("stop!")
+	})
+	c.Assert(err, ErrorMatches, "stop!")
+}
+
+func (cs *clientSuite) TestStreamNoticesError(c *C) {
+	cs.status = http.StatusForbidden
+	cs.rsp = `{"type": "error", "status-code": 403, "result": {"message": "cannot determine UID of request, so cannot stream notices"}}`
+	err := cs.cli.StreamNotices(context.Background(), nil, func(notice *client.Notice) error {
+		c.Fatalf("unexpected notice: %v", notice)
+		return nil
+	})
+	c.Assert(err, ErrorMatches, "cannot determine UID of request, so cannot stream notices")
+}
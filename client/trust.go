@@ -0,0 +1,284 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ServerFingerprint verifies the certificate chain presented by a Pebble
+// server and returns the fingerprint of the server's identity key.
+//
+// By default, a Pebble server presents its TLS certificate followed by a
+// self-signed identity certificate, which signs the TLS certificate. The
+// fingerprint is the SHA512/384 hash of the identity certificate's Ed25519
+// public key, encoded in base32 without padding.
+//
+// A server using a certificate supplied by the user (signed by a certificate
+// authority) doesn't present an identity certificate, so it has no
+// fingerprint; use CAVerifier to verify such a server instead.
+func ServerFingerprint(state tls.ConnectionState) (string, error) {
+	certs := state.PeerCertificates
+	if len(certs) < 2 {
+		return "", errors.New("server did not present an identity certificate")
+	}
+	leaf, idCert := certs[0], certs[len(certs)-1]
+
+	publicKey, ok := idCert.PublicKey.(ed25519.PublicKey)
+	if !ok || !idCert.IsCA {
+		return "", errors.New("server identity certificate is not a valid identity certificate")
+	}
+	if err := idCert.CheckSignatureFrom(idCert); err != nil {
+		return "", fmt.Errorf("server identity certificate is not self-signed: %w", err)
+	}
+	if err := leaf.CheckSignatureFrom(idCert); err != nil {
+		return "", fmt.Errorf("server TLS certificate is not signed by its identity certificate: %w", err)
+	}
+	now := time.Now()
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return "", errors.New("server TLS certificate has expired or is not yet valid")
+	}
+
+	hash := sha512.Sum384(publicKey)
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(hash[:]), nil
+}
+
+// UnknownServerError is returned when connecting to a server whose address
+// is not in the known hosts file.
+type UnknownServerError struct {
+	Address     string
+	Fingerprint string
+}
+
+func (e *UnknownServerError) Error() string {
+	return fmt.Sprintf("server %s is not trusted (fingerprint %s)", e.Address, e.Fingerprint)
+}
+
+// FingerprintMismatchError is returned when a server's fingerprint differs
+// from the fingerprint recorded for its address in the known hosts file.
+type FingerprintMismatchError struct {
+	Address  string
+	Expected string
+	Actual   string
+}
+
+func (e *FingerprintMismatchError) Error() string {
+	return fmt.Sprintf("server %s fingerprint %s does not match trusted fingerprint %s",
+		e.Address, e.Actual, e.Expected)
+}
+
+// isTrustError reports whether err is due to the server not being trusted,
+// in which case retrying the request won't help.
+func isTrustError(err error) bool {
+	var unknownErr *UnknownServerError
+	var mismatchErr *FingerprintMismatchError
+	var verifyErr *tls.CertificateVerificationError
+	return errors.As(err, &unknownErr) || errors.As(err, &mismatchErr) || errors.As(err, &verifyErr)
+}
+
+// KnownHosts holds the fingerprints of trusted servers, keyed by address.
+//
+// The file has one "<address> <fingerprint>" entry per line, where the
+// address is the host and port of the server. Blank lines and lines
+// starting with '#' are ignored.
+type KnownHosts struct {
+	path         string
+	fingerprints map[string]string
+}
+
+// LoadKnownHosts loads the known hosts file at path. A missing file is not
+// an error, and results in no trusted servers.
+func LoadKnownHosts(path string) (*KnownHosts, error) {
+	k := &KnownHosts{
+		path:         path,
+		fingerprints: make(map[string]string),
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return k, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read known hosts: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("cannot parse known hosts %q line %d: expected address and fingerprint", path, lineNum)
+		}
+		k.fingerprints[fields[0]] = fields[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read known hosts: %w", err)
+	}
+	return k, nil
+}
+
+// Fingerprint returns the trusted fingerprint for address, and whether the
+// address is known.
+func (k *KnownHosts) Fingerprint(address string) (string, bool) {
+	fingerprint, ok := k.fingerprints[address]
+	return fingerprint, ok
+}
+
+// Addresses returns the known addresses, sorted.
+func (k *KnownHosts) Addresses() []string {
+	addresses := make([]string, 0, len(k.fingerprints))
+	for address := range k.fingerprints {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// Add trusts the server at address with the given fingerprint, replacing
+// any existing entry. Call Save to persist the change.
+func (k *KnownHosts) Add(address, fingerprint string) {
+	k.fingerprints[address] = fingerprint
+}
+
+// Remove stops trusting the server at address, reporting whether it was
+// known. Call Save to persist the change.
+func (k *KnownHosts) Remove(address string) bool {
+	_, ok := k.fingerprints[address]
+	delete(k.fingerprints, address)
+	return ok
+}
+
+// Save writes the known hosts file, creating its directory if necessary.
+func (k *KnownHosts) Save() error {
+	var buf bytes.Buffer
+	for _, address := range k.Addresses() {
+		fmt.Fprintf(&buf, "%s %s\n", address, k.fingerprints[address])
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), 0o700); err != nil {
+		return fmt.Errorf("cannot save known hosts: %w", err)
+	}
+	// Write to a temporary file and rename, so a crash never leaves a
+	// partially-written file.
+	tempPath := k.path + ".tmp"
+	if err := os.WriteFile(tempPath, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("cannot save known hosts: %w", err)
+	}
+	if err := os.Rename(tempPath, k.path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("cannot save known hosts: %w", err)
+	}
+	return nil
+}
+
+// Verifier returns a function, suitable for Config.VerifyTLSConnection,
+// that only accepts the server at address if its fingerprint matches the
+// trusted fingerprint for that address.
+func (k *KnownHosts) Verifier(address string) func(tls.ConnectionState) error {
+	expected, known := k.fingerprints[address]
+	if !known {
+		return func(state tls.ConnectionState) error {
+			actual, err := ServerFingerprint(state)
+			if err != nil {
+				return err
+			}
+			return &UnknownServerError{Address: address, Fingerprint: actual}
+		}
+	}
+	return FingerprintVerifier(address, expected)
+}
+
+// FingerprintVerifier returns a function, suitable for
+// Config.VerifyTLSConnection, that only accepts the server at address if its
+// fingerprint is the given fingerprint.
+func FingerprintVerifier(address, fingerprint string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		actual, err := ServerFingerprint(state)
+		if err != nil {
+			return err
+		}
+		if actual != fingerprint {
+			return &FingerprintMismatchError{Address: address, Expected: fingerprint, Actual: actual}
+		}
+		return nil
+	}
+}
+
+// CAVerifier returns a function, suitable for Config.VerifyTLSConnection,
+// that only accepts a server whose certificate chain is signed by one of the
+// certificate authorities in roots and is valid for host. If roots is nil,
+// the system's certificate authorities are used.
+//
+// Use this to verify a server that uses a certificate supplied by the user,
+// rather than the certificate Pebble generates from its identity key.
+func CAVerifier(host string, roots *x509.CertPool) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		certs := state.PeerCertificates
+		if len(certs) == 0 {
+			return errors.New("server did not present a certificate")
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			DNSName:       host,
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		if err != nil {
+			return &tls.CertificateVerificationError{UnverifiedCertificates: certs, Err: err}
+		}
+		return nil
+	}
+}
+
+// ServerAddress returns the address used to identify a server in the known
+// hosts file: the host and port of an "https://" base URL. A bare
+// "host:port" is also accepted.
+func ServerAddress(baseURL string) (string, error) {
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse server address: %w", err)
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("cannot use %q scheme for trusted server, must be https", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("cannot parse server address %q: missing host", baseURL)
+	}
+	return u.Host, nil
+}
@@ -0,0 +1,316 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/canonical/pebble/client"
+	"github.com/canonical/pebble/internals/idkey"
+)
+
+type trustSuite struct{}
+
+var _ = Suite(&trustSuite{})
+
+// serverChain returns a TLS certificate chain like the one a Pebble server
+// presents: a leaf certificate signed by a self-signed identity certificate.
+func serverChain(c *C, signer crypto.Signer) *tls.Certificate {
+	now := time.Now()
+	idTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "pebble-id"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	idDER, err := x509.CreateCertificate(rand.Reader, idTemplate, idTemplate, signer.Public(), signer)
+	c.Assert(err, IsNil)
+	idCert, err := x509.ParseCertificate(idDER)
+	c.Assert(err, IsNil)
+
+	_, leafKey, err := ed25519.GenerateKey(rand.Reader)
+	c.Assert(err, IsNil)
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "pebble"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, idCert, leafKey.Public(), signer)
+	c.Assert(err, IsNil)
+	leafCert, err := x509.ParseCertificate(leafDER)
+	c.Assert(err, IsNil)
+
+	return &tls.Certificate{
+		PrivateKey:  leafKey,
+		Certificate: [][]byte{leafDER, idDER},
+		Leaf:        leafCert,
+	}
+}
+
+func connectionState(chain *tls.Certificate) tls.ConnectionState {
+	var certs []*x509.Certificate
+	for _, der := range chain.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			panic(err)
+		}
+		certs = append(certs, cert)
+	}
+	return tls.ConnectionState{PeerCertificates: certs}
+}
+
+func (s *trustSuite) TestServerFingerprint(c *C) {
+	key, err := idkey.Generate(filepath.Join(c.MkDir(), "identity"))
+	c.Assert(err, IsNil)
+	chain := serverChain(c, key)
+
+	fingerprint, err := client.ServerFingerprint(connectionState(chain))
+	c.Assert(err, IsNil)
+	c.Check(fingerprint, Equals, key.Fingerprint())
+}
+
+func (s *trustSuite) TestServerFingerprintErrors(c *C) {
+	key, err := idkey.Generate(filepath.Join(c.MkDir(), "identity"))
+	c.Assert(err, IsNil)
+	chain := serverChain(c, key)
+	state := connectionState(chain)
+
+	_, err = client.ServerFingerprint(tls.ConnectionState{PeerCertificates: state.PeerCertificates[:1]})
+	c.Check(err, ErrorMatches, "server did not present an identity certificate")
+
+	// The leaf certificate must be signed by the identity certificate.
+	otherKey, err := idkey.Generate(filepath.Join(c.MkDir(), "identity"))
+	c.Assert(err, IsNil)
+	other := connectionState(serverChain(c, otherKey))
+	_, err = client.ServerFingerprint(tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{state.PeerCertificates[0], other.PeerCertificates[1]},
+	})
+	c.Check(err, ErrorMatches, "server TLS certificate is not signed by its identity certificate: .*")
+}
+
+func (s *trustSuite) TestKnownHosts(c *C) {
+	path := filepath.Join(c.MkDir(), "pebble", "known-hosts")
+	knownHosts, err := client.LoadKnownHosts(path)
+	c.Assert(err, IsNil)
+	c.Check(knownHosts.Addresses(), DeepEquals, []string{})
+
+	knownHosts.Add("example.com:4443", "FINGERPRINT2")
+	knownHosts.Add("10.0.0.1:8443", "FINGERPRINT1")
+	c.Assert(knownHosts.Save(), IsNil)
+
+	data, err := os.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "10.0.0.1:8443 FINGERPRINT1\nexample.com:4443 FINGERPRINT2\n")
+	st, err := os.Stat(path)
+	c.Assert(err, IsNil)
+	c.Check(st.Mode().Perm(), Equals, os.FileMode(0o600))
+
+	knownHosts, err = client.LoadKnownHosts(path)
+	c.Assert(err, IsNil)
+	c.Check(knownHosts.Addresses(), DeepEquals, []string{"10.0.0.1:8443", "example.com:4443"})
+	fingerprint, ok := knownHosts.Fingerprint("example.com:4443")
+	c.Check(ok, Equals, true)
+	c.Check(fingerprint, Equals, "FINGERPRINT2")
+
+	c.Check(knownHosts.Remove("example.com:4443"), Equals, true)
+	c.Check(knownHosts.Remove("example.com:4443"), Equals, false)
+	_, ok = knownHosts.Fingerprint("example.com:4443")
+	c.Check(ok, Equals, false)
+}
+
+func (s *trustSuite) TestLoadKnownHostsComments(c *C) {
+	path := filepath.Join(c.MkDir(), "known-hosts")
+	err := os.WriteFile(path, []byte("# Trusted servers\n\nexample.com:4443 FINGERPRINT\n"), 0o600)
+	c.Assert(err, IsNil)
+	knownHosts, err := client.LoadKnownHosts(path)
+	c.Assert(err, IsNil)
+	c.Check(knownHosts.Addresses(), DeepEquals, []string{"example.com:4443"})
+
+	err = os.WriteFile(path, []byte("example.com:4443\n"), 0o600)
+	c.Assert(err, IsNil)
+	_, err = client.LoadKnownHosts(path)
+	c.Check(err, ErrorMatches, `cannot parse known hosts ".*" line 1: expected address and fingerprint`)
+}
+
+func (s *trustSuite) TestVerifier(c *C) {
+	key, err := idkey.Generate(filepath.Join(c.MkDir(), "identity"))
+	c.Assert(err, IsNil)
+	state := connectionState(serverChain(c, key))
+
+	knownHosts, err := client.LoadKnownHosts(filepath.Join(c.MkDir(), "known-hosts"))
+	c.Assert(err, IsNil)
+	knownHosts.Add("trusted:4443", key.Fingerprint())
+	knownHosts.Add("changed:4443", "OLDFINGERPRINT")
+
+	c.Check(knownHosts.Verifier("trusted:4443")(state), IsNil)
+
+	err = knownHosts.Verifier("unknown:4443")(state)
+	var unknownErr *client.UnknownServerError
+	c.Assert(errors.As(err, &unknownErr), Equals, true)
+	c.Check(unknownErr.Address, Equals, "unknown:4443")
+	c.Check(unknownErr.Fingerprint, Equals, key.Fingerprint())
+	c.Check(err, ErrorMatches, "server unknown:4443 is not trusted \\(fingerprint "+key.Fingerprint()+"\\)")
+
+	err = knownHosts.Verifier("changed:4443")(state)
+	var mismatchErr *client.FingerprintMismatchError
+	c.Assert(errors.As(err, &mismatchErr), Equals, true)
+	c.Check(mismatchErr.Expected, Equals, "OLDFINGERPRINT")
+	c.Check(mismatchErr.Actual, Equals, key.Fingerprint())
+	c.Check(err, ErrorMatches, "server changed:4443 fingerprint "+key.Fingerprint()+" does not match trusted fingerprint OLDFINGERPRINT")
+}
+
+func (s *trustSuite) TestFingerprintVerifier(c *C) {
+	key, err := idkey.Generate(filepath.Join(c.MkDir(), "identity"))
+	c.Assert(err, IsNil)
+	state := connectionState(serverChain(c, key))
+
+	c.Check(client.FingerprintVerifier("example.com:4443", key.Fingerprint())(state), IsNil)
+
+	err = client.FingerprintVerifier("example.com:4443", "OTHER")(state)
+	var mismatchErr *client.FingerprintMismatchError
+	c.Assert(errors.As(err, &mismatchErr), Equals, true)
+	c.Check(mismatchErr.Address, Equals, "example.com:4443")
+	c.Check(mismatchErr.Expected, Equals, "OTHER")
+}
+
+func (s *trustSuite) TestServerAddress(c *C) {
+	tests := []struct {
+		input   string
+		address string
+		err     string
+	}{
+		{"https://example.com:4443", "example.com:4443", ""},
+		{"https://example.com:4443/", "example.com:4443", ""},
+		{"example.com:4443", "example.com:4443", ""},
+		{"https://[::1]:4443", "[::1]:4443", ""},
+		{"http://example.com:4000", "", `cannot use "http" scheme for trusted server, must be https`},
+		{"https://", "", `cannot parse server address "https://": missing host`},
+	}
+	for _, test := range tests {
+		address, err := client.ServerAddress(test.input)
+		if test.err != "" {
+			c.Check(err, ErrorMatches, test.err, Commentf("input %q", test.input))
+			continue
+		}
+		c.Check(err, IsNil, Commentf("input %q", test.input))
+		c.Check(address, Equals, test.address, Commentf("input %q", test.input))
+	}
+}
+
+func (s *trustSuite) TestKnownHostsFileConfig(c *C) {
+	key, err := idkey.Generate(filepath.Join(c.MkDir(), "identity"))
+	c.Assert(err, IsNil)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type": "sync", "result": {"version": "1.0"}}`)
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{*serverChain(c, key)}}
+	server.StartTLS()
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "https://")
+
+	path := filepath.Join(c.MkDir(), "known-hosts")
+	newClient := func() *client.Client {
+		cli, err := client.New(&client.Config{BaseURL: server.URL, KnownHostsFile: path})
+		c.Assert(err, IsNil)
+		return cli
+	}
+
+	// Not yet trusted.
+	_, err = newClient().SysInfo()
+	var unknownErr *client.UnknownServerError
+	c.Assert(errors.As(err, &unknownErr), Equals, true)
+	c.Check(unknownErr.Fingerprint, Equals, key.Fingerprint())
+
+	// Trusted with the right fingerprint.
+	knownHosts, err := client.LoadKnownHosts(path)
+	c.Assert(err, IsNil)
+	knownHosts.Add(address, key.Fingerprint())
+	c.Assert(knownHosts.Save(), IsNil)
+	info, err := newClient().SysInfo()
+	c.Assert(err, IsNil)
+	c.Check(info.Version, Equals, "1.0")
+
+	// Trusted with a different fingerprint.
+	knownHosts.Add(address, "OTHER")
+	c.Assert(knownHosts.Save(), IsNil)
+	_, err = newClient().SysInfo()
+	var mismatchErr *client.FingerprintMismatchError
+	c.Check(errors.As(err, &mismatchErr), Equals, true)
+}
+
+func (s *trustSuite) TestRootCAsConfig(c *C) {
+	// The test server's certificate is signed by a certificate authority,
+	// like a certificate supplied by the user, so it has no fingerprint.
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type": "sync", "result": {"version": "1.0"}}`)
+	}))
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	cli, err := client.New(&client.Config{
+		BaseURL:        server.URL,
+		RootCAs:        roots,
+		KnownHostsFile: filepath.Join(c.MkDir(), "known-hosts"),
+	})
+	c.Assert(err, IsNil)
+	info, err := cli.SysInfo()
+	c.Assert(err, IsNil)
+	c.Check(info.Version, Equals, "1.0")
+
+	// Signed by an unknown certificate authority.
+	cli, err = client.New(&client.Config{BaseURL: server.URL, RootCAs: x509.NewCertPool()})
+	c.Assert(err, IsNil)
+	_, err = cli.SysInfo()
+	var verifyErr *tls.CertificateVerificationError
+	c.Check(errors.As(err, &verifyErr), Equals, true)
+	c.Check(err, ErrorMatches, ".*certificate signed by unknown authority.*")
+}
+
+func (s *trustSuite) TestCAVerifier(c *C) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{server.Certificate()}}
+
+	c.Check(client.CAVerifier("127.0.0.1", roots)(state), IsNil)
+	err := client.CAVerifier("other.test", roots)(state)
+	c.Check(err, ErrorMatches, ".*certificate is valid for .*, not other.test")
+	err = client.CAVerifier("127.0.0.1", roots)(tls.ConnectionState{})
+	c.Check(err, ErrorMatches, "server did not present a certificate")
+}
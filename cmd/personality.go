@@ -25,3 +25,6 @@ var DefaultDir string = "/var/lib/pebble/default"
 
 // StateFile is the file name of the state file in pebble dir.
 var StateFile string = ".pebble.state"
+
+// AuditFile is the file name of the audit log in pebble dir.
+var AuditFile string = ".pebble.audit"
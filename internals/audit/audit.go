@@ -0,0 +1,353 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package audit implements an append-only log of security-relevant
+// events, such as authentication attempts and changes to the plan.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Event is the kind of action an audit record describes.
+type Event string
+
+const (
+	EventAuthenticate     Event = "authenticate"
+	EventAuthorize        Event = "authorize"
+	EventChangeIdentities Event = "change-identities"
+	EventAddLayer         Event = "add-layer"
+	EventExec             Event = "exec"
+	EventWriteFile        Event = "write-file"
+)
+
+// Outcome is the result of the action an audit record describes.
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Record is a single entry in the audit log.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Event     Event     `json:"event"`
+	User      string    `json:"user"`
+	Transport string    `json:"transport,omitempty"`
+	Outcome   Outcome   `json:"outcome"`
+	Message   string    `json:"message,omitempty"`
+}
+
+const (
+	defaultMaxSize    = 10 * 1024 * 1024
+	defaultMaxBackups = 3
+
+	// maxMemoryRecords is the number of records kept by an in-memory log.
+	maxMemoryRecords = 1000
+)
+
+// Options holds the rotation settings for a Log.
+type Options struct {
+	// MaxSize is the size in bytes at which the log file is rotated.
+	// Defaults to 10MiB if zero.
+	MaxSize int64
+
+	// MaxBackups is the number of rotated log files to keep. Defaults to
+	// 3 if zero; a negative value means no rotated files are kept.
+	MaxBackups int
+}
+
+// Log is an audit log, written as JSON lines to a file that is rotated
+// when it reaches a maximum size. It is safe for concurrent use.
+type Log struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+	records    []Record // only used for in-memory logs
+	now        func() time.Time
+}
+
+// Open opens the audit log at path, creating it if necessary. If path is
+// empty, records are kept in memory only, and just the most recent ones
+// are retained.
+func Open(path string, opts *Options) (*Log, error) {
+	l := &Log{
+		path:       path,
+		maxSize:    defaultMaxSize,
+		maxBackups: defaultMaxBackups,
+		now:        time.Now,
+	}
+	if opts != nil {
+		if opts.MaxSize > 0 {
+			l.maxSize = opts.MaxSize
+		}
+		if opts.MaxBackups > 0 {
+			l.maxBackups = opts.MaxBackups
+		} else if opts.MaxBackups < 0 {
+			l.maxBackups = 0
+		}
+	}
+	if path == "" {
+		return l, nil
+	}
+	if err := l.openFile(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) openFile() error {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("cannot open audit log: %w", err)
+	}
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// Add appends a record to the log. If the record's time is zero, it is
+// set to the current time.
+func (l *Log) Add(record Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if record.Time.IsZero() {
+		record.Time = l.now()
+	}
+	record.Time = record.Time.UTC()
+
+	if l.path == "" {
+		l.records = append(l.records, record)
+		if len(l.records) > maxMemoryRecords {
+			l.records = slices.Clone(l.records[len(l.records)-maxMemoryRecords:])
+		}
+		return nil
+	}
+	if l.file == nil {
+		return fmt.Errorf("cannot write to closed audit log")
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("cannot marshal audit record: %w", err)
+	}
+	line = append(line, '\n')
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("cannot write audit record: %w", err)
+	}
+	return nil
+}
+
+// rotate moves the current log file to the first backup, shifting older
+// backups along and dropping the oldest, then opens a new current file.
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("cannot close audit log: %w", err)
+	}
+	l.file = nil
+	if l.maxBackups == 0 {
+		if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot rotate audit log: %w", err)
+		}
+	} else {
+		for i := l.maxBackups - 1; i >= 0; i-- {
+			err := os.Rename(l.backupPath(i), l.backupPath(i+1))
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("cannot rotate audit log: %w", err)
+			}
+		}
+	}
+	return l.openFile()
+}
+
+// backupPath returns the path of the nth backup, or of the current log
+// file if n is zero.
+func (l *Log) backupPath(n int) string {
+	if n == 0 {
+		return l.path
+	}
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
+
+// Filter selects which records are returned by Records.
+type Filter struct {
+	// Events, if non-empty, restricts records to these events.
+	Events []Event
+
+	// Users, if non-empty, restricts records to these users.
+	Users []string
+
+	// Outcome, if set, restricts records to this outcome.
+	Outcome Outcome
+
+	// After, if set, restricts records to those after this time.
+	After time.Time
+
+	// Last, if positive, limits the result to the most recent Last records.
+	Last int
+}
+
+func (f *Filter) matches(r *Record) bool {
+	if len(f.Events) > 0 && !slices.Contains(f.Events, r.Event) {
+		return false
+	}
+	if len(f.Users) > 0 && !slices.Contains(f.Users, r.User) {
+		return false
+	}
+	if f.Outcome != "" && r.Outcome != f.Outcome {
+		return false
+	}
+	if !f.After.IsZero() && !r.Time.After(f.After) {
+		return false
+	}
+	return true
+}
+
+// Records returns the records that match the filter, oldest first,
+// including those in rotated log files. Lines that cannot be decoded are
+// skipped.
+func (l *Log) Records(filter Filter) ([]Record, error) {
+	l.mu.Lock()
+	if l.path == "" {
+		var records []Record
+		for _, r := range l.records {
+			if filter.matches(&r) {
+				records = append(records, r)
+			}
+		}
+		l.mu.Unlock()
+		return lastRecords(records, filter.Last), nil
+	}
+
+	// Open the files while holding the lock, so that they're consistent
+	// even if the log is rotated, but read them after releasing it, so
+	// that reading doesn't block adding records.
+	files, err := l.openBackups()
+	size := l.size
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, f := range files {
+			if f != nil {
+				f.Close()
+			}
+		}
+	}()
+
+	var records []Record
+	for i, f := range files {
+		if f == nil {
+			continue
+		}
+		var r io.Reader = f
+		if i == len(files)-1 {
+			// Ignore records added to the current file after it was opened.
+			r = io.LimitReader(f, size)
+		}
+		records, err = readRecords(r, &filter, records)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lastRecords(records, filter.Last), nil
+}
+
+// openBackups opens the backup files, oldest first, followed by the current
+// log file. Files that don't exist are nil.
+func (l *Log) openBackups() ([]*os.File, error) {
+	var files []*os.File
+	for i := l.maxBackups; i >= 0; i-- {
+		f, err := os.Open(l.backupPath(i))
+		if os.IsNotExist(err) {
+			files = append(files, nil)
+			continue
+		}
+		if err != nil {
+			for _, f := range files {
+				if f != nil {
+					f.Close()
+				}
+			}
+			return nil, fmt.Errorf("cannot read audit log: %w", err)
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// lastRecords returns the last n records, or all records if n isn't
+// positive.
+func lastRecords(records []Record, n int) []Record {
+	if n > 0 && len(records) > n {
+		return records[len(records)-n:]
+	}
+	return records
+}
+
+func readRecords(r io.Reader, filter *Filter, records []Record) ([]Record, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		if filter.matches(&r) {
+			records = append(records, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read audit log: %w", err)
+	}
+	return records, nil
+}
+
+// Close closes the log file. Further calls to Add will fail.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package audit_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/canonical/pebble/internals/audit"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type auditSuite struct{}
+
+var _ = Suite(&auditSuite{})
+
+var baseTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func (s *auditSuite) TestAddAndRecords(c *C) {
+	path := filepath.Join(c.MkDir(), "audit")
+	l, err := audit.Open(path, nil)
+	c.Assert(err, IsNil)
+	defer l.Close()
+
+	err = l.Add(audit.Record{
+		Time:      baseTime,
+		Event:     audit.EventAuthenticate,
+		User:      "bob",
+		Transport: "https",
+		Outcome:   audit.OutcomeFailure,
+		Message:   "invalid password",
+	})
+	c.Assert(err, IsNil)
+	err = l.Add(audit.Record{Event: audit.EventExec, User: "alice", Outcome: audit.OutcomeSuccess})
+	c.Assert(err, IsNil)
+
+	st, err := os.Stat(path)
+	c.Assert(err, IsNil)
+	c.Check(st.Mode().Perm(), Equals, os.FileMode(0600))
+	data, err := os.ReadFile(path)
+	c.Assert(err, IsNil)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	c.Assert(lines, HasLen, 2)
+	c.Check(lines[0], Equals, `{"time":"2026-01-02T03:04:05Z","event":"authenticate","user":"bob","transport":"https","outcome":"failure","message":"invalid password"}`)
+
+	records, err := l.Records(audit.Filter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	c.Check(records[0].User, Equals, "bob")
+	c.Check(records[1].User, Equals, "alice")
+	c.Check(records[1].Time.IsZero(), Equals, false)
+}
+
+func (s *auditSuite) TestReopenAppends(c *C) {
+	path := filepath.Join(c.MkDir(), "audit")
+	l, err := audit.Open(path, nil)
+	c.Assert(err, IsNil)
+	c.Assert(l.Add(audit.Record{Event: audit.EventAddLayer, User: "0", Outcome: audit.OutcomeSuccess}), IsNil)
+	c.Assert(l.Close(), IsNil)
+
+	err = l.Add(audit.Record{Event: audit.EventAddLayer, User: "0", Outcome: audit.OutcomeSuccess})
+	c.Assert(err, ErrorMatches, "cannot write to closed audit log")
+
+	l, err = audit.Open(path, nil)
+	c.Assert(err, IsNil)
+	defer l.Close()
+	c.Assert(l.Add(audit.Record{Event: audit.EventExec, User: "0", Outcome: audit.OutcomeSuccess}), IsNil)
+
+	records, err := l.Records(audit.Filter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	c.Check(records[0].Event, Equals, audit.EventAddLayer)
+	c.Check(records[1].Event, Equals, audit.EventExec)
+}
+
+func (s *auditSuite) TestRotation(c *C) {
+	path := filepath.Join(c.MkDir(), "audit")
+	l, err := audit.Open(path, &audit.Options{MaxSize: 200, MaxBackups: 2})
+	c.Assert(err, IsNil)
+	defer l.Close()
+
+	for i := 0; i < 20; i++ {
+		err := l.Add(audit.Record{
+			Time:    baseTime.Add(time.Duration(i) * time.Second),
+			Event:   audit.EventWriteFile,
+			User:    "0",
+			Outcome: audit.OutcomeSuccess,
+			Message: fmt.Sprintf("file %d", i),
+		})
+		c.Assert(err, IsNil)
+	}
+
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		st, err := os.Stat(p)
+		c.Assert(err, IsNil)
+		c.Check(st.Size() <= 200, Equals, true)
+	}
+	_, err = os.Stat(path + ".3")
+	c.Check(os.IsNotExist(err), Equals, true)
+
+	// Records are returned oldest first across the rotated files, and the
+	// most recent one is always present.
+	records, err := l.Records(audit.Filter{})
+	c.Assert(err, IsNil)
+	c.Assert(len(records) > 0 && len(records) < 20, Equals, true)
+	for i := 1; i < len(records); i++ {
+		c.Check(records[i].Time.After(records[i-1].Time), Equals, true)
+	}
+	c.Check(records[len(records)-1].Message, Equals, "file 19")
+}
+
+func (s *auditSuite) TestRecordsWhileRotating(c *C) {
+	path := filepath.Join(c.MkDir(), "audit")
+	l, err := audit.Open(path, &audit.Options{MaxSize: 500, MaxBackups: 2})
+	c.Assert(err, IsNil)
+	defer l.Close()
+
+	done := make(chan error)
+	go func() {
+		for i := 0; i < 500; i++ {
+			err := l.Add(audit.Record{
+				Time:    baseTime.Add(time.Duration(i) * time.Second),
+				Event:   audit.EventWriteFile,
+				User:    "0",
+				Outcome: audit.OutcomeSuccess,
+				Message: fmt.Sprintf("file %d", i),
+			})
+			if err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	// Records read while the log is rotated are never duplicated or out of
+	// order.
+	for {
+		records, err := l.Records(audit.Filter{})
+		c.Assert(err, IsNil)
+		for i := 1; i < len(records); i++ {
+			c.Assert(records[i].Time.After(records[i-1].Time), Equals, true)
+		}
+		select {
+		case err := <-done:
+			c.Assert(err, IsNil)
+			return
+		default:
+		}
+	}
+}
+
+func (s *auditSuite) TestRotationNoBackups(c *C) {
+	path := filepath.Join(c.MkDir(), "audit")
+	l, err := audit.Open(path, &audit.Options{MaxSize: 200, MaxBackups: -1})
+	c.Assert(err, IsNil)
+	defer l.Close()
+
+	for i := 0; i < 10; i++ {
+		c.Assert(l.Add(audit.Record{Event: audit.EventExec, User: "0", Outcome: audit.OutcomeSuccess}), IsNil)
+	}
+	_, err = os.Stat(path + ".1")
+	c.Check(os.IsNotExist(err), Equals, true)
+	records, err := l.Records(audit.Filter{})
+	c.Assert(err, IsNil)
+	c.Check(len(records) < 10, Equals, true)
+}
+
+func (s *auditSuite) TestFilter(c *C) {
+	l, err := audit.Open("", nil)
+	c.Assert(err, IsNil)
+	defer l.Close()
+
+	add := func(offset int, event audit.Event, user string, outcome audit.Outcome) {
+		err := l.Add(audit.Record{
+			Time:    baseTime.Add(time.Duration(offset) * time.Minute),
+			Event:   event,
+			User:    user,
+			Outcome: outcome,
+		})
+		c.Assert(err, IsNil)
+	}
+	add(0, audit.EventAuthenticate, "bob", audit.OutcomeFailure)
+	add(1, audit.EventAuthenticate, "alice", audit.OutcomeSuccess)
+	add(2, audit.EventExec, "alice", audit.OutcomeSuccess)
+	add(3, audit.EventAuthorize, "bob", audit.OutcomeFailure)
+	add(4, audit.EventAddLayer, "alice", audit.OutcomeSuccess)
+
+	users := func(records []audit.Record) []string {
+		var result []string
+		for _, r := range records {
+			result = append(result, fmt.Sprintf("%s/%s", r.Event, r.User))
+		}
+		return result
+	}
+
+	tests := []struct {
+		filter   audit.Filter
+		expected []string
+	}{{
+		filter:   audit.Filter{},
+		expected: []string{"authenticate/bob", "authenticate/alice", "exec/alice", "authorize/bob", "add-layer/alice"},
+	}, {
+		filter:   audit.Filter{Events: []audit.Event{audit.EventAuthenticate, audit.EventAuthorize}},
+		expected: []string{"authenticate/bob", "authenticate/alice", "authorize/bob"},
+	}, {
+		filter:   audit.Filter{Users: []string{"bob"}},
+		expected: []string{"authenticate/bob", "authorize/bob"},
+	}, {
+		filter:   audit.Filter{Outcome: audit.OutcomeSuccess},
+		expected: []string{"authenticate/alice", "exec/alice", "add-layer/alice"},
+	}, {
+		filter:   audit.Filter{After: baseTime.Add(2 * time.Minute)},
+		expected: []string{"authorize/bob", "add-layer/alice"},
+	}, {
+		filter:   audit.Filter{Last: 2},
+		expected: []string{"authorize/bob", "add-layer/alice"},
+	}, {
+		filter:   audit.Filter{Users: []string{"alice"}, Last: 1},
+		expected: []string{"add-layer/alice"},
+	}, {
+		filter:   audit.Filter{Users: []string{"carol"}},
+		expected: nil,
+	}}
+	for _, test := range tests {
+		records, err := l.Records(test.filter)
+		c.Assert(err, IsNil)
+		c.Check(users(records), DeepEquals, test.expected, Commentf("filter %+v", test.filter))
+	}
+}
+
+func (s *auditSuite) TestSkipsMalformedLines(c *C) {
+	path := filepath.Join(c.MkDir(), "audit")
+	err := os.WriteFile(path, []byte("not json\n"+`{"event":"exec","user":"0","outcome":"success"}`+"\n"), 0600)
+	c.Assert(err, IsNil)
+
+	l, err := audit.Open(path, nil)
+	c.Assert(err, IsNil)
+	defer l.Close()
+	records, err := l.Records(audit.Filter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Check(records[0].Event, Equals, audit.EventExec)
+}
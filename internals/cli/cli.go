@@ -93,6 +93,10 @@ type CmdInfo struct {
 
 	// When set, the command will be a subcommand of the `debug` command.
 	Debug bool
+
+	// When set, the command will be a subcommand of the named command,
+	// which must be added before it.
+	Parent string
 }
 
 // commands holds information about all the regular Pebble commands.
@@ -147,6 +151,9 @@ func fixupArg(optName string) string {
 
 type defaultOptions struct {
 	Version func() `long:"version" hidden:"yes" description:"Print the version and exit"`
+	// Context is applied before parsing (see contextArg), but is declared
+	// here so that it's accepted with any command.
+	Context string `long:"context" hidden:"yes" description:"Use the named context"`
 }
 
 type ParserOptions struct {
@@ -166,9 +173,12 @@ func Parser(opts *ParserOptions) *flags.Parser {
 			panic(&exitStatus{0})
 		},
 	}
+	return newParser(opts, &defaultOpts)
+}
 
+func newParser(opts *ParserOptions, defaultOpts *defaultOptions) *flags.Parser {
 	flagOpts := flags.Options(flags.PassDoubleDash)
-	parser := flags.NewParser(&defaultOpts, flagOpts)
+	parser := flags.NewParser(defaultOpts, flagOpts)
 	parser.Command.Name = cmd.ProgramName
 	parser.ShortDescription = "System and service manager"
 	parser.LongDescription = applyPersonality(HelpHeader)
@@ -197,9 +207,15 @@ func Parser(opts *ParserOptions) *flags.Parser {
 		})
 
 		var target *flags.Command
-		if c.Debug {
+		switch {
+		case c.Debug:
 			target = debugCmd
-		} else {
+		case c.Parent != "":
+			target = parser.Find(c.Parent)
+			if target == nil {
+				logger.Panicf("internal error: cannot find parent command %q of %q", c.Parent, c.Name)
+			}
+		default:
 			target = parser.Command
 		}
 		cmd, err := target.AddCommand(c.Name, applyPersonality(c.Summary), applyPersonality(strings.TrimSpace(c.Description)), obj)
@@ -207,6 +223,10 @@ func Parser(opts *ParserOptions) *flags.Parser {
 			logger.Panicf("internal error: cannot add command %q: %v", c.Name, err)
 		}
 		cmd.PassAfterNonOption = c.PassAfterNonOption
+		if c.Parent != "" {
+			// Let the parent command run on its own too.
+			target.SubcommandsOptional = true
+		}
 
 		// Extract help for flags and positional arguments from ArgsHelp
 		flagHelp := map[string]string{}
@@ -320,10 +340,17 @@ func withDefaultRunOptions(opts *RunOptions) *RunOptions {
 	if localOpts.ClientConfig.BaseURL == "" {
 		localOpts.ClientConfig.BaseURL = os.Getenv("PEBBLE_BASEURL")
 	}
+	if localOpts.ClientConfig.VerifyTLSConnection == nil && localOpts.ClientConfig.KnownHostsFile == "" {
+		localOpts.ClientConfig.KnownHostsFile = knownHostsPath()
+	}
 	return &localOpts
 }
 
 func Run(options *RunOptions) error {
+	options, err := withContext(options, contextArg(os.Args[1:]))
+	if err != nil {
+		return err
+	}
 	localOptions := withDefaultRunOptions(options)
 
 	logger.SetLogger(localOptions.Logger)
@@ -338,6 +365,14 @@ func Run(options *RunOptions) error {
 		}
 	}()
 
+	if caCert := os.Getenv("PEBBLE_CA_CERT"); caCert != "" && localOptions.ClientConfig.RootCAs == nil {
+		roots, err := loadRootCAs(caCert)
+		if err != nil {
+			return err
+		}
+		localOptions.ClientConfig.RootCAs = roots
+	}
+
 	cli, err := client.New(localOptions.ClientConfig)
 	if err != nil {
 		return fmt.Errorf("cannot create client: %v", err)
@@ -391,6 +426,22 @@ func Run(options *RunOptions) error {
 var errorPrefix = "error: "
 
 func errorToMessage(e error) (normalMessage string, err error) {
+	var unknownErr *client.UnknownServerError
+	if errors.As(e, &unknownErr) {
+		return "", fmt.Errorf("%v\nVerify the fingerprint with the server's administrator, then run '%s trust %s'",
+			unknownErr, cmd.ProgramName, unknownErr.Address)
+	}
+	var contextErr *contextFingerprintError
+	if errors.As(e, &contextErr) {
+		return "", fmt.Errorf("%v\nThe server's identity has changed, which may mean the connection is being intercepted.\nIf the change is expected, remove context %q and add it again with the new fingerprint",
+			contextErr, contextErr.context)
+	}
+	var mismatchErr *client.FingerprintMismatchError
+	if errors.As(e, &mismatchErr) {
+		return "", fmt.Errorf("%v\nThe server's identity has changed, which may mean the connection is being intercepted.\nIf the change is expected, run '%s trust --replace %s'",
+			mismatchErr, cmd.ProgramName, mismatchErr.Address)
+	}
+
 	cerr, ok := e.(*client.Error)
 	if !ok {
 		return "", e
@@ -503,9 +554,19 @@ func saveCLIState(socketPath string, state *cliState) error {
 }
 
 func cliStatePath() string {
+	return filepath.Join(cliConfigDir(), "cli.json")
+}
+
+// knownHostsPath returns the path of the file holding the fingerprints of
+// trusted remote servers.
+func knownHostsPath() string {
+	return filepath.Join(cliConfigDir(), "known-hosts")
+}
+
+func cliConfigDir() string {
 	configDir := os.Getenv("XDG_CONFIG_HOME")
 	if configDir == "" {
 		configDir = os.ExpandEnv("$HOME/.config")
 	}
-	return filepath.Join(configDir, "pebble", "cli.json")
+	return filepath.Join(configDir, "pebble")
 }
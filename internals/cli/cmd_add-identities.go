@@ -15,9 +15,14 @@
 package cli
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 
 	"github.com/canonical/go-flags"
@@ -49,6 +54,44 @@ To add an identity named "alice" with metrics access using HTTP basic authentica
 >             password: <password hash>
 
 Use "openssl passwd -6" to generate a hashed password (sha512-crypt format).
+
+To add an identity named "ci" with read access using a bearer token, leave
+the token hash empty to have a random token generated:
+
+> identities:
+>     ci:
+>         access: read
+>         token: {}
+
+The generated token is printed once, and only its hash is stored, so save it
+somewhere safe. Clients present it with an "Authorization: Bearer <token>"
+header. To use your own token, set "hash" to its SHA-256 hash (hex-encoded).
+
+To add an identity named "controller" with admin access using a TLS client
+certificate (only used over HTTPS), include the PEM-encoded certificate:
+
+> identities:
+>     controller:
+>         access: admin
+>         cert:
+>             pem: |
+>                 -----BEGIN CERTIFICATE-----
+>                 ...
+>                 -----END CERTIFICATE-----
+
+To limit an identity to specific actions, add a list of permissions. For
+example, to let a local user manage only the "web" service and read its logs:
+
+> identities:
+>     deployer:
+>         access: read
+>         local:
+>             user-id: 1001
+>         permissions:
+>             - action: manage-services
+>               resources: [web]
+>             - action: read-logs
+>               resources: [web]
 `
 
 type cmdAddIdentities struct {
@@ -80,15 +123,55 @@ func (cmd *cmdAddIdentities) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
+	tokens, err := generateTokens(identities)
+	if err != nil {
+		return err
+	}
 	err = cmd.client.AddIdentities(identities)
 	if err != nil {
 		return err
 	}
 
 	fmt.Fprintf(Stdout, "Added %s.\n", numItems(len(identities), "new identity", "new identities"))
+	if len(tokens) > 0 {
+		names := make([]string, 0, len(tokens))
+		for name := range tokens {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintln(Stdout, "Generated tokens (these will not be shown again):")
+		for _, name := range names {
+			fmt.Fprintf(Stdout, "%s: %s\n", name, tokens[name])
+		}
+	}
 	return nil
 }
 
+// tokenSize is the number of random bytes in a generated token.
+const tokenSize = 32
+
+// generateTokens generates a random token for each "token" type identity
+// without a hash, setting the hash of the token in the identity. It returns
+// a map of identity name to the generated token.
+func generateTokens(identities map[string]*client.Identity) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for name, identity := range identities {
+		if identity == nil || identity.Token == nil || identity.Token.Hash != "" {
+			continue
+		}
+		b := make([]byte, tokenSize)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate token: %w", err)
+		}
+		token := base64.RawURLEncoding.EncodeToString(b)
+		sum := sha256.Sum256([]byte(token))
+		identity.Token.Hash = hex.EncodeToString(sum[:])
+		tokens[name] = token
+	}
+	return tokens, nil
+}
+
 func readIdentities(path string) (map[string]*client.Identity, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -15,12 +15,15 @@
 package cli_test
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	. "gopkg.in/check.v1"
 
@@ -104,6 +107,54 @@ identities:
 	c.Check(s.Stderr(), Equals, "")
 }
 
+func (s *PebbleSuite) TestAddIdentitiesToken(c *C) {
+	var hash string
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Identities map[string]struct {
+				Token struct {
+					Hash string `json:"hash"`
+				} `json:"token"`
+			} `json:"identities"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		c.Assert(err, IsNil)
+		hash = payload.Identities["ci"].Token.Hash
+		c.Check(payload.Identities["own"].Token.Hash, Equals, "0123")
+		fmt.Fprint(w, `{
+			"type": "sync",
+			"status-code": 200,
+			"result": null
+		}`)
+	})
+
+	path := filepath.Join(c.MkDir(), "identities.yaml")
+	data := `
+identities:
+    ci:
+        access: read
+        token: {}
+    own:
+        access: read
+        token: {hash: "0123"}
+`
+	err := os.WriteFile(path, []byte(data), 0o666)
+	c.Assert(err, IsNil)
+
+	rest, err := cli.ParserForTest().ParseArgs([]string{"add-identities", "--from", path})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stderr(), Equals, "")
+
+	matches := regexp.MustCompile(`^Added 2 new identities.
+Generated tokens \(these will not be shown again\):
+ci: ([A-Za-z0-9_-]{43})
+$`).FindStringSubmatch(s.Stdout())
+	c.Assert(matches, HasLen, 2, Commentf("%s", s.Stdout()))
+	sum := sha256.Sum256([]byte(matches[1]))
+	c.Check(hash, Equals, hex.EncodeToString(sum[:]))
+}
+
 func (s *PebbleSuite) TestAddIdentitiesUnmarshalError(c *C) {
 	path := filepath.Join(c.MkDir(), "identities.yaml")
 	err := os.WriteFile(path, []byte("}not yaml{"), 0o666)
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/canonical/go-flags"
+
+	"github.com/canonical/pebble/client"
+)
+
+const cmdAuditSummary = "Show the security audit log"
+const cmdAuditDescription = `
+The audit command shows records from the security audit log, oldest first.
+The log records authentication attempts, authorization failures, identity
+changes, layer additions, exec invocations, and file writes.
+
+Only admin users may view the audit log.
+`
+
+type cmdAudit struct {
+	client *client.Client
+
+	timeMixin
+	Event   []client.AuditEvent `long:"event"`
+	User    []string            `long:"user"`
+	Outcome client.AuditOutcome `long:"outcome" choice:"success" choice:"failure"`
+	N       int                 `short:"n"`
+}
+
+func init() {
+	AddCommand(&CmdInfo{
+		Name:        "audit",
+		Summary:     cmdAuditSummary,
+		Description: cmdAuditDescription,
+		ArgsHelp: merge(timeArgsHelp, map[string]string{
+			"--event":   "Only show records of this event type (multiple allowed)",
+			"--user":    "Only show records for this user (multiple allowed)",
+			"--outcome": `Only show records with this outcome: "success" or "failure"`,
+			"-n":        "Number of most recent records to show",
+		}),
+		New: func(opts *CmdOptions) flags.Commander {
+			return &cmdAudit{client: opts.Client}
+		},
+	})
+}
+
+func (cmd *cmdAudit) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	if cmd.N < 0 {
+		return fmt.Errorf("expected n to be a non-negative integer, not %d", cmd.N)
+	}
+
+	records, err := cmd.client.Audit(&client.AuditOptions{
+		Events:  cmd.Event,
+		Users:   cmd.User,
+		Outcome: cmd.Outcome,
+		N:       cmd.N,
+	})
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Fprintln(Stderr, "No matching audit records.")
+		return nil
+	}
+
+	writer := tabWriter()
+	defer writer.Flush()
+
+	fmt.Fprintln(writer, "Time\tEvent\tUser\tTransport\tOutcome\tMessage")
+	for _, r := range records {
+		transport := r.Transport
+		if transport == "" {
+			transport = "-"
+		}
+		message := r.Message
+		if message == "" {
+			message = "-"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			cmd.fmtTime(r.Time), r.Event, r.User, transport, r.Outcome, message)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/canonical/pebble/internals/cli"
+)
+
+func (s *PebbleSuite) TestAudit(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v1/audit")
+		c.Check(r.URL.Query(), DeepEquals, url.Values{
+			"events":  {"authenticate,authorize"},
+			"users":   {"bob"},
+			"outcome": {"failure"},
+			"n":       {"5"},
+		})
+		fmt.Fprint(w, `{
+			"type": "sync",
+			"status-code": 200,
+			"result": [{
+				"time": "2026-01-02T03:04:05Z",
+				"event": "authenticate",
+				"user": "bob",
+				"transport": "https",
+				"outcome": "failure",
+				"message": "Invalid basic credentials"
+			}, {
+				"time": "2026-01-02T03:05:05Z",
+				"event": "authorize",
+				"user": "bob",
+				"outcome": "failure"
+			}]
+		}`)
+	})
+
+	rest, err := cli.ParserForTest().ParseArgs([]string{
+		"audit", "--abs-time", "--event", "authenticate", "--event", "authorize",
+		"--user", "bob", "--outcome", "failure", "-n", "5",
+	})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, `
+Time                  Event         User  Transport  Outcome  Message
+2026-01-02T03:04:05Z  authenticate  bob   https      failure  Invalid basic credentials
+2026-01-02T03:05:05Z  authorize     bob   -          failure  -
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *PebbleSuite) TestAuditNoRecords(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v1/audit")
+		c.Check(r.URL.Query(), DeepEquals, url.Values{})
+		fmt.Fprint(w, `{"type": "sync", "status-code": 200, "result": []}`)
+	})
+
+	rest, err := cli.ParserForTest().ParseArgs([]string{"audit"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(s.Stderr(), Equals, "No matching audit records.\n")
+}
+
+func (s *PebbleSuite) TestAuditErrors(c *C) {
+	_, err := cli.ParserForTest().ParseArgs([]string{"audit", "extra"})
+	c.Check(err, Equals, cli.ErrExtraArgs)
+
+	_, err = cli.ParserForTest().ParseArgs([]string{"audit", "-n", "-1"})
+	c.Check(err, ErrorMatches, "expected n to be a non-negative integer, not -1")
+
+	_, err = cli.ParserForTest().ParseArgs([]string{"audit", "--outcome", "maybe"})
+	c.Check(err, ErrorMatches, ".*Invalid value `maybe' for option `--outcome'.*")
+}
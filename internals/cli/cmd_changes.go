@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"time"
 
 	"github.com/canonical/go-flags"
 
@@ -28,12 +29,17 @@ import (
 const cmdChangesSummary = "List system changes"
 const cmdChangesDescription = `
 The changes command displays a summary of system changes performed recently.
+
+The --since option accepts either a timestamp in RFC 3339 format, such as
+2024-01-02T15:04:05Z, or a duration relative to now, such as 24h.
 `
 
 type cmdChanges struct {
 	client *client.Client
 
 	timeMixin
+	Kind       []string `long:"kind"`
+	Since      string   `long:"since"`
 	Positional struct {
 		Service string `positional-arg-name:"<service>"`
 	} `positional-args:"yes"`
@@ -57,7 +63,10 @@ func init() {
 		Name:        "changes",
 		Summary:     cmdChangesSummary,
 		Description: cmdChangesDescription,
-		ArgsHelp:    timeArgsHelp,
+		ArgsHelp: merge(timeArgsHelp, map[string]string{
+			"--kind":  "Only list changes of this kind (multiple allowed)",
+			"--since": "Only list changes spawned at or after this time or duration ago",
+		}),
 		New: func(opts *CmdOptions) flags.Commander {
 			return &cmdChanges{client: opts.Client}
 		},
@@ -81,6 +90,27 @@ func (s changesByTime) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 
 var allDigits = regexp.MustCompile(`^[0-9]+$`).MatchString
 
+var timeNow = time.Now
+
+// parseSince parses the --since option, which is either an RFC 3339
+// timestamp or a duration before now. It returns the zero time if s is empty.
+func parseSince(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid --since duration %q: must not be negative", s)
+		}
+		return timeNow().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since value %q: must be a timestamp or duration", s)
+	}
+	return t, nil
+}
+
 func queryChanges(cli *client.Client, opts *client.ChangesOptions) ([]*client.Change, error) {
 	chgs, err := cli.Changes(opts)
 	if err != nil {
@@ -106,9 +136,16 @@ func (c *cmdChanges) Execute(args []string) error {
 		return nil
 	}
 
+	since, err := parseSince(c.Since)
+	if err != nil {
+		return err
+	}
+
 	opts := client.ChangesOptions{
 		ServiceName: c.Positional.Service,
 		Selector:    client.ChangesAll,
+		Kinds:       c.Kind,
+		Since:       since,
 	}
 
 	changes, err := queryChanges(c.client, &opts)
@@ -24,6 +24,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -101,6 +102,51 @@ two +Do +2016-04-21 +2016-04-2[12] +...
 	c.Check(s.Stderr(), check.Equals, "")
 }
 
+func (s *PebbleSuite) TestChangesFilters(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v1/changes")
+		c.Check(r.URL.Query(), check.DeepEquals, url.Values{
+			"select": {"all"},
+			"for":    {"svc1"},
+			"kinds":  {"start,stop"},
+			"since":  {"2024-01-02T03:04:05Z"},
+		})
+		fmt.Fprintln(w, fakeChangesJSON)
+	})
+
+	rest, err := cli.ParserForTest().ParseArgs([]string{
+		"changes", "--kind", "start", "--kind", "stop", "--since", "2024-01-02T03:04:05Z", "svc1"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *PebbleSuite) TestChangesSinceDuration(c *check.C) {
+	restore := cli.FakeTimeNow(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	defer restore()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Query(), check.DeepEquals, url.Values{
+			"select": {"all"},
+			"since":  {"2024-01-01T03:04:05Z"},
+		})
+		fmt.Fprintln(w, fakeChangesJSON)
+	})
+
+	rest, err := cli.ParserForTest().ParseArgs([]string{"changes", "--since", "24h"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+}
+
+func (s *PebbleSuite) TestChangesSinceInvalid(c *check.C) {
+	_, err := cli.ParserForTest().ParseArgs([]string{"changes", "--since", "yesterday"})
+	c.Assert(err, check.ErrorMatches, `invalid --since value "yesterday": must be a timestamp or duration`)
+
+	_, err = cli.ParserForTest().ParseArgs([]string{"changes", "--since=-1h"})
+	c.Assert(err, check.ErrorMatches, `invalid --since duration "-1h": must not be negative`)
+}
+
 func (s *PebbleSuite) TestChangesUnknownMaintenance(c *check.C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		c.Check(r.Method, check.Equals, "GET")
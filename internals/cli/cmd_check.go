@@ -17,6 +17,7 @@ package cli
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/canonical/go-flags"
 	"gopkg.in/yaml.v3"
@@ -33,6 +34,7 @@ type cmdCheck struct {
 	client *client.Client
 
 	Refresh bool `long:"refresh"`
+	History int  `long:"history" optional:"yes" optional-value:"10"`
 
 	Positional struct {
 		Check string `positional-arg-name:"<check>" required:"1"`
@@ -50,6 +52,15 @@ type checkInfo struct {
 	ChangeID  string `yaml:"change-id,omitempty"`
 	Error     string `yaml:"error,omitempty"`
 	Logs      string `yaml:"logs,omitempty"`
+
+	History []checkResult `yaml:"history,omitempty"`
+}
+
+type checkResult struct {
+	Time     string `yaml:"time"`
+	Duration string `yaml:"duration"`
+	Error    string `yaml:"error,omitempty"`
+	Details  string `yaml:"details,omitempty"`
 }
 
 func init() {
@@ -59,6 +70,7 @@ func init() {
 		Description: cmdCheckDescription,
 		ArgsHelp: map[string]string{
 			"--refresh": "Run the check immediately",
+			"--history": "Show the last N results of the check (default 10)",
 		},
 		New: func(opts *CmdOptions) flags.Commander {
 			return &cmdCheck{client: opts.Client}
@@ -76,7 +88,21 @@ func checkInfoFromClient(check client.CheckInfo) checkInfo {
 		Failures:  check.Failures,
 		Threshold: check.Threshold,
 		ChangeID:  check.ChangeID,
+		History:   checkResultsFromClient(check.History),
+	}
+}
+
+func checkResultsFromClient(results []client.CheckResult) []checkResult {
+	var history []checkResult
+	for _, result := range results {
+		history = append(history, checkResult{
+			Time:     result.Time.Format(time.RFC3339Nano),
+			Duration: result.Duration.String(),
+			Error:    result.Error,
+			Details:  result.Details,
+		})
 	}
+	return history
 }
 
 func (cmd *cmdCheck) Execute(args []string) error {
@@ -96,9 +122,11 @@ func (cmd *cmdCheck) Execute(args []string) error {
 
 		info = checkInfoFromClient(res.Info)
 		info.Error = res.Error
-	} else {
+	}
+	if !cmd.Refresh || cmd.History > 0 {
 		opts := client.ChecksOptions{
-			Names: []string{cmd.Positional.Check},
+			Names:   []string{cmd.Positional.Check},
+			History: cmd.History,
 		}
 		checks, err := cmd.client.Checks(&opts)
 		if err != nil {
@@ -107,7 +135,11 @@ func (cmd *cmdCheck) Execute(args []string) error {
 		if len(checks) == 0 {
 			return fmt.Errorf("cannot find check %q", cmd.Positional.Check)
 		}
-		info = checkInfoFromClient(*checks[0])
+		if cmd.Refresh {
+			info.History = checkResultsFromClient(checks[0].History)
+		} else {
+			info = checkInfoFromClient(*checks[0])
+		}
 	}
 
 	if info.Failures > 0 || info.Error != "" {
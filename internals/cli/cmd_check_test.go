@@ -51,6 +51,59 @@ change-id: "1"
 	c.Check(s.Stderr(), Equals, "")
 }
 
+func (s *PebbleSuite) TestCheckHistory(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Assert(r.Method, Equals, "GET")
+		c.Assert(r.URL.Path, Equals, "/v1/checks")
+		c.Assert(r.URL.Query(), DeepEquals, url.Values{"names": {"chk1"}, "history": {"2"}})
+		fmt.Fprint(w, `
+{
+    "type": "sync",
+    "status-code": 200,
+    "result": [{"name": "chk1", "startup": "enabled", "status": "up", "successes": 1, "threshold": 3, "change-id": "1", "history": [
+        {"time": "2025-03-13T10:04:40Z", "duration": "2.1ms", "error": "non-2xx status code 500", "details": "oops"},
+        {"time": "2025-03-13T10:04:50Z", "duration": "1.8ms"}
+    ]}]
+}`)
+	})
+	rest, err := cli.ParserForTest().ParseArgs([]string{"check", "chk1", "--history=2"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, `
+name: chk1
+startup: enabled
+status: up
+successes: 1
+failures: 0
+threshold: 3
+change-id: "1"
+history:
+    - time: "2025-03-13T10:04:40Z"
+      duration: 2.1ms
+      error: non-2xx status code 500
+      details: oops
+    - time: "2025-03-13T10:04:50Z"
+      duration: 1.8ms
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *PebbleSuite) TestCheckHistoryDefault(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Assert(r.URL.Query(), DeepEquals, url.Values{"names": {"chk1"}, "history": {"10"}})
+		fmt.Fprint(w, `
+{
+    "type": "sync",
+    "status-code": 200,
+    "result": [{"name": "chk1", "startup": "enabled", "status": "up", "successes": 1, "threshold": 3, "change-id": "1"}]
+}`)
+	})
+	rest, err := cli.ParserForTest().ParseArgs([]string{"check", "chk1", "--history"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, HasLen, 0)
+	c.Check(s.Stderr(), Equals, "")
+}
+
 func (s *PebbleSuite) TestCheckFailure(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -34,7 +34,7 @@ arguments.
 type cmdChecks struct {
 	client *client.Client
 
-	Level      string `long:"level" choice:"alive" choice:"ready"`
+	Level      string `long:"level" choice:"alive" choice:"ready" choice:"startup"`
 	Positional struct {
 		Checks []string `positional-arg-name:"<check>"`
 	} `positional-args:"yes"`
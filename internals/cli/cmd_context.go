@@ -0,0 +1,369 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/canonical/go-flags"
+)
+
+const cmdContextSummary = "List or manage named contexts"
+const cmdContextDescription = `
+The context command lists the named contexts. A context records how to
+connect to a {{.DisplayName}} daemon: its Unix socket or HTTPS URL, the credentials
+to use, and optionally the fingerprint of the server's identity.
+
+The current context is used by all commands, unless the PEBBLE_SOCKET or
+PEBBLE_BASEURL environment variable is set. Pass --context=<name> to any
+command to use a different context for that command.
+
+Contexts are stored in ~/.config/pebble/contexts.json.
+
+The context subcommands are as follows:
+
+{{.ProgramName}} context add     Add a named context
+{{.ProgramName}} context use     Set the current context
+{{.ProgramName}} context list    List named contexts
+{{.ProgramName}} context remove  Remove a named context
+`
+
+type cmdContext struct{}
+
+const cmdContextAddSummary = "Add a named context"
+const cmdContextAddDescription = `
+The context add command adds a named context for connecting to a
+{{.DisplayName}} daemon, either over its Unix socket (--socket) or over HTTPS
+(--url).
+
+For HTTPS, the server's identity is checked against --fingerprint if it's
+given, otherwise against the fingerprint trusted with '{{.ProgramName}} trust'.
+If the server uses a certificate signed by a certificate authority, rather
+than one generated from its identity, use --ca-cert to verify it with the
+certificate authorities in a PEM file, or --ca-cert=system for the system's
+certificate authorities.
+
+With --username, the password for HTTP basic authentication is read from
+standard input. With --token, the bearer token of a "token" identity is read
+from standard input.
+`
+
+type cmdContextAdd struct {
+	Socket      string `long:"socket"`
+	URL         string `long:"url"`
+	Fingerprint string `long:"fingerprint"`
+	CACert      string `long:"ca-cert"`
+	Username    string `long:"username"`
+	Token       bool   `long:"token"`
+	Use         bool   `long:"use"`
+
+	Positional struct {
+		Name string `positional-arg-name:"<name>" required:"1"`
+	} `positional-args:"yes"`
+}
+
+const cmdContextUseSummary = "Set the current context"
+const cmdContextUseDescription = `
+The context use command sets the current context, which is used by all
+commands that connect to a {{.DisplayName}} daemon.
+`
+
+type cmdContextUse struct {
+	Positional struct {
+		Name string `positional-arg-name:"<name>" required:"1"`
+	} `positional-args:"yes"`
+}
+
+const cmdContextListSummary = "List named contexts"
+const cmdContextListDescription = `
+The context list command lists the named contexts. The current context is
+marked with an asterisk.
+`
+
+type cmdContextList struct{}
+
+const cmdContextRemoveSummary = "Remove a named context"
+const cmdContextRemoveDescription = `
+The context remove command removes a named context, including its stored
+credentials.
+`
+
+type cmdContextRemove struct {
+	Positional struct {
+		Name string `positional-arg-name:"<name>" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func init() {
+	AddCommand(&CmdInfo{
+		Name:        "context",
+		Summary:     cmdContextSummary,
+		Description: cmdContextDescription,
+		New: func(opts *CmdOptions) flags.Commander {
+			return &cmdContext{}
+		},
+	})
+	AddCommand(&CmdInfo{
+		Name:        "add",
+		Summary:     cmdContextAddSummary,
+		Description: cmdContextAddDescription,
+		ArgsHelp: map[string]string{
+			"--socket":      "Path of the Unix socket of the daemon",
+			"--url":         `URL of the daemon, for example "https://example.com:8443"`,
+			"--fingerprint": "Fingerprint of the HTTPS server's identity",
+			"--ca-cert":     `PEM file of CA certificates to verify the HTTPS server with, or "system"`,
+			"--username":    "Username for HTTP basic authentication (password is read from stdin)",
+			"--token":       "Read a bearer token from stdin",
+			"--use":         "Make the new context the current context",
+		},
+		New: func(opts *CmdOptions) flags.Commander {
+			return &cmdContextAdd{}
+		},
+		Parent: "context",
+	})
+	AddCommand(&CmdInfo{
+		Name:        "use",
+		Summary:     cmdContextUseSummary,
+		Description: cmdContextUseDescription,
+		New: func(opts *CmdOptions) flags.Commander {
+			return &cmdContextUse{}
+		},
+		Parent: "context",
+	})
+	AddCommand(&CmdInfo{
+		Name:        "list",
+		Summary:     cmdContextListSummary,
+		Description: cmdContextListDescription,
+		New: func(opts *CmdOptions) flags.Commander {
+			return &cmdContextList{}
+		},
+		Parent: "context",
+	})
+	AddCommand(&CmdInfo{
+		Name:        "remove",
+		Summary:     cmdContextRemoveSummary,
+		Description: cmdContextRemoveDescription,
+		New: func(opts *CmdOptions) flags.Commander {
+			return &cmdContextRemove{}
+		},
+		Parent: "context",
+	})
+}
+
+func (cmd *cmdContext) Execute(args []string) error {
+	return (&cmdContextList{}).Execute(args)
+}
+
+func (cmd *cmdContextAdd) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	name := cmd.Positional.Name
+	if err := validateContextName(name); err != nil {
+		return err
+	}
+
+	ctx := &cliContext{
+		Socket:      cmd.Socket,
+		URL:         cmd.URL,
+		Fingerprint: cmd.Fingerprint,
+		CACert:      cmd.CACert,
+	}
+	switch {
+	case cmd.Socket == "" && cmd.URL == "":
+		return errors.New("must specify --socket or --url")
+	case cmd.Socket != "" && cmd.URL != "":
+		return errors.New("cannot specify both --socket and --url")
+	case cmd.URL != "":
+		u, err := url.Parse(cmd.URL)
+		if err != nil {
+			return fmt.Errorf("cannot parse URL: %w", err)
+		}
+		if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid URL %q, must be http:// or https://", cmd.URL)
+		}
+		ctx.URL = strings.TrimSuffix(cmd.URL, "/")
+	}
+	if cmd.Fingerprint != "" && !strings.HasPrefix(ctx.URL, "https://") {
+		return errors.New("cannot use --fingerprint without an https:// URL")
+	}
+	if cmd.CACert != "" {
+		if !strings.HasPrefix(ctx.URL, "https://") {
+			return errors.New("cannot use --ca-cert without an https:// URL")
+		}
+		if cmd.Fingerprint != "" {
+			return errors.New("cannot specify both --fingerprint and --ca-cert")
+		}
+		if cmd.CACert != "system" {
+			path, err := filepath.Abs(cmd.CACert)
+			if err != nil {
+				return err
+			}
+			ctx.CACert = path
+		}
+		if _, err := loadRootCAs(ctx.CACert); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case cmd.Username != "" && cmd.Token:
+		return errors.New("cannot specify both --username and --token")
+	case cmd.Username != "":
+		password, err := readSecret("Password: ")
+		if err != nil {
+			return err
+		}
+		if password == "" {
+			return errors.New("cannot use an empty password")
+		}
+		ctx.Username = cmd.Username
+		ctx.Password = password
+	case cmd.Token:
+		token, err := readSecret("Token: ")
+		if err != nil {
+			return err
+		}
+		if token == "" {
+			return errors.New("cannot use an empty token")
+		}
+		ctx.Token = token
+	}
+
+	contexts, err := loadContexts()
+	if err != nil {
+		return err
+	}
+	if _, ok := contexts.Contexts[name]; ok {
+		return fmt.Errorf("context %q already exists", name)
+	}
+	contexts.Contexts[name] = ctx
+	if cmd.Use {
+		contexts.Current = name
+	}
+	err = saveContexts(contexts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(Stdout, "Added context %q.\n", name)
+	return nil
+}
+
+// readSecret reads a password or token from the terminal without echoing
+// it, or from standard input if that's not a terminal.
+func readSecret(prompt string) (string, error) {
+	if isStdinTTY {
+		fmt.Fprint(Stderr, prompt)
+		secret, err := ReadPassword(0)
+		fmt.Fprintln(Stderr)
+		if err != nil {
+			return "", err
+		}
+		return string(secret), nil
+	}
+	line, err := bufio.NewReader(Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("cannot read %s from stdin", strings.ToLower(strings.TrimSuffix(prompt, ": ")))
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (cmd *cmdContextUse) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	contexts, err := loadContexts()
+	if err != nil {
+		return err
+	}
+	if _, ok := contexts.Contexts[cmd.Positional.Name]; !ok {
+		return fmt.Errorf("cannot find context %q", cmd.Positional.Name)
+	}
+	contexts.Current = cmd.Positional.Name
+	err = saveContexts(contexts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(Stdout, "Using context %q.\n", cmd.Positional.Name)
+	return nil
+}
+
+func (cmd *cmdContextList) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	contexts, err := loadContexts()
+	if err != nil {
+		return err
+	}
+	if len(contexts.Contexts) == 0 {
+		fmt.Fprintln(Stderr, "No contexts.")
+		return nil
+	}
+
+	names := make([]string, 0, len(contexts.Contexts))
+	for name := range contexts.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	writer := tabWriter()
+	defer writer.Flush()
+
+	fmt.Fprintln(writer, "Name\tCurrent\tAddress\tAuth")
+	for _, name := range names {
+		ctx := contexts.Contexts[name]
+		current := ""
+		if name == contexts.Current {
+			current = "*"
+		}
+		auth := ctx.auth()
+		if auth == "" {
+			auth = "-"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", name, current, ctx.address(), auth)
+	}
+	return nil
+}
+
+func (cmd *cmdContextRemove) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	contexts, err := loadContexts()
+	if err != nil {
+		return err
+	}
+	name := cmd.Positional.Name
+	if _, ok := contexts.Contexts[name]; !ok {
+		return fmt.Errorf("cannot find context %q", name)
+	}
+	delete(contexts.Contexts, name)
+	if contexts.Current == name {
+		contexts.Current = ""
+	}
+	err = saveContexts(contexts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(Stdout, "Removed context %q.\n", name)
+	return nil
+}
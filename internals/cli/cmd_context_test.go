@@ -0,0 +1,271 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli_test
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/canonical/pebble/internals/cli"
+)
+
+func contextsPath() string {
+	return filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "pebble", "contexts.json")
+}
+
+func (s *PebbleSuite) readContexts(c *C) map[string]any {
+	data, err := os.ReadFile(contextsPath())
+	c.Assert(err, IsNil)
+	var contexts map[string]any
+	err = json.Unmarshal(data, &contexts)
+	c.Assert(err, IsNil)
+	return contexts
+}
+
+func (s *PebbleSuite) TestContextAddUseList(c *C) {
+	rest, err := cli.ParserForTest().ParseArgs([]string{"context", "add", "local", "--socket", "/run/pebble.socket"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, "Added context \"local\".\n")
+
+	s.ResetStdStreams()
+	s.stdin.Write([]byte("secret\n"))
+	_, err = cli.ParserForTest().ParseArgs([]string{
+		"context", "add", "prod", "--url", "https://example.com:8443/",
+		"--fingerprint", "FINGERPRINT", "--username", "alice", "--use",
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "Added context \"prod\".\n")
+
+	s.ResetStdStreams()
+	s.stdin.Write([]byte("tok\n"))
+	_, err = cli.ParserForTest().ParseArgs([]string{"context", "add", "staging", "--url", "http://10.0.0.1:4000", "--token"})
+	c.Assert(err, IsNil)
+
+	c.Check(s.readContexts(c), DeepEquals, map[string]any{
+		"current": "prod",
+		"contexts": map[string]any{
+			"local": map[string]any{"socket": "/run/pebble.socket"},
+			"prod": map[string]any{
+				"url":         "https://example.com:8443",
+				"fingerprint": "FINGERPRINT",
+				"username":    "alice",
+				"password":    "secret",
+			},
+			"staging": map[string]any{"url": "http://10.0.0.1:4000", "token": "tok"},
+		},
+	})
+	st, err := os.Stat(contextsPath())
+	c.Assert(err, IsNil)
+	c.Check(st.Mode().Perm(), Equals, os.FileMode(0o600))
+
+	s.ResetStdStreams()
+	_, err = cli.ParserForTest().ParseArgs([]string{"context", "list"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `
+Name     Current  Address                   Auth
+local             /run/pebble.socket        -
+prod     *        https://example.com:8443  basic
+staging           http://10.0.0.1:4000      token
+`[1:])
+
+	s.ResetStdStreams()
+	_, err = cli.ParserForTest().ParseArgs([]string{"context", "use", "local"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "Using context \"local\".\n")
+
+	// Without a subcommand, the context command lists contexts.
+	s.ResetStdStreams()
+	_, err = cli.ParserForTest().ParseArgs([]string{"context"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Matches, `(?s).*\nlocal +\* +/run/pebble.socket .*`)
+}
+
+func (s *PebbleSuite) TestContextListEmpty(c *C) {
+	_, err := cli.ParserForTest().ParseArgs([]string{"context", "list"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(s.Stderr(), Equals, "No contexts.\n")
+}
+
+func (s *PebbleSuite) TestContextRemove(c *C) {
+	_, err := cli.ParserForTest().ParseArgs([]string{"context", "add", "local", "--socket", "/run/pebble.socket", "--use"})
+	c.Assert(err, IsNil)
+
+	s.ResetStdStreams()
+	_, err = cli.ParserForTest().ParseArgs([]string{"context", "remove", "local"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "Removed context \"local\".\n")
+	c.Check(s.readContexts(c), DeepEquals, map[string]any{"contexts": map[string]any{}})
+
+	_, err = cli.ParserForTest().ParseArgs([]string{"context", "remove", "local"})
+	c.Check(err, ErrorMatches, `cannot find context "local"`)
+}
+
+func (s *PebbleSuite) TestContextErrors(c *C) {
+	tests := []struct {
+		args  []string
+		stdin string
+		err   string
+	}{
+		{[]string{"add", "bad name", "--socket", "/s"}, "", `invalid context name "bad name"`},
+		{[]string{"add", "c"}, "", "must specify --socket or --url"},
+		{[]string{"add", "c", "--socket", "/s", "--url", "https://h:1"}, "", "cannot specify both --socket and --url"},
+		{[]string{"add", "c", "--url", "ftp://h:1"}, "", `invalid URL "ftp://h:1", must be http:// or https://`},
+		{[]string{"add", "c", "--url", "http://h:1", "--fingerprint", "F"}, "", "cannot use --fingerprint without an https:// URL"},
+		{[]string{"add", "c", "--url", "http://h:1", "--ca-cert", "system"}, "", "cannot use --ca-cert without an https:// URL"},
+		{[]string{"add", "c", "--url", "https://h:1", "--ca-cert", "system", "--fingerprint", "F"}, "", "cannot specify both --fingerprint and --ca-cert"},
+		{[]string{"add", "c", "--url", "https://h:1", "--ca-cert", "/missing.pem"}, "", "cannot load CA certificates: .*"},
+		{[]string{"add", "c", "--socket", "/s", "--username", "u", "--token"}, "", "cannot specify both --username and --token"},
+		{[]string{"add", "c", "--socket", "/s", "--username", "u"}, "", "cannot read password from stdin"},
+		{[]string{"add", "c", "--socket", "/s", "--token"}, "\n", "cannot use an empty token"},
+		{[]string{"use", "missing"}, "", `cannot find context "missing"`},
+		{[]string{"list", "extra"}, "", "too many arguments for command"},
+	}
+	for _, test := range tests {
+		s.ResetStdStreams()
+		s.stdin.Write([]byte(test.stdin))
+		_, err := cli.ParserForTest().ParseArgs(append([]string{"context"}, test.args...))
+		c.Check(err, ErrorMatches, test.err, Commentf("args %q", test.args))
+	}
+
+	_, err := cli.ParserForTest().ParseArgs([]string{"context", "add", "c", "--socket", "/s"})
+	c.Assert(err, IsNil)
+	_, err = cli.ParserForTest().ParseArgs([]string{"context", "add", "c", "--socket", "/s"})
+	c.Check(err, ErrorMatches, `context "c" already exists`)
+}
+
+func (s *PebbleSuite) TestContextOption(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v1/identities")
+		username, password, ok := r.BasicAuth()
+		c.Check(ok, Equals, true)
+		c.Check(username, Equals, "alice")
+		c.Check(password, Equals, "secret")
+		fmt.Fprint(w, `{"type": "sync", "status-code": 200, "result": {}}`)
+	}))
+	defer server.Close()
+
+	s.stdin.Write([]byte("secret\n"))
+	_, err := cli.ParserForTest().ParseArgs([]string{"context", "add", "test", "--url", server.URL, "--username", "alice"})
+	c.Assert(err, IsNil)
+
+	for _, args := range [][]string{
+		{"pebble", "--context", "test", "identities"},
+		{"pebble", "identities", "--context=test"},
+	} {
+		s.ResetStdStreams()
+		restore := fakeArgs(args...)
+		err = cli.RunMain()
+		restore()
+		c.Assert(err, IsNil)
+		c.Check(s.Stderr(), Equals, "No identities.\n")
+	}
+
+	restore := fakeArgs("pebble", "--context", "missing", "identities")
+	defer restore()
+	err = cli.RunMain()
+	c.Check(err, ErrorMatches, `cannot find context "missing"`)
+}
+
+func (s *PebbleSuite) TestWithContext(c *C) {
+	os.Setenv("PEBBLE_SOCKET", "")
+	os.Setenv("PEBBLE_BASEURL", "")
+	defer os.Setenv("PEBBLE_BASEURL", "")
+
+	// No contexts.
+	opts, err := cli.WithContext(nil, "")
+	c.Assert(err, IsNil)
+	c.Check(opts, IsNil)
+
+	s.stdin.Write([]byte("tok\n"))
+	_, err = cli.ParserForTest().ParseArgs([]string{
+		"context", "add", "prod", "--url", "https://example.com:8443", "--token", "--fingerprint", "F", "--use",
+	})
+	c.Assert(err, IsNil)
+
+	// The current context is used by default.
+	opts, err = cli.WithContext(nil, "")
+	c.Assert(err, IsNil)
+	c.Check(opts.ClientConfig.BaseURL, Equals, "https://example.com:8443")
+	c.Check(opts.ClientConfig.BearerToken, Equals, "tok")
+	c.Check(opts.ClientConfig.VerifyTLSConnection, NotNil)
+
+	// But not if the environment configures the daemon's address.
+	os.Setenv("PEBBLE_BASEURL", "http://localhost:4000")
+	opts, err = cli.WithContext(nil, "")
+	c.Assert(err, IsNil)
+	c.Check(opts, IsNil)
+
+	// An explicit context overrides the environment.
+	opts, err = cli.WithContext(nil, "prod")
+	c.Assert(err, IsNil)
+	c.Check(opts.ClientConfig.BaseURL, Equals, "https://example.com:8443")
+}
+
+func (s *PebbleSuite) TestContextCACert(c *C) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	caPath := filepath.Join(c.MkDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	err := os.WriteFile(caPath, data, 0o644)
+	c.Assert(err, IsNil)
+
+	_, err = cli.ParserForTest().ParseArgs([]string{
+		"context", "add", "ca", "--url", "https://example.com:8443", "--ca-cert", caPath,
+	})
+	c.Assert(err, IsNil)
+	contexts := s.readContexts(c)["contexts"].(map[string]any)
+	c.Check(contexts["ca"].(map[string]any)["ca-cert"], Equals, caPath)
+
+	opts, err := cli.WithContext(nil, "ca")
+	c.Assert(err, IsNil)
+	c.Check(opts.ClientConfig.RootCAs, NotNil)
+
+	// The file is loaded each time the context is used.
+	err = os.WriteFile(caPath, []byte("bad"), 0o644)
+	c.Assert(err, IsNil)
+	_, err = cli.WithContext(nil, "ca")
+	c.Check(err, ErrorMatches, `cannot use context "ca": cannot load CA certificates: no certificates found in .*`)
+}
+
+func (s *PebbleSuite) TestContextArg(c *C) {
+	tests := []struct {
+		args    []string
+		context string
+	}{
+		{[]string{"services"}, ""},
+		{[]string{"--context", "prod", "services"}, "prod"},
+		{[]string{"--context=prod", "services"}, "prod"},
+		{[]string{"services", "--context=prod"}, "prod"},
+		{[]string{"--context", "prod", "exec", "grep", "--context", "3", "file"}, "prod"},
+		// Arguments of the command run by exec aren't global options.
+		{[]string{"exec", "grep", "--context", "3", "file"}, ""},
+		{[]string{"exec", "--", "grep", "--context=3", "file"}, ""},
+		// Nor is the exec command's own --context option.
+		{[]string{"exec", "--context", "svc", "ls"}, ""},
+		{[]string{"--version"}, ""},
+	}
+	for _, test := range tests {
+		c.Check(cli.ContextArg(test.args), Equals, test.context, Commentf("args %q", test.args))
+	}
+}
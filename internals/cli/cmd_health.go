@@ -33,7 +33,7 @@ an exit code 1 if at least one of the requested checks are unhealthy.
 type cmdHealth struct {
 	client *client.Client
 
-	Level      string `long:"level" choice:"alive" choice:"ready"`
+	Level      string `long:"level" choice:"alive" choice:"ready" choice:"startup"`
 	Positional struct {
 		Checks []string `positional-arg-name:"<check>"`
 	} `positional-args:"yes"`
@@ -97,5 +97,5 @@ func (s *PebbleSuite) TestHealthBadLevel(c *check.C) {
 	exitCode := cli.PebbleMain()
 	c.Check(exitCode, check.Equals, 1)
 	c.Check(s.Stdout(), check.Equals, "")
-	c.Check(s.Stderr(), check.Matches, "error: Invalid value .* Allowed values are: alive or ready\n")
+	c.Check(s.Stderr(), check.Matches, "error: Invalid value .* Allowed values are: alive, ready or startup\n")
 }
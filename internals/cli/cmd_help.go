@@ -213,7 +213,11 @@ var HelpCategories = []HelpCategory{{
 }, {
 	Label:       "Identities", // special-cased in printShortHelp
 	Description: "manage user identities",
-	Commands:    []string{"identities", "identity", "add-identities", "update-identities", "remove-identities"},
+	Commands:    []string{"identities", "identity", "add-identities", "update-identities", "rotate-identities", "remove-identities", "audit"},
+}, {
+	Label:       "Remote",
+	Description: "connect to remote daemons",
+	Commands:    []string{"context", "trust"},
 }}
 
 var (
@@ -228,6 +232,7 @@ the system that is running them.
 Set the PEBBLE environment variable to override the configuration directory
 (which defaults to {{.DefaultDir}}). Set PEBBLE_SOCKET to override
 the unix socket used for the API (defaults to $PEBBLE/.pebble.socket).
+Use --context=<name> with any command to connect using a named context.
 `)
 
 	pebbleHelpAllFooter = "For more information about a command, run '{{.ProgramName}} help <command>'."
@@ -36,7 +36,9 @@ subcommand for details):
 {{.ProgramName}} identity           Show a single identity
 {{.ProgramName}} add-identities     Add new identities
 {{.ProgramName}} update-identities  Update or replace identities
+{{.ProgramName}} rotate-identities  Rotate identity credentials
 {{.ProgramName}} remove-identities  Remove identities
+{{.ProgramName}} audit              Show the security audit log
 `
 
 type cmdIdentities struct {
@@ -115,6 +117,12 @@ func (cmd *cmdIdentities) writeText(identities map[string]*client.Identity) erro
 		if identity.Basic != nil {
 			types = append(types, "basic")
 		}
+		if identity.Token != nil {
+			types = append(types, "token")
+		}
+		if identity.Cert != nil {
+			types = append(types, "cert")
+		}
 		sort.Strings(types)
 		if len(types) == 0 {
 			types = append(types, "unknown")
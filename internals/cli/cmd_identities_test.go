@@ -26,9 +26,11 @@ import (
 
 func (s *PebbleSuite) TestIdentitiesText(c *C) {
 	expected := `
-Name  Access  Types
-bob   read    local
-mary  admin   local
+Name    Access  Types
+bob     read    local
+carol   admin   cert
+mary    admin   local
+olivia  read    token
 `[1:]
 	s.testIdentities(c, "", expected)
 	s.testIdentities(c, "text", expected)
@@ -41,16 +43,24 @@ identities:
         access: read
         local:
             user-id: 42
+    carol:
+        access: admin
+        cert:
+            pem: PEM
     mary:
         access: admin
         local:
             user-id: 1000
+    olivia:
+        access: read
+        token:
+            hash: '*****'
 `[1:]
 	s.testIdentities(c, "yaml", expected)
 }
 
 func (s *PebbleSuite) TestIdentitiesJSON(c *C) {
-	expected := `{"identities":{"bob":{"access":"read","local":{"user-id":42}},"mary":{"access":"admin","local":{"user-id":1000}}}}` + "\n"
+	expected := `{"identities":{"bob":{"access":"read","local":{"user-id":42}},"carol":{"access":"admin","cert":{"pem":"PEM"}},"mary":{"access":"admin","local":{"user-id":1000}},"olivia":{"access":"read","token":{"hash":"*****"}}}}` + "\n"
 	s.testIdentities(c, "json", expected)
 }
 
@@ -64,7 +74,9 @@ func (s *PebbleSuite) testIdentities(c *C, format string, expected string) {
 			"status-code": 200,
 			"result": {
 				"bob": {"access": "read", "local": {"user-id": 42}},
-				"mary": {"access": "admin", "local": {"user-id": 1000}}
+				"carol": {"access": "admin", "cert": {"pem": "PEM"}},
+				"mary": {"access": "admin", "local": {"user-id": 1000}},
+				"olivia": {"access": "read", "token": {"hash": "*****"}}
 			}
 		}`)
 	})
@@ -16,7 +16,9 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"strconv"
@@ -50,6 +52,7 @@ type cmdNotices struct {
 	Type    []client.NoticeType `long:"type"`
 	Key     []string            `long:"key"`
 	Timeout time.Duration       `long:"timeout"`
+	Follow  bool                `short:"f" long:"follow"`
 }
 
 func init() {
@@ -63,6 +66,7 @@ func init() {
 			"--type":    "Only list notices of this type (multiple allowed)",
 			"--key":     "Only list notices with this key (multiple allowed)",
 			"--timeout": "Wait up to this duration for matching notices to arrive",
+			"--follow":  "Show matching notices as they occur until Ctrl-C is pressed",
 		}),
 		New: func(opts *CmdOptions) flags.Commander {
 			return &cmdNotices{
@@ -88,6 +92,16 @@ func (cmd *cmdNotices) Execute(args []string) error {
 		Types:  cmd.Type,
 		Keys:   cmd.Key,
 		After:  state.NoticesLastOkayed,
+
+		// Older servers ignore this, so also filter by "after" above.
+		Unacknowledged: true,
+	}
+
+	if cmd.Follow {
+		if cmd.Timeout != 0 {
+			return errors.New("cannot use --follow and --timeout together")
+		}
+		return cmd.follow(state, &options)
 	}
 
 	var notices []*client.Notice
@@ -114,26 +128,9 @@ func (cmd *cmdNotices) Execute(args []string) error {
 	writer := tabWriter()
 	defer writer.Flush()
 
-	fmt.Fprintln(writer, "ID\tUser\tType\tKey\tFirst\tRepeated\tOccurrences")
-
+	fmt.Fprintln(writer, noticesHeader)
 	for _, notice := range notices {
-		key := notice.Key
-		if len(key) > 32 {
-			// Truncate to 32 bytes with ellipsis in the middle
-			key = key[:14] + "..." + key[len(key)-15:]
-		}
-		userIDStr := "public"
-		if notice.UserID != nil {
-			userIDStr = strconv.FormatUint(uint64(*notice.UserID), 10)
-		}
-		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
-			notice.ID,
-			userIDStr,
-			notice.Type,
-			key,
-			cmd.fmtTime(notice.FirstOccurred),
-			cmd.fmtTime(notice.LastRepeated),
-			notice.Occurrences)
+		cmd.writeNotice(writer, notice)
 	}
 
 	state.NoticesLastListed = notices[len(notices)-1].LastRepeated
@@ -143,3 +140,56 @@ func (cmd *cmdNotices) Execute(args []string) error {
 	}
 	return nil
 }
+
+// follow streams matching notices as they occur until Ctrl-C is pressed,
+// recording the last one shown in the CLI state.
+func (cmd *cmdNotices) follow(state *cliState, options *client.NoticesOptions) error {
+	// Stop following when Ctrl-C pressed (SIGINT).
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	writer := tabWriter()
+	fmt.Fprintln(writer, noticesHeader)
+	writer.Flush()
+
+	var lastListed time.Time
+	err := cmd.client.StreamNotices(ctx, options, func(notice *client.Notice) error {
+		cmd.writeNotice(writer, notice)
+		lastListed = notice.LastRepeated
+		return writer.Flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	if !lastListed.IsZero() {
+		state.NoticesLastListed = lastListed
+		err = saveCLIState(cmd.socketPath, state)
+		if err != nil {
+			return fmt.Errorf("cannot save CLI state: %w", err)
+		}
+	}
+	return nil
+}
+
+const noticesHeader = "ID\tUser\tType\tKey\tFirst\tRepeated\tOccurrences"
+
+func (cmd *cmdNotices) writeNotice(writer io.Writer, notice *client.Notice) {
+	key := notice.Key
+	if len(key) > 32 {
+		// Truncate to 32 bytes with ellipsis in the middle
+		key = key[:14] + "..." + key[len(key)-15:]
+	}
+	userIDStr := "public"
+	if notice.UserID != nil {
+		userIDStr = strconv.FormatUint(uint64(*notice.UserID), 10)
+	}
+	fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+		notice.ID,
+		userIDStr,
+		notice.Type,
+		key,
+		cmd.fmtTime(notice.FirstOccurred),
+		cmd.fmtTime(notice.LastRepeated),
+		notice.Occurrences)
+}
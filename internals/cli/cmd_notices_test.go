@@ -32,7 +32,7 @@ func (s *PebbleSuite) TestNotices(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		c.Check(r.Method, Equals, "GET")
 		c.Check(r.URL.Path, Equals, "/v1/notices")
-		c.Check(r.URL.Query(), DeepEquals, url.Values{})
+		c.Check(r.URL.Query(), DeepEquals, url.Values{"unacknowledged": {"true"}})
 
 		fmt.Fprint(w, `{
 			"type": "sync",
@@ -90,9 +90,10 @@ func (s *PebbleSuite) TestNoticesFiltersUsers(c *C) {
 		c.Check(r.Method, Equals, "GET")
 		c.Check(r.URL.Path, Equals, "/v1/notices")
 		c.Check(r.URL.Query(), DeepEquals, url.Values{
-			"users": {"all"},
-			"types": {"custom", "warning"},
-			"keys":  {"a.b/c"},
+			"users":          {"all"},
+			"types":          {"custom", "warning"},
+			"keys":           {"a.b/c"},
+			"unacknowledged": {"true"},
 		})
 
 		fmt.Fprint(w, `{
@@ -133,9 +134,10 @@ func (s *PebbleSuite) TestNoticesFiltersUID(c *C) {
 		c.Check(r.Method, Equals, "GET")
 		c.Check(r.URL.Path, Equals, "/v1/notices")
 		c.Check(r.URL.Query(), DeepEquals, url.Values{
-			"user-id": {"1000"},
-			"types":   {"custom", "warning"},
-			"keys":    {"a.b/c"},
+			"user-id":        {"1000"},
+			"types":          {"custom", "warning"},
+			"keys":           {"a.b/c"},
+			"unacknowledged": {"true"},
 		})
 
 		fmt.Fprint(w, `{
@@ -176,7 +178,8 @@ func (s *PebbleSuite) TestNoticesAfter(c *C) {
 		c.Check(r.Method, Equals, "GET")
 		c.Check(r.URL.Path, Equals, "/v1/notices")
 		c.Check(r.URL.Query(), DeepEquals, url.Values{
-			"after": {"2023-08-04T01:02:03Z"}, // from "notices-last-okayed" in notices.json
+			"after":          {"2023-08-04T01:02:03Z"}, // from "notices-last-okayed" in notices.json
+			"unacknowledged": {"true"},
 		})
 
 		fmt.Fprint(w, `{
@@ -220,7 +223,7 @@ func (s *PebbleSuite) TestNoticesNoNotices(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		c.Check(r.Method, Equals, "GET")
 		c.Check(r.URL.Path, Equals, "/v1/notices")
-		c.Check(r.URL.Query(), DeepEquals, url.Values{})
+		c.Check(r.URL.Query(), DeepEquals, url.Values{"unacknowledged": {"true"}})
 		fmt.Fprint(w, `{
 			"type": "sync",
 			"status-code": 200,
@@ -242,7 +245,10 @@ func (s *PebbleSuite) TestNoticesTimeout(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		c.Check(r.Method, Equals, "GET")
 		c.Check(r.URL.Path, Equals, "/v1/notices")
-		c.Check(r.URL.Query(), DeepEquals, url.Values{"timeout": {"1s"}})
+		c.Check(r.URL.Query(), DeepEquals, url.Values{
+			"timeout":        {"1s"},
+			"unacknowledged": {"true"},
+		})
 
 		fmt.Fprint(w, `{
 			"type": "sync",
@@ -281,7 +287,10 @@ func (s *PebbleSuite) TestNoticesNoNoticesTimeout(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		c.Check(r.Method, Equals, "GET")
 		c.Check(r.URL.Path, Equals, "/v1/notices")
-		c.Check(r.URL.Query(), DeepEquals, url.Values{"timeout": {"1s"}})
+		c.Check(r.URL.Query(), DeepEquals, url.Values{
+			"timeout":        {"1s"},
+			"unacknowledged": {"true"},
+		})
 		fmt.Fprint(w, `{
 			"type": "sync",
 			"status-code": 200,
@@ -298,3 +307,42 @@ func (s *PebbleSuite) TestNoticesNoNoticesTimeout(c *C) {
 	_, err = os.Stat(s.cliStatePath)
 	c.Assert(errors.Is(err, fs.ErrNotExist), Equals, true)
 }
+
+func (s *PebbleSuite) TestNoticesFollow(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v1/notices/stream")
+		c.Check(r.URL.Query(), DeepEquals, url.Values{
+			"types":          {"service-status"},
+			"unacknowledged": {"true"},
+		})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: 2023-09-05T18:18:00Z\nevent: notice\n"+
+			`data: {"id":"1","user-id":null,"type":"service-status","key":"svc1","first-occurred":"2023-09-05T17:18:00Z","last-occurred":"2023-09-05T18:18:00Z","last-repeated":"2023-09-05T18:18:00Z","occurrences":2}`+"\n\n")
+		fmt.Fprint(w, ": keepalive\n\n")
+		fmt.Fprint(w, "id: 2023-09-06T18:18:00Z\nevent: notice\n"+
+			`data: {"id":"2","user-id":null,"type":"service-status","key":"svc2","first-occurred":"2023-09-06T18:18:00Z","last-occurred":"2023-09-06T18:18:00Z","last-repeated":"2023-09-06T18:18:00Z","occurrences":1}`+"\n\n")
+	})
+
+	rest, err := cli.ParserForTest().ParseArgs([]string{"notices", "--abs-time", "--follow", "--type", "service-status"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, `
+ID   User  Type  Key  First  Repeated  Occurrences
+1    public  service-status  svc1  2023-09-05T17:18:00Z  2023-09-05T18:18:00Z  2
+2    public  service-status  svc2  2023-09-06T18:18:00Z  2023-09-06T18:18:00Z  1
+`[1:])
+	c.Check(s.Stderr(), Equals, "")
+
+	cliState := s.readNoticesCLIState(c)
+	c.Check(cliState, DeepEquals, map[string]any{
+		"notices-last-listed": "2023-09-06T18:18:00Z",
+		"notices-last-okayed": "0001-01-01T00:00:00Z",
+	})
+}
+
+func (s *PebbleSuite) TestNoticesFollowTimeout(c *C) {
+	_, err := cli.ParserForTest().ParseArgs([]string{"notices", "--follow", "--timeout", "1s"})
+	c.Assert(err, ErrorMatches, "cannot use --follow and --timeout together")
+}
@@ -16,9 +16,11 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/canonical/go-flags"
 
+	"github.com/canonical/pebble/client"
 	cmdpkg "github.com/canonical/pebble/cmd"
 )
 
@@ -28,9 +30,14 @@ The okay command acknowledges warnings and notices that have been previously
 listed using '{{.ProgramName}} warnings' or '{{.ProgramName}} notices', so that they are omitted
 from future runs of either command. When a notice or warning is repeated, it
 will again show up until the next '{{.ProgramName}} okay'.
+
+Notices are acknowledged on the server for the current user, so they are also
+omitted for other clients that only list unacknowledged notices.
 `
 
 type cmdOkay struct {
+	client *client.Client
+
 	socketPath string
 
 	Warnings bool `long:"warnings"`
@@ -43,6 +50,7 @@ func init() {
 		Description: cmdOkayDescription,
 		New: func(opts *CmdOptions) flags.Commander {
 			return &cmdOkay{
+				client:     opts.Client,
 				socketPath: opts.SocketPath,
 			}
 		},
@@ -88,5 +96,16 @@ func (cmd *cmdOkay) Execute(args []string) error {
 		return fmt.Errorf("no notices or warnings have been listed; try '%s notices' or '%s warnings'", cmdpkg.ProgramName, cmdpkg.ProgramName)
 	}
 
+	if okayedNotices {
+		// The "before" filter is strict, so acknowledge up to and including
+		// the last notice listed.
+		_, err := cmd.client.AcknowledgeNotices(&client.AcknowledgeNoticesOptions{
+			Before: state.NoticesLastListed.Add(time.Nanosecond),
+		})
+		if err != nil {
+			return fmt.Errorf("cannot acknowledge notices: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -15,6 +15,9 @@
 package cli_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"time"
 
 	. "gopkg.in/check.v1"
@@ -23,6 +26,18 @@ import (
 )
 
 func (s *PebbleSuite) TestOkay(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		c.Check(r.URL.Path, Equals, "/v1/notices")
+		var body map[string]any
+		err := json.NewDecoder(r.Body).Decode(&body)
+		c.Assert(err, IsNil)
+		c.Check(body, DeepEquals, map[string]any{
+			"action": "acknowledge",
+			"before": "2023-09-06T15:06:00.000000001Z",
+		})
+		fmt.Fprint(w, `{"type": "sync", "status-code": 200, "result": {"count": 1}}`)
+	})
 	s.writeCLIState(c, map[string]any{
 		"notices-last-listed": time.Date(2023, 9, 6, 15, 6, 0, 0, time.UTC),
 		"notices-last-okayed": time.Time{},
@@ -64,6 +79,26 @@ func (s *PebbleSuite) TestOkayWarnings(c *C) {
 	})
 }
 
+func (s *PebbleSuite) TestOkayAcknowledgeError(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type": "error", "status-code": 400, "result": {"message": "invalid action \"acknowledge\""}}`)
+	})
+	s.writeCLIState(c, map[string]any{
+		"notices-last-listed": time.Date(2023, 9, 6, 15, 6, 0, 0, time.UTC),
+		"notices-last-okayed": time.Time{},
+	})
+
+	_, err := cli.ParserForTest().ParseArgs([]string{"okay"})
+	c.Assert(err, ErrorMatches, `cannot acknowledge notices: invalid action "acknowledge"`)
+
+	// The notices are still okayed locally.
+	cliState := s.readNoticesCLIState(c)
+	c.Check(cliState, DeepEquals, map[string]any{
+		"notices-last-listed": "2023-09-06T15:06:00Z",
+		"notices-last-okayed": "2023-09-06T15:06:00Z",
+	})
+}
+
 func (s *PebbleSuite) TestOkayNoNotices(c *C) {
 	_, err := cli.ParserForTest().ParseArgs([]string{"okay"})
 	c.Assert(err, ErrorMatches, "no notices.* have been listed.*")
@@ -0,0 +1,110 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/canonical/go-flags"
+
+	"github.com/canonical/pebble/client"
+)
+
+const cmdRotateIdentitiesSummary = "Rotate identity credentials"
+const cmdRotateIdentitiesDescription = `
+The rotate-identities command replaces the password or token of one or more
+existing basic or token identities.
+
+The previous password or token remains valid for the duration given by
+--grace-period, so that clients can be updated without losing access. If
+--grace-period is not specified, the previous credentials are invalid
+immediately.
+
+For example, to rotate the token of "ci", leave the token hash empty to have
+a random token generated, and to rotate the password of "alice", provide the
+new hashed password:
+
+> identities:
+>     ci:
+>         token: {}
+>     alice:
+>         basic:
+>             password: <password hash>
+
+Other fields, such as the access level, are not changed.
+`
+
+type cmdRotateIdentities struct {
+	client *client.Client
+
+	From        string        `long:"from" required:"1"`
+	GracePeriod time.Duration `long:"grace-period"`
+}
+
+func init() {
+	AddCommand(&CmdInfo{
+		Name:        "rotate-identities",
+		Summary:     cmdRotateIdentitiesSummary,
+		Description: cmdRotateIdentitiesDescription,
+		ArgsHelp: map[string]string{
+			"--from":         "Path of YAML file to read identities from (required)",
+			"--grace-period": "How long the previous credentials remain valid (for example, 24h)",
+		},
+		New: func(opts *CmdOptions) flags.Commander {
+			return &cmdRotateIdentities{client: opts.Client}
+		},
+	})
+}
+
+func (cmd *cmdRotateIdentities) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	if cmd.GracePeriod < 0 {
+		return errors.New("grace period must not be negative")
+	}
+
+	identities, err := readIdentities(cmd.From)
+	if err != nil {
+		return err
+	}
+	tokens, err := generateTokens(identities)
+	if err != nil {
+		return err
+	}
+	err = cmd.client.RotateIdentities(identities, &client.RotateIdentitiesOptions{
+		GracePeriod: cmd.GracePeriod,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(Stdout, "Rotated credentials for %s.\n", numItems(len(identities), "identity", "identities"))
+	if len(tokens) > 0 {
+		names := make([]string, 0, len(tokens))
+		for name := range tokens {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintln(Stdout, "Generated tokens (these will not be shown again):")
+		for _, name := range names {
+			fmt.Fprintf(Stdout, "%s: %s\n", name, tokens[name])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/canonical/pebble/internals/cli"
+)
+
+func (s *PebbleSuite) TestRotateIdentities(c *C) {
+	var payload map[string]any
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		c.Check(r.URL.Path, Equals, "/v1/identities")
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		c.Assert(err, IsNil)
+		fmt.Fprint(w, `{
+			"type": "sync",
+			"status-code": 200,
+			"result": null
+		}`)
+	})
+
+	path := filepath.Join(c.MkDir(), "identities.yaml")
+	data := `
+identities:
+    ci:
+        token: {}
+    alice:
+        basic: {password: hash}
+`
+	err := os.WriteFile(path, []byte(data), 0o666)
+	c.Assert(err, IsNil)
+
+	rest, err := cli.ParserForTest().ParseArgs([]string{"rotate-identities", "--from", path, "--grace-period", "24h"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stderr(), Equals, "")
+
+	matches := regexp.MustCompile(`^Rotated credentials for 2 identities.
+Generated tokens \(these will not be shown again\):
+ci: ([A-Za-z0-9_-]{43})
+$`).FindStringSubmatch(s.Stdout())
+	c.Assert(matches, HasLen, 2, Commentf("%s", s.Stdout()))
+	sum := sha256.Sum256([]byte(matches[1]))
+	c.Check(payload, DeepEquals, map[string]any{
+		"action":       "rotate",
+		"grace-period": "24h0m0s",
+		"identities": map[string]any{
+			"ci": map[string]any{
+				"token": map[string]any{"hash": hex.EncodeToString(sum[:])},
+			},
+			"alice": map[string]any{
+				"basic": map[string]any{"password": "hash"},
+			},
+		},
+	})
+}
+
+func (s *PebbleSuite) TestRotateIdentitiesNegativeGracePeriod(c *C) {
+	_, err := cli.ParserForTest().ParseArgs([]string{"rotate-identities", "--from", "x", "--grace-period", "-1h"})
+	c.Assert(err, ErrorMatches, "grace period must not be negative")
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/canonical/pebble/internals/idkey"
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/overlord"
+	"github.com/canonical/pebble/internals/overlord/noticestate"
 	"github.com/canonical/pebble/internals/plan"
 	"github.com/canonical/pebble/internals/reaper"
 	"github.com/canonical/pebble/internals/systemd"
@@ -190,6 +191,7 @@ func runDaemon(rcmd *cmdRun, ch chan os.Signal, ready chan<- func()) error {
 	}
 
 	plan.RegisterSectionExtension(workloads.WorkloadsField, &workloads.WorkloadsSectionExtension{})
+	plan.RegisterSectionExtension(noticestate.SubscribersField, &noticestate.SubscribersSectionExtension{})
 
 	idPath := filepath.Join(rcmd.pebbleDir, "identity")
 	idSigner, err := idkey.Get(idPath)
@@ -210,6 +212,9 @@ func runDaemon(rcmd *cmdRun, ch chan os.Signal, ready chan<- func()) error {
 	if os.Getenv("PEBBLE_PERSIST") == "never" {
 		dopts.Persist = overlord.PersistNever
 	}
+	if os.Getenv("PEBBLE_ENCRYPT_STATE") == "1" {
+		dopts.EncryptState = true
+	}
 
 	d, err := daemon.New(&dopts)
 	if err != nil {
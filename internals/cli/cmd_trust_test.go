@@ -0,0 +1,124 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/canonical/pebble/client"
+	"github.com/canonical/pebble/internals/cli"
+)
+
+func knownHostsPath() string {
+	return filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "pebble", "known-hosts")
+}
+
+func (s *PebbleSuite) fakeFingerprint(c *C, fingerprint string) {
+	restore := cli.FakeFetchFingerprint(func(address string) (string, error) {
+		c.Check(address, Equals, "example.com:8443")
+		return fingerprint, nil
+	})
+	s.AddCleanup(restore)
+}
+
+func (s *PebbleSuite) TestTrust(c *C) {
+	s.fakeFingerprint(c, "FINGERPRINT")
+
+	rest, err := cli.ParserForTest().ParseArgs([]string{"trust", "https://example.com:8443"})
+	c.Assert(err, IsNil)
+	c.Check(rest, HasLen, 0)
+	c.Check(s.Stdout(), Equals, "Trusted server example.com:8443 with fingerprint FINGERPRINT\n")
+	c.Check(s.Stderr(), Equals, "")
+
+	data, err := os.ReadFile(knownHostsPath())
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "example.com:8443 FINGERPRINT\n")
+
+	s.ResetStdStreams()
+	_, err = cli.ParserForTest().ParseArgs([]string{"trust", "example.com:8443"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "Server example.com:8443 is already trusted with fingerprint FINGERPRINT\n")
+}
+
+func (s *PebbleSuite) TestTrustFingerprintFlag(c *C) {
+	s.fakeFingerprint(c, "FINGERPRINT")
+
+	_, err := cli.ParserForTest().ParseArgs([]string{"trust", "--fingerprint", "OTHER", "example.com:8443"})
+	c.Check(err, ErrorMatches, "server example.com:8443 fingerprint FINGERPRINT does not match expected fingerprint OTHER")
+	_, err = os.Stat(knownHostsPath())
+	c.Check(os.IsNotExist(err), Equals, true)
+
+	_, err = cli.ParserForTest().ParseArgs([]string{"trust", "--fingerprint", "FINGERPRINT", "example.com:8443"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "Trusted server example.com:8443 with fingerprint FINGERPRINT\n")
+}
+
+func (s *PebbleSuite) TestTrustReplace(c *C) {
+	err := os.MkdirAll(filepath.Dir(knownHostsPath()), 0o700)
+	c.Assert(err, IsNil)
+	err = os.WriteFile(knownHostsPath(), []byte("example.com:8443 OLD\n"), 0o600)
+	c.Assert(err, IsNil)
+	s.fakeFingerprint(c, "NEW")
+
+	_, err = cli.ParserForTest().ParseArgs([]string{"trust", "example.com:8443"})
+	c.Check(err, ErrorMatches, `server example.com:8443 fingerprint NEW does not match trusted fingerprint OLD \(use --replace if the change is expected\)`)
+
+	_, err = cli.ParserForTest().ParseArgs([]string{"trust", "--replace", "example.com:8443"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "Trusted server example.com:8443 with fingerprint NEW\n")
+	data, err := os.ReadFile(knownHostsPath())
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "example.com:8443 NEW\n")
+}
+
+func (s *PebbleSuite) TestTrustErrors(c *C) {
+	restore := cli.FakeFetchFingerprint(func(address string) (string, error) {
+		return "", errors.New("cannot fetch server fingerprint: connection refused")
+	})
+	defer restore()
+
+	_, err := cli.ParserForTest().ParseArgs([]string{"trust", "example.com:8443"})
+	c.Check(err, ErrorMatches, "cannot fetch server fingerprint: connection refused")
+
+	_, err = cli.ParserForTest().ParseArgs([]string{"trust", "http://example.com:8443"})
+	c.Check(err, ErrorMatches, `cannot use "http" scheme for trusted server, must be https`)
+
+	_, err = cli.ParserForTest().ParseArgs([]string{"trust", "example.com:8443", "extra"})
+	c.Check(err, Equals, cli.ErrExtraArgs)
+}
+
+func (s *PebbleSuite) TestTrustErrorMessages(c *C) {
+	err := fmt.Errorf("cannot obtain system details: %w", &client.UnknownServerError{
+		Address:     "example.com:8443",
+		Fingerprint: "FINGERPRINT",
+	})
+	_, err = cli.ErrorToMessage(err)
+	c.Check(err, ErrorMatches, `(?s)server example.com:8443 is not trusted \(fingerprint FINGERPRINT\)
+Verify the fingerprint .*, then run 'pebble trust example.com:8443'`)
+
+	err = fmt.Errorf("cannot obtain system details: %w", &client.FingerprintMismatchError{
+		Address:  "example.com:8443",
+		Expected: "OLD",
+		Actual:   "NEW",
+	})
+	_, err = cli.ErrorToMessage(err)
+	c.Check(err, ErrorMatches, `(?s)server example.com:8443 fingerprint NEW does not match trusted fingerprint OLD
+The server's identity has changed.*run 'pebble trust --replace example.com:8443'`)
+}
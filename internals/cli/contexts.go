@@ -0,0 +1,268 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/canonical/go-flags"
+
+	"github.com/canonical/pebble/client"
+)
+
+// cliContext describes how to connect to one Pebble daemon.
+type cliContext struct {
+	// Exactly one of Socket and URL is set.
+	Socket string `json:"socket,omitempty"`
+	URL    string `json:"url,omitempty"`
+
+	// Fingerprint, if set, pins the identity of an HTTPS server. Otherwise
+	// the server must be in the known hosts file.
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// CACert, if set, is the path of a PEM file holding the certificate
+	// authorities that sign the HTTPS server's certificate, or "system" for
+	// the system's certificate authorities.
+	CACert string `json:"ca-cert,omitempty"`
+
+	// At most one of Username (with Password) and Token is set.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// address returns the socket path or URL of the context.
+func (ctx *cliContext) address() string {
+	if ctx.URL != "" {
+		return ctx.URL
+	}
+	return ctx.Socket
+}
+
+// auth returns the kind of credentials stored in the context, or "" if
+// there are none.
+func (ctx *cliContext) auth() string {
+	switch {
+	case ctx.Username != "":
+		return "basic"
+	case ctx.Token != "":
+		return "token"
+	}
+	return ""
+}
+
+// applyTo updates config to connect to the daemon described by the context.
+func (ctx *cliContext) applyTo(name string, config *client.Config) error {
+	config.Socket = ctx.Socket
+	config.BaseURL = ctx.URL
+	config.BasicUsername = ctx.Username
+	config.BasicPassword = ctx.Password
+	config.BearerToken = ctx.Token
+	if ctx.CACert != "" {
+		roots, err := loadRootCAs(ctx.CACert)
+		if err != nil {
+			return err
+		}
+		config.RootCAs = roots
+	}
+	if ctx.Fingerprint != "" && strings.HasPrefix(ctx.URL, "https://") {
+		address, err := client.ServerAddress(ctx.URL)
+		if err != nil {
+			return err
+		}
+		verify := client.FingerprintVerifier(address, ctx.Fingerprint)
+		config.VerifyTLSConnection = func(state tls.ConnectionState) error {
+			err := verify(state)
+			var mismatchErr *client.FingerprintMismatchError
+			if errors.As(err, &mismatchErr) {
+				return &contextFingerprintError{context: name, err: mismatchErr}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRootCAs loads the certificate authorities from the PEM file at path,
+// or the system's certificate authorities if path is "system".
+func loadRootCAs(path string) (*x509.CertPool, error) {
+	if path == "system" {
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("cannot load system CA certificates: %w", err)
+		}
+		return roots, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load CA certificates: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("cannot load CA certificates: no certificates found in %q", path)
+	}
+	return roots, nil
+}
+
+// contextFingerprintError is returned when the server's fingerprint differs
+// from the fingerprint pinned in a context, so that the user can be told how
+// to update the context rather than the known hosts file.
+type contextFingerprintError struct {
+	context string
+	err     *client.FingerprintMismatchError
+}
+
+func (e *contextFingerprintError) Error() string {
+	return e.err.Error()
+}
+
+func (e *contextFingerprintError) Unwrap() error {
+	return e.err
+}
+
+// cliContexts is the content of the contexts file.
+type cliContexts struct {
+	Current  string                 `json:"current,omitempty"`
+	Contexts map[string]*cliContext `json:"contexts"`
+}
+
+var contextNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9](?:[a-zA-Z0-9._-]*[a-zA-Z0-9])?$`)
+
+func validateContextName(name string) error {
+	if !contextNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid context name %q", name)
+	}
+	return nil
+}
+
+// contextsPath returns the path of the file holding the named contexts.
+func contextsPath() string {
+	return filepath.Join(cliConfigDir(), "contexts.json")
+}
+
+func loadContexts() (*cliContexts, error) {
+	contexts := &cliContexts{Contexts: make(map[string]*cliContext)}
+	data, err := os.ReadFile(contextsPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return contexts, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot load contexts: %w", err)
+	}
+	err = json.Unmarshal(data, contexts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load contexts: %w", err)
+	}
+	if contexts.Contexts == nil {
+		contexts.Contexts = make(map[string]*cliContext)
+	}
+	return contexts, nil
+}
+
+func saveContexts(contexts *cliContexts) error {
+	data, err := json.MarshalIndent(contexts, "", "    ")
+	if err != nil {
+		return err
+	}
+	path := contextsPath()
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return fmt.Errorf("cannot save contexts: %w", err)
+	}
+	// The file may hold credentials, so keep it private to the user, and
+	// write it atomically so it's never left partially written.
+	tempPath := path + ".tmp"
+	err = os.WriteFile(tempPath, append(data, '\n'), 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot save contexts: %w", err)
+	}
+	err = os.Rename(tempPath, path)
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("cannot save contexts: %w", err)
+	}
+	return nil
+}
+
+// contextArg returns the value of the global --context option in args, or
+// "" if it's not present. The option is needed before parsing the command
+// line because the client is created before the parser, so args are first
+// parsed without running the command. This tells the global option apart
+// from a command's own --context option, or a --context argument of the
+// command it runs, like in "exec grep --context 3 file".
+func contextArg(args []string) string {
+	defaultOpts := defaultOptions{Version: func() {}}
+	parser := newParser(&ParserOptions{}, &defaultOpts)
+	parser.CommandHandler = func(command flags.Commander, args []string) error {
+		return nil
+	}
+	// Any error is reported when the command line is parsed for real.
+	parser.ParseArgs(args)
+	return defaultOpts.Context
+}
+
+// withContext returns a copy of opts with the client configured from the
+// named context, or from the current context if name is "". The current
+// context isn't used if the socket or base URL is configured explicitly,
+// for example by the PEBBLE_SOCKET or PEBBLE_BASEURL environment variables.
+func withContext(opts *RunOptions, name string) (*RunOptions, error) {
+	if name == "" {
+		if os.Getenv("PEBBLE_SOCKET") != "" || os.Getenv("PEBBLE_BASEURL") != "" {
+			return opts, nil
+		}
+		if opts != nil && opts.ClientConfig != nil && (opts.ClientConfig.Socket != "" || opts.ClientConfig.BaseURL != "") {
+			return opts, nil
+		}
+	}
+
+	contexts, err := loadContexts()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = contexts.Current
+		if name == "" {
+			return opts, nil
+		}
+	}
+	ctx, ok := contexts.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("cannot find context %q", name)
+	}
+
+	localOpts := RunOptions{}
+	config := client.Config{}
+	if opts != nil {
+		localOpts = *opts
+		if opts.ClientConfig != nil {
+			config = *opts.ClientConfig
+		}
+	}
+	err = ctx.applyTo(name, &config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot use context %q: %w", name, err)
+	}
+	localOpts.ClientConfig = &config
+	return &localOpts, nil
+}
@@ -16,6 +16,7 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/canonical/go-flags"
 
@@ -67,6 +68,11 @@ var (
 	MaybeCopyPebbleDir = maybeCopyPebbleDir
 
 	WithDefaultRunOptions = withDefaultRunOptions
+
+	ErrorToMessage = errorToMessage
+
+	WithContext = withContext
+	ContextArg  = contextArg
 )
 
 func FakeIsStdoutTTY(t bool) (restore func()) {
@@ -77,6 +83,14 @@ func FakeIsStdoutTTY(t bool) (restore func()) {
 	}
 }
 
+func FakeTimeNow(t time.Time) (restore func()) {
+	oldTimeNow := timeNow
+	timeNow = func() time.Time { return t }
+	return func() {
+		timeNow = oldTimeNow
+	}
+}
+
 func FakeIsStdinTTY(t bool) (restore func()) {
 	oldIsStdinTTY := isStdinTTY
 	isStdinTTY = t
@@ -116,3 +130,11 @@ func ParserForTest() *flags.Parser {
 		PebbleDir:  runOpts.PebbleDir,
 	})
 }
+
+func FakeFetchFingerprint(f func(address string) (string, error)) (restore func()) {
+	old := fetchFingerprint
+	fetchFingerprint = f
+	return func() {
+		fetchFingerprint = old
+	}
+}
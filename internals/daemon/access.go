@@ -16,6 +16,9 @@ package daemon
 
 import (
 	"net/http"
+	"slices"
+
+	"github.com/gorilla/mux"
 
 	"github.com/canonical/pebble/internals/overlord/state"
 )
@@ -32,6 +35,43 @@ type AccessChecker interface {
 	CheckAccess(d *Daemon, r *http.Request, user *UserState) Response
 }
 
+// accessLevels orders the identity access levels from lowest to highest.
+var accessLevels = []state.IdentityAccess{
+	state.UntrustedAccess,
+	state.MetricsAccess,
+	state.ReadAccess,
+	state.AdminAccess,
+}
+
+// CheckSocketAccess checks the access policy of the extra socket that the
+// request arrived on, if any. It denies access if the endpoint isn't in the
+// socket's list of endpoints, or if checker requires a higher access level
+// than the socket's maximum. Command.ServeHTTP calls it before the endpoint's
+// own checker, so requests over a restricted socket are denied whatever the
+// user's access and however the endpoint checks it.
+func CheckSocketAccess(r *http.Request, checker AccessChecker) Response {
+	socket := RequestSocket(r)
+	if socket == nil {
+		return nil
+	}
+	if len(socket.Endpoints) > 0 {
+		path := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				path = template
+			}
+		}
+		if !slices.Contains(socket.Endpoints, path) {
+			return Unauthorized(accessDenied)
+		}
+	}
+	required := requiredAccess(checker)
+	if socket.MaxAccess != "" && slices.Index(accessLevels, required) > slices.Index(accessLevels, socket.MaxAccess) {
+		return Unauthorized(accessDenied)
+	}
+	return nil
+}
+
 // OpenAccess allows all incoming requests over unix domain sockets, HTTP
 // and HTTPS, even without user credentials (or invalid credentials).
 type OpenAccess struct{}
@@ -41,7 +81,9 @@ func (ac OpenAccess) CheckAccess(d *Daemon, r *http.Request, user *UserState) Re
 }
 
 // AdminAccess only allows incoming requests over unix domain sockets and
-// HTTPS, and only if the user is valid and has AdminAccess role.
+// HTTPS, and only if the user is valid and has AdminAccess role. Users with
+// fine-grained permissions are limited to what their permissions grant, so
+// they're denied.
 type AdminAccess struct{}
 
 func (ac AdminAccess) CheckAccess(d *Daemon, r *http.Request, user *UserState) Response {
@@ -52,6 +94,11 @@ func (ac AdminAccess) CheckAccess(d *Daemon, r *http.Request, user *UserState) R
 		// Not Unix Domain Socket or HTTPS.
 		return Unauthorized(accessDenied)
 	}
+	if user.hasPermissions() {
+		// An identity with permissions is limited to the actions granted,
+		// even if it has "access: admin".
+		return Unauthorized(accessDenied)
+	}
 	if user.Access == state.AdminAccess {
 		return nil
 	}
@@ -110,3 +157,46 @@ func (ac MetricsAccess) CheckAccess(d *Daemon, r *http.Request, user *UserState)
 		return Unauthorized(accessDenied)
 	}
 }
+
+// PermissionAccess checks access for an endpoint whose resources can be
+// restricted with fine-grained identity permissions. Users without
+// permissions are checked with the Default checker. Users with permissions
+// must connect over unix domain sockets or HTTPS and have a permission for
+// Action; the handler then checks the specific resources. Over an extra
+// socket, the endpoint requires the access level of the Default checker.
+type PermissionAccess struct {
+	Action  state.PermissionAction
+	Default AccessChecker
+}
+
+func (ac PermissionAccess) CheckAccess(d *Daemon, r *http.Request, user *UserState) Response {
+	if !user.hasPermissions() {
+		return ac.Default.CheckAccess(d, r, user)
+	}
+	if !RequestTransportType(r).IsConcealed() {
+		// Not Unix Domain Socket or HTTPS.
+		return Unauthorized(accessDenied)
+	}
+	for _, permission := range user.Permissions {
+		if permission.Action == ac.Action {
+			return nil
+		}
+	}
+	return Unauthorized(accessDenied)
+}
+
+// requiredAccess returns the lowest access level allowed by a built-in
+// checker, or AdminAccess for any other checker.
+func requiredAccess(checker AccessChecker) state.IdentityAccess {
+	switch checker := checker.(type) {
+	case PermissionAccess:
+		return requiredAccess(checker.Default)
+	case OpenAccess:
+		return state.UntrustedAccess
+	case MetricsAccess:
+		return state.MetricsAccess
+	case UserAccess:
+		return state.ReadAccess
+	}
+	return state.AdminAccess
+}
@@ -190,3 +190,96 @@ func (s *accessSuite) TestAccess(c *C) {
 		c.Assert(err, DeepEquals, t.metricsCheckErr)
 	}
 }
+
+func (s *accessSuite) TestAdminAccessWithPermissions(c *C) {
+	user := &daemon.UserState{
+		Access:      state.AdminAccess,
+		Permissions: []state.Permission{{Action: state.ManageServicesPermission, Resources: []string{"svc-a"}}},
+	}
+	r := &http.Request{
+		URL: &url.URL{},
+	}
+	r = r.WithContext(context.WithValue(context.Background(), daemon.TransportTypeKey{}, daemon.TransportTypeUnixSocket))
+	c.Check(daemon.AdminAccess{}.CheckAccess(nil, r, user), DeepEquals, errUnauthorized)
+	c.Check(daemon.UserAccess{}.CheckAccess(nil, r, user), IsNil)
+}
+
+func (s *accessSuite) TestPermissionAccess(c *C) {
+	readLogs := []state.Permission{{Action: state.ReadLogsPermission, Resources: []string{"web"}}}
+	tests := []struct {
+		apiSource daemon.TransportType
+		user      *daemon.UserState
+		err       daemon.Response
+	}{
+		// Users without permissions use the default checker (AdminAccess).
+		{daemon.TransportTypeUnixSocket, nil, errUnauthorized},
+		{daemon.TransportTypeUnixSocket, &daemon.UserState{Access: state.ReadAccess}, errUnauthorized},
+		{daemon.TransportTypeUnixSocket, &daemon.UserState{Access: state.AdminAccess}, nil},
+		// Users with a permission for the action are allowed.
+		{daemon.TransportTypeUnixSocket, &daemon.UserState{Access: state.ReadAccess, Permissions: readLogs}, nil},
+		{daemon.TransportTypeHTTPS, &daemon.UserState{Access: state.ReadAccess, Permissions: readLogs}, nil},
+		{daemon.TransportTypeHTTP, &daemon.UserState{Access: state.ReadAccess, Permissions: readLogs}, errUnauthorized},
+		// Users with permissions, but not for the action, are denied, even admins.
+		{daemon.TransportTypeUnixSocket, &daemon.UserState{
+			Access:      state.AdminAccess,
+			Permissions: []state.Permission{{Action: state.ExecPermission, Resources: []string{"/bin/ls"}}},
+		}, errUnauthorized},
+	}
+	access := daemon.PermissionAccess{Action: state.ReadLogsPermission, Default: daemon.AdminAccess{}}
+	for _, t := range tests {
+		r := &http.Request{
+			URL: &url.URL{},
+		}
+		r = r.WithContext(context.WithValue(context.Background(), daemon.TransportTypeKey{}, t.apiSource))
+		err := access.CheckAccess(nil, r, t.user)
+		c.Check(err, DeepEquals, t.err)
+	}
+}
+
+func (s *accessSuite) TestSocketAccess(c *C) {
+	metricsSocket := &daemon.ExtraSocket{Path: "/tmp/metrics.socket", MaxAccess: state.MetricsAccess}
+	healthSocket := &daemon.ExtraSocket{Path: "/tmp/health.socket", Endpoints: []string{"/v1/health"}}
+	tests := []struct {
+		socket  *daemon.ExtraSocket
+		path    string
+		checker daemon.AccessChecker
+		err     daemon.Response
+	}{
+		// Requests on the main socket aren't restricted.
+		{nil, "/v1/services", daemon.AdminAccess{}, nil},
+		// The maximum access level limits the endpoints, even for admins.
+		{metricsSocket, "/v1/health", daemon.OpenAccess{}, nil},
+		{metricsSocket, "/v1/metrics", daemon.MetricsAccess{}, nil},
+		{metricsSocket, "/v1/services", daemon.UserAccess{}, errUnauthorized},
+		{metricsSocket, "/v1/exec", daemon.AdminAccess{}, errUnauthorized},
+		{metricsSocket, "/v1/logs", daemon.PermissionAccess{Action: state.ReadLogsPermission, Default: daemon.UserAccess{}}, errUnauthorized},
+		// Only the listed endpoints can be used.
+		{healthSocket, "/v1/health", daemon.OpenAccess{}, nil},
+		{healthSocket, "/v1/metrics", daemon.MetricsAccess{}, errUnauthorized},
+		{healthSocket, "/v1/exec", daemon.AdminAccess{}, errUnauthorized},
+	}
+	for _, t := range tests {
+		r := &http.Request{
+			URL: &url.URL{Path: t.path},
+		}
+		ctx := context.WithValue(context.Background(), daemon.TransportTypeKey{}, daemon.TransportTypeUnixSocket)
+		if t.socket != nil {
+			ctx = context.WithValue(ctx, daemon.SocketKey{}, t.socket)
+		}
+		r = r.WithContext(ctx)
+		c.Check(daemon.RequestSocket(r), Equals, t.socket)
+		err := daemon.CheckSocketAccess(r, t.checker)
+		c.Check(err, DeepEquals, t.err, Commentf("socket %v, path %s", t.socket, t.path))
+	}
+
+	// Endpoints with fine-grained permissions require the access level of
+	// the default checker.
+	r := &http.Request{URL: &url.URL{Path: "/v1/logs"}}
+	ctx := context.WithValue(context.Background(), daemon.TransportTypeKey{}, daemon.TransportTypeUnixSocket)
+	r = r.WithContext(context.WithValue(ctx, daemon.SocketKey{}, metricsSocket))
+	access := daemon.PermissionAccess{Action: state.ReadLogsPermission, Default: daemon.UserAccess{}}
+	c.Check(daemon.CheckSocketAccess(r, access), DeepEquals, errUnauthorized)
+	readSocket := &daemon.ExtraSocket{Path: "/tmp/read.socket", MaxAccess: state.ReadAccess}
+	r = r.WithContext(context.WithValue(ctx, daemon.SocketKey{}, readSocket))
+	c.Check(daemon.CheckSocketAccess(r, access), IsNil)
+}
@@ -17,9 +17,11 @@ package daemon
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/overlord"
 	"github.com/canonical/pebble/internals/overlord/restart"
 	"github.com/canonical/pebble/internals/overlord/state"
@@ -49,14 +51,14 @@ var API = []*Command{{
 	GET:        v1GetChangeWait,
 }, {
 	Path:        "/v1/services",
-	ReadAccess:  UserAccess{},
-	WriteAccess: AdminAccess{},
+	ReadAccess:  PermissionAccess{Action: state.ReadServicesPermission, Default: UserAccess{}},
+	WriteAccess: PermissionAccess{Action: state.ManageServicesPermission, Default: AdminAccess{}},
 	GET:         v1GetServices,
 	POST:        v1PostServices,
 }, {
 	Path:        "/v1/services/{name}",
-	ReadAccess:  UserAccess{},
-	WriteAccess: AdminAccess{},
+	ReadAccess:  PermissionAccess{Action: state.ReadServicesPermission, Default: UserAccess{}},
+	WriteAccess: PermissionAccess{Action: state.ManageServicesPermission, Default: AdminAccess{}},
 	GET:         v1GetService,
 	POST:        v1PostService,
 }, {
@@ -69,35 +71,35 @@ var API = []*Command{{
 	POST:        v1PostLayers,
 }, {
 	Path:        "/v1/files",
-	ReadAccess:  AdminAccess{}, // some files are sensitive, so require admin
-	WriteAccess: AdminAccess{},
+	ReadAccess:  PermissionAccess{Action: state.ReadFilesPermission, Default: AdminAccess{}}, // some files are sensitive, so require admin
+	WriteAccess: PermissionAccess{Action: state.WriteFilesPermission, Default: AdminAccess{}},
 	GET:         v1GetFiles,
 	POST:        v1PostFiles,
 }, {
 	Path:       "/v1/logs",
-	ReadAccess: UserAccess{},
+	ReadAccess: PermissionAccess{Action: state.ReadLogsPermission, Default: UserAccess{}},
 	GET:        v1GetLogs,
 }, {
 	Path:        "/v1/exec",
-	WriteAccess: AdminAccess{},
+	WriteAccess: PermissionAccess{Action: state.ExecPermission, Default: AdminAccess{}},
 	POST:        v1PostExec,
 }, {
 	Path:       "/v1/tasks/{task-id}/websocket/{websocket-id}",
-	ReadAccess: AdminAccess{}, // used by exec, so require admin
+	ReadAccess: PermissionAccess{Action: state.ExecPermission, Default: AdminAccess{}}, // non-admins may only connect to their own exec tasks
 	GET:        v1GetTaskWebsocket,
 }, {
 	Path:        "/v1/signals",
-	WriteAccess: AdminAccess{},
+	WriteAccess: PermissionAccess{Action: state.ManageServicesPermission, Default: AdminAccess{}},
 	POST:        v1PostSignals,
 }, {
 	Path:        "/v1/checks",
-	ReadAccess:  UserAccess{},
-	WriteAccess: AdminAccess{},
+	ReadAccess:  PermissionAccess{Action: state.ReadChecksPermission, Default: UserAccess{}},
+	WriteAccess: PermissionAccess{Action: state.ManageChecksPermission, Default: AdminAccess{}},
 	GET:         v1GetChecks,
 	POST:        v1PostChecks,
 }, {
 	Path:        "/v1/checks/refresh",
-	WriteAccess: AdminAccess{},
+	WriteAccess: PermissionAccess{Action: state.ManageChecksPermission, Default: AdminAccess{}},
 	POST:        v1PostChecksRefresh,
 }, {
 	Path:        "/v1/notices",
@@ -105,6 +107,10 @@ var API = []*Command{{
 	WriteAccess: UserAccess{}, // any user is allowed to add a notice with their own uid
 	GET:         v1GetNotices,
 	POST:        v1PostNotices,
+}, {
+	Path:       "/v1/notices/stream",
+	ReadAccess: UserAccess{},
+	GET:        v1GetNoticesStream,
 }, {
 	Path:       "/v1/notices/{id}",
 	ReadAccess: UserAccess{},
@@ -119,6 +125,10 @@ var API = []*Command{{
 	Path:       "/v1/metrics",
 	ReadAccess: MetricsAccess{},
 	GET:        v1GetMetrics,
+}, {
+	Path:       "/v1/audit",
+	ReadAccess: AdminAccess{},
+	GET:        v1GetAudit,
 }}
 
 var (
@@ -131,21 +141,49 @@ var (
 	muxVars = mux.Vars
 )
 
+type tlsCertificateInfo struct {
+	Source    string    `json:"source"`
+	Subject   string    `json:"subject"`
+	SANs      []string  `json:"sans,omitempty"`
+	NotBefore time.Time `json:"not-before"`
+	NotAfter  time.Time `json:"not-after"`
+}
+
 func v1SystemInfo(c *Command, r *http.Request, _ *UserState) Response {
+	var tlsCert *tlsCertificateInfo
+	if c.d.options.HTTPSAddress != "" {
+		info, err := c.d.overlord.TLSManager().CertInfo()
+		if err != nil {
+			// Don't fail the whole request, as clients use it to check
+			// they can connect.
+			logger.Noticef("Cannot get TLS certificate details: %v", err)
+		} else {
+			tlsCert = &tlsCertificateInfo{
+				Source:    info.Source,
+				Subject:   info.Subject,
+				SANs:      info.SANs,
+				NotBefore: info.NotBefore,
+				NotAfter:  info.NotAfter,
+			}
+		}
+	}
+
 	state := c.d.overlord.State()
 	state.Lock()
 	defer state.Unlock()
 
 	result := struct {
-		BootID       string `json:"boot-id"`
-		HTTPAddress  string `json:"http-address,omitempty"`
-		HTTPSAddress string `json:"https-address,omitempty"`
-		Version      string `json:"version"`
+		BootID         string              `json:"boot-id"`
+		HTTPAddress    string              `json:"http-address,omitempty"`
+		HTTPSAddress   string              `json:"https-address,omitempty"`
+		TLSCertificate *tlsCertificateInfo `json:"tls-certificate,omitempty"`
+		Version        string              `json:"version"`
 	}{
-		BootID:       restart.BootID(state),
-		HTTPAddress:  c.d.options.HTTPAddress,
-		HTTPSAddress: c.d.options.HTTPSAddress,
-		Version:      c.d.Version,
+		BootID:         restart.BootID(state),
+		HTTPAddress:    c.d.options.HTTPAddress,
+		HTTPSAddress:   c.d.options.HTTPSAddress,
+		TLSCertificate: tlsCert,
+		Version:        c.d.Version,
 	}
 	return SyncResponse(result)
 }
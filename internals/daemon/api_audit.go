@@ -0,0 +1,94 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package daemon
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/canonical/x-go/strutil"
+
+	"github.com/canonical/pebble/internals/audit"
+	"github.com/canonical/pebble/internals/logger"
+)
+
+func v1GetAudit(c *Command, r *http.Request, _ *UserState) Response {
+	query := r.URL.Query()
+
+	var filter audit.Filter
+	for _, event := range strutil.MultiCommaSeparatedList(query["events"]) {
+		filter.Events = append(filter.Events, audit.Event(event))
+	}
+	filter.Users = strutil.MultiCommaSeparatedList(query["users"])
+
+	switch outcome := audit.Outcome(query.Get("outcome")); outcome {
+	case "", audit.OutcomeSuccess, audit.OutcomeFailure:
+		filter.Outcome = outcome
+	default:
+		return BadRequest(`invalid "outcome" filter: must be "success" or "failure"`)
+	}
+
+	after, err := parseOptionalTime(query.Get("after"))
+	if err != nil {
+		return BadRequest(`invalid "after" timestamp: %v`, err)
+	}
+	filter.After = after
+
+	if s := query.Get("n"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return BadRequest(`invalid "n" parameter: must be a non-negative integer`)
+		}
+		filter.Last = n
+	}
+
+	if c.d.auditLog == nil {
+		return SyncResponse([]audit.Record{})
+	}
+	records, err := c.d.auditLog.Records(filter)
+	if err != nil {
+		return InternalError("%v", err)
+	}
+	if records == nil {
+		records = []audit.Record{} // avoid null result
+	}
+	return SyncResponse(records)
+}
+
+// recordAudit adds a record to the daemon's audit log. Failures to write
+// the record are logged, but otherwise don't affect the request.
+func (d *Daemon) recordAudit(r *http.Request, user string, event audit.Event, outcome audit.Outcome, message string) {
+	if d == nil || d.auditLog == nil {
+		return
+	}
+	err := d.auditLog.Add(audit.Record{
+		Event:     event,
+		User:      user,
+		Transport: RequestTransportType(r).String(),
+		Outcome:   outcome,
+		Message:   message,
+	})
+	if err != nil {
+		logger.Noticef("Cannot add audit record: %v", err)
+	}
+}
+
+// auditOutcome returns the audit outcome corresponding to an error.
+func auditOutcome(err error) audit.Outcome {
+	if err != nil {
+		return audit.OutcomeFailure
+	}
+	return audit.OutcomeSuccess
+}
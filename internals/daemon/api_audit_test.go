@@ -0,0 +1,155 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/canonical/pebble/cmd"
+	"github.com/canonical/pebble/internals/audit"
+)
+
+func (s *apiSuite) TestAuditGet(c *C) {
+	d := s.daemon(c)
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []audit.Record{
+		{Time: base, Event: audit.EventAuthenticate, User: "bob", Transport: "https", Outcome: audit.OutcomeFailure},
+		{Time: base.Add(time.Minute), Event: audit.EventAuthenticate, User: "alice", Transport: "https", Outcome: audit.OutcomeSuccess},
+		{Time: base.Add(2 * time.Minute), Event: audit.EventExec, User: "alice", Transport: "https", Outcome: audit.OutcomeSuccess, Message: "Execute command echo"},
+		{Time: base.Add(3 * time.Minute), Event: audit.EventAuthorize, User: "bob", Transport: "http+unix", Outcome: audit.OutcomeFailure},
+	}
+	for _, r := range records {
+		c.Assert(d.auditLog.Add(r), IsNil)
+	}
+
+	tests := []struct {
+		query    url.Values
+		expected []string
+	}{{
+		query:    nil,
+		expected: []string{"authenticate/bob", "authenticate/alice", "exec/alice", "authorize/bob"},
+	}, {
+		query:    url.Values{"events": {"authenticate,authorize"}},
+		expected: []string{"authenticate/bob", "authenticate/alice", "authorize/bob"},
+	}, {
+		query:    url.Values{"users": {"bob"}, "outcome": {"failure"}},
+		expected: []string{"authenticate/bob", "authorize/bob"},
+	}, {
+		query:    url.Values{"after": {base.Add(time.Minute).Format(time.RFC3339)}},
+		expected: []string{"exec/alice", "authorize/bob"},
+	}, {
+		query:    url.Values{"n": {"1"}},
+		expected: []string{"authorize/bob"},
+	}, {
+		query:    url.Values{"users": {"carol"}},
+		expected: []string{},
+	}}
+	for _, test := range tests {
+		req, err := http.NewRequest("GET", "/v1/audit?"+test.query.Encode(), nil)
+		c.Assert(err, IsNil)
+		rsp := v1GetAudit(apiCmd("/v1/audit"), req, nil).(*resp)
+		c.Assert(rsp.Status, Equals, http.StatusOK)
+		result := rsp.Result.([]audit.Record)
+		summary := []string{}
+		for _, r := range result {
+			summary = append(summary, string(r.Event)+"/"+r.User)
+		}
+		c.Check(summary, DeepEquals, test.expected, Commentf("query %v", test.query))
+	}
+}
+
+func (s *apiSuite) TestAuditGetErrors(c *C) {
+	s.daemon(c)
+
+	tests := []struct {
+		query   string
+		message string
+	}{
+		{"outcome=maybe", `invalid "outcome" filter: must be "success" or "failure"`},
+		{"after=yesterday", `invalid "after" timestamp: .*`},
+		{"n=-1", `invalid "n" parameter: must be a non-negative integer`},
+		{"n=x", `invalid "n" parameter: must be a non-negative integer`},
+	}
+	for _, test := range tests {
+		req, err := http.NewRequest("GET", "/v1/audit?"+test.query, nil)
+		c.Assert(err, IsNil)
+		rsp := v1GetAudit(apiCmd("/v1/audit"), req, nil).(*resp)
+		c.Check(rsp.Status, Equals, http.StatusBadRequest, Commentf("query %s", test.query))
+		c.Check(rsp.Result.(*errorResult).Message, Matches, test.message)
+	}
+}
+
+func (s *apiSuite) TestAuditFile(c *C) {
+	d := s.daemon(c)
+	d.recordAudit(nil, "alice", audit.EventAddLayer, audit.OutcomeSuccess, "Add layer foo")
+
+	data, err := os.ReadFile(filepath.Join(s.pebbleDir, cmd.AuditFile))
+	c.Assert(err, IsNil)
+	var record audit.Record
+	c.Assert(json.Unmarshal(data, &record), IsNil)
+	c.Check(record.Event, Equals, audit.EventAddLayer)
+	c.Check(record.User, Equals, "alice")
+	c.Check(record.Transport, Equals, "unknown")
+	c.Check(record.Outcome, Equals, audit.OutcomeSuccess)
+	c.Check(record.Message, Equals, "Add layer foo")
+}
+
+func (s *apiSuite) TestAuditFileResults(c *C) {
+	d := s.daemon(c)
+	tmpDir := c.MkDir()
+
+	headers := http.Header{"Content-Type": []string{"application/json"}}
+	body := `{"action": "make-dirs", "dirs": [{"path": "` + tmpDir + `/newdir"}, {"path": "relative"}]}`
+	response, _ := doRequest(c, v1PostFiles, "POST", "/v1/files", nil, headers, []byte(body))
+	c.Assert(response.StatusCode, Equals, http.StatusOK)
+
+	records, err := d.auditLog.Records(audit.Filter{Events: []audit.Event{audit.EventWriteFile}})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	c.Check(records[0].Outcome, Equals, audit.OutcomeSuccess)
+	c.Check(records[0].Message, Equals, "Make directory "+tmpDir+"/newdir")
+	c.Check(records[1].Outcome, Equals, audit.OutcomeFailure)
+	c.Check(records[1].Message, Equals, "Make directory relative")
+}
+
+func (s *apiSuite) TestAuditRequiresAdmin(c *C) {
+	d := s.daemon(c)
+
+	ctx := context.WithValue(context.Background(), TransportTypeKey{}, TransportTypeUnixSocket)
+	req, err := http.NewRequestWithContext(ctx, "GET", "/v1/audit", nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = "pid=100;uid=4242;socket=;"
+	rec := httptest.NewRecorder()
+	apiCmd("/v1/audit").ServeHTTP(rec, req)
+	c.Check(rec.Code, Equals, http.StatusUnauthorized)
+
+	records, err := d.auditLog.Records(audit.Filter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Check(records[0].Event, Equals, audit.EventAuthorize)
+	c.Check(records[0].User, Equals, "4242")
+	c.Check(records[0].Transport, Equals, "http+unix")
+	c.Check(records[0].Outcome, Equals, audit.OutcomeFailure)
+	c.Check(records[0].Message, Equals, "Not authorized to GET /v1/audit")
+}
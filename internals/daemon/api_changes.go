@@ -16,9 +16,15 @@ package daemon
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
 	"time"
 
+	"github.com/canonical/x-go/strutil"
+
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/overlord/state"
 )
@@ -156,20 +162,126 @@ func v1GetChanges(c *Command, r *http.Request, _ *UserState) Response {
 		}
 	}
 
-	state := c.d.overlord.State()
-	state.Lock()
-	defer state.Unlock()
-	chgs := state.Changes()
-	chgInfos := make([]*changeInfo, 0, len(chgs))
+	if kinds := strutil.MultiCommaSeparatedList(query["kinds"]); len(kinds) > 0 {
+		outerFilter := filter
+		filter = func(chg *state.Change) bool {
+			return outerFilter(chg) && slices.Contains(kinds, chg.Kind())
+		}
+	}
+
+	if statuses := strutil.MultiCommaSeparatedList(query["statuses"]); len(statuses) > 0 {
+		for _, status := range statuses {
+			if !validChangeStatus(status) {
+				return BadRequest("invalid status %q", status)
+			}
+		}
+		outerFilter := filter
+		filter = func(chg *state.Change) bool {
+			return outerFilter(chg) && slices.Contains(statuses, chg.Status().String())
+		}
+	}
+
+	since, err := parseOptionalTime(query.Get("since"))
+	if err != nil {
+		return BadRequest(`invalid "since" timestamp: %v`, err)
+	}
+	until, err := parseOptionalTime(query.Get("until"))
+	if err != nil {
+		return BadRequest(`invalid "until" timestamp: %v`, err)
+	}
+	if !since.IsZero() || !until.IsZero() {
+		outerFilter := filter
+		filter = func(chg *state.Change) bool {
+			if !outerFilter(chg) {
+				return false
+			}
+			spawnTime := chg.SpawnTime()
+			if !since.IsZero() && spawnTime.Before(since) {
+				return false
+			}
+			if !until.IsZero() && !spawnTime.Before(until) {
+				return false
+			}
+			return true
+		}
+	}
+
+	limit, err := parseOptionalCount(query.Get("limit"))
+	if err != nil {
+		return BadRequest("invalid limit: %v", err)
+	}
+	offset, err := parseOptionalCount(query.Get("offset"))
+	if err != nil {
+		return BadRequest("invalid offset: %v", err)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+	chgs := st.Changes()
+	matched := make([]*state.Change, 0, len(chgs))
 	for _, chg := range chgs {
-		if !filter(chg) {
-			continue
+		if filter(chg) {
+			matched = append(matched, chg)
 		}
-		chgInfos = append(chgInfos, change2changeInfo(chg))
+	}
+
+	// Sort the changes so that pagination is stable across requests.
+	sort.Slice(matched, func(i, j int) bool {
+		return changeLess(matched[i], matched[j])
+	})
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+
+	chgInfos := make([]*changeInfo, len(matched))
+	for i, chg := range matched {
+		chgInfos[i] = change2changeInfo(chg)
 	}
 	return SyncResponse(chgInfos)
 }
 
+// changeLess reports whether change a sorts before change b: changes are
+// ordered by spawn time, then by ID.
+func changeLess(a, b *state.Change) bool {
+	if !a.SpawnTime().Equal(b.SpawnTime()) {
+		return a.SpawnTime().Before(b.SpawnTime())
+	}
+	// Change IDs are increasing integers, so compare by length first.
+	if len(a.ID()) != len(b.ID()) {
+		return len(a.ID()) < len(b.ID())
+	}
+	return a.ID() < b.ID()
+}
+
+func validChangeStatus(status string) bool {
+	for s := state.DefaultStatus; s <= state.WaitStatus; s++ {
+		if s.String() == status {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOptionalCount parses a non-negative integer, or returns 0 if s is empty.
+func parseOptionalCount(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("must not be negative")
+	}
+	return n, nil
+}
+
 func v1GetChange(c *Command, r *http.Request, _ *UserState) Response {
 	changeID := muxVars(r)["id"]
 	st := c.d.overlord.State()
@@ -191,6 +191,140 @@ func (s *apiSuite) TestStateChangesForServiceName(c *check.C) {
 	c.Assert(err, check.IsNil)
 }
 
+func (s *apiSuite) TestStateChangesFilterKinds(c *check.C) {
+	d := s.daemon(c)
+	st := d.overlord.State()
+	st.Lock()
+	setupChanges(st)
+	st.NewChange("replan", "replan...")
+	st.Unlock()
+
+	chgs := s.getChanges(c, "select=all&kinds=install,replan")
+	c.Assert(chgs, check.HasLen, 2)
+	c.Check(chgs[0].Kind, check.Equals, "install")
+	c.Check(chgs[1].Kind, check.Equals, "replan")
+
+	chgs = s.getChanges(c, "select=all&kinds=remove&kinds=replan")
+	c.Assert(chgs, check.HasLen, 2)
+	c.Check(chgs[0].Kind, check.Equals, "remove")
+	c.Check(chgs[1].Kind, check.Equals, "replan")
+}
+
+func (s *apiSuite) TestStateChangesFilterStatuses(c *check.C) {
+	d := s.daemon(c)
+	st := d.overlord.State()
+	st.Lock()
+	setupChanges(st)
+	st.Unlock()
+
+	chgs := s.getChanges(c, "select=all&statuses=Error")
+	c.Assert(chgs, check.HasLen, 1)
+	c.Check(chgs[0].Kind, check.Equals, "remove")
+
+	chgs = s.getChanges(c, "select=all&statuses=Do,Error")
+	c.Assert(chgs, check.HasLen, 2)
+
+	// The select filter still applies.
+	chgs = s.getChanges(c, "statuses=Error")
+	c.Assert(chgs, check.HasLen, 0)
+}
+
+func (s *apiSuite) TestStateChangesFilterTimeRange(c *check.C) {
+	d := s.daemon(c)
+	st := d.overlord.State()
+	st.Lock()
+	for i := 1; i <= 3; i++ {
+		restore := state.FakeTime(time.Date(2024, 1, i, 0, 0, 0, 0, time.UTC))
+		st.NewChange(fmt.Sprintf("kind%d", i), "...")
+		restore()
+	}
+	st.Unlock()
+
+	chgs := s.getChanges(c, "select=all&since=2024-01-02T00:00:00Z")
+	c.Assert(chgs, check.HasLen, 2)
+	c.Check(chgs[0].Kind, check.Equals, "kind2")
+	c.Check(chgs[1].Kind, check.Equals, "kind3")
+
+	// Fractional seconds are significant.
+	chgs = s.getChanges(c, "select=all&since=2024-01-02T00:00:00.000000001Z")
+	c.Assert(chgs, check.HasLen, 1)
+	c.Check(chgs[0].Kind, check.Equals, "kind3")
+
+	chgs = s.getChanges(c, "select=all&until=2024-01-02T00:00:00Z")
+	c.Assert(chgs, check.HasLen, 1)
+	c.Check(chgs[0].Kind, check.Equals, "kind1")
+
+	chgs = s.getChanges(c, "select=all&since=2024-01-02T00:00:00Z&until=2024-01-03T00:00:00Z")
+	c.Assert(chgs, check.HasLen, 1)
+	c.Check(chgs[0].Kind, check.Equals, "kind2")
+}
+
+func (s *apiSuite) TestStateChangesPagination(c *check.C) {
+	restore := state.FakeTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer restore()
+
+	d := s.daemon(c)
+	st := d.overlord.State()
+	st.Lock()
+	var ids []string
+	for i := 0; i < 12; i++ {
+		ids = append(ids, st.NewChange("foo", "...").ID())
+	}
+	st.Unlock()
+
+	// Changes with the same spawn time are ordered by ID.
+	chgs := s.getChanges(c, "select=all")
+	c.Assert(chgs, check.HasLen, 12)
+	for i, chg := range chgs {
+		c.Check(chg.ID, check.Equals, ids[i])
+	}
+
+	chgs = s.getChanges(c, "select=all&limit=5")
+	c.Assert(chgs, check.HasLen, 5)
+	c.Check(chgs[0].ID, check.Equals, ids[0])
+	c.Check(chgs[4].ID, check.Equals, ids[4])
+
+	chgs = s.getChanges(c, "select=all&limit=5&offset=10")
+	c.Assert(chgs, check.HasLen, 2)
+	c.Check(chgs[0].ID, check.Equals, ids[10])
+	c.Check(chgs[1].ID, check.Equals, ids[11])
+
+	chgs = s.getChanges(c, "select=all&offset=20")
+	c.Assert(chgs, check.HasLen, 0)
+}
+
+func (s *apiSuite) TestStateChangesBadRequest(c *check.C) {
+	s.daemon(c)
+	stateChangesCmd := apiCmd("/v1/changes")
+
+	for _, test := range []struct {
+		query string
+		error string
+	}{
+		{"statuses=Bad", `invalid status "Bad"`},
+		{"since=foo", `invalid "since" timestamp: .*`},
+		{"until=foo", `invalid "until" timestamp: .*`},
+		{"limit=foo", `invalid limit: .*`},
+		{"limit=-1", `invalid limit: must not be negative`},
+		{"offset=-1", `invalid offset: must not be negative`},
+	} {
+		req, err := http.NewRequest("GET", "/v1/changes?"+test.query, nil)
+		c.Assert(err, check.IsNil)
+		rsp := v1GetChanges(stateChangesCmd, req, nil).(*resp)
+		c.Check(rsp.Status, check.Equals, http.StatusBadRequest, check.Commentf("%s", test.query))
+		c.Check(rsp.Result.(*errorResult).Message, check.Matches, test.error)
+	}
+}
+
+func (s *apiSuite) getChanges(c *check.C, query string) []*changeInfo {
+	req, err := http.NewRequest("GET", "/v1/changes?"+query, nil)
+	c.Assert(err, check.IsNil)
+	rsp := v1GetChanges(apiCmd("/v1/changes"), req, nil).(*resp)
+	c.Assert(rsp.Status, check.Equals, http.StatusOK)
+	c.Assert(rsp.Result, check.FitsTypeOf, []*changeInfo(nil))
+	return rsp.Result.([]*changeInfo)
+}
+
 func (s *apiSuite) TestStateChange(c *check.C) {
 	restore := state.FakeTime(time.Date(2016, 04, 21, 1, 2, 3, 0, time.UTC))
 	defer restore()
@@ -19,11 +19,14 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strconv"
+	"time"
 
 	"github.com/canonical/x-go/strutil"
 
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/overlord/checkstate"
+	"github.com/canonical/pebble/internals/overlord/state"
 	"github.com/canonical/pebble/internals/plan"
 )
 
@@ -36,19 +39,37 @@ type checkInfo struct {
 	Failures  int    `json:"failures,omitempty"`
 	Threshold int    `json:"threshold"`
 	ChangeID  string `json:"change-id,omitempty"`
+
+	History []checkResult `json:"history,omitempty"`
+}
+
+type checkResult struct {
+	Time     time.Time `json:"time"`
+	Duration string    `json:"duration"`
+	Error    string    `json:"error,omitempty"`
+	Details  string    `json:"details,omitempty"`
 }
 
-func v1GetChecks(c *Command, r *http.Request, _ *UserState) Response {
+func v1GetChecks(c *Command, r *http.Request, user *UserState) Response {
 	query := r.URL.Query()
 	level := plan.CheckLevel(query.Get("level"))
 	switch level {
-	case plan.UnsetLevel, plan.AliveLevel, plan.ReadyLevel:
+	case plan.UnsetLevel, plan.AliveLevel, plan.ReadyLevel, plan.StartupLevel:
 	default:
-		return BadRequest(`level must be "alive" or "ready"`)
+		return BadRequest(`level must be "alive", "ready", or "startup"`)
 	}
 
 	names := strutil.MultiCommaSeparatedList(query["names"])
 
+	history := 0
+	if s := query.Get("history"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return BadRequest("history must be a non-negative integer")
+		}
+		history = n
+	}
+
 	checkMgr := c.d.overlord.CheckManager()
 	checks, err := checkMgr.Checks()
 	if err != nil {
@@ -59,8 +80,16 @@ func v1GetChecks(c *Command, r *http.Request, _ *UserState) Response {
 	for _, check := range checks {
 		levelMatch := level == plan.UnsetLevel || level == check.Level
 		namesMatch := len(names) == 0 || strutil.ListContains(names, check.Name)
-		if levelMatch && namesMatch {
+		if levelMatch && namesMatch && user.allowed(state.ReadChecksPermission, check.Name) {
 			info := checkInfoFromInternal(check)
+			for _, result := range checkMgr.CheckHistory(check.Name, history) {
+				info.History = append(info.History, checkResult{
+					Time:     result.Time,
+					Duration: result.Duration.String(),
+					Error:    result.Error,
+					Details:  result.Details,
+				})
+			}
 			infos = append(infos, info)
 		}
 	}
@@ -81,6 +110,11 @@ func v1PostChecks(c *Command, r *http.Request, user *UserState) Response {
 	if len(payload.Checks) == 0 {
 		return BadRequest("must specify checks for %s action", payload.Action)
 	}
+	for _, name := range payload.Checks {
+		if !user.allowed(state.ManageChecksPermission, name) {
+			return Forbidden("not allowed to manage check %q", name)
+		}
+	}
 
 	checkmgr := c.d.overlord.CheckManager()
 
@@ -114,7 +148,7 @@ func v1PostChecks(c *Command, r *http.Request, user *UserState) Response {
 	return SyncResponse(responsePayload{Changed: changed})
 }
 
-func v1PostChecksRefresh(c *Command, r *http.Request, _ *UserState) Response {
+func v1PostChecksRefresh(c *Command, r *http.Request, user *UserState) Response {
 	var payload struct {
 		Name string `json:"name"`
 	}
@@ -126,6 +160,9 @@ func v1PostChecksRefresh(c *Command, r *http.Request, _ *UserState) Response {
 	if payload.Name == "" {
 		return BadRequest("must specify check name")
 	}
+	if !user.allowed(state.ManageChecksPermission, payload.Name) {
+		return Forbidden("not allowed to manage check %q", payload.Name)
+	}
 
 	plan := c.d.overlord.PlanManager().Plan()
 	check, ok := plan.Checks[payload.Name]
@@ -28,6 +28,7 @@ import (
 	. "gopkg.in/check.v1"
 
 	"github.com/canonical/pebble/internals/logger"
+	"github.com/canonical/pebble/internals/overlord/state"
 )
 
 func (s *apiSuite) TestChecksGet(c *C) {
@@ -120,10 +121,24 @@ func (s *apiSuite) TestChecksGetInvalidLevel(c *C) {
 	c.Check(rsp.Type, Equals, ResponseTypeError)
 	c.Check(rsp.Result, NotNil)
 	c.Check(body["result"], DeepEquals, map[string]any{
-		"message": `level must be "alive" or "ready"`,
+		"message": `level must be "alive", "ready", or "startup"`,
 	})
 }
 
+func (s *apiSuite) TestChecksGetInvalidHistory(c *C) {
+	s.daemon(c)
+	s.startOverlord()
+
+	for _, history := range []string{"foo", "-1"} {
+		rsp, body := s.getChecks(c, "?history="+history)
+		c.Check(rsp.Status, Equals, 400)
+		c.Check(rsp.Type, Equals, ResponseTypeError)
+		c.Check(body["result"], DeepEquals, map[string]any{
+			"message": "history must be a non-negative integer",
+		})
+	}
+}
+
 func (s *apiSuite) TestChecksEmpty(c *C) {
 	s.daemon(c)
 	s.startOverlord()
@@ -223,6 +238,61 @@ checks:
 	ensureSecurityLog(c, logBuf.String(), "WARN", "sys_monitor_disabled:<unknown>,chk3", "Stopping check chk3")
 }
 
+func (s *apiSuite) TestChecksPermissions(c *C) {
+	writeTestLayer(s.pebbleDir, `
+checks:
+    chk1:
+        override: replace
+        startup: disabled
+        tcp:
+            port: 8080
+
+    chk2:
+        override: replace
+        startup: disabled
+        tcp:
+            port: 8081
+`)
+	s.daemon(c)
+	s.startOverlord()
+
+	user := &UserState{
+		Access: state.ReadAccess,
+		Permissions: []state.Permission{
+			{Action: state.ReadChecksPermission, Resources: []string{"chk1"}},
+			{Action: state.ManageChecksPermission, Resources: []string{"chk1"}},
+		},
+	}
+
+	// Only checks the user is allowed to read are listed.
+	req, err := http.NewRequest("GET", "/v1/checks", nil)
+	c.Assert(err, IsNil)
+	rsp := v1GetChecks(apiCmd("/v1/checks"), req, user).(*resp)
+	c.Assert(rsp.Status, Equals, 200)
+	infos := rsp.Result.([]checkInfo)
+	c.Assert(infos, HasLen, 1)
+	c.Check(infos[0].Name, Equals, "chk1")
+
+	// Managing a check that isn't allowed is forbidden.
+	req, err = http.NewRequest("POST", "/v1/checks", strings.NewReader(`{"action": "start", "checks": ["chk1", "chk2"]}`))
+	c.Assert(err, IsNil)
+	rsp = v1PostChecks(apiCmd("/v1/checks"), req, user).(*resp)
+	c.Check(rsp.Status, Equals, 403)
+	c.Check(rsp.Result.(*errorResult).Message, Equals, `not allowed to manage check "chk2"`)
+
+	req, err = http.NewRequest("POST", "/v1/checks/refresh", strings.NewReader(`{"name": "chk2"}`))
+	c.Assert(err, IsNil)
+	rsp = v1PostChecksRefresh(apiCmd("/v1/checks/refresh"), req, user).(*resp)
+	c.Check(rsp.Status, Equals, 403)
+	c.Check(rsp.Result.(*errorResult).Message, Equals, `not allowed to manage check "chk2"`)
+
+	req, err = http.NewRequest("POST", "/v1/checks", strings.NewReader(`{"action": "start", "checks": ["chk1"]}`))
+	c.Assert(err, IsNil)
+	rsp = v1PostChecks(apiCmd("/v1/checks"), req, user).(*resp)
+	c.Check(rsp.Status, Equals, 200)
+	c.Check(rsp.Result.(responsePayload).Changed, DeepEquals, []string{"chk1"})
+}
+
 func (s *apiSuite) postChecks(c *C, body string) *resp {
 	req, err := http.NewRequest("POST", "/v1/checks", strings.NewReader(body))
 	c.Assert(err, IsNil)
@@ -19,7 +19,10 @@ import (
 	"fmt"
 	"net/http"
 	"os/exec"
+	"path"
+	"strings"
 
+	"github.com/canonical/pebble/internals/audit"
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/osutil"
 	"github.com/canonical/pebble/internals/overlord/cmdstate"
@@ -60,10 +63,23 @@ func v1PostExec(c *Command, req *http.Request, user *UserState) Response {
 	}
 
 	// Check up-front that the executable exists.
-	_, err = exec.LookPath(payload.Command[0])
+	executable, err := exec.LookPath(payload.Command[0])
 	if err != nil {
 		return BadRequest("cannot find executable %q", payload.Command[0])
 	}
+	// Exec permissions match the executable's path, as resolved using the
+	// daemon's PATH (which is what's used to run it).
+	if !user.allowed(state.ExecPermission, path.Clean(executable)) {
+		return Forbidden("not allowed to execute %q", payload.Command[0])
+	}
+	if user.hasPermissions() {
+		// Exec permissions only grant running the executable, so don't let
+		// restricted users run it as another user, in a service's context,
+		// or with environment variables that change how it's loaded.
+		if rsp := checkRestrictedExec(&payload); rsp != nil {
+			return rsp
+		}
+	}
 
 	p := c.d.overlord.PlanManager().Plan()
 	overrides := plan.ContextOptions{
@@ -103,10 +119,17 @@ func v1PostExec(c *Command, req *http.Request, user *UserState) Response {
 		Height:      payload.Height,
 	}
 	task, metadata, err := cmdstate.Exec(st, args)
+	c.d.recordAudit(req, userString(user), audit.EventExec, auditOutcome(err), "Execute command "+args.Command[0])
 	if err != nil {
 		return InternalError("cannot call exec: %v", err)
 	}
 
+	if user != nil && user.Username != "" {
+		// Record who started the command, so that only they (or an admin)
+		// can connect to its websockets.
+		task.Set("identity", user.Username)
+	}
+
 	change := st.NewChange("exec", fmt.Sprintf("Execute command %q", args.Command[0]))
 	taskSet := state.NewTaskSet(task)
 	change.AddAll(taskSet)
@@ -122,3 +145,41 @@ func v1PostExec(c *Command, req *http.Request, user *UserState) Response {
 	}
 	return AsyncResponse(result, change.ID())
 }
+
+// unsafeExecEnv lists the environment variables, other than "LD_*", that
+// glibc ignores for setuid programs because they can change how a program
+// is loaded or behaves.
+var unsafeExecEnv = map[string]bool{
+	"GCONV_PATH":       true,
+	"GETCONF_DIR":      true,
+	"HOSTALIASES":      true,
+	"LOCALDOMAIN":      true,
+	"LOCPATH":          true,
+	"MALLOC_TRACE":     true,
+	"NIS_PATH":         true,
+	"NLSPATH":          true,
+	"RESOLV_HOST_CONF": true,
+	"RES_OPTIONS":      true,
+	"TMPDIR":           true,
+	"TZDIR":            true,
+}
+
+// checkRestrictedExec checks the exec options of a user with fine-grained
+// permissions.
+func checkRestrictedExec(payload *execPayload) Response {
+	if payload.UserID != nil || payload.User != "" || payload.GroupID != nil || payload.Group != "" {
+		return Forbidden("not allowed to specify user or group")
+	}
+	if payload.ServiceContext != "" {
+		return Forbidden("not allowed to specify service context")
+	}
+	if payload.WorkingDir != "" {
+		return Forbidden("not allowed to specify working directory")
+	}
+	for name := range payload.Environment {
+		if strings.HasPrefix(name, "LD_") || unsafeExecEnv[name] {
+			return Forbidden("not allowed to set environment variable %q", name)
+		}
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"os/exec"
 	"os/user"
 	"path/filepath"
 	"strconv"
@@ -431,6 +432,103 @@ func (s *execSuite) TestCommandNotFound(c *C) {
 	c.Check(execResp.Result["message"], Matches, "cannot find executable .*")
 }
 
+func (s *execSuite) TestCommandNotAllowed(c *C) {
+	user := &UserState{
+		Access: state.ReadAccess,
+		Permissions: []state.Permission{
+			{Action: state.ExecPermission, Resources: []string{"/usr/local/bin/*"}},
+		},
+	}
+	requestBody, err := json.Marshal(&execPayload{Command: []string{"echo", "foo"}})
+	c.Assert(err, IsNil)
+	httpResp, body := doUserRequest(c, v1PostExec, user, "POST", "/v1/exec", nil, nil, requestBody)
+	var execResp execResponse
+	err = json.Unmarshal(body.Bytes(), &execResp)
+	c.Assert(err, IsNil)
+	c.Check(httpResp.StatusCode, Equals, http.StatusForbidden)
+	c.Check(execResp.Type, Equals, "error")
+	c.Check(execResp.Result["message"], Equals, `not allowed to execute "echo"`)
+}
+
+func (s *execSuite) TestRestrictedOptions(c *C) {
+	echo, err := exec.LookPath("echo")
+	c.Assert(err, IsNil)
+	user := &UserState{
+		Access: state.ReadAccess,
+		Permissions: []state.Permission{
+			{Action: state.ExecPermission, Resources: []string{echo}},
+		},
+	}
+	uid := 0
+	tests := []struct {
+		payload execPayload
+		message string
+	}{
+		{execPayload{UserID: &uid}, "not allowed to specify user or group"},
+		{execPayload{User: "root"}, "not allowed to specify user or group"},
+		{execPayload{GroupID: &uid}, "not allowed to specify user or group"},
+		{execPayload{Group: "root"}, "not allowed to specify user or group"},
+		{execPayload{ServiceContext: "svc"}, "not allowed to specify service context"},
+		{execPayload{WorkingDir: "/"}, "not allowed to specify working directory"},
+		{execPayload{Environment: map[string]string{"LD_PRELOAD": "/tmp/evil.so"}}, `not allowed to set environment variable "LD_PRELOAD"`},
+		{execPayload{Environment: map[string]string{"GCONV_PATH": "/tmp"}}, `not allowed to set environment variable "GCONV_PATH"`},
+	}
+	for _, test := range tests {
+		payload := test.payload
+		payload.Command = []string{"echo", "foo"}
+		requestBody, err := json.Marshal(&payload)
+		c.Assert(err, IsNil)
+		httpResp, body := doUserRequest(c, v1PostExec, user, "POST", "/v1/exec", nil, nil, requestBody)
+		var execResp execResponse
+		err = json.Unmarshal(body.Bytes(), &execResp)
+		c.Assert(err, IsNil)
+		c.Check(httpResp.StatusCode, Equals, http.StatusForbidden)
+		c.Check(execResp.Result["message"], Equals, test.message)
+	}
+}
+
+func (s *execSuite) TestWebsocketOtherIdentity(c *C) {
+	echo, err := exec.LookPath("echo")
+	c.Assert(err, IsNil)
+	permissions := []state.Permission{
+		{Action: state.ExecPermission, Resources: []string{echo}},
+	}
+	alice := &UserState{Access: state.ReadAccess, Username: "alice", Permissions: permissions}
+	bob := &UserState{Access: state.ReadAccess, Username: "bob", Permissions: permissions}
+
+	requestBody, err := json.Marshal(&execPayload{Command: []string{"echo", "foo"}})
+	c.Assert(err, IsNil)
+	httpResp, body := doUserRequest(c, v1PostExec, alice, "POST", "/v1/exec", nil, nil, requestBody)
+	c.Assert(httpResp.StatusCode, Equals, http.StatusAccepted)
+	var execResp execResponse
+	err = json.Unmarshal(body.Bytes(), &execResp)
+	c.Assert(err, IsNil)
+	taskID, ok := execResp.Result["task-id"].(string)
+	c.Assert(ok, Equals, true)
+
+	restoreMuxVars := FakeMuxVars(func(*http.Request) map[string]string {
+		return map[string]string{"task-id": taskID, "websocket-id": "control"}
+	})
+	defer restoreMuxVars()
+	websocketCmd := apiCmd("/v1/tasks/{task-id}/websocket/{websocket-id}")
+	req, err := http.NewRequest("GET", fmt.Sprintf("/v1/tasks/%s/websocket/control", taskID), nil)
+	c.Assert(err, IsNil)
+
+	// Another identity with exec permission can't connect.
+	rsp := v1GetTaskWebsocket(websocketCmd, req, bob)
+	rec := httptest.NewRecorder()
+	rsp.ServeHTTP(rec, req)
+	c.Check(rec.Code, Equals, http.StatusForbidden)
+	c.Check(rec.Body.String(), Matches, fmt.Sprintf(`.*cannot access task \\"%s\\".*`, taskID))
+
+	// The identity that started the command and admins can.
+	_, ok = v1GetTaskWebsocket(websocketCmd, req, alice).(websocketResponse)
+	c.Check(ok, Equals, true)
+	admin := &UserState{Access: state.AdminAccess, Username: "admin"}
+	_, ok = v1GetTaskWebsocket(websocketCmd, req, admin).(websocketResponse)
+	c.Check(ok, Equals, true)
+}
+
 func (s *execSuite) TestUserGroupError(c *C) {
 	gid := os.Getgid()
 	httpResp, execResp := execRequest(c, &client.ExecOptions{
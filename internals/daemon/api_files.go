@@ -27,13 +27,16 @@ import (
 	"os"
 	"os/user"
 	pathpkg "path"
+	"path/filepath"
 	"strconv"
 	"syscall"
 	"time"
 
+	"github.com/canonical/pebble/internals/audit"
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/osutil"
 	"github.com/canonical/pebble/internals/osutil/sys"
+	"github.com/canonical/pebble/internals/overlord/state"
 )
 
 const minBoundaryLength = 32
@@ -61,7 +64,7 @@ func v1GetFiles(_ *Command, req *http.Request, user *UserState) Response {
 		if itself != "true" && itself != "false" && itself != "" {
 			return BadRequest(`itself parameter must be "true" or "false"`)
 		}
-		return listFilesResponse(path, pattern, itself == "true")
+		return listFilesResponse(path, pattern, itself == "true", user)
 	default:
 		return BadRequest("invalid action %q", action)
 	}
@@ -92,7 +95,10 @@ func (r readFilesResponse) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	result := make([]fileResult, len(r.paths))
 	for i, path := range r.paths {
 		logger.SecurityWarn(logger.SecurityAuthzAdmin, userString(r.user)+",pull_file", "Pulling file "+path)
-		err := readFile(path, mw)
+		err := checkPathAllowed(r.user, state.ReadFilesPermission, path)
+		if err == nil {
+			err = readFile(path, mw)
+		}
 		result[i] = fileResult{
 			Path:  path,
 			Error: fileErrorToResult(err),
@@ -135,6 +141,46 @@ func nonAbsolutePathError(path string) error {
 	return fmt.Errorf("paths must be absolute, got %q", path)
 }
 
+// checkPathAllowed returns a permission error if the user isn't allowed to
+// perform the action on the given path. Non-absolute paths are allowed here,
+// as they're rejected later with a more specific error.
+//
+// The path must be allowed both as given and with symbolic links resolved,
+// so that a link under an allowed directory can't be used to access files
+// outside it.
+func checkPathAllowed(user *UserState, action state.PermissionAction, path string) error {
+	if !pathpkg.IsAbs(path) || !user.hasPermissions() {
+		return nil
+	}
+	cleaned := pathpkg.Clean(path)
+	if user.allowed(action, cleaned) {
+		resolved, err := resolvePath(cleaned)
+		if err == nil && user.allowed(action, resolved) {
+			return nil
+		}
+	}
+	return fmt.Errorf("not allowed to access %q: %w", path, os.ErrPermission)
+}
+
+// resolvePath returns the absolute path with any symbolic links resolved. If
+// the path doesn't exist, its longest existing parent is resolved instead,
+// so that the result is where the path would be created.
+func resolvePath(path string) (string, error) {
+	var rest []string
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+		parent := filepath.Dir(path)
+		if !errors.Is(err, os.ErrNotExist) || parent == path {
+			return "", err
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
 func readFile(path string, mw *multipart.Writer) error {
 	if !pathpkg.IsAbs(path) {
 		return nonAbsolutePathError(path)
@@ -284,11 +330,15 @@ func fileInfoToResult(fullPath string, info os.FileInfo, userCache, groupCache m
 	return result
 }
 
-func listFilesResponse(path, pattern string, itself bool) Response {
+func listFilesResponse(path, pattern string, itself bool, user *UserState) Response {
 	if !pathpkg.IsAbs(path) {
 		return BadRequest("path must be absolute, got %q", path)
 	}
-	result, err := listFiles(path, pattern, itself)
+	err := checkPathAllowed(user, state.ReadFilesPermission, path)
+	var result []fileInfoResult
+	if err == nil {
+		result, err = listFiles(path, pattern, itself)
+	}
 	if err != nil {
 		return &resp{
 			Type:   ResponseTypeError,
@@ -344,7 +394,7 @@ func listFiles(path, pattern string, itself bool) ([]fileInfoResult, error) {
 	return result, nil
 }
 
-func v1PostFiles(_ *Command, req *http.Request, user *UserState) Response {
+func v1PostFiles(c *Command, req *http.Request, user *UserState) Response {
 	contentType := req.Header.Get("Content-Type")
 	mediaType, params, err := mime.ParseMediaType(contentType)
 	if err != nil {
@@ -357,7 +407,7 @@ func v1PostFiles(_ *Command, req *http.Request, user *UserState) Response {
 		if len(boundary) < minBoundaryLength {
 			return BadRequest("invalid boundary %q", boundary)
 		}
-		return writeFiles(req.Body, boundary, user)
+		return auditFileResults(c, req, user, "Write", writeFiles(req.Body, boundary, user))
 	case "application/json":
 		var payload struct {
 			Action string            `json:"action"`
@@ -370,9 +420,9 @@ func v1PostFiles(_ *Command, req *http.Request, user *UserState) Response {
 		}
 		switch payload.Action {
 		case "make-dirs":
-			return makeDirs(payload.Dirs)
+			return auditFileResults(c, req, user, "Make directory", makeDirs(payload.Dirs, user))
 		case "remove":
-			return removePaths(payload.Paths)
+			return auditFileResults(c, req, user, "Remove", removePaths(payload.Paths, user))
 		case "write":
 			return BadRequest(`must use multipart with "write" action`)
 		default:
@@ -383,6 +433,27 @@ func v1PostFiles(_ *Command, req *http.Request, user *UserState) Response {
 	}
 }
 
+// auditFileResults records a write-file audit record for each path in the
+// response to a files request, and returns the response unchanged.
+func auditFileResults(c *Command, req *http.Request, user *UserState, verb string, rsp Response) Response {
+	r, ok := rsp.(*resp)
+	if !ok {
+		return rsp
+	}
+	results, ok := r.Result.([]fileResult)
+	if !ok {
+		return rsp
+	}
+	for _, result := range results {
+		outcome := audit.OutcomeSuccess
+		if result.Error != nil {
+			outcome = audit.OutcomeFailure
+		}
+		c.d.recordAudit(req, userString(user), audit.EventWriteFile, outcome, verb+" "+result.Path)
+	}
+	return rsp
+}
+
 // Writing files
 
 type writeFilesItem struct {
@@ -444,7 +515,10 @@ func writeFiles(body io.Reader, boundary string, user *UserState) Response {
 			return BadRequest("no metadata for path %q", path)
 		}
 		logger.SecurityWarn(logger.SecurityAuthzAdmin, userString(user)+",push_file", "Pushing file "+path)
-		errors[path] = writeFile(info, part)
+		errors[path] = checkPathAllowed(user, state.WriteFilesPermission, path)
+		if errors[path] == nil {
+			errors[path] = writeFile(info, part)
+		}
 		part.Close()
 	}
 
@@ -560,10 +634,13 @@ type makeDirsItem struct {
 	Group       string `json:"group"`
 }
 
-func makeDirs(dirs []makeDirsItem) Response {
+func makeDirs(dirs []makeDirsItem, user *UserState) Response {
 	result := make([]fileResult, len(dirs))
 	for i, dir := range dirs {
-		err := makeDir(dir)
+		err := checkPathAllowed(user, state.WriteFilesPermission, dir.Path)
+		if err == nil {
+			err = makeDir(dir)
+		}
 		result[i] = fileResult{
 			Path:  dir.Path,
 			Error: fileErrorToResult(err),
@@ -609,10 +686,13 @@ type removePathsItem struct {
 	Recursive bool   `json:"recursive"`
 }
 
-func removePaths(paths []removePathsItem) Response {
+func removePaths(paths []removePathsItem, user *UserState) Response {
 	result := make([]fileResult, len(paths))
 	for i, path := range paths {
-		err := removePath(path.Path, path.Recursive)
+		err := checkPathAllowed(user, state.WriteFilesPermission, path.Path)
+		if err == nil {
+			err = removePath(path.Path, path.Recursive)
+		}
 		result[i] = fileResult{
 			Path:  path.Path,
 			Error: fileErrorToResult(err),
@@ -36,6 +36,7 @@ import (
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/osutil"
 	"github.com/canonical/pebble/internals/osutil/sys"
+	"github.com/canonical/pebble/internals/overlord/state"
 )
 
 var _ = Suite(&filesSuite{})
@@ -1258,7 +1259,137 @@ func readMultipart(c *C, response *http.Response, body io.Reader, result any) ma
 	return files
 }
 
+func (s *filesSuite) TestListFilesNotAllowed(c *C) {
+	tmpDir := createTestFiles(c)
+	user := &UserState{
+		Access: state.ReadAccess,
+		Permissions: []state.Permission{
+			{Action: state.ReadFilesPermission, Resources: []string{tmpDir + "/sub/**"}},
+		},
+	}
+
+	query := url.Values{
+		"action": []string{"list"},
+		"path":   []string{tmpDir},
+	}
+	response, body := doUserRequest(c, v1GetFiles, user, "GET", "/v1/files", query, nil, nil)
+	c.Assert(response.StatusCode, Equals, http.StatusForbidden)
+	assertError(c, body, http.StatusForbidden, "permission-denied", "not allowed to access .*: permission denied")
+
+	query.Set("path", tmpDir+"/sub")
+	response, _ = doUserRequest(c, v1GetFiles, user, "GET", "/v1/files", query, nil, nil)
+	c.Assert(response.StatusCode, Equals, http.StatusOK)
+}
+
+func (s *filesSuite) TestMakeDirsAndRemoveNotAllowed(c *C) {
+	tmpDir := c.MkDir()
+	user := &UserState{
+		Access: state.ReadAccess,
+		Permissions: []state.Permission{
+			{Action: state.WriteFilesPermission, Resources: []string{tmpDir + "/allowed/**"}},
+		},
+	}
+
+	headers := http.Header{
+		"Content-Type": []string{"application/json"},
+	}
+	reqBody, err := json.Marshal(map[string]any{
+		"action": "make-dirs",
+		"dirs": []makeDirsItem{
+			{Path: tmpDir + "/allowed"},
+			{Path: tmpDir + "/denied"},
+		},
+	})
+	c.Assert(err, IsNil)
+	response, body := doUserRequest(c, v1PostFiles, user, "POST", "/v1/files", nil, headers, reqBody)
+	c.Check(response.StatusCode, Equals, http.StatusOK)
+
+	var r testFilesResponse
+	c.Assert(json.NewDecoder(body).Decode(&r), IsNil)
+	c.Assert(r.Result, HasLen, 2)
+	checkFileResult(c, r.Result[0], tmpDir+"/allowed", "", "")
+	checkFileResult(c, r.Result[1], tmpDir+"/denied", "permission-denied", "not allowed to access .*: permission denied")
+	c.Check(osutil.IsDir(tmpDir+"/allowed"), Equals, true)
+	c.Check(osutil.CanStat(tmpDir+"/denied"), Equals, false)
+
+	reqBody, err = json.Marshal(map[string]any{
+		"action": "remove",
+		"paths": []removePathsItem{
+			{Path: tmpDir},
+			{Path: tmpDir + "/allowed"},
+		},
+	})
+	c.Assert(err, IsNil)
+	response, body = doUserRequest(c, v1PostFiles, user, "POST", "/v1/files", nil, headers, reqBody)
+	c.Check(response.StatusCode, Equals, http.StatusOK)
+
+	r = testFilesResponse{}
+	c.Assert(json.NewDecoder(body).Decode(&r), IsNil)
+	c.Assert(r.Result, HasLen, 2)
+	checkFileResult(c, r.Result[0], tmpDir, "permission-denied", "not allowed to access .*: permission denied")
+	checkFileResult(c, r.Result[1], tmpDir+"/allowed", "", "")
+	c.Check(osutil.CanStat(tmpDir+"/allowed"), Equals, false)
+}
+
+func (s *filesSuite) TestSymlinkEscapeNotAllowed(c *C) {
+	tmpDir := c.MkDir()
+	outside := filepath.Join(tmpDir, "outside")
+	c.Assert(os.Mkdir(outside, 0o755), IsNil)
+	c.Assert(os.Mkdir(filepath.Join(tmpDir, "allowed"), 0o755), IsNil)
+	c.Assert(os.Mkdir(filepath.Join(tmpDir, "allowed", "real"), 0o755), IsNil)
+	c.Assert(os.Symlink(outside, filepath.Join(tmpDir, "allowed", "escape")), IsNil)
+	c.Assert(os.Symlink("real", filepath.Join(tmpDir, "allowed", "inside")), IsNil)
+	user := &UserState{
+		Access: state.ReadAccess,
+		Permissions: []state.Permission{
+			{Action: state.ReadFilesPermission, Resources: []string{tmpDir + "/allowed/**"}},
+			{Action: state.WriteFilesPermission, Resources: []string{tmpDir + "/allowed/**"}},
+		},
+	}
+
+	// Listing through a link that points outside the grant is denied.
+	query := url.Values{
+		"action": []string{"list"},
+		"path":   []string{tmpDir + "/allowed/escape"},
+	}
+	response, body := doUserRequest(c, v1GetFiles, user, "GET", "/v1/files", query, nil, nil)
+	c.Assert(response.StatusCode, Equals, http.StatusForbidden)
+	assertError(c, body, http.StatusForbidden, "permission-denied", "not allowed to access .*: permission denied")
+
+	// But a link to somewhere inside the grant is fine.
+	query.Set("path", tmpDir+"/allowed/inside")
+	response, _ = doUserRequest(c, v1GetFiles, user, "GET", "/v1/files", query, nil, nil)
+	c.Assert(response.StatusCode, Equals, http.StatusOK)
+
+	// Creating paths that don't exist yet through the link is denied too.
+	headers := http.Header{
+		"Content-Type": []string{"application/json"},
+	}
+	reqBody, err := json.Marshal(map[string]any{
+		"action": "make-dirs",
+		"dirs": []makeDirsItem{
+			{Path: tmpDir + "/allowed/escape/new/dir", MakeParents: true},
+			{Path: tmpDir + "/allowed/inside/new"},
+		},
+	})
+	c.Assert(err, IsNil)
+	response, body = doUserRequest(c, v1PostFiles, user, "POST", "/v1/files", nil, headers, reqBody)
+	c.Check(response.StatusCode, Equals, http.StatusOK)
+
+	var r testFilesResponse
+	c.Assert(json.NewDecoder(body).Decode(&r), IsNil)
+	c.Assert(r.Result, HasLen, 2)
+	checkFileResult(c, r.Result[0], tmpDir+"/allowed/escape/new/dir", "permission-denied", "not allowed to access .*: permission denied")
+	checkFileResult(c, r.Result[1], tmpDir+"/allowed/inside/new", "", "")
+	c.Check(osutil.CanStat(filepath.Join(outside, "new")), Equals, false)
+	c.Check(osutil.IsDir(filepath.Join(tmpDir, "allowed", "real", "new")), Equals, true)
+}
+
 func doRequest(c *C, f ResponseFunc, method, url string, query url.Values, headers http.Header, body []byte) (*http.Response, *bytes.Buffer) {
+	return doUserRequest(c, f, nil, method, url, query, headers, body)
+}
+
+func doUserRequest(c *C, f ResponseFunc, user *UserState, method, url string, query url.Values, headers http.Header, body []byte) (*http.Response, *bytes.Buffer) {
 	var bodyReader io.Reader
 	if body != nil {
 		bodyReader = bytes.NewBuffer(body)
@@ -1269,7 +1400,7 @@ func doRequest(c *C, f ResponseFunc, method, url string, query url.Values, heade
 		req.URL.RawQuery = query.Encode()
 	}
 	req.Header = headers
-	handler := f(apiCmd(url), req, nil)
+	handler := f(apiCmd(url), req, user)
 	recorder := httptest.NewRecorder()
 	handler.ServeHTTP(recorder, req)
 	response := recorder.Result()
@@ -25,16 +25,17 @@ import (
 )
 
 type healthInfo struct {
-	Healthy bool `json:"healthy"`
+	Healthy  bool `json:"healthy"`
+	Starting bool `json:"starting,omitempty"`
 }
 
 func v1Health(c *Command, r *http.Request, _ *UserState) Response {
 	query := r.URL.Query()
 	level := plan.CheckLevel(query.Get("level"))
 	switch level {
-	case plan.UnsetLevel, plan.AliveLevel, plan.ReadyLevel:
+	case plan.UnsetLevel, plan.AliveLevel, plan.ReadyLevel, plan.StartupLevel:
 	default:
-		return BadRequest(`level must be "alive" or "ready"`)
+		return BadRequest(`level must be "alive", "ready", or "startup"`)
 	}
 
 	names := strutil.MultiCommaSeparatedList(query["names"])
@@ -46,6 +47,7 @@ func v1Health(c *Command, r *http.Request, _ *UserState) Response {
 	}
 
 	healthy := true
+	starting := false
 	status := http.StatusOK
 	for _, check := range checks {
 		levelMatch := level == plan.UnsetLevel || level == check.Level ||
@@ -56,11 +58,21 @@ func v1Health(c *Command, r *http.Request, _ *UserState) Response {
 			healthy = false
 			status = http.StatusBadGateway
 		}
+		if namesMatch && check.Starting() {
+			starting = true
+		}
+	}
+
+	// While startup checks are pending, report unhealthy, except for the
+	// alive level, which reports the alive checks as usual.
+	if starting && level != plan.AliveLevel {
+		healthy = false
+		status = http.StatusServiceUnavailable
 	}
 
 	return SyncResponse(&resp{
 		Type:   ResponseTypeSync,
 		Status: status,
-		Result: healthInfo{Healthy: healthy},
+		Result: healthInfo{Healthy: healthy, Starting: starting},
 	})
 }
@@ -205,6 +205,57 @@ func (s *healthSuite) TestNames(c *C) {
 	})
 }
 
+func (s *healthSuite) TestStarting(c *C) {
+	restore := FakeGetChecks(func(o *overlord.Overlord) ([]*checkstate.CheckInfo, error) {
+		return []*checkstate.CheckInfo{
+			{Name: "a", Level: plan.AliveLevel, Status: checkstate.CheckStatusDown, Failures: 3},
+			{Name: "a2", Level: plan.AliveLevel, Status: checkstate.CheckStatusUp},
+			{Name: "r", Level: plan.ReadyLevel, Status: checkstate.CheckStatusUp},
+			{Name: "s", Level: plan.StartupLevel, Status: checkstate.CheckStatusUp, Failures: 2},
+		}, nil
+	})
+	defer restore()
+
+	status, response := serveHealth(c, "GET", "/v1/health", nil)
+	c.Check(status, Equals, 503)
+	c.Check(response, DeepEquals, map[string]any{"healthy": false, "starting": true})
+
+	status, response = serveHealth(c, "GET", "/v1/health?level=ready", nil)
+	c.Check(status, Equals, 503)
+	c.Check(response, DeepEquals, map[string]any{"healthy": false, "starting": true})
+
+	status, response = serveHealth(c, "GET", "/v1/health?level=startup", nil)
+	c.Check(status, Equals, 503)
+	c.Check(response, DeepEquals, map[string]any{"healthy": false, "starting": true})
+
+	// Alive checks are reported as usual while starting.
+	status, response = serveHealth(c, "GET", "/v1/health?level=alive", nil)
+	c.Check(status, Equals, 502)
+	c.Check(response, DeepEquals, map[string]any{"healthy": false, "starting": true})
+	status, response = serveHealth(c, "GET", "/v1/health?level=alive&names=a2,s", nil)
+	c.Check(status, Equals, 200)
+	c.Check(response, DeepEquals, map[string]any{"healthy": true, "starting": true})
+
+	// Checks not matching the names filter are ignored.
+	status, response = serveHealth(c, "GET", "/v1/health?names=r", nil)
+	c.Check(status, Equals, 200)
+	c.Check(response, DeepEquals, map[string]any{"healthy": true})
+}
+
+func (s *healthSuite) TestStarted(c *C) {
+	restore := FakeGetChecks(func(o *overlord.Overlord) ([]*checkstate.CheckInfo, error) {
+		return []*checkstate.CheckInfo{
+			{Name: "r", Level: plan.ReadyLevel, Status: checkstate.CheckStatusUp},
+			{Name: "s", Level: plan.StartupLevel, Status: checkstate.CheckStatusUp, Successes: 1},
+		}, nil
+	})
+	defer restore()
+
+	status, response := serveHealth(c, "GET", "/v1/health?level=startup", nil)
+	c.Check(status, Equals, 200)
+	c.Check(response, DeepEquals, map[string]any{"healthy": true})
+}
+
 func (s *healthSuite) TestBadLevel(c *C) {
 	restore := FakeGetChecks(func(o *overlord.Overlord) ([]*checkstate.CheckInfo, error) {
 		return nil, nil
@@ -215,7 +266,7 @@ func (s *healthSuite) TestBadLevel(c *C) {
 
 	c.Assert(status, Equals, 400)
 	c.Assert(response, DeepEquals, map[string]any{
-		"message": `level must be "alive" or "ready"`,
+		"message": `level must be "alive", "ready", or "startup"`,
 	})
 }
 
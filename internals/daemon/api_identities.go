@@ -17,8 +17,12 @@ package daemon
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
+	"time"
 
+	"github.com/canonical/pebble/internals/audit"
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/overlord/state"
 )
@@ -32,15 +36,47 @@ func v1GetIdentities(c *Command, r *http.Request, _ *UserState) Response {
 	return SyncResponse(identities)
 }
 
+// identityActionVerbs maps identity actions to the verbs used in audit records.
+var identityActionVerbs = map[string]string{
+	"add":     "Add",
+	"update":  "Update",
+	"replace": "Replace",
+	"remove":  "Remove",
+}
+
+// rotateIdentity holds the new credentials for the "rotate" action.
+type rotateIdentity struct {
+	Basic *struct {
+		Password string `json:"password"`
+	} `json:"basic"`
+	Token *struct {
+		Hash string `json:"hash"`
+	} `json:"token"`
+}
+
 func v1PostIdentities(c *Command, r *http.Request, user *UserState) Response {
-	var payload struct {
-		Action     string                     `json:"action"`
-		Identities map[string]*state.Identity `json:"identities"`
+	var rawPayload struct {
+		Action      string          `json:"action"`
+		Identities  json.RawMessage `json:"identities"`
+		GracePeriod string          `json:"grace-period"`
 	}
 	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&payload); err != nil {
+	if err := decoder.Decode(&rawPayload); err != nil {
 		return BadRequest("cannot decode request body: %v", err)
 	}
+	if rawPayload.Action == "rotate" {
+		return postRotateIdentities(c, r, rawPayload.Identities, rawPayload.GracePeriod, user)
+	}
+
+	payload := struct {
+		Action     string
+		Identities map[string]*state.Identity
+	}{Action: rawPayload.Action}
+	if len(rawPayload.Identities) > 0 {
+		if err := json.Unmarshal(rawPayload.Identities, &payload.Identities); err != nil {
+			return BadRequest("cannot decode request body: %v", err)
+		}
+	}
 
 	var identityNames map[string]struct{}
 	switch payload.Action {
@@ -61,7 +97,7 @@ func v1PostIdentities(c *Command, r *http.Request, user *UserState) Response {
 			identityNames[name] = struct{}{}
 		}
 	default:
-		return BadRequest(`invalid action %q, must be "add", "update", "replace", or "remove"`, payload.Action)
+		return BadRequest(`invalid action %q, must be "add", "update", "replace", "remove", or "rotate"`, payload.Action)
 	}
 
 	st := c.d.overlord.State()
@@ -105,6 +141,66 @@ func v1PostIdentities(c *Command, r *http.Request, user *UserState) Response {
 		}
 		err = st.RemoveIdentities(identityNames)
 	}
+	for _, name := range slices.Sorted(maps.Keys(payload.Identities)) {
+		verb := identityActionVerbs[payload.Action]
+		if payload.Action == "replace" && payload.Identities[name] == nil {
+			verb = identityActionVerbs["remove"]
+		}
+		c.d.recordAudit(r, userString(user), audit.EventChangeIdentities, auditOutcome(err),
+			fmt.Sprintf("%s identity %s", verb, name))
+	}
+	if err != nil {
+		return BadRequest("%v", err)
+	}
+
+	return SyncResponse(nil)
+}
+
+func postRotateIdentities(c *Command, r *http.Request, rawIdentities json.RawMessage, gracePeriodStr string, user *UserState) Response {
+	var identities map[string]*rotateIdentity
+	if len(rawIdentities) > 0 {
+		if err := json.Unmarshal(rawIdentities, &identities); err != nil {
+			return BadRequest("cannot decode request body: %v", err)
+		}
+	}
+	var gracePeriod time.Duration
+	if gracePeriodStr != "" {
+		var err error
+		gracePeriod, err = time.ParseDuration(gracePeriodStr)
+		if err != nil {
+			return BadRequest("invalid grace-period: %v", err)
+		}
+	}
+
+	credentials := make(map[string]*state.IdentityCredentials, len(identities))
+	for name, identity := range identities {
+		if identity == nil {
+			return BadRequest(`identity value for %q must not be null for rotate operation`, name)
+		}
+		creds := &state.IdentityCredentials{}
+		if identity.Basic != nil {
+			creds.Basic = &state.BasicIdentity{Password: identity.Basic.Password}
+		}
+		if identity.Token != nil {
+			creds.Token = &state.TokenIdentity{Hash: identity.Token.Hash}
+		}
+		credentials[name] = creds
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	for name := range credentials {
+		logger.SecurityWarn(logger.SecurityUserUpdated,
+			fmt.Sprintf("%s,%s", userString(user), name),
+			fmt.Sprintf("Rotating credentials for user %s", name))
+	}
+	err := st.RotateIdentities(credentials, gracePeriod)
+	for _, name := range slices.Sorted(maps.Keys(credentials)) {
+		c.d.recordAudit(r, userString(user), audit.EventChangeIdentities, auditOutcome(err),
+			fmt.Sprintf("Rotate credentials for identity %s", name))
+	}
 	if err != nil {
 		return BadRequest("%v", err)
 	}
@@ -18,9 +18,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	. "gopkg.in/check.v1"
 
+	"github.com/canonical/pebble/internals/audit"
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/overlord/state"
 )
@@ -284,6 +286,15 @@ func (s *apiSuite) TestReplaceIdentities(c *C) {
 	ensureSecurityLog(c, logBuf.String(), "WARN", "user_deleted:<unknown>,bob", "Deleting user bob")
 	ensureSecurityLog(c, logBuf.String(), "WARN", "user_updated:<unknown>,mary,read", "Updating read user mary")
 	ensureSecurityLog(c, logBuf.String(), "WARN", "user_updated:<unknown>,newguy,admin", "Updating admin user newguy")
+
+	records, err := s.d.auditLog.Records(audit.Filter{Events: []audit.Event{audit.EventChangeIdentities}})
+	c.Assert(err, IsNil)
+	var messages []string
+	for _, r := range records {
+		c.Check(r.Outcome, Equals, audit.OutcomeSuccess)
+		messages = append(messages, r.Message)
+	}
+	c.Check(messages, DeepEquals, []string{"Remove identity bob", "Replace identity mary", "Replace identity newguy"})
 }
 
 func (s *apiSuite) TestRemoveIdentities(c *C) {
@@ -355,6 +366,87 @@ func (s *apiSuite) TestRemoveIdentitiesNotNull(c *C) {
 	c.Assert(result.Message, Matches, `identity value for "mary" must be null for remove operation`)
 }
 
+func (s *apiSuite) TestRotateIdentities(c *C) {
+	logBuf, restore := logger.MockLogger("")
+	defer restore()
+
+	s.daemon(c)
+
+	st := s.d.overlord.State()
+	st.Lock()
+	err := st.AddIdentities(map[string]*state.Identity{
+		"olivia": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("old-token")},
+		},
+	})
+	c.Assert(err, IsNil)
+	st.Unlock()
+
+	body := `
+{
+    "action": "rotate",
+    "grace-period": "1h",
+    "identities": {
+        "olivia": {
+            "token": {
+                "hash": "` + state.HashToken("new-token") + `"
+            }
+        }
+    }
+}`
+	rsp := s.postIdentities(c, body)
+	c.Check(rsp.Type, Equals, ResponseTypeSync)
+	c.Check(rsp.Status, Equals, http.StatusOK)
+
+	st.Lock()
+	c.Check(st.IdentityFromToken("old-token"), NotNil)
+	c.Check(st.IdentityFromToken("new-token"), NotNil)
+	olivia := st.Identities()["olivia"]
+	c.Check(olivia.Access, Equals, state.ReadAccess)
+	c.Check(olivia.Token.PreviousExpiresAt.After(time.Now().Add(59*time.Minute)), Equals, true)
+	st.Unlock()
+
+	ensureSecurityLog(c, logBuf.String(), "WARN", "user_updated:<unknown>,olivia", "Rotating credentials for user olivia")
+}
+
+func (s *apiSuite) TestRotateIdentitiesErrors(c *C) {
+	s.daemon(c)
+
+	st := s.d.overlord.State()
+	st.Lock()
+	err := st.AddIdentities(map[string]*state.Identity{
+		"bob": {
+			Access: state.ReadAccess,
+			Local:  &state.LocalIdentity{UserID: 42},
+		},
+	})
+	c.Assert(err, IsNil)
+	st.Unlock()
+
+	tests := []struct {
+		body    string
+		message string
+	}{{
+		body:    `{"action": "rotate", "grace-period": "foo", "identities": {"bob": {"token": {"hash": "x"}}}}`,
+		message: `invalid grace-period: .*`,
+	}, {
+		body:    `{"action": "rotate", "identities": {"bob": null}}`,
+		message: `identity value for "bob" must not be null for rotate operation`,
+	}, {
+		body:    `{"action": "rotate", "identities": {"bob": {"token": {"hash": "x"}}}}`,
+		message: `identity "bob" has no token credentials to rotate`,
+	}}
+	for _, test := range tests {
+		rsp := s.postIdentities(c, test.body)
+		c.Check(rsp.Type, Equals, ResponseTypeError)
+		c.Check(rsp.Status, Equals, http.StatusBadRequest)
+		result, ok := rsp.Result.(*errorResult)
+		c.Assert(ok, Equals, true)
+		c.Check(result.Message, Matches, test.message)
+	}
+}
+
 func (s *apiSuite) TestPostIdentitiesInvalidAction(c *C) {
 	s.daemon(c)
 
@@ -368,7 +460,7 @@ func (s *apiSuite) TestPostIdentitiesInvalidAction(c *C) {
 	c.Check(rsp.Status, Equals, http.StatusBadRequest)
 	result, ok := rsp.Result.(*errorResult)
 	c.Assert(ok, Equals, true)
-	c.Assert(result.Message, Matches, `invalid action "foobar", must be "add", "update", "replace", or "remove"`)
+	c.Assert(result.Message, Matches, `invalid action "foobar", must be "add", "update", "replace", "remove", or "rotate"`)
 }
 
 func (s *apiSuite) postIdentities(c *C, body string) *resp {
@@ -29,6 +29,7 @@ import (
 
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/overlord/servstate"
+	"github.com/canonical/pebble/internals/overlord/state"
 	"github.com/canonical/pebble/internals/servicelog"
 )
 
@@ -42,9 +43,10 @@ type serviceManager interface {
 	ServiceLogs(services []string, last int) (map[string]servicelog.Iterator, error)
 }
 
-func v1GetLogs(c *Command, _ *http.Request, _ *UserState) Response {
+func v1GetLogs(c *Command, _ *http.Request, user *UserState) Response {
 	return logsResponse{
 		svcMgr: overlordServiceManager(c.d.overlord),
+		user:   user,
 	}
 }
 
@@ -52,6 +54,7 @@ func v1GetLogs(c *Command, _ *http.Request, _ *UserState) Response {
 // JSON Lines format.
 type logsResponse struct {
 	svcMgr serviceManager
+	user   *UserState
 }
 
 func (r logsResponse) ServeHTTP(w http.ResponseWriter, req *http.Request) {
@@ -97,6 +100,17 @@ func (r logsResponse) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	// Only include logs from services the user is allowed to read.
+	if r.user.hasPermissions() {
+		var allowed []string
+		for _, name := range services {
+			if r.user.allowed(state.ReadLogsPermission, name) {
+				allowed = append(allowed, name)
+			}
+		}
+		services = allowed
+	}
+
 	itsByName, err := r.svcMgr.ServiceLogs(services, numLogs)
 	if err != nil {
 		response := InternalError("cannot fetch log iterators: %v", err)
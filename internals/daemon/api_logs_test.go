@@ -30,6 +30,7 @@ import (
 	. "gopkg.in/check.v1"
 
 	"github.com/canonical/pebble/internals/overlord/servstate"
+	"github.com/canonical/pebble/internals/overlord/state"
 	"github.com/canonical/pebble/internals/servicelog"
 )
 
@@ -312,6 +313,43 @@ func (s *logsSuite) TestMultipleServicesNFewLogs(c *C) {
 	checkLog(c, logs[1], "two", "message2 1")
 }
 
+func (s *logsSuite) TestPermissions(c *C) {
+	rb1 := servicelog.NewRingBuffer(4096)
+	rb2 := servicelog.NewRingBuffer(4096)
+	lw1 := servicelog.NewFormatWriter(rb1, "web")
+	lw2 := servicelog.NewFormatWriter(rb2, "db")
+	fmt.Fprintf(lw1, "web message\n")
+	fmt.Fprintf(lw2, "db message\n")
+
+	svcMgr := testServiceManager{
+		buffers: map[string]*servicelog.RingBuffer{
+			"web": rb1,
+			"db":  rb2,
+		},
+	}
+	user := &UserState{
+		Access: state.ReadAccess,
+		Permissions: []state.Permission{
+			{Action: state.ReadLogsPermission, Resources: []string{"web"}},
+		},
+	}
+
+	// Logs from services the user isn't allowed to read are omitted, whether
+	// or not the services were requested explicitly.
+	for _, url := range []string{"/v1/logs", "/v1/logs?services=web,db"} {
+		req, err := http.NewRequest("GET", url, nil)
+		c.Assert(err, IsNil)
+		rsp := logsResponse{svcMgr: svcMgr, user: user}
+		rec := httptest.NewRecorder()
+		rsp.ServeHTTP(rec, req)
+		c.Assert(rec.Code, Equals, http.StatusOK)
+
+		logs := decodeLogs(c, rec.Body)
+		c.Assert(logs, HasLen, 1)
+		checkLog(c, logs[0], "web", "web message")
+	}
+}
+
 func (s *logsSuite) TestLoggingTooFast(c *C) {
 	rb := servicelog.NewRingBuffer(1024)
 	lw := servicelog.NewFormatWriter(rb, "svc")
@@ -19,13 +19,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/canonical/x-go/strutil"
 
+	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/overlord/state"
 )
 
@@ -49,56 +53,17 @@ func v1GetNotices(c *Command, r *http.Request, user *UserState) Response {
 		return Forbidden("cannot determine UID of request, so cannot retrieve notices")
 	}
 
-	// By default, return notices with the request UID and public notices.
-	userID := user.UID
-
 	query := r.URL.Query()
-	if len(query["user-id"]) > 0 {
-		if user.Access != state.AdminAccess {
-			return Forbidden(`only admins may use the "user-id" filter`)
-		}
-		var err error
-		userID, err = sanitizeUserIDFilter(query["user-id"])
-		if err != nil {
-			return BadRequest(`invalid "user-id" filter: %v`, err)
-		}
+	filter, response := noticesFilter(query, user)
+	if response != nil {
+		return response
 	}
-
-	if len(query["users"]) > 0 {
-		if user.Access != state.AdminAccess {
-			return Forbidden(`only admins may use the "users" filter`)
-		}
-		if len(query["user-id"]) > 0 {
-			return BadRequest(`cannot use both "users" and "user-id" parameters`)
-		}
-		if query.Get("users") != "all" {
-			return BadRequest(`invalid "users" filter: must be "all"`)
-		}
-		// Clear the userID filter so all notices will be returned.
-		userID = nil
-	}
-
-	types, err := sanitizeTypesFilter(query["types"])
-	if err != nil {
+	if filter == nil {
 		// Caller did provide a types filter, but they're all invalid notice types.
 		// Return no notices, rather than the default of all notices.
 		return SyncResponse([]*state.Notice{})
 	}
 
-	keys := strutil.MultiCommaSeparatedList(query["keys"])
-
-	after, err := parseOptionalTime(query.Get("after"))
-	if err != nil {
-		return BadRequest(`invalid "after" timestamp: %v`, err)
-	}
-
-	filter := &state.NoticeFilter{
-		UserID: userID,
-		Types:  types,
-		Keys:   keys,
-		After:  after,
-	}
-
 	timeout, err := parseOptionalDuration(query.Get("timeout"))
 	if err != nil {
 		return BadRequest("invalid timeout: %v", err)
@@ -136,6 +101,78 @@ func v1GetNotices(c *Command, r *http.Request, user *UserState) Response {
 	return SyncResponse(notices)
 }
 
+// noticesFilter constructs the notices filter from the query parameters of a
+// notices request. It returns a nil filter (and nil response) if the request
+// has a types filter but all its types are invalid, meaning no notices match.
+func noticesFilter(query url.Values, user *UserState) (*state.NoticeFilter, Response) {
+	// By default, return notices with the request UID and public notices.
+	userID := user.UID
+
+	if len(query["user-id"]) > 0 {
+		if user.Access != state.AdminAccess {
+			return nil, Forbidden(`only admins may use the "user-id" filter`)
+		}
+		var err error
+		userID, err = sanitizeUserIDFilter(query["user-id"])
+		if err != nil {
+			return nil, BadRequest(`invalid "user-id" filter: %v`, err)
+		}
+	}
+
+	if len(query["users"]) > 0 {
+		if user.Access != state.AdminAccess {
+			return nil, Forbidden(`only admins may use the "users" filter`)
+		}
+		if len(query["user-id"]) > 0 {
+			return nil, BadRequest(`cannot use both "users" and "user-id" parameters`)
+		}
+		if query.Get("users") != "all" {
+			return nil, BadRequest(`invalid "users" filter: must be "all"`)
+		}
+		// Clear the userID filter so all notices will be returned.
+		userID = nil
+	}
+
+	types, err := sanitizeTypesFilter(query["types"])
+	if err != nil {
+		// Caller did provide a types filter, but they're all invalid notice
+		// types, so no notices match (rather than the default of all notices).
+		return nil, nil
+	}
+
+	keys := strutil.MultiCommaSeparatedList(query["keys"])
+
+	after, err := parseOptionalTime(query.Get("after"))
+	if err != nil {
+		return nil, BadRequest(`invalid "after" timestamp: %v`, err)
+	}
+
+	before, err := parseOptionalTime(query.Get("before"))
+	if err != nil {
+		return nil, BadRequest(`invalid "before" timestamp: %v`, err)
+	}
+
+	filter := &state.NoticeFilter{
+		UserID: userID,
+		Types:  types,
+		Keys:   keys,
+		After:  after,
+		Before: before,
+	}
+
+	switch query.Get("unacknowledged") {
+	case "", "false":
+	case "true":
+		// Acknowledgements are per user, so this always refers to the
+		// requesting user's acknowledgements.
+		filter.UnacknowledgedBy = user.UID
+	default:
+		return nil, BadRequest(`unacknowledged parameter must be "true" or "false"`)
+	}
+
+	return filter, nil
+}
+
 // Construct the user IDs filter which will be passed to state.Notices.
 // Must only be called if the query user ID argument is set.
 func sanitizeUserIDFilter(queryUserID []string) (*uint32, error) {
@@ -178,26 +215,48 @@ func sanitizeTypesFilter(queryTypes []string) ([]state.NoticeType, error) {
 	return types, nil
 }
 
+type postNoticesPayload struct {
+	Action string `json:"action"`
+
+	// Fields for the "add" action
+	Type        string          `json:"type"`
+	Key         string          `json:"key"`
+	RepeatAfter string          `json:"repeat-after"`
+	DataJSON    json.RawMessage `json:"data"`
+
+	// Fields for the "acknowledge" action
+	Types  []string `json:"types"`
+	Keys   []string `json:"keys"`
+	Before string   `json:"before"`
+
+	// Fields for the "delete" action
+	ID string `json:"id"`
+}
+
 func v1PostNotices(c *Command, r *http.Request, user *UserState) Response {
 	if user == nil || user.UID == nil {
 		return Forbidden("cannot determine UID of request, so cannot create notice")
 	}
 
-	var payload struct {
-		Action      string          `json:"action"`
-		Type        string          `json:"type"`
-		Key         string          `json:"key"`
-		RepeatAfter string          `json:"repeat-after"`
-		DataJSON    json.RawMessage `json:"data"`
-	}
+	var payload postNoticesPayload
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&payload); err != nil {
 		return BadRequest("cannot decode request body: %v", err)
 	}
 
-	if payload.Action != "add" {
+	switch payload.Action {
+	case "add":
+		return postAddNotice(c, &payload, user)
+	case "acknowledge":
+		return postAcknowledgeNotices(c, &payload, user)
+	case "delete":
+		return postDeleteNotice(c, &payload, user)
+	default:
 		return BadRequest("invalid action %q", payload.Action)
 	}
+}
+
+func postAddNotice(c *Command, payload *postNoticesPayload, user *UserState) Response {
 	if payload.Type != "custom" {
 		return BadRequest(`invalid type %q (can only add "custom" notices)`, payload.Type)
 	}
@@ -239,6 +298,62 @@ func v1PostNotices(c *Command, r *http.Request, user *UserState) Response {
 	return SyncResponse(addedNotice{ID: noticeId})
 }
 
+type acknowledgedNotices struct {
+	Count int `json:"count"`
+}
+
+// postAcknowledgeNotices acknowledges the notices visible to the user (their
+// own and public notices) that match the given filters, on behalf of that
+// user only.
+func postAcknowledgeNotices(c *Command, payload *postNoticesPayload, user *UserState) Response {
+	types, err := sanitizeTypesFilter(payload.Types)
+	if err != nil {
+		// None of the requested types are valid, so no notices match.
+		return SyncResponse(acknowledgedNotices{Count: 0})
+	}
+	before, err := parseOptionalTime(payload.Before)
+	if err != nil {
+		return BadRequest(`invalid "before" timestamp: %v`, err)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	count := st.AcknowledgeNotices(*user.UID, &state.NoticeFilter{
+		UserID: user.UID,
+		Types:  types,
+		Keys:   payload.Keys,
+		Before: before,
+	})
+	return SyncResponse(acknowledgedNotices{Count: count})
+}
+
+// postDeleteNotice deletes a custom notice, which only its owner or an admin
+// may do.
+func postDeleteNotice(c *Command, payload *postNoticesPayload, user *UserState) Response {
+	if payload.ID == "" {
+		return BadRequest("must specify notice ID to delete")
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	notice := st.Notice(payload.ID)
+	if notice == nil {
+		return NotFound("cannot find notice with ID %q", payload.ID)
+	}
+	if !noticeViewableByUser(notice, user) {
+		return Forbidden("not allowed to delete notice with ID %q", payload.ID)
+	}
+	if notice.Type() != state.CustomNotice {
+		return BadRequest(`cannot delete %q notice (can only delete "custom" notices)`, notice.Type())
+	}
+	st.DeleteNotice(payload.ID)
+	return SyncResponse(nil)
+}
+
 func v1GetNotice(c *Command, r *http.Request, user *UserState) Response {
 	if user == nil || user.UID == nil {
 		return Forbidden("cannot determine UID of request, so cannot retrieve notice")
@@ -270,3 +385,107 @@ func noticeViewableByUser(notice *state.Notice, user *UserState) bool {
 	// Otherwise user's UID must match notice's UID.
 	return *user.UID == userID
 }
+
+// noticesKeepaliveInterval is how often a keepalive comment is sent on a
+// notices stream when no notices occur. It's a variable so tests can
+// override it.
+var noticesKeepaliveInterval = 30 * time.Second
+
+func v1GetNoticesStream(c *Command, r *http.Request, user *UserState) Response {
+	if user == nil || user.UID == nil {
+		return Forbidden("cannot determine UID of request, so cannot stream notices")
+	}
+
+	query := r.URL.Query()
+	filter, response := noticesFilter(query, user)
+	if response != nil {
+		return response
+	}
+
+	// EventSource clients send the ID of the last event they received when
+	// reconnecting. Event IDs are notices' last-repeated times, so resume
+	// after that unless "after" was specified explicitly.
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if filter != nil && lastEventID != "" && query.Get("after") == "" {
+		after, err := time.Parse(time.RFC3339Nano, lastEventID)
+		if err != nil {
+			return BadRequest("invalid Last-Event-ID header: %v", err)
+		}
+		filter.After = after
+	}
+
+	return noticesStreamResponse{
+		state:  c.d.overlord.State(),
+		filter: filter,
+	}
+}
+
+// noticesStreamResponse is a Response implementation that streams notices
+// as server-sent events until the request is cancelled.
+type noticesStreamResponse struct {
+	state *state.State
+
+	// filter is nil if no notices can match, in which case only keepalives
+	// are sent.
+	filter *state.NoticeFilter
+}
+
+func (r noticesStreamResponse) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flushWriter(w)
+
+	ctx := req.Context()
+	for {
+		notices, err := r.waitNotices(ctx)
+		if ctx.Err() != nil {
+			return // request cancelled
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			// Send a comment so proxies and clients know the stream is alive.
+			_, err = io.WriteString(w, ": keepalive\n\n")
+			if err != nil {
+				return
+			}
+			flushWriter(w)
+			continue
+		}
+		if err != nil {
+			logger.Noticef("Cannot wait for notices: %v", err)
+			return
+		}
+
+		for _, notice := range notices {
+			data, err := json.Marshal(notice)
+			if err != nil {
+				logger.Noticef("Cannot marshal notice %s: %v", notice, err)
+				return
+			}
+			_, err = fmt.Fprintf(w, "id: %s\nevent: notice\ndata: %s\n\n",
+				notice.LastRepeated().Format(time.RFC3339Nano), data)
+			if err != nil {
+				return
+			}
+		}
+		flushWriter(w)
+		r.filter.After = notices[len(notices)-1].LastRepeated()
+	}
+}
+
+// waitNotices waits up to the keepalive interval for notices matching the
+// filter, returning context.DeadlineExceeded if none occur.
+func (r noticesStreamResponse) waitNotices(ctx context.Context) ([]*state.Notice, error) {
+	ctx, cancel := context.WithTimeout(ctx, noticesKeepaliveInterval)
+	defer cancel()
+
+	if r.filter == nil {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	r.state.Lock()
+	defer r.state.Unlock()
+	// WaitNotices releases the state lock while waiting.
+	return r.state.WaitNotices(ctx, r.filter)
+}
@@ -23,6 +23,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	. "gopkg.in/check.v1"
@@ -550,6 +551,14 @@ func (s *apiSuite) TestNoticesInvalidAfter(c *C) {
 	s.testNoticesBadRequest(c, "after=foo", `invalid "after" timestamp.*`)
 }
 
+func (s *apiSuite) TestNoticesInvalidBefore(c *C) {
+	s.testNoticesBadRequest(c, "before=foo", `invalid "before" timestamp.*`)
+}
+
+func (s *apiSuite) TestNoticesInvalidUnacknowledged(c *C) {
+	s.testNoticesBadRequest(c, "unacknowledged=foo", `unacknowledged parameter must be "true" or "false"`)
+}
+
 func (s *apiSuite) TestNoticesInvalidTimeout(c *C) {
 	s.testNoticesBadRequest(c, "timeout=foo", "invalid timeout.*")
 }
@@ -747,6 +756,194 @@ func (s *apiSuite) testAddNoticeBadRequest(c *C, body, errorMatch string) {
 	c.Assert(result.Message, Matches, errorMatch)
 }
 
+func (s *apiSuite) TestNoticesFilterBefore(c *C) {
+	s.daemon(c)
+
+	st := s.d.overlord.State()
+	st.Lock()
+	addNotice(c, st, nil, state.CustomNotice, "a.b/1", nil)
+	time.Sleep(time.Microsecond)
+	before := time.Now()
+	time.Sleep(time.Microsecond)
+	addNotice(c, st, nil, state.CustomNotice, "a.b/2", nil)
+	st.Unlock()
+
+	notices := s.getNotices(c, "before="+url.QueryEscape(before.UTC().Format(time.RFC3339Nano)), userState(state.ReadAccess, 1000))
+	c.Assert(notices, HasLen, 1)
+	c.Check(notices[0]["key"], Equals, "a.b/1")
+}
+
+func (s *apiSuite) TestAcknowledgeNotices(c *C) {
+	s.daemon(c)
+
+	st := s.d.overlord.State()
+	st.Lock()
+	addNotice(c, st, nil, state.CustomNotice, "a.b/1", nil)
+	addNotice(c, st, nil, state.WarningNotice, "danger", nil)
+	uid := uint32(1001)
+	addNotice(c, st, &uid, state.CustomNotice, "a.b/2", nil)
+	st.Unlock()
+
+	// Acknowledging only affects notices visible to the user.
+	rsp := s.postNotices(c, `{"action": "acknowledge", "types": ["custom"]}`, userState(state.ReadAccess, 1000))
+	c.Check(rsp.Status, Equals, http.StatusOK)
+	c.Check(rsp.Result, DeepEquals, acknowledgedNotices{Count: 1})
+
+	notices := s.getNotices(c, "unacknowledged=true", userState(state.ReadAccess, 1000))
+	c.Assert(notices, HasLen, 1)
+	c.Check(notices[0]["key"], Equals, "danger")
+
+	// Acknowledgements are per user.
+	notices = s.getNotices(c, "unacknowledged=true&users=all", userState(state.AdminAccess, 1001))
+	c.Assert(notices, HasLen, 3)
+
+	// Acknowledged notices are still returned when not filtering.
+	notices = s.getNotices(c, "", userState(state.ReadAccess, 1000))
+	c.Assert(notices, HasLen, 2)
+
+	// A repeated notice becomes unacknowledged again.
+	st.Lock()
+	addNotice(c, st, nil, state.CustomNotice, "a.b/1", nil)
+	st.Unlock()
+	notices = s.getNotices(c, "unacknowledged=true&types=custom", userState(state.ReadAccess, 1000))
+	c.Assert(notices, HasLen, 1)
+	c.Check(notices[0]["key"], Equals, "a.b/1")
+}
+
+func (s *apiSuite) TestAcknowledgeNoticesBefore(c *C) {
+	s.daemon(c)
+
+	st := s.d.overlord.State()
+	st.Lock()
+	addNotice(c, st, nil, state.CustomNotice, "a.b/1", nil)
+	time.Sleep(time.Microsecond)
+	before := time.Now()
+	time.Sleep(time.Microsecond)
+	addNotice(c, st, nil, state.CustomNotice, "a.b/2", nil)
+	st.Unlock()
+
+	body, err := json.Marshal(map[string]any{
+		"action": "acknowledge",
+		"before": before.UTC().Format(time.RFC3339Nano),
+	})
+	c.Assert(err, IsNil)
+	rsp := s.postNotices(c, string(body), userState(state.ReadAccess, 1000))
+	c.Check(rsp.Status, Equals, http.StatusOK)
+	c.Check(rsp.Result, DeepEquals, acknowledgedNotices{Count: 1})
+
+	notices := s.getNotices(c, "unacknowledged=true", userState(state.ReadAccess, 1000))
+	c.Assert(notices, HasLen, 1)
+	c.Check(notices[0]["key"], Equals, "a.b/2")
+}
+
+func (s *apiSuite) TestAcknowledgeNoticesInvalidBefore(c *C) {
+	s.testAddNoticeBadRequest(c, `{"action": "acknowledge", "before": "foo"}`, `invalid "before" timestamp.*`)
+}
+
+func (s *apiSuite) TestDeleteNotice(c *C) {
+	s.daemon(c)
+
+	st := s.d.overlord.State()
+	st.Lock()
+	uid := uint32(1000)
+	noticeID, err := st.AddNotice(&uid, state.CustomNotice, "a.b/1", nil)
+	c.Assert(err, IsNil)
+	st.Unlock()
+
+	rsp := s.postNotices(c, `{"action": "delete", "id": "`+noticeID+`"}`, userState(state.ReadAccess, 1000))
+	c.Check(rsp.Type, Equals, ResponseTypeSync)
+	c.Check(rsp.Status, Equals, http.StatusOK)
+
+	st.Lock()
+	c.Check(st.Notice(noticeID), IsNil)
+	st.Unlock()
+
+	rsp = s.postNotices(c, `{"action": "delete", "id": "`+noticeID+`"}`, userState(state.ReadAccess, 1000))
+	c.Check(rsp.Status, Equals, http.StatusNotFound)
+}
+
+func (s *apiSuite) TestDeleteNoticeAdminAllowed(c *C) {
+	s.daemon(c)
+
+	st := s.d.overlord.State()
+	st.Lock()
+	uid := uint32(1000)
+	noticeID, err := st.AddNotice(&uid, state.CustomNotice, "a.b/1", nil)
+	c.Assert(err, IsNil)
+	st.Unlock()
+
+	rsp := s.postNotices(c, `{"action": "delete", "id": "`+noticeID+`"}`, userState(state.AdminAccess, 0))
+	c.Check(rsp.Status, Equals, http.StatusOK)
+
+	st.Lock()
+	c.Check(st.Notice(noticeID), IsNil)
+	st.Unlock()
+}
+
+func (s *apiSuite) TestDeleteNoticeNonAdminNotAllowed(c *C) {
+	s.daemon(c)
+
+	st := s.d.overlord.State()
+	st.Lock()
+	uid := uint32(1000)
+	noticeID, err := st.AddNotice(&uid, state.CustomNotice, "a.b/1", nil)
+	c.Assert(err, IsNil)
+	st.Unlock()
+
+	rsp := s.postNotices(c, `{"action": "delete", "id": "`+noticeID+`"}`, userState(state.ReadAccess, 1001))
+	c.Check(rsp.Status, Equals, http.StatusForbidden)
+
+	st.Lock()
+	c.Check(st.Notice(noticeID), NotNil)
+	st.Unlock()
+}
+
+func (s *apiSuite) TestDeleteNoticeNotCustom(c *C) {
+	s.daemon(c)
+
+	st := s.d.overlord.State()
+	st.Lock()
+	noticeID, err := st.AddNotice(nil, state.WarningNotice, "danger", nil)
+	c.Assert(err, IsNil)
+	st.Unlock()
+
+	rsp := s.postNotices(c, `{"action": "delete", "id": "`+noticeID+`"}`, userState(state.AdminAccess, 0))
+	c.Check(rsp.Status, Equals, http.StatusBadRequest)
+	result, ok := rsp.Result.(*errorResult)
+	c.Assert(ok, Equals, true)
+	c.Check(result.Message, Matches, `cannot delete "warning" notice.*`)
+}
+
+func (s *apiSuite) TestDeleteNoticeMissingID(c *C) {
+	s.testAddNoticeBadRequest(c, `{"action": "delete"}`, "must specify notice ID to delete")
+}
+
+func (s *apiSuite) getNotices(c *C, query string, user *UserState) []map[string]any {
+	req, err := http.NewRequest("GET", "/v1/notices?"+query, nil)
+	c.Assert(err, IsNil)
+	noticesCmd := apiCmd("/v1/notices")
+	rsp, ok := noticesCmd.GET(noticesCmd, req, user).(*resp)
+	c.Assert(ok, Equals, true)
+	c.Assert(rsp.Status, Equals, http.StatusOK)
+
+	notices, ok := rsp.Result.([]*state.Notice)
+	c.Assert(ok, Equals, true)
+	maps := make([]map[string]any, len(notices))
+	for i, notice := range notices {
+		maps[i] = noticeToMap(c, notice)
+	}
+	return maps
+}
+
+func (s *apiSuite) postNotices(c *C, body string, user *UserState) *resp {
+	req, err := http.NewRequest("POST", "/v1/notices", strings.NewReader(body))
+	c.Assert(err, IsNil)
+	noticesCmd := apiCmd("/v1/notices")
+	rsp, ok := noticesCmd.POST(noticesCmd, req, user).(*resp)
+	c.Assert(ok, Equals, true)
+	return rsp
+}
+
 func (s *apiSuite) TestNotice(c *C) {
 	s.daemon(c)
 
@@ -876,6 +1073,180 @@ func (s *apiSuite) TestNoticeNonAdminNotAllowed(c *C) {
 	c.Assert(ok, Equals, true)
 }
 
+func (s *apiSuite) TestNoticesStream(c *C) {
+	s.daemon(c)
+
+	st := s.d.overlord.State()
+	st.Lock()
+	addNotice(c, st, nil, state.CustomNotice, "a.b/1", nil)
+	otherUID := uint32(1001)
+	addNotice(c, st, &otherUID, state.CustomNotice, "a.b/private", nil)
+	st.Unlock()
+
+	rec, stop := s.startNoticesStream(c, "/v1/notices/stream?types=custom", nil)
+	defer stop()
+	events := waitStreamEvents(c, rec, 1)
+	c.Check(events[0]["event"], Equals, "notice")
+	c.Check(events[0]["data"], Matches, `\{"id":"1",.*"key":"a.b/1".*\}`)
+
+	st.Lock()
+	addNotice(c, st, nil, state.WarningNotice, "not matched", nil)
+	addNotice(c, st, nil, state.CustomNotice, "a.b/2", nil)
+	notices := st.Notices(&state.NoticeFilter{Keys: []string{"a.b/2"}})
+	st.Unlock()
+
+	events = waitStreamEvents(c, rec, 2)
+	c.Assert(events, HasLen, 2)
+	c.Check(events[1]["id"], Equals, notices[0].LastRepeated().Format(time.RFC3339Nano))
+	c.Check(events[1]["data"], Matches, `\{.*"key":"a.b/2".*\}`)
+	c.Check(rec.Header().Get("Content-Type"), Equals, "text/event-stream")
+}
+
+func (s *apiSuite) TestNoticesStreamResume(c *C) {
+	s.daemon(c)
+
+	st := s.d.overlord.State()
+	st.Lock()
+	addNotice(c, st, nil, state.CustomNotice, "a.b/1", nil)
+	time.Sleep(time.Microsecond) // ensure there's time between the occurrences
+	addNotice(c, st, nil, state.CustomNotice, "a.b/2", nil)
+	notices := st.Notices(nil)
+	st.Unlock()
+	c.Assert(notices, HasLen, 2)
+
+	rec, stop := s.startNoticesStream(c, "/v1/notices/stream", map[string]string{
+		"Last-Event-ID": notices[0].LastRepeated().Format(time.RFC3339Nano),
+	})
+	defer stop()
+	events := waitStreamEvents(c, rec, 1)
+	c.Assert(events, HasLen, 1)
+	c.Check(events[0]["data"], Matches, `\{.*"key":"a.b/2".*\}`)
+}
+
+func (s *apiSuite) TestNoticesStreamKeepalive(c *C) {
+	s.daemon(c)
+	restore := fakeNoticesKeepaliveInterval(10 * time.Millisecond)
+	defer restore()
+
+	// Keepalives are also sent if no notices can match.
+	rec, stop := s.startNoticesStream(c, "/v1/notices/stream?types=foo", nil)
+	defer stop()
+	for i := 0; i < 100 && !strings.Contains(rec.String(), ": keepalive\n\n"); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Check(rec.String(), Matches, `(?s)^: keepalive\n\n.*`)
+}
+
+func (s *apiSuite) TestNoticesStreamBadRequest(c *C) {
+	s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v1/notices/stream", nil)
+	c.Assert(err, IsNil)
+	req.Header.Set("Last-Event-ID", "foo")
+	streamCmd := apiCmd("/v1/notices/stream")
+	rsp, ok := streamCmd.GET(streamCmd, req, userState(state.ReadAccess, 1000)).(*resp)
+	c.Assert(ok, Equals, true)
+	c.Check(rsp.Status, Equals, http.StatusBadRequest)
+	c.Check(rsp.Result.(*errorResult).Message, Matches, "invalid Last-Event-ID header: .*")
+
+	req, err = http.NewRequest("GET", "/v1/notices/stream?after=foo", nil)
+	c.Assert(err, IsNil)
+	rsp, ok = streamCmd.GET(streamCmd, req, userState(state.ReadAccess, 1000)).(*resp)
+	c.Assert(ok, Equals, true)
+	c.Check(rsp.Status, Equals, http.StatusBadRequest)
+}
+
+// startNoticesStream serves a notices stream request as user 1000 in the
+// background, returning the recorder and a function that cancels the request
+// and waits for the handler to finish.
+func (s *apiSuite) startNoticesStream(c *C, url string, headers map[string]string) (*syncRecorder, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	c.Assert(err, IsNil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	streamCmd := apiCmd("/v1/notices/stream")
+	rsp := streamCmd.GET(streamCmd, req, userState(state.ReadAccess, 1000))
+	_, ok := rsp.(noticesStreamResponse)
+	c.Assert(ok, Equals, true, Commentf("unexpected response %#v", rsp))
+
+	rec := &syncRecorder{header: make(http.Header)}
+	done := make(chan struct{})
+	go func() {
+		rsp.ServeHTTP(rec, req)
+		close(done)
+	}()
+	return rec, func() {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			c.Fatalf("timed out waiting for notices stream to finish")
+		}
+	}
+}
+
+// waitStreamEvents waits until at least n server-sent events have been
+// written, and returns the parsed events.
+func waitStreamEvents(c *C, rec *syncRecorder, n int) []map[string]string {
+	for i := 0; i < 500; i++ {
+		var events []map[string]string
+		for _, block := range strings.Split(rec.String(), "\n\n") {
+			event := make(map[string]string)
+			for _, line := range strings.Split(block, "\n") {
+				field, value, ok := strings.Cut(line, ": ")
+				if ok && field != "" {
+					event[field] = value
+				}
+			}
+			if len(event) > 0 {
+				events = append(events, event)
+			}
+		}
+		if len(events) >= n {
+			return events
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Fatalf("timed out waiting for %d events, got output %q", n, rec.String())
+	return nil
+}
+
+func fakeNoticesKeepaliveInterval(d time.Duration) (restore func()) {
+	old := noticesKeepaliveInterval
+	noticesKeepaliveInterval = d
+	return func() {
+		noticesKeepaliveInterval = old
+	}
+}
+
+// syncRecorder is an http.ResponseWriter that can be read while the handler
+// is writing to it.
+type syncRecorder struct {
+	mu     sync.Mutex
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *syncRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *syncRecorder) WriteHeader(statusCode int) {}
+
+func (r *syncRecorder) Write(data []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.Write(data)
+}
+
+func (r *syncRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.String()
+}
+
 func noticeToMap(c *C, notice *state.Notice) map[string]any {
 	buf, err := json.Marshal(notice)
 	c.Assert(err, IsNil)
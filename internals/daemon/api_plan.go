@@ -20,6 +20,7 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/canonical/pebble/internals/audit"
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/overlord/planstate"
 	"github.com/canonical/pebble/internals/plan"
@@ -76,6 +77,7 @@ func v1PostLayers(c *Command, r *http.Request, user *UserState) Response {
 	} else {
 		err = planMgr.AppendLayer(layer, payload.Inner)
 	}
+	c.d.recordAudit(r, userString(user), audit.EventAddLayer, auditOutcome(err), "Add layer "+payload.Label)
 	if err != nil {
 		if _, ok := err.(*planstate.LabelExists); ok {
 			return BadRequest("%v", err)
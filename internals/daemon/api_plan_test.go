@@ -22,6 +22,7 @@ import (
 	. "gopkg.in/check.v1"
 	"gopkg.in/yaml.v3"
 
+	"github.com/canonical/pebble/internals/audit"
 	"github.com/canonical/pebble/internals/logger"
 )
 
@@ -157,6 +158,13 @@ services:
 	s.planLayersHasLen(c, 2)
 
 	ensureSecurityLog(c, logBuf.String(), "WARN", "authz_admin:<unknown>,add_layer", "Adding layer foo")
+
+	records, err := s.d.auditLog.Records(audit.Filter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Check(records[0].Event, Equals, audit.EventAddLayer)
+	c.Check(records[0].Outcome, Equals, audit.OutcomeSuccess)
+	c.Check(records[0].Message, Equals, "Add layer foo")
 }
 
 func (s *apiSuite) TestLayersAddCombine(c *C) {
@@ -35,7 +35,7 @@ type serviceInfo struct {
 	CurrentSince *time.Time `json:"current-since,omitempty"` // pointer as omitempty doesn't work with time.Time directly
 }
 
-func v1GetServices(c *Command, r *http.Request, _ *UserState) Response {
+func v1GetServices(c *Command, r *http.Request, user *UserState) Response {
 	names := strutil.MultiCommaSeparatedList(r.URL.Query()["names"])
 
 	servmgr := overlordServiceManager(c.d.overlord)
@@ -46,6 +46,9 @@ func v1GetServices(c *Command, r *http.Request, _ *UserState) Response {
 
 	infos := make([]serviceInfo, 0, len(services))
 	for _, svc := range services {
+		if !user.allowed(state.ReadServicesPermission, svc.Name) {
+			continue
+		}
 		info := serviceInfo{
 			Name:    svc.Name,
 			Startup: string(svc.Startup),
@@ -59,7 +62,7 @@ func v1GetServices(c *Command, r *http.Request, _ *UserState) Response {
 	return SyncResponse(infos)
 }
 
-func v1PostServices(c *Command, r *http.Request, _ *UserState) Response {
+func v1PostServices(c *Command, r *http.Request, user *UserState) Response {
 	var payload struct {
 		Action   string   `json:"action"`
 		Services []string `json:"services"`
@@ -99,6 +102,24 @@ func v1PostServices(c *Command, r *http.Request, _ *UserState) Response {
 		}
 	}
 
+	if user.hasPermissions() {
+		// The user must be allowed to manage all the services affected,
+		// including dependencies that will be started or stopped too.
+		var lanes [][]string
+		switch payload.Action {
+		case "start", "autostart", "restart":
+			lanes, err = servmgr.StartOrder(payload.Services)
+		case "stop":
+			lanes, err = servmgr.StopOrder(payload.Services)
+		}
+		if err == nil {
+			if rsp := checkManageServices(user, lanes); rsp != nil {
+				return rsp
+			}
+		}
+		// Any error is reported below, when the services are ordered again.
+	}
+
 	st := c.d.overlord.State()
 	st.Lock()
 	defer st.Unlock()
@@ -149,6 +170,12 @@ func v1PostServices(c *Command, r *http.Request, _ *UserState) Response {
 		if err != nil {
 			break
 		}
+		if rsp := checkManageServices(user, stopLanes); rsp != nil {
+			return rsp
+		}
+		if rsp := checkManageServices(user, startLanes); rsp != nil {
+			return rsp
+		}
 		var stopTasks *state.TaskSet
 		stopTasks, err = servstate.Stop(st, stopLanes)
 		if err != nil {
@@ -241,6 +268,19 @@ func v1PostService(c *Command, r *http.Request, _ *UserState) Response {
 	return BadRequest("not implemented")
 }
 
+// checkManageServices returns an error response if the user isn't allowed
+// to manage one of the services in the given lanes.
+func checkManageServices(user *UserState, lanes [][]string) Response {
+	for _, lane := range lanes {
+		for _, name := range lane {
+			if !user.allowed(state.ManageServicesPermission, name) {
+				return Forbidden("not allowed to manage service %q", name)
+			}
+		}
+	}
+	return nil
+}
+
 // intersectOrdered returns the intersection of left and right where
 // the right's ordering is persisted in the resulting set.
 func intersectOrdered(left []string, orderedRight [][]string) [][]string {
@@ -233,6 +233,64 @@ func (s *apiSuite) TestServicesGet(c *C) {
 	})
 }
 
+func (s *apiSuite) TestServicesGetPermissions(c *C) {
+	writeTestLayer(s.pebbleDir, servicesLayer)
+	s.daemon(c)
+
+	user := &UserState{
+		Access: state.ReadAccess,
+		Permissions: []state.Permission{
+			{Action: state.ReadServicesPermission, Resources: []string{"test1", "test3"}},
+		},
+	}
+	req, err := http.NewRequest("GET", "/v1/services", nil)
+	c.Assert(err, IsNil)
+	rsp := v1GetServices(apiCmd("/v1/services"), req, user).(*resp)
+	rec := httptest.NewRecorder()
+	rsp.ServeHTTP(rec, req)
+
+	c.Check(rec.Code, Equals, 200)
+	var body map[string]any
+	err = json.Unmarshal(rec.Body.Bytes(), &body)
+	c.Check(err, IsNil)
+	c.Check(body["result"], DeepEquals, []any{
+		map[string]any{"startup": "enabled", "name": "test1", "current": "inactive"},
+		map[string]any{"startup": "disabled", "name": "test3", "current": "inactive"},
+	})
+}
+
+func (s *apiSuite) TestServicesStartPermissions(c *C) {
+	writeTestLayer(s.pebbleDir, servicesLayer)
+	s.daemon(c)
+
+	user := &UserState{
+		Access: state.ReadAccess,
+		Permissions: []state.Permission{
+			{Action: state.ManageServicesPermission, Resources: []string{"test1"}},
+		},
+	}
+
+	// Starting test1 also starts test2, which the user may not manage.
+	payload := bytes.NewBufferString(`{"action": "start", "services": ["test1"]}`)
+	req, err := http.NewRequest("POST", "/v1/services", payload)
+	c.Assert(err, IsNil)
+	rsp := v1PostServices(apiCmd("/v1/services"), req, user).(*resp)
+	rec := httptest.NewRecorder()
+	rsp.ServeHTTP(rec, req)
+	c.Check(rec.Code, Equals, 403)
+	c.Check(rsp.Result.(*errorResult).Message, Equals, `not allowed to manage service "test2"`)
+
+	// Once allowed to manage test2 as well, the start succeeds.
+	user.Permissions[0].Resources = []string{"test1", "test2"}
+	payload = bytes.NewBufferString(`{"action": "start", "services": ["test1"]}`)
+	req, err = http.NewRequest("POST", "/v1/services", payload)
+	c.Assert(err, IsNil)
+	rsp = v1PostServices(apiCmd("/v1/services"), req, user).(*resp)
+	rec = httptest.NewRecorder()
+	rsp.ServeHTTP(rec, req)
+	c.Check(rec.Code, Equals, 202)
+}
+
 func (s *apiSuite) TestServicesRestart(c *C) {
 	// Setup
 	writeTestLayer(s.pebbleDir, servicesLayer)
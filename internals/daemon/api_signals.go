@@ -24,7 +24,7 @@ type signalsPayload struct {
 	Services []string `json:"services"`
 }
 
-func v1PostSignals(c *Command, req *http.Request, _ *UserState) Response {
+func v1PostSignals(c *Command, req *http.Request, user *UserState) Response {
 	var payload signalsPayload
 	decoder := json.NewDecoder(req.Body)
 	if err := decoder.Decode(&payload); err != nil {
@@ -33,6 +33,9 @@ func v1PostSignals(c *Command, req *http.Request, _ *UserState) Response {
 	if len(payload.Services) == 0 {
 		return BadRequest("must specify one or more services")
 	}
+	if rsp := checkManageServices(user, [][]string{payload.Services}); rsp != nil {
+		return rsp
+	}
 
 	serviceMgr := c.d.overlord.ServiceManager()
 	err := serviceMgr.SendSignal(payload.Services, payload.Signal)
@@ -23,7 +23,7 @@ import (
 	"github.com/canonical/pebble/internals/overlord/state"
 )
 
-func v1GetTaskWebsocket(c *Command, req *http.Request, _ *UserState) Response {
+func v1GetTaskWebsocket(c *Command, req *http.Request, user *UserState) Response {
 	vars := muxVars(req)
 	taskID := vars["task-id"]
 	websocketID := vars["websocket-id"]
@@ -41,6 +41,20 @@ func v1GetTaskWebsocket(c *Command, req *http.Request, _ *UserState) Response {
 		return NotFound("cannot find task %q", taskID)
 	}
 
+	if user != nil && user.Access != state.AdminAccess {
+		// Non-admins may only connect to the websockets of tasks they
+		// started themselves.
+		var identity string
+		err := task.Get("identity", &identity)
+		if err != nil && !errors.Is(err, state.ErrNoState) {
+			return InternalError("cannot get task identity: %v", err)
+		}
+		if identity == "" || identity != user.Username {
+			logger.Noticef("Websocket %s: identity %q cannot access task", task.ID(), user.Username)
+			return Forbidden("cannot access task %q", taskID)
+		}
+	}
+
 	var connect websocketConnectFunc
 	switch task.Kind() {
 	case "exec":
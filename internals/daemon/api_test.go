@@ -19,6 +19,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -131,6 +132,35 @@ func (s *apiSuite) TestSysInfo(c *check.C) {
 	c.Check(rsp.Result, check.DeepEquals, expected)
 }
 
+func (s *apiSuite) TestSysInfoTLSCertificate(c *check.C) {
+	d, err := New(&Options{Dir: s.pebbleDir, HTTPSAddress: ":0", IDSigner: newIDKey(c)})
+	c.Assert(err, check.IsNil)
+	d.addRoutes()
+	c.Assert(d.overlord.StartUp(), check.IsNil)
+	s.d = d
+
+	sysInfoCmd := apiCmd("/v1/system-info")
+	rec := httptest.NewRecorder()
+	sysInfoCmd.GET(sysInfoCmd, nil, nil).ServeHTTP(rec, nil)
+	c.Assert(rec.Code, check.Equals, 200)
+
+	var rsp struct {
+		Result struct {
+			TLSCertificate struct {
+				Source    string    `json:"source"`
+				Subject   string    `json:"subject"`
+				NotBefore time.Time `json:"not-before"`
+				NotAfter  time.Time `json:"not-after"`
+			} `json:"tls-certificate"`
+		} `json:"result"`
+	}
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &rsp), check.IsNil)
+	cert := rsp.Result.TLSCertificate
+	c.Check(cert.Source, check.Equals, "generated")
+	c.Check(cert.Subject, check.Matches, "CN=pebble-.*")
+	c.Check(cert.NotAfter.After(cert.NotBefore), check.Equals, true)
+}
+
 func fakeEnv(key, value string) (restore func()) {
 	oldEnv, envWasSet := os.LookupEnv(key)
 	err := os.Setenv(key, value)
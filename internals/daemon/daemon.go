@@ -26,6 +26,7 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"runtime/debug"
 	"strconv"
@@ -37,6 +38,8 @@ import (
 	"github.com/gorilla/mux"
 	"gopkg.in/tomb.v2"
 
+	"github.com/canonical/pebble/cmd"
+	"github.com/canonical/pebble/internals/audit"
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/osutil"
 	"github.com/canonical/pebble/internals/overlord"
@@ -86,6 +89,38 @@ func RequestTransportType(r *http.Request) TransportType {
 	return transport
 }
 
+// SocketKey is used with context.WithValue as the key for the extra socket
+// a request arrived on.
+type SocketKey struct{}
+
+// ExtraSocket describes an additional unix socket for the API, with a
+// restricted access policy. This allows, for example, giving a sidecar a
+// socket that can only be used for health and metrics.
+type ExtraSocket struct {
+	// Path is the path of the unix socket.
+	Path string
+
+	// MaxAccess, if set, is the highest access level an endpoint can
+	// require to be used over the socket, whatever the user's own access.
+	// For example, with state.MetricsAccess only endpoints with open or
+	// metrics access can be used.
+	MaxAccess state.IdentityAccess
+
+	// Endpoints, if set, lists the endpoint paths that can be used over
+	// the socket, for example "/v1/health" or "/v1/changes/{id}".
+	Endpoints []string
+}
+
+// RequestSocket returns the extra socket the HTTP request arrived on, or
+// nil if the request arrived on the main socket, HTTP or HTTPS.
+func RequestSocket(r *http.Request) *ExtraSocket {
+	if r == nil {
+		return nil
+	}
+	socket, _ := r.Context().Value(SocketKey{}).(*ExtraSocket)
+	return socket
+}
+
 // String returns a string representation of the transport type.
 func (t TransportType) String() string {
 	switch t {
@@ -142,6 +177,10 @@ type Options struct {
 	// the pebble directory.
 	SocketPath string
 
+	// ExtraSockets are additional unix sockets for the API, each with its
+	// own access policy.
+	ExtraSockets []*ExtraSocket
+
 	// HTTPAddress is the address for the plain HTTP API server, for example
 	// ":4000" to listen on any address, port 4000. If not set, the HTTP API
 	// server is not started.
@@ -162,6 +201,10 @@ type Options struct {
 
 	// Persist specifies whether the state should be persisted to disk.
 	Persist overlord.PersistMode
+
+	// EncryptState specifies whether the persisted state is encrypted with
+	// a key derived from IDSigner.
+	EncryptState bool
 }
 
 // A Daemon listens for requests and routes them to the right command
@@ -172,6 +215,7 @@ type Daemon struct {
 	overlord        *overlord.Overlord
 	state           *state.State
 	generalListener net.Listener
+	extraListeners  map[string]net.Listener
 	httpListener    net.Listener
 	httpsListener   net.Listener
 	connTracker     *connTracker
@@ -179,6 +223,7 @@ type Daemon struct {
 	tomb            tomb.Tomb
 	router          *mux.Router
 	standbyOpinions *standby.StandbyOpinions
+	auditLog        *audit.Log
 
 	// set to what kind of restart was requested (if any)
 	requestedRestart restart.RestartType
@@ -196,6 +241,30 @@ type UserState struct {
 	Access   state.IdentityAccess
 	UID      *uint32
 	Username string
+
+	// Permissions holds the identity's fine-grained permissions, if any.
+	Permissions []state.Permission
+}
+
+// hasPermissions reports whether the user is restricted by fine-grained
+// permissions.
+func (u *UserState) hasPermissions() bool {
+	return u != nil && len(u.Permissions) > 0
+}
+
+// allowed reports whether the user is allowed to perform the action on the
+// given resource. Users without fine-grained permissions are allowed, as
+// their access level has already been checked.
+func (u *UserState) allowed(action state.PermissionAction, resource string) bool {
+	if !u.hasPermissions() {
+		return true
+	}
+	for i := range u.Permissions {
+		if u.Permissions[i].Allows(action, resource) {
+			return true
+		}
+	}
+	return false
 }
 
 // A ResponseFunc handles one of the individual verbs for a method
@@ -223,6 +292,30 @@ func userFromRequest(st *state.State, r *http.Request, ucred *Ucrednet, username
 		userID = &ucred.Uid
 	}
 
+	if token, ok := bearerToken(r); ok {
+		// A bearer token is per-request and client controlled, like basic
+		// authentication, so it takes priority over the UID.
+		st.Lock()
+		identity := st.IdentityFromToken(token)
+		st.Unlock()
+		if identity == nil || identity.Expired() {
+			return nil, nil
+		}
+		return &UserState{Access: identity.Access, Username: identity.Name, Permissions: identity.Permissions}, nil
+	}
+
+	if username == "" && password == "" && r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		// The client presented a certificate over HTTPS (mutual TLS), so use
+		// the identity with that certificate, if any.
+		st.Lock()
+		identity := st.IdentityFromCert(r.TLS.PeerCertificates[0])
+		st.Unlock()
+		if identity == nil || identity.Expired() {
+			return nil, nil
+		}
+		return &UserState{Access: identity.Access, Username: identity.Name, Permissions: identity.Permissions}, nil
+	}
+
 	st.Lock()
 	identity := st.IdentityFromInputs(userID, username, password)
 	st.Unlock()
@@ -231,15 +324,46 @@ func userFromRequest(st *state.State, r *http.Request, ucred *Ucrednet, username
 		// No identity that matches these inputs (for now, just UID).
 		return nil, nil
 	}
+	if identity.Expired() {
+		// Expired identities are pruned periodically, but may still be
+		// present until then.
+		return nil, nil
+	}
 	if identity.Basic != nil {
 		// Prioritize basic type (HTTP basic authentication) and ignore UID in this case.
-		return &UserState{Access: identity.Access, Username: identity.Name}, nil
+		return &UserState{Access: identity.Access, Username: identity.Name, Permissions: identity.Permissions}, nil
 	} else if identity.Local != nil {
-		return &UserState{Access: identity.Access, UID: userID}, nil
+		return &UserState{Access: identity.Access, UID: userID, Permissions: identity.Permissions}, nil
 	}
 	return nil, nil
 }
 
+// bearerToken returns the token from the request's "Authorization: Bearer"
+// header, and whether the header was present.
+func bearerToken(r *http.Request) (string, bool) {
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(auth[len(prefix):]), true
+}
+
+// credentialsMethod returns the kind of credentials explicitly presented
+// with the request, or "" if there are none.
+func credentialsMethod(r *http.Request, username, password string) string {
+	if _, ok := bearerToken(r); ok {
+		return "token"
+	}
+	if username == "" && password == "" && r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		return "certificate"
+	}
+	if username != "" || password != "" {
+		return "basic"
+	}
+	return ""
+}
+
 func (d *Daemon) Overlord() *overlord.Overlord {
 	return d.overlord
 }
@@ -291,6 +415,21 @@ func (c *Command) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if _, isOpen := access.(OpenAccess); !isOpen {
 		username, password, _ := r.BasicAuth()
 		user, err = userFromRequest(c.d.state, r, ucred, username, password)
+		if method := credentialsMethod(r, username, password); method != "" {
+			// Only record explicit authentication attempts, not every
+			// request identified by its peer credentials.
+			if err != nil || user == nil {
+				attempted := username
+				if attempted == "" {
+					attempted = userString(nil)
+				}
+				c.d.recordAudit(r, attempted, audit.EventAuthenticate, audit.OutcomeFailure,
+					fmt.Sprintf("Invalid %s credentials", method))
+			} else {
+				c.d.recordAudit(r, userString(user), audit.EventAuthenticate, audit.OutcomeSuccess,
+					fmt.Sprintf("Authenticated with %s credentials", method))
+			}
+		}
 		if err != nil {
 			Forbidden("forbidden").ServeHTTP(w, r)
 			return
@@ -314,7 +453,13 @@ func (c *Command) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if rspe := access.CheckAccess(c.d, r, user); rspe != nil {
+	rspe := CheckSocketAccess(r, access)
+	if rspe == nil {
+		rspe = access.CheckAccess(c.d, r, user)
+	}
+	if rspe != nil {
+		c.d.recordAudit(r, userString(user), audit.EventAuthorize, audit.OutcomeFailure,
+			fmt.Sprintf("Not authorized to %s %s", r.Method, r.URL.Path))
 		if user != nil {
 			userStr := userString(user)
 			logger.SecurityCritical(logger.SecurityAuthzFail,
@@ -328,6 +473,12 @@ func (c *Command) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	rsp := rspf(c, r, user)
 
 	if rsp, ok := rsp.(*resp); ok {
+		if result, ok := rsp.Result.(*errorResult); ok && rsp.Status == http.StatusForbidden {
+			// Handlers deny access to individual resources themselves, for
+			// example when the user has fine-grained permissions.
+			c.d.recordAudit(r, userString(user), audit.EventAuthorize, audit.OutcomeFailure,
+				fmt.Sprintf("Not authorized to %s %s: %s", r.Method, r.URL.Path, result.Message))
+		}
 		_, rst := c.d.overlord.RestartManager().Pending()
 		switch rst {
 		case restart.RestartSystem:
@@ -448,6 +599,19 @@ func (d *Daemon) Init() error {
 		return fmt.Errorf("when trying to listen on %s: %v", d.options.SocketPath, err)
 	}
 
+	d.extraListeners = make(map[string]net.Listener)
+	for _, socket := range d.options.ExtraSockets {
+		if err := validateExtraSocket(socket, d.options.SocketPath, d.extraListeners); err != nil {
+			return err
+		}
+		listener, err := getListener(socket.Path, listenerMap)
+		if err != nil {
+			return fmt.Errorf("when trying to listen on %s: %v", socket.Path, err)
+		}
+		d.extraListeners[socket.Path] = &ucrednetListener{Listener: listener}
+		logger.Noticef("Restricted API socket listening on %q.", socket.Path)
+	}
+
 	d.addRoutes()
 
 	if d.options.HTTPAddress != "" {
@@ -466,7 +630,8 @@ func (d *Daemon) Init() error {
 			return fmt.Errorf("cannot TLS listen on %q: %v", d.options.HTTPSAddress, err)
 		}
 		d.httpsListener = listener
-		logger.Noticef("HTTPS API server listening on %q.", d.options.HTTPSAddress)
+		logger.Noticef("HTTPS API server listening on %q (identity fingerprint %s).",
+			d.options.HTTPSAddress, d.overlord.TLSManager().Fingerprint())
 	}
 
 	logger.Noticef("Started daemon.")
@@ -565,9 +730,13 @@ func (d *Daemon) Start() error {
 			// Flag the incoming requests with a context value so we can identify the
 			// transport of the request in the http.Request object. We can use this for
 			// refined access checker decisions.
-			switch c.(type) {
+			switch c := c.(type) {
 			case *ucrednetConn:
-				return context.WithValue(ctx, TransportTypeKey{}, TransportTypeUnixSocket)
+				ctx = context.WithValue(ctx, TransportTypeKey{}, TransportTypeUnixSocket)
+				if socket := d.extraSocket(c.Ucrednet); socket != nil {
+					ctx = context.WithValue(ctx, SocketKey{}, socket)
+				}
+				return ctx
 			case *net.TCPConn:
 				return context.WithValue(ctx, TransportTypeKey{}, TransportTypeHTTP)
 			case *tls.Conn:
@@ -592,6 +761,17 @@ func (d *Daemon) Start() error {
 		return nil
 	})
 
+	for _, listener := range d.extraListeners {
+		// Start additional restricted unix socket APIs
+		d.tomb.Go(func() error {
+			err := d.serve.Serve(listener)
+			if err != http.ErrServerClosed && d.tomb.Err() == tomb.ErrStillAlive {
+				return err
+			}
+			return nil
+		})
+	}
+
 	if d.httpListener != nil {
 		// Start additional HTTP API
 		d.tomb.Go(func() error {
@@ -718,6 +898,12 @@ func (d *Daemon) Stop(sigCh chan<- os.Signal) error {
 	}
 	d.overlord.Stop()
 
+	if d.auditLog != nil {
+		if err := d.auditLog.Close(); err != nil {
+			logger.Noticef("Cannot close audit log: %v", err)
+		}
+	}
+
 	err = d.tomb.Wait()
 	if err != nil {
 		// do not stop the shutdown even if the tomb errors
@@ -981,6 +1167,18 @@ func New(opts *Options) (*Daemon, error) {
 		Extension:      opts.OverlordExtension,
 		IDSigner:       opts.IDSigner,
 		Persist:        opts.Persist,
+		EncryptState:   opts.EncryptState,
+	}
+
+	// The audit log is kept in memory only if the state isn't persisted.
+	// It's opened first so that there's nothing to clean up if it fails.
+	var auditPath string
+	if opts.Persist == overlord.PersistDefault {
+		auditPath = filepath.Join(opts.Dir, cmd.AuditFile)
+	}
+	auditLog, err := audit.Open(auditPath, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	ovld, err := overlord.New(&ovldOptions)
@@ -989,17 +1187,53 @@ func New(opts *Options) (*Daemon, error) {
 		// where we will schedule and wait again for a system restart.
 		// ATM we cannot do that in New because we need to satisfy
 		// systemd notify mechanisms.
+		auditLog.Close()
 		d.rebootIsMissing = true
 		return d, nil
 	}
 	if err != nil {
+		auditLog.Close()
 		return nil, err
 	}
 	d.overlord = ovld
 	d.state = ovld.State()
+	d.auditLog = auditLog
 	return d, nil
 }
 
+// validateExtraSocket checks the options for an extra socket.
+func validateExtraSocket(socket *ExtraSocket, mainSocketPath string, listeners map[string]net.Listener) error {
+	if socket.Path == "" {
+		return errors.New("extra socket must have a path")
+	}
+	if socket.Path == mainSocketPath {
+		return fmt.Errorf("extra socket %q must not be the main socket", socket.Path)
+	}
+	if _, ok := listeners[socket.Path]; ok {
+		return fmt.Errorf("extra socket %q specified more than once", socket.Path)
+	}
+	switch socket.MaxAccess {
+	case "", state.UntrustedAccess, state.MetricsAccess, state.ReadAccess, state.AdminAccess:
+	default:
+		return fmt.Errorf("extra socket %q has invalid maximum access %q", socket.Path, socket.MaxAccess)
+	}
+	return nil
+}
+
+// extraSocket returns the extra socket a unix socket connection was accepted
+// on, or nil if it was accepted on the main socket.
+func (d *Daemon) extraSocket(ucred *Ucrednet) *ExtraSocket {
+	if ucred == nil {
+		return nil
+	}
+	for _, socket := range d.options.ExtraSockets {
+		if socket.Path == ucred.Socket {
+			return socket
+		}
+	}
+	return nil
+}
+
 // GetListener tries to get a listener for the given socket path from
 // the listener map, and if it fails it tries to set it up directly.
 func getListener(socketPath string, listenerMap map[string]net.Listener) (net.Listener, error) {
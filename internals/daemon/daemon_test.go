@@ -21,11 +21,14 @@ import (
 	"crypto/rand"
 	"crypto/sha512"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base32"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -43,6 +46,7 @@ import (
 	. "gopkg.in/check.v1"
 
 	"github.com/canonical/pebble/cmd"
+	"github.com/canonical/pebble/internals/audit"
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/osutil"
 	"github.com/canonical/pebble/internals/overlord"
@@ -1371,6 +1375,120 @@ func (s *daemonSuite) TestHTTPAPI(c *C) {
 	ensureSecurityLog(c, logBuf.String(), "WARN", fmt.Sprintf("sys_shutdown:%d", os.Getuid()), "Shutting down daemon")
 }
 
+func (s *daemonSuite) TestExtraSockets(c *C) {
+	s.socketPath = filepath.Join(c.MkDir(), "pebble.socket")
+	metricsSocketPath := filepath.Join(c.MkDir(), "metrics.socket")
+	healthSocketPath := filepath.Join(c.MkDir(), "health.socket")
+	d := s.newDaemon(c)
+	d.options.ExtraSockets = []*ExtraSocket{
+		{Path: metricsSocketPath, MaxAccess: state.MetricsAccess},
+		{Path: healthSocketPath, Endpoints: []string{"/v1/health"}},
+	}
+	c.Assert(d.Init(), IsNil)
+	c.Assert(d.Start(), IsNil)
+	defer d.Stop(nil)
+
+	get := func(socketPath, path string) int {
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+			},
+		}}
+		response, err := client.Get("http://localhost" + path)
+		c.Assert(err, IsNil)
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	// The test runs as the daemon's UID, so it has admin access, but the
+	// extra sockets restrict it.
+	c.Check(get(s.socketPath, "/v1/services"), Equals, http.StatusOK)
+	c.Check(get(metricsSocketPath, "/v1/health"), Equals, http.StatusOK)
+	c.Check(get(metricsSocketPath, "/v1/metrics"), Equals, http.StatusOK)
+	c.Check(get(metricsSocketPath, "/v1/services"), Equals, http.StatusUnauthorized)
+	c.Check(get(healthSocketPath, "/v1/health"), Equals, http.StatusOK)
+	c.Check(get(healthSocketPath, "/v1/metrics"), Equals, http.StatusUnauthorized)
+	c.Check(get(healthSocketPath, "/v1/services"), Equals, http.StatusUnauthorized)
+}
+
+func (s *daemonSuite) TestNewAuditLogError(c *C) {
+	err := os.Mkdir(filepath.Join(s.pebbleDir, cmd.AuditFile), 0o755)
+	c.Assert(err, IsNil)
+	_, err = New(&Options{
+		Dir:        s.pebbleDir,
+		SocketPath: s.socketPath,
+		IDSigner:   newIDKey(c),
+	})
+	c.Assert(err, ErrorMatches, "cannot open audit log: .*")
+
+	// The audit log is opened before the overlord, so the state wasn't
+	// loaded or created.
+	_, err = os.Stat(filepath.Join(s.pebbleDir, cmd.StateFile))
+	c.Check(os.IsNotExist(err), Equals, true)
+}
+
+// allowAll is a custom AccessChecker that allows every request.
+type allowAll struct{}
+
+func (allowAll) CheckAccess(d *Daemon, r *http.Request, user *UserState) Response {
+	return nil
+}
+
+func (s *daemonSuite) TestExtraSocketsCustomChecker(c *C) {
+	d := s.newDaemon(c)
+	cmd := &Command{
+		d: d,
+		GET: func(c *Command, r *http.Request, s *UserState) Response {
+			return SyncResponse(true)
+		},
+		ReadAccess: allowAll{},
+	}
+	get := func(socket *ExtraSocket) int {
+		ctx := context.WithValue(context.Background(), TransportTypeKey{}, TransportTypeUnixSocket)
+		if socket != nil {
+			ctx = context.WithValue(ctx, SocketKey{}, socket)
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", "/v1/custom", nil)
+		c.Assert(err, IsNil)
+		req.RemoteAddr = fmt.Sprintf("pid=100;uid=%d;socket=;", os.Getuid())
+		rec := httptest.NewRecorder()
+		cmd.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// The socket policy is enforced even though the endpoint's own checker
+	// allows everything. Custom checkers require admin access.
+	c.Check(get(nil), Equals, http.StatusOK)
+	c.Check(get(&ExtraSocket{Path: "/tmp/admin.socket", MaxAccess: state.AdminAccess}), Equals, http.StatusOK)
+	c.Check(get(&ExtraSocket{Path: "/tmp/read.socket", MaxAccess: state.ReadAccess}), Equals, http.StatusUnauthorized)
+	c.Check(get(&ExtraSocket{Path: "/tmp/health.socket", Endpoints: []string{"/v1/health"}}), Equals, http.StatusUnauthorized)
+	c.Check(get(&ExtraSocket{Path: "/tmp/custom.socket", Endpoints: []string{"/v1/custom"}}), Equals, http.StatusOK)
+}
+
+func (s *daemonSuite) TestExtraSocketsInvalid(c *C) {
+	s.socketPath = filepath.Join(c.MkDir(), "pebble.socket")
+	socketPath := filepath.Join(c.MkDir(), "extra.socket")
+	tests := []struct {
+		sockets []*ExtraSocket
+		error   string
+	}{
+		{[]*ExtraSocket{{}}, "extra socket must have a path"},
+		{[]*ExtraSocket{{Path: s.socketPath}}, `extra socket ".*" must not be the main socket`},
+		{[]*ExtraSocket{{Path: socketPath}, {Path: socketPath}}, `extra socket ".*" specified more than once`},
+		{[]*ExtraSocket{{Path: socketPath, MaxAccess: "root"}}, `extra socket ".*" has invalid maximum access "root"`},
+	}
+	for _, t := range tests {
+		d := s.newDaemon(c)
+		d.options.ExtraSockets = t.sockets
+		err := d.Init()
+		c.Check(err, ErrorMatches, t.error)
+		for _, listener := range d.extraListeners {
+			listener.Close()
+		}
+		d.generalListener.Close()
+	}
+}
+
 func (s *daemonSuite) TestHTTPSAPI(c *C) {
 	s.httpsAddress = ":0" // Go will choose port (use listener.Addr() to find it)
 	d := s.newDaemon(c)
@@ -1428,6 +1546,99 @@ func (s *daemonSuite) TestHTTPSAPI(c *C) {
 	c.Assert(err, ErrorMatches, ".* connection refused")
 }
 
+func (s *daemonSuite) TestHTTPSAPIClientCert(c *C) {
+	s.httpsAddress = ":0" // Go will choose port (use listener.Addr() to find it)
+	d := s.newDaemon(c)
+	d.Init()
+	c.Assert(d.Start(), IsNil)
+	defer d.Stop(nil)
+
+	knownCert := newTestClientCert(c)
+	unknownCert := newTestClientCert(c)
+
+	st := d.overlord.State()
+	st.Lock()
+	err := st.AddIdentities(map[string]*state.Identity{
+		"controller": {
+			Access: state.ReadAccess,
+			Cert:   &state.CertIdentity{X509: knownCert.Leaf},
+		},
+	})
+	st.Unlock()
+	c.Assert(err, IsNil)
+
+	port := d.httpsListener.Addr().(*net.TCPAddr).Port
+	get := func(clientCert *tls.Certificate) int {
+		tlsConfig := &tls.Config{InsecureSkipVerify: true}
+		if clientCert != nil {
+			tlsConfig.Certificates = []tls.Certificate{*clientCert}
+		}
+		httpsClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		response, err := httpsClient.Get(fmt.Sprintf("https://localhost:%d/v1/checks", port))
+		c.Assert(err, IsNil)
+		defer response.Body.Close()
+		return response.StatusCode
+	}
+
+	c.Check(get(&knownCert), Equals, http.StatusOK)
+	c.Check(get(&unknownCert), Equals, http.StatusUnauthorized)
+	c.Check(get(nil), Equals, http.StatusUnauthorized)
+}
+
+func (s *daemonSuite) TestUserFromRequestCert(c *C) {
+	cert := newTestClientCert(c)
+
+	st := state.New(nil)
+	st.Lock()
+	err := st.AddIdentities(map[string]*state.Identity{
+		"controller": {
+			Access: state.AdminAccess,
+			Cert:   &state.CertIdentity{X509: cert.Leaf},
+		},
+	})
+	st.Unlock()
+	c.Assert(err, IsNil)
+
+	request, err := http.NewRequest("GET", "https://localhost/v1/changes", nil)
+	c.Assert(err, IsNil)
+	request.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert.Leaf}}
+	user, err := userFromRequest(st, request, nil, "", "")
+	c.Assert(err, IsNil)
+	c.Check(user, DeepEquals, &UserState{Access: state.AdminAccess, Username: "controller"})
+
+	other := newTestClientCert(c)
+	request.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{other.Leaf}}
+	user, err = userFromRequest(st, request, nil, "", "")
+	c.Assert(err, IsNil)
+	c.Check(user, IsNil)
+}
+
+// newTestClientCert returns a new self-signed TLS client certificate, with
+// Leaf set to the parsed certificate.
+func newTestClientCert(c *C) tls.Certificate {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	c.Assert(err, IsNil)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+	c.Assert(err, IsNil)
+	leaf, err := x509.ParseCertificate(der)
+	c.Assert(err, IsNil)
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  priv,
+		Leaf:        leaf,
+	}
+}
+
 func (s *daemonSuite) TestStopRunning(c *C) {
 	// Start the daemon.
 	writeTestLayer(s.pebbleDir, `
@@ -1668,6 +1879,220 @@ func (s *daemonSuite) TestAPIAccessLevels(c *C) {
 	}
 }
 
+func (s *daemonSuite) TestTokenIdentityAccess(c *C) {
+	d := s.newDaemon(c)
+
+	st := d.overlord.State()
+	st.Lock()
+	err := st.AddIdentities(map[string]*state.Identity{
+		"reader": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("read-token")},
+		},
+		"admin": {
+			Access: state.AdminAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("admin-token")},
+		},
+	})
+	st.Unlock()
+	c.Assert(err, IsNil)
+
+	tests := []struct {
+		method string
+		path   string
+		auth   string
+		uid    int // -1 means no peer cred user
+		status int
+	}{
+		{"GET", "/v1/changes", "", -1, http.StatusUnauthorized},
+		{"GET", "/v1/changes", "Bearer read-token", -1, http.StatusOK},
+		{"GET", "/v1/changes", "bearer read-token", -1, http.StatusOK},
+		{"GET", "/v1/changes", "Bearer admin-token", -1, http.StatusOK},
+		{"GET", "/v1/changes", "Bearer wrong-token", -1, http.StatusUnauthorized},
+		{"GET", "/v1/changes", "Bearer " + state.HashToken("read-token"), -1, http.StatusUnauthorized},
+		{"POST", "/v1/services", "Bearer read-token", -1, http.StatusUnauthorized},
+		{"POST", "/v1/services", "Bearer admin-token", -1, http.StatusBadRequest},
+
+		// The token takes priority over the peer credentials.
+		{"POST", "/v1/services", "Bearer read-token", 0, http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		remoteAddr := ""
+		if test.uid >= 0 {
+			remoteAddr = fmt.Sprintf("pid=100;uid=%d;socket=;", test.uid)
+		}
+		ctx := context.WithValue(context.Background(), TransportTypeKey{}, TransportTypeUnixSocket)
+		request, err := http.NewRequestWithContext(ctx, test.method, "http://localhost"+test.path, strings.NewReader(""))
+		c.Assert(err, IsNil)
+		request.RemoteAddr = remoteAddr
+		if test.auth != "" {
+			request.Header.Set("Authorization", test.auth)
+		}
+		recorder := httptest.NewRecorder()
+
+		apiCmd(test.path).ServeHTTP(recorder, request)
+
+		c.Check(recorder.Code, Equals, test.status, Commentf("%s %s %q uid=%d", test.method, test.path, test.auth, test.uid))
+	}
+}
+
+func (s *daemonSuite) TestRestrictedAdminIdentity(c *C) {
+	d := s.newDaemon(c)
+
+	st := d.overlord.State()
+	st.Lock()
+	err := st.AddIdentities(map[string]*state.Identity{
+		"restricted": {
+			Access: state.AdminAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("restricted-token")},
+			Permissions: []state.Permission{
+				{Action: state.ManageServicesPermission, Resources: []string{"svc-a"}},
+			},
+		},
+	})
+	st.Unlock()
+	c.Assert(err, IsNil)
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		// Endpoints that need admin access, but aren't covered by a permission,
+		// are denied. Endpoints that need read access are allowed.
+		{"POST", "/v1/layers", http.StatusUnauthorized},
+		{"GET", "/v1/changes", http.StatusOK},
+		{"POST", "/v1/identities", http.StatusUnauthorized},
+		{"GET", "/v1/audit", http.StatusUnauthorized},
+		// Endpoints covered by a permission (the empty body is invalid).
+		{"POST", "/v1/services", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		ctx := context.WithValue(context.Background(), TransportTypeKey{}, TransportTypeUnixSocket)
+		request, err := http.NewRequestWithContext(ctx, test.method, "http://localhost"+test.path, strings.NewReader(""))
+		c.Assert(err, IsNil)
+		request.Header.Set("Authorization", "Bearer restricted-token")
+		recorder := httptest.NewRecorder()
+
+		apiCmd(test.path).ServeHTTP(recorder, request)
+
+		c.Check(recorder.Code, Equals, test.status, Commentf("%s %s", test.method, test.path))
+	}
+}
+
+func (s *daemonSuite) TestAuditAuthentication(c *C) {
+	d := s.newDaemon(c)
+
+	st := d.overlord.State()
+	st.Lock()
+	err := st.AddIdentities(map[string]*state.Identity{
+		"reader": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("read-token")},
+		},
+	})
+	st.Unlock()
+	c.Assert(err, IsNil)
+
+	for _, auth := range []string{"", "Bearer read-token", "Bearer wrong-token"} {
+		ctx := context.WithValue(context.Background(), TransportTypeKey{}, TransportTypeHTTP)
+		request, err := http.NewRequestWithContext(ctx, "POST", "http://localhost/v1/services", strings.NewReader(""))
+		c.Assert(err, IsNil)
+		if auth != "" {
+			request.Header.Set("Authorization", auth)
+		}
+		apiCmd("/v1/services").ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	records, err := d.auditLog.Records(audit.Filter{})
+	c.Assert(err, IsNil)
+	var summary []string
+	for _, r := range records {
+		c.Check(r.Transport, Equals, "http")
+		summary = append(summary, fmt.Sprintf("%s/%s/%s: %s", r.Event, r.User, r.Outcome, r.Message))
+	}
+	c.Check(summary, DeepEquals, []string{
+		"authorize/<unknown>/failure: Not authorized to POST /v1/services",
+		"authenticate/reader/success: Authenticated with token credentials",
+		"authorize/reader/failure: Not authorized to POST /v1/services",
+		"authenticate/<unknown>/failure: Invalid token credentials",
+		"authorize/<unknown>/failure: Not authorized to POST /v1/services",
+	})
+}
+
+func (s *daemonSuite) TestUserFromRequestToken(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	err := st.AddIdentities(map[string]*state.Identity{
+		"reader": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("read-token")},
+		},
+	})
+	st.Unlock()
+	c.Assert(err, IsNil)
+
+	request, err := http.NewRequest("GET", "http://localhost/v1/changes", nil)
+	c.Assert(err, IsNil)
+	request.Header.Set("Authorization", "Bearer read-token")
+	user, err := userFromRequest(st, request, nil, "", "")
+	c.Assert(err, IsNil)
+	c.Check(user, DeepEquals, &UserState{Access: state.ReadAccess, Username: "reader"})
+
+	request.Header.Set("Authorization", "Bearer wrong-token")
+	user, err = userFromRequest(st, request, nil, "", "")
+	c.Assert(err, IsNil)
+	c.Check(user, IsNil)
+}
+
+func (s *daemonSuite) TestUserFromRequestExpired(c *C) {
+	now := time.Now()
+	restore := state.FakeTime(now)
+	defer restore()
+
+	st := state.New(nil)
+	st.Lock()
+	err := st.AddIdentities(map[string]*state.Identity{
+		"reader": {
+			Access:    state.ReadAccess,
+			Token:     &state.TokenIdentity{Hash: state.HashToken("read-token")},
+			ExpiresAt: now.Add(time.Hour),
+		},
+		"bob": {
+			Access:    state.AdminAccess,
+			Local:     &state.LocalIdentity{UserID: 42},
+			ExpiresAt: now.Add(time.Hour),
+		},
+	})
+	st.Unlock()
+	c.Assert(err, IsNil)
+
+	request, err := http.NewRequest("GET", "http://localhost/v1/changes", nil)
+	c.Assert(err, IsNil)
+	request.Header.Set("Authorization", "Bearer read-token")
+	user, err := userFromRequest(st, request, nil, "", "")
+	c.Assert(err, IsNil)
+	c.Check(user, NotNil)
+
+	localRequest, err := http.NewRequest("GET", "http://localhost/v1/changes", nil)
+	c.Assert(err, IsNil)
+	user, err = userFromRequest(st, localRequest, &Ucrednet{Uid: 42}, "", "")
+	c.Assert(err, IsNil)
+	c.Check(user, NotNil)
+
+	// Once expired, the identities are rejected, even before they're pruned.
+	restore = state.FakeTime(now.Add(time.Hour))
+	defer restore()
+	user, err = userFromRequest(st, request, nil, "", "")
+	c.Assert(err, IsNil)
+	c.Check(user, IsNil)
+	user, err = userFromRequest(st, localRequest, &Ucrednet{Uid: 42}, "", "")
+	c.Assert(err, IsNil)
+	c.Check(user, IsNil)
+}
+
 func (s *daemonSuite) TestSecurityLoggingAuthzFail(c *C) {
 	logBuf, restore := logger.MockLogger("")
 	defer restore()
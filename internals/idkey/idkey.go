@@ -125,6 +125,12 @@ func (k *IDKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) (sig
 	return k.key.Sign(rand, digest, opts)
 }
 
+// Seed returns the private key seed, from which other keys can be derived
+// with a key derivation function such as HKDF. It must be kept secret.
+func (k *IDKey) Seed() []byte {
+	return k.key.Seed()
+}
+
 // load loads the private identity key from storage.
 func (k *IDKey) load() error {
 	exists, err := pathExists(k.keyDir)
@@ -165,6 +165,20 @@ func (ks *keySuite) TestKeySign(c *C) {
 	c.Assert(ok, Equals, true)
 }
 
+// TestKeySeed checks that the seed is the seed of the persisted key.
+func (ks *keySuite) TestKeySeed(c *C) {
+	keyDir := filepath.Join(c.MkDir(), "identity")
+	key, err := idkey.Generate(keyDir)
+	c.Assert(err, IsNil)
+	seed := key.Seed()
+	c.Assert(seed, HasLen, ed25519.SeedSize)
+	c.Check(ed25519.NewKeyFromSeed(seed).Public(), DeepEquals, key.Public())
+
+	loaded, err := idkey.Load(keyDir)
+	c.Assert(err, IsNil)
+	c.Check(loaded.Seed(), DeepEquals, seed)
+}
+
 // BenchmarkKeyGeneration prints some performance metrics. To run this test
 // use: go test -check.b
 func (ks *keySuite) BenchmarkKeyGeneration(c *C) {
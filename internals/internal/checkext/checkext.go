@@ -0,0 +1,25 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package checkext connects the check extension registry of the plan
+// package to the checkstate package, which is the only place check
+// extensions are registered (see checkstate.RegisterCheckExtension).
+package checkext
+
+// Register and Unregister are set by the plan package. Register adds a
+// plan.CheckExtension for the given check field, and Unregister removes it.
+var (
+	Register   func(field string, ext any)
+	Unregister func(field string)
+)
@@ -15,20 +15,126 @@
 package overlord
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/canonical/pebble/internals/osutil"
 )
 
+// stateEncryptionHeader starts the state file when it's encrypted. It's not
+// valid JSON, so older versions of Pebble fail to read an encrypted state
+// instead of silently starting with an empty one.
+const stateEncryptionHeader = "pebble-encrypted-state-v1\n"
+
+// stateKeyInfo is the HKDF info used to derive the state encryption key from
+// the identity key's seed, so the key is unrelated to any other key derived
+// from the seed.
+const stateKeyInfo = "pebble state encryption key v1"
+
+// seeder is implemented by identity keys (like idkey.IDKey) that expose
+// their private key seed, from which the state encryption key is derived.
+type seeder interface {
+	Seed() []byte
+}
+
 type overlordStateBackend struct {
 	path         string
 	ensureBefore func(d time.Duration)
+
+	// idKey is the identity key the state encryption key is derived from,
+	// or nil if there's no identity key that exposes its seed.
+	idKey seeder
+	// encrypt specifies whether checkpoints are encrypted.
+	encrypt bool
+	// aead is derived from idKey when it's first needed.
+	aead cipher.AEAD
 }
 
 func (osb *overlordStateBackend) Checkpoint(data []byte) error {
+	if osb.encrypt {
+		var err error
+		data, err = osb.seal(data)
+		if err != nil {
+			return err
+		}
+	}
 	return osutil.AtomicWriteFile(osb.path, data, 0600, 0)
 }
 
+// seal encrypts checkpoint data with AES-256-GCM.
+func (osb *overlordStateBackend) seal(data []byte) ([]byte, error) {
+	aead, err := osb.cipher()
+	if err != nil {
+		return nil, fmt.Errorf("cannot encrypt state: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("cannot encrypt state: %w", err)
+	}
+	sealed := make([]byte, 0, len(stateEncryptionHeader)+len(nonce)+len(data)+aead.Overhead())
+	sealed = append(sealed, stateEncryptionHeader...)
+	sealed = append(sealed, nonce...)
+	return aead.Seal(sealed, nonce, data, []byte(stateEncryptionHeader)), nil
+}
+
+// open returns the plain text of state data read from disk, decrypting it
+// if needed, and whether it was encrypted.
+func (osb *overlordStateBackend) open(data []byte) (plain []byte, encrypted bool, err error) {
+	if !bytes.HasPrefix(data, []byte(stateEncryptionHeader)) {
+		return data, false, nil
+	}
+	aead, err := osb.cipher()
+	if err != nil {
+		return nil, true, fmt.Errorf("cannot decrypt state: %w", err)
+	}
+	data = data[len(stateEncryptionHeader):]
+	if len(data) < aead.NonceSize() {
+		return nil, true, errors.New("cannot decrypt state: data too short")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plain, err = aead.Open(nil, nonce, ciphertext, []byte(stateEncryptionHeader))
+	if err != nil {
+		return nil, true, fmt.Errorf("cannot decrypt state (was it encrypted with another identity key?): %w", err)
+	}
+	return plain, true, nil
+}
+
+func (osb *overlordStateBackend) cipher() (cipher.AEAD, error) {
+	if osb.aead != nil {
+		return osb.aead, nil
+	}
+	if osb.idKey == nil {
+		return nil, errors.New("no identity key")
+	}
+	aead, err := stateCipher(osb.idKey.Seed())
+	if err != nil {
+		return nil, err
+	}
+	osb.aead = aead
+	return aead, nil
+}
+
+// stateCipher derives the state encryption key from the identity key's seed
+// using HKDF-SHA256.
+func stateCipher(seed []byte) (cipher.AEAD, error) {
+	key, err := hkdf.Key(sha256.New, seed, nil, stateKeyInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive state encryption key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
 func (osb *overlordStateBackend) EnsureBefore(d time.Duration) {
 	osb.ensureBefore(d)
 }
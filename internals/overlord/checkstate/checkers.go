@@ -15,13 +15,22 @@
 package checkstate
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/url"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -31,27 +40,69 @@ import (
 
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/osutil"
+	"github.com/canonical/pebble/internals/plan"
 	"github.com/canonical/pebble/internals/reaper"
 	"github.com/canonical/pebble/internals/servicelog"
 )
 
 const (
-	maxErrorBytes = 512
-	maxErrorLines = 5
-	execWaitDelay = time.Second
+	maxErrorBytes     = 512
+	maxErrorLines     = 5
+	maxBodyRegexBytes = 64 * 1024
+	maxFileRegexBytes = 64 * 1024
+	execWaitDelay     = time.Second
 )
 
-// httpChecker is a checker that ensures an HTTP GET at a specified URL returns 2xx.
+// procRoot is the mount point of the proc filesystem, used by process
+// checks. It's a variable so tests can override it.
+var procRoot = "/proc"
+
+// httpChecker is a checker that ensures an HTTP request to a specified URL
+// returns an expected status code (2xx by default), and optionally that the
+// response body matches a regex.
 type httpChecker struct {
-	name    string
-	url     string
-	headers map[string]string
+	name               string
+	url                string
+	method             string
+	headers            map[string]string
+	body               string
+	expectedStatus     []int
+	bodyRegex          *regexp.Regexp
+	insecureSkipVerify bool
+	caFile             string
+	certFile           string
+	keyFile            string
 }
 
 func (c *httpChecker) check(ctx context.Context) error {
-	logger.Debugf("Check %q (http): requesting %q", c.name, c.url)
+	method := c.method
+	if method == "" {
+		method = "GET"
+	}
+	logger.Debugf("Check %q (http): requesting %s %q", c.name, method, c.url)
+
 	client := &http.Client{}
-	request, err := http.NewRequestWithContext(ctx, "GET", c.url, nil)
+	if c.insecureSkipVerify || c.caFile != "" || c.certFile != "" {
+		tlsConfig, err := newTLSConfig(tlsOptions{
+			insecureSkipVerify: c.insecureSkipVerify,
+			caFile:             c.caFile,
+			certFile:           c.certFile,
+			keyFile:            c.keyFile,
+		})
+		if err != nil {
+			return err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		defer transport.CloseIdleConnections()
+		client.Transport = transport
+	}
+
+	var body io.Reader
+	if c.body != "" {
+		body = strings.NewReader(c.body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, c.url, body)
 	if err != nil {
 		return fmt.Errorf("cannot build request: %w", err)
 	}
@@ -65,33 +116,83 @@ func (c *httpChecker) check(ctx context.Context) error {
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode < 200 || response.StatusCode > 299 {
+	if !c.statusOK(response.StatusCode) {
 		// Include first few lines of response body in error details
 		output, err := io.ReadAll(io.LimitReader(response.Body, maxErrorBytes))
 		details := ""
 		if err != nil {
 			details = fmt.Sprintf("cannot read response: %v", err)
 		} else {
-			lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-			if len(lines) > maxErrorLines {
-				lines = lines[:maxErrorLines+1]
-				lines[maxErrorLines] = "(...)"
-			}
-			details = strings.Join(lines, "\n")
+			details = firstLines(output)
 		}
-		return &detailsError{
-			error:   fmt.Errorf("non-2xx status code %d", response.StatusCode),
-			details: details,
+		var statusErr error
+		if len(c.expectedStatus) == 0 {
+			statusErr = fmt.Errorf("non-2xx status code %d", response.StatusCode)
+		} else {
+			statusErr = fmt.Errorf("unexpected status code %d (expected %s)",
+				response.StatusCode, formatStatusCodes(c.expectedStatus))
+		}
+		return &detailsError{error: statusErr, details: details}
+	}
+
+	if c.bodyRegex != nil {
+		output, err := io.ReadAll(io.LimitReader(response.Body, maxBodyRegexBytes))
+		if err != nil {
+			return fmt.Errorf("cannot read response: %w", err)
+		}
+		if !c.bodyRegex.Match(output) {
+			return &detailsError{
+				error:   fmt.Errorf("response body does not match regex %q", c.bodyRegex.String()),
+				details: firstLines(output[:min(len(output), maxErrorBytes)]),
+			}
 		}
 	}
 	return nil
 }
 
-// tcpChecker is a checker that ensures a TCP port is open.
+// statusOK reports whether status is one of the expected status codes, or
+// is 2xx if no expected codes were configured.
+func (c *httpChecker) statusOK(status int) bool {
+	if len(c.expectedStatus) == 0 {
+		return status >= 200 && status <= 299
+	}
+	return slices.Contains(c.expectedStatus, status)
+}
+
+func formatStatusCodes(codes []int) string {
+	strs := make([]string, len(codes))
+	for i, code := range codes {
+		strs[i] = strconv.Itoa(code)
+	}
+	return strings.Join(strs, ", ")
+}
+
+// firstLines returns the first few lines of output, for use in error details.
+func firstLines(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) > maxErrorLines {
+		lines = lines[:maxErrorLines+1]
+		lines[maxErrorLines] = "(...)"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tcpChecker is a checker that ensures a TCP port is open, and optionally
+// that a TLS handshake succeeds and the peer's certificate is valid for at
+// least minValidity.
 type tcpChecker struct {
-	name string
-	host string
-	port int
+	name               string
+	host               string
+	port               int
+	tls                bool
+	insecureSkipVerify bool
+	caFile             string
+	serverName         string
+	minValidity        time.Duration
+
+	// notAfter is the expiry time of the peer certificate from the last
+	// successful TLS handshake.
+	notAfter time.Time
 }
 
 func (c *tcpChecker) check(ctx context.Context) error {
@@ -101,17 +202,78 @@ func (c *tcpChecker) check(ctx context.Context) error {
 	if host == "" {
 		host = "localhost"
 	}
+	address := net.JoinHostPort(host, strconv.Itoa(c.port))
+
+	if !c.tls {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		err = conn.Close()
+		if err != nil {
+			logger.Noticef("Check %q (tcp): unexpected error closing connection: %v", c.name, err)
+		}
+		return nil
+	}
 
-	var dialer net.Dialer
-	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(c.port)))
+	tlsConfig, err := newTLSConfig(tlsOptions{
+		insecureSkipVerify: c.insecureSkipVerify,
+		caFile:             c.caFile,
+		serverName:         c.serverName,
+	})
 	if err != nil {
 		return err
 	}
-	err = conn.Close()
+	dialer := tls.Dialer{Config: tlsConfig}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
 	if err != nil {
-		logger.Noticef("Check %q (tcp): unexpected error closing connection: %v", c.name, err)
+		// Unless verification is skipped, an expired certificate fails the
+		// handshake, so report its expiry in the same way as below.
+		var invalidErr x509.CertificateInvalidError
+		if errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired &&
+			invalidErr.Cert != nil && time.Now().After(invalidErr.Cert.NotAfter) {
+			return certValidityError(invalidErr.Cert, c.minValidity)
+		}
+		return err
 	}
-	return nil
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return fmt.Errorf("peer sent no certificates")
+	}
+	cert := certs[0]
+	c.notAfter = cert.NotAfter
+	return certValidityError(cert, c.minValidity)
+}
+
+// certValidityError returns an error with the certificate's expiry time as
+// details if it has expired or expires within minValidity, otherwise nil.
+func certValidityError(cert *x509.Certificate, minValidity time.Duration) error {
+	remaining := time.Until(cert.NotAfter)
+	if remaining >= minValidity && remaining > 0 {
+		return nil
+	}
+	details := fmt.Sprintf("certificate %q expires at %s", cert.Subject.CommonName,
+		cert.NotAfter.UTC().Format(time.RFC3339))
+	if remaining <= 0 {
+		return &detailsError{
+			error:   fmt.Errorf("certificate expired %v ago", (-remaining).Truncate(time.Second)),
+			details: details,
+		}
+	}
+	return &detailsError{
+		error: fmt.Errorf("certificate expires in %v, less than min-validity %v",
+			remaining.Truncate(time.Second), minValidity),
+		details: details,
+	}
+}
+
+// certExpiry returns the expiry time of the peer certificate seen in the last
+// successful TLS handshake, or the zero time if there hasn't been one.
+func (c *tcpChecker) certExpiry() time.Time {
+	return c.notAfter
 }
 
 // execChecker is a checker that ensures a command executes successfully.
@@ -127,9 +289,16 @@ type execChecker struct {
 }
 
 func (c *execChecker) check(ctx context.Context) error {
+	_, err := c.run(ctx)
+	return err
+}
+
+// run runs the command and returns the last few lines of its output. If the
+// command fails, the returned error includes the output as its details.
+func (c *execChecker) run(ctx context.Context) (output string, err error) {
 	args, err := shlex.Split(c.command)
 	if err != nil {
-		return fmt.Errorf("cannot parse command: %v", err)
+		return "", fmt.Errorf("cannot parse command: %v", err)
 	}
 
 	// Similar to services and exec, inherit the daemon's environment.
@@ -149,7 +318,7 @@ func (c *execChecker) check(ctx context.Context) error {
 	// Start as another user if specified in the check config.
 	uid, gid, err := osutil.NormalizeUidGid(c.userID, c.groupID, c.user, c.group)
 	if err != nil {
-		return err
+		return "", err
 	}
 	if uid != nil && gid != nil {
 		isCurrent, err := osutil.IsCurrent(*uid, *gid)
@@ -174,7 +343,7 @@ func (c *execChecker) check(ctx context.Context) error {
 	cmd.WaitDelay = execWaitDelay
 	err = reaper.StartCommand(cmd)
 	if err != nil {
-		return err
+		return "", err
 	}
 	logger.Debugf("Check %q (exec): running %q (PID %d)", c.name, c.command, cmd.Process.Pid)
 
@@ -182,19 +351,581 @@ func (c *execChecker) check(ctx context.Context) error {
 	if errors.Is(ctx.Err(), context.Canceled) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
 		// If context is cancelled or times out, exitCode will be 137
 		// and err will be nil, so return the ctx.Err() directly.
-		return ctx.Err()
+		return "", ctx.Err()
 	}
 	if err == nil && exitCode > 0 {
 		err = fmt.Errorf("exit status %d", exitCode)
 	}
+	output, linesErr := servicelog.LastLines(ringBuffer, maxErrorLines, "", false)
+	if linesErr != nil {
+		output = fmt.Sprintf("cannot read output: %v", linesErr)
+	}
 	if err != nil {
 		// Include the last few lines of output in the error details
-		var details string
-		details, linesErr := servicelog.LastLines(ringBuffer, maxErrorLines, "", false)
-		if linesErr != nil {
-			details = fmt.Sprintf("cannot read output: %v", linesErr)
+		return output, &detailsError{error: err, details: output}
+	}
+	return output, nil
+}
+
+// grpcChecker is a checker that ensures a gRPC server reports the configured
+// service as serving, using the grpc.health.v1 health checking protocol.
+type grpcChecker struct {
+	name               string
+	address            string
+	service            string
+	tls                bool
+	insecureSkipVerify bool
+	caFile             string
+	serverName         string
+	metadata           map[string]string
+}
+
+// gRPC status codes and health-check serving statuses we care about, from
+// the gRPC and grpc.health.v1 specifications.
+const (
+	grpcStatusOK            = "0"
+	grpcStatusUnimplemented = "12"
+
+	grpcHealthServing = 1
+)
+
+var grpcHealthStatusNames = map[uint64]string{
+	0: "UNKNOWN",
+	1: "SERVING",
+	2: "NOT_SERVING",
+	3: "SERVICE_UNKNOWN",
+}
+
+func (c *grpcChecker) check(ctx context.Context) error {
+	logger.Debugf("Check %q (grpc): checking service %q at %q", c.name, c.service, c.address)
+
+	// gRPC requires HTTP/2, either over TLS or in cleartext (h2c).
+	transport := &http.Transport{Protocols: &http.Protocols{}}
+	scheme := "http"
+	if c.tls {
+		tlsConfig, err := newTLSConfig(tlsOptions{
+			insecureSkipVerify: c.insecureSkipVerify,
+			caFile:             c.caFile,
+			serverName:         c.serverName,
+		})
+		if err != nil {
+			return err
+		}
+		transport.TLSClientConfig = tlsConfig
+		transport.Protocols.SetHTTP2(true)
+		scheme = "https"
+	} else {
+		transport.Protocols.SetUnencryptedHTTP2(true)
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport}
+
+	target := scheme + "://" + c.address + "/grpc.health.v1.Health/Check"
+	body := grpcFrame(grpcHealthCheckRequest(c.service))
+	request, err := http.NewRequestWithContext(ctx, "POST", target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot build request: %w", err)
+	}
+	for k, v := range c.metadata {
+		request.Header.Set(k, v)
+	}
+	request.Header.Set("Content-Type", "application/grpc")
+	request.Header.Set("TE", "trailers")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-200 HTTP status code %d", response.StatusCode)
+	}
+	message, err := io.ReadAll(io.LimitReader(response.Body, maxErrorBytes))
+	if err != nil {
+		return fmt.Errorf("cannot read response: %w", err)
+	}
+
+	// The status is usually sent in the trailers, but a "trailers-only"
+	// response (used for errors) sends it in the headers instead.
+	status := response.Trailer.Get("Grpc-Status")
+	statusMessage := response.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status = response.Header.Get("Grpc-Status")
+		statusMessage = response.Header.Get("Grpc-Message")
+	}
+	if status == "" {
+		return fmt.Errorf("response has no gRPC status")
+	}
+	if status != grpcStatusOK {
+		statusMessage = grpcDecodeMessage(statusMessage)
+		if status == grpcStatusUnimplemented {
+			return &detailsError{
+				error:   fmt.Errorf("server does not implement grpc.health.v1"),
+				details: statusMessage,
+			}
+		}
+		return &detailsError{
+			error:   fmt.Errorf("gRPC status %s", status),
+			details: statusMessage,
+		}
+	}
+
+	servingStatus, err := grpcHealthCheckResponse(message)
+	if err != nil {
+		return fmt.Errorf("cannot parse health check response: %w", err)
+	}
+	if servingStatus != grpcHealthServing {
+		name, ok := grpcHealthStatusNames[servingStatus]
+		if !ok {
+			name = strconv.FormatUint(servingStatus, 10)
+		}
+		return fmt.Errorf("service %q has status %s", c.service, name)
+	}
+	return nil
+}
+
+// grpcFrame wraps a serialized protobuf message in a gRPC length-prefixed
+// message frame (uncompressed).
+func grpcFrame(message []byte) []byte {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	return append(frame, message...)
+}
+
+// grpcHealthCheckRequest returns the protobuf encoding of a
+// grpc.health.v1.HealthCheckRequest message for the given service.
+func grpcHealthCheckRequest(service string) []byte {
+	if service == "" {
+		return nil
+	}
+	// Field 1 ("service"), wire type 2 (length-delimited).
+	message := []byte{1<<3 | 2}
+	message = binary.AppendUvarint(message, uint64(len(service)))
+	return append(message, service...)
+}
+
+// grpcHealthCheckResponse parses a framed grpc.health.v1.HealthCheckResponse
+// message and returns its serving status.
+func grpcHealthCheckResponse(frame []byte) (uint64, error) {
+	if len(frame) < 5 {
+		return 0, fmt.Errorf("message frame too short")
+	}
+	if frame[0] != 0 {
+		return 0, fmt.Errorf("compressed messages not supported")
+	}
+	length := binary.BigEndian.Uint32(frame[1:5])
+	if uint32(len(frame)-5) < length {
+		return 0, fmt.Errorf("message truncated")
+	}
+	message := frame[5 : 5+length]
+
+	// Field 1 ("status") is an enum; an absent field means UNKNOWN (0).
+	var status uint64
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return 0, fmt.Errorf("invalid field key")
+		}
+		message = message[n:]
+		field, wireType := key>>3, key&7
+		switch wireType {
+		case 0: // varint
+			value, n := binary.Uvarint(message)
+			if n <= 0 {
+				return 0, fmt.Errorf("invalid varint")
+			}
+			message = message[n:]
+			if field == 1 {
+				status = value
+			}
+		case 1: // 64-bit
+			if len(message) < 8 {
+				return 0, fmt.Errorf("invalid 64-bit field")
+			}
+			message = message[8:]
+		case 2: // length-delimited
+			size, n := binary.Uvarint(message)
+			if n <= 0 || uint64(len(message)-n) < size {
+				return 0, fmt.Errorf("invalid length-delimited field")
+			}
+			message = message[n+int(size):]
+		case 5: // 32-bit
+			if len(message) < 4 {
+				return 0, fmt.Errorf("invalid 32-bit field")
+			}
+			message = message[4:]
+		default:
+			return 0, fmt.Errorf("unsupported wire type %d", wireType)
+		}
+	}
+	return status, nil
+}
+
+// grpcDecodeMessage decodes the percent-encoded grpc-message value.
+func grpcDecodeMessage(message string) string {
+	decoded, err := url.PathUnescape(message)
+	if err != nil {
+		return message
+	}
+	return decoded
+}
+
+// logChecker is a checker that follows a service's log output, failing if a
+// line matching regex was logged within the window, or if no line matching
+// heartbeatRegex has been logged within the heartbeat timeout.
+//
+// The first time it runs, it reads all the logs currently in the service's
+// ring buffer. After that, it only reads new logs. If the service's ring
+// buffer is replaced, for example when the service is started again after
+// being pruned, it starts reading the new buffer from the tail.
+type logChecker struct {
+	name             string
+	service          string
+	regex            *regexp.Regexp
+	window           time.Duration
+	heartbeatRegex   *regexp.Regexp
+	heartbeatTimeout time.Duration
+	serviceLogs      ServiceLogsFunc
+
+	buffer        *servicelog.RingBuffer
+	iterator      servicelog.Iterator
+	parser        *servicelog.Parser
+	seenEntry     bool
+	lastMatch     servicelog.Entry
+	lastHeartbeat time.Time
+}
+
+const logReaderSize = 4 * 1024
+
+func newLogChecker(name string, config *plan.LogCheck, window time.Duration, serviceLogs ServiceLogsFunc) *logChecker {
+	// The regexes have already been checked when parsing the config.
+	chk := &logChecker{
+		name:             name,
+		service:          config.Service,
+		window:           window,
+		heartbeatTimeout: config.HeartbeatTimeout.Value,
+		serviceLogs:      serviceLogs,
+		lastHeartbeat:    time.Now(),
+	}
+	if config.Regex != "" {
+		chk.regex = regexp.MustCompile(config.Regex)
+	}
+	if config.HeartbeatRegex != "" {
+		chk.heartbeatRegex = regexp.MustCompile(config.HeartbeatRegex)
+	}
+	return chk
+}
+
+func (c *logChecker) check(ctx context.Context) error {
+	logger.Debugf("Check %q (log): reading logs of service %q", c.name, c.service)
+
+	if c.serviceLogs != nil {
+		if buffer := c.serviceLogs(c.service); buffer != c.buffer {
+			// The service's ring buffer has changed, so stop following
+			// the old one and start from the tail (the oldest logs still
+			// buffered) of the new one.
+			c.closeIterator()
+			c.buffer = buffer
+			if buffer != nil {
+				c.iterator = buffer.TailIterator()
+				c.parser = servicelog.NewParser(c.iterator, logReaderSize)
+			}
+		}
+	}
+
+	if c.iterator != nil {
+		for ctx.Err() == nil {
+			if c.parser.Next() {
+				c.handleEntry(c.parser.Entry())
+			} else if !c.iterator.Next(nil) {
+				break
+			}
+		}
+		if err := c.parser.Err(); err != nil {
+			return fmt.Errorf("cannot parse service logs: %w", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	if c.regex != nil && !c.lastMatch.Time.IsZero() && now.Sub(c.lastMatch.Time) < c.window {
+		return &detailsError{
+			error: fmt.Errorf("log matched regex %q at %s", c.regex.String(),
+				c.lastMatch.Time.Format(time.RFC3339)),
+			details: strings.TrimSpace(c.lastMatch.Message),
+		}
+	}
+	if c.heartbeatRegex != nil && now.Sub(c.lastHeartbeat) > c.heartbeatTimeout {
+		return fmt.Errorf("no log matching heartbeat regex %q for %v", c.heartbeatRegex.String(),
+			now.Sub(c.lastHeartbeat).Truncate(time.Second))
+	}
+	return nil
+}
+
+func (c *logChecker) handleEntry(entry servicelog.Entry) {
+	if !c.seenEntry {
+		// If the service has been logging for longer than we've been
+		// checking without a heartbeat, count from the first log we see.
+		c.seenEntry = true
+		if entry.Time.Before(c.lastHeartbeat) {
+			c.lastHeartbeat = entry.Time
+		}
+	}
+	message := []byte(strings.TrimSuffix(entry.Message, "\n"))
+	if c.regex != nil && c.regex.Match(message) {
+		c.lastMatch = entry
+	}
+	if c.heartbeatRegex != nil && c.heartbeatRegex.Match(message) && entry.Time.After(c.lastHeartbeat) {
+		c.lastHeartbeat = entry.Time
+	}
+}
+
+// Close releases the log iterator, if any.
+func (c *logChecker) Close() error {
+	return c.closeIterator()
+}
+
+func (c *logChecker) closeIterator() error {
+	if c.iterator == nil {
+		return nil
+	}
+	err := c.iterator.Close()
+	c.iterator = nil
+	c.parser = nil
+	return err
+}
+
+// tlsOptions holds the TLS client settings shared by the checkers that
+// support TLS.
+type tlsOptions struct {
+	insecureSkipVerify bool
+	caFile             string
+	certFile           string
+	keyFile            string
+	serverName         string
+}
+
+// newTLSConfig returns a TLS client configuration for the given options. If
+// no CA file is given, the system CA pool is used.
+func newTLSConfig(opts tlsOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: opts.insecureSkipVerify,
+		ServerName:         opts.serverName,
+	}
+	if opts.caFile != "" {
+		pem, err := os.ReadFile(opts.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("cannot find any certificates in CA file %q", opts.caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if opts.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.certFile, opts.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// fileChecker is a checker that ensures a file exists and, optionally, that
+// it was modified recently or that its content matches a regex.
+type fileChecker struct {
+	name   string
+	path   string
+	maxAge time.Duration
+	regex  *regexp.Regexp
+}
+
+func (c *fileChecker) check(ctx context.Context) error {
+	logger.Debugf("Check %q (file): checking %q", c.name, c.path)
+
+	info, err := os.Stat(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("file %q does not exist", c.path)
+	}
+	if err != nil {
+		return err
+	}
+	if c.maxAge > 0 {
+		age := time.Since(info.ModTime())
+		if age > c.maxAge {
+			return fmt.Errorf("file %q last modified %v ago (max-age %v)",
+				c.path, age.Truncate(time.Millisecond), c.maxAge)
+		}
+	}
+	if c.regex != nil {
+		f, err := os.Open(c.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		content, err := io.ReadAll(io.LimitReader(f, maxFileRegexBytes))
+		if err != nil {
+			return fmt.Errorf("cannot read file %q: %w", c.path, err)
+		}
+		if !c.regex.Match(content) {
+			return &detailsError{
+				error:   fmt.Errorf("file %q content does not match regex %q", c.path, c.regex.String()),
+				details: firstLines(content),
+			}
+		}
+	}
+	return nil
+}
+
+// processChecker is a checker that ensures the process in a PID file is
+// running, or that a service's process group contains a running process
+// (with the given name, if set).
+type processChecker struct {
+	name         string
+	pidFile      string
+	service      string
+	processName  string
+	processGroup ServiceProcessGroupFunc
+}
+
+func (c *processChecker) check(ctx context.Context) error {
+	if c.pidFile != "" {
+		logger.Debugf("Check %q (process): checking PID file %q", c.name, c.pidFile)
+		data, err := os.ReadFile(c.pidFile)
+		if err != nil {
+			return fmt.Errorf("cannot read PID file: %w", err)
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil || pid <= 0 {
+			return fmt.Errorf("PID file %q has invalid PID %q", c.pidFile, strings.TrimSpace(string(data)))
+		}
+		stat, err := readProcStat(pid)
+		if err != nil || stat.state == 'Z' || stat.state == 'X' {
+			return fmt.Errorf("process %d from PID file %q is not running", pid, c.pidFile)
+		}
+		return nil
+	}
+
+	logger.Debugf("Check %q (process): checking service %q", c.name, c.service)
+	pgid := 0
+	if c.processGroup != nil {
+		pgid = c.processGroup(c.service)
+	}
+	if pgid == 0 {
+		return fmt.Errorf("service %q is not running", c.service)
+	}
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return fmt.Errorf("cannot read processes: %w", err)
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue // not a process directory
+		}
+		stat, err := readProcStat(pid)
+		if err != nil {
+			continue // process has probably exited
+		}
+		if stat.pgrp != pgid || stat.state == 'Z' || stat.state == 'X' {
+			continue
+		}
+		if c.processName == "" || stat.comm == c.processName || argv0Name(pid) == c.processName {
+			return nil
+		}
+	}
+	if c.processName == "" {
+		return fmt.Errorf("no running processes in service %q", c.service)
+	}
+	return fmt.Errorf("no process named %q in service %q", c.processName, c.service)
+}
+
+type procStat struct {
+	comm  string
+	state byte
+	pgrp  int
+}
+
+// readProcStat reads the command name, state, and process group ID of the
+// given process from /proc/<pid>/stat.
+func readProcStat(pid int) (procStat, error) {
+	data, err := os.ReadFile(fmt.Sprintf("%s/%d/stat", procRoot, pid))
+	if err != nil {
+		return procStat{}, err
+	}
+	// The format is "pid (comm) state ppid pgrp ...", where comm may itself
+	// contain spaces and parentheses, so find the last closing parenthesis.
+	start := bytes.IndexByte(data, '(')
+	end := bytes.LastIndexByte(data, ')')
+	if start < 0 || end < start {
+		return procStat{}, fmt.Errorf("invalid stat format for process %d", pid)
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 3 || len(fields[0]) != 1 {
+		return procStat{}, fmt.Errorf("invalid stat format for process %d", pid)
+	}
+	pgrp, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return procStat{}, fmt.Errorf("invalid stat format for process %d", pid)
+	}
+	return procStat{
+		comm:  string(data[start+1 : end]),
+		state: fields[0][0],
+		pgrp:  pgrp,
+	}, nil
+}
+
+// argv0Name returns the base name of the given process's first argument,
+// which isn't truncated like the command name in /proc/<pid>/stat is.
+func argv0Name(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("%s/%d/cmdline", procRoot, pid))
+	if err != nil {
+		return ""
+	}
+	argv0, _, _ := bytes.Cut(data, []byte{0})
+	return filepath.Base(string(argv0))
+}
+
+// compositeChecker is a checker that succeeds if enough of the referenced
+// checks are currently up. It uses the checks' live status rather than
+// running them.
+type compositeChecker struct {
+	name    string
+	checks  []string
+	require plan.CompositeRequire
+	n       int
+	status  func(name string) CheckStatus
+}
+
+func (c *compositeChecker) check(ctx context.Context) error {
+	var down []string
+	for _, name := range c.checks {
+		status := c.status(name)
+		if status != CheckStatusUp {
+			down = append(down, fmt.Sprintf("%s (%s)", name, status))
+		}
+	}
+	up := len(c.checks) - len(down)
+
+	var need int
+	switch c.require {
+	case plan.CompositeRequireAny:
+		need = 1
+	case plan.CompositeRequireNOf:
+		need = c.n
+	default:
+		need = len(c.checks)
+	}
+	if up < need {
+		return &detailsError{
+			error:   fmt.Errorf("%d of %d checks up, need %d", up, len(c.checks), need),
+			details: "not up: " + strings.Join(down, ", "),
 		}
-		return &detailsError{error: err, details: details}
 	}
 	return nil
 }
@@ -17,18 +17,32 @@ package checkstate
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"log"
+	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"os/user"
+	"path/filepath"
+	"regexp"
 	"strconv"
+	"time"
 
 	. "gopkg.in/check.v1"
 
 	"github.com/canonical/pebble/internals/plan"
 	"github.com/canonical/pebble/internals/reaper"
+	"github.com/canonical/pebble/internals/servicelog"
 )
 
 type CheckersSuite struct{}
@@ -119,6 +133,124 @@ func (s *CheckersSuite) TestHTTP(c *C) {
 	c.Assert(err, ErrorMatches, "cannot build request: .*")
 }
 
+func (s *CheckersSuite) TestHTTPMethodStatusAndBody(c *C) {
+	var method, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		b, err := io.ReadAll(r.Body)
+		c.Check(err, IsNil)
+		body = string(b)
+		status, err := strconv.Atoi(r.URL.Path[1:])
+		if err == nil {
+			w.WriteHeader(status)
+		}
+		fmt.Fprint(w, "status: ok\nversion: 1.2.3\n")
+	}))
+	defer server.Close()
+
+	// Method and body are sent through
+	chk := &httpChecker{url: server.URL + "/200", method: "POST", body: `{"ping": true}`}
+	err := chk.check(context.Background())
+	c.Assert(err, IsNil)
+	c.Assert(method, Equals, "POST")
+	c.Assert(body, Equals, `{"ping": true}`)
+
+	// Expected status codes replace the default 2xx
+	chk = &httpChecker{url: server.URL + "/401", expectedStatus: []int{200, 401}}
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+	c.Assert(method, Equals, "GET")
+	chk = &httpChecker{url: server.URL + "/204", expectedStatus: []int{200, 401}}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `unexpected status code 204 \(expected 200, 401\)`)
+
+	// Body regex must match
+	chk = &httpChecker{url: server.URL + "/200", bodyRegex: regexp.MustCompile(`(?m)^status: ok$`)}
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+	chk = &httpChecker{url: server.URL + "/200", bodyRegex: regexp.MustCompile(`version: 2\.`)}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `response body does not match regex "version: 2\\\\."`)
+	detailsErr, ok := err.(*detailsError)
+	c.Assert(ok, Equals, true)
+	c.Assert(detailsErr.Details(), Equals, "status: ok\nversion: 1.2.3")
+}
+
+func (s *CheckersSuite) TestHTTPTLS(c *C) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			fmt.Fprintf(w, "client: %s", r.TLS.PeerCertificates[0].Subject.CommonName)
+		}
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	// Self-signed server certificate is rejected by default
+	chk := &httpChecker{url: server.URL}
+	err := chk.check(context.Background())
+	c.Assert(err, ErrorMatches, ".* certificate .*")
+
+	// Verification can be skipped
+	chk = &httpChecker{url: server.URL, insecureSkipVerify: true}
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Or the server certificate can be trusted with a CA file
+	dir := c.MkDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	err = os.WriteFile(caFile, caPEM, 0o644)
+	c.Assert(err, IsNil)
+	chk = &httpChecker{url: server.URL, caFile: caFile}
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Client certificate is presented
+	certFile, keyFile := writeClientCert(c, dir, "pebble-check")
+	chk = &httpChecker{
+		url:       server.URL,
+		caFile:    caFile,
+		certFile:  certFile,
+		keyFile:   keyFile,
+		bodyRegex: regexp.MustCompile(`^client: pebble-check$`),
+	}
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Missing client key returns error
+	chk = &httpChecker{url: server.URL, certFile: certFile, keyFile: filepath.Join(dir, "missing")}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, "cannot load client certificate: .*")
+}
+
+// writeClientCert writes a self-signed client certificate and its key to PEM
+// files in dir, and returns the paths of the certificate and key files.
+func writeClientCert(c *C, dir, commonName string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	c.Assert(err, IsNil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, IsNil)
+
+	certFile = filepath.Join(dir, commonName+".crt")
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+	c.Assert(err, IsNil)
+	keyFile = filepath.Join(dir, commonName+".key")
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	c.Assert(err, IsNil)
+	return certFile, keyFile
+}
+
 func (s *CheckersSuite) TestTCP(c *C) {
 	listener, err := net.Listen("tcp", "localhost:")
 	c.Assert(err, IsNil)
@@ -154,6 +286,117 @@ func (s *CheckersSuite) TestTCP(c *C) {
 	c.Assert(err, ErrorMatches, ".* connection refused")
 }
 
+func (s *CheckersSuite) TestTCPTLS(c *C) {
+	notAfter := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	cert, certPEM := newServerCert(c, "localhost", notAfter)
+	listener, err := tls.Listen("tcp", "localhost:", &tls.Config{Certificates: []tls.Certificate{cert}})
+	c.Assert(err, IsNil)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	// Self-signed certificate is rejected by default
+	chk := &tcpChecker{port: port, tls: true}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, ".* certificate .*")
+	c.Check(chk.certExpiry().IsZero(), Equals, true)
+
+	// Certificate can be trusted with a CA file
+	caFile := filepath.Join(c.MkDir(), "ca.pem")
+	err = os.WriteFile(caFile, certPEM, 0o644)
+	c.Assert(err, IsNil)
+	chk = &tcpChecker{port: port, tls: true, caFile: caFile}
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+	c.Check(chk.certExpiry().Equal(notAfter), Equals, true)
+
+	// Server name must match the certificate
+	chk = &tcpChecker{port: port, tls: true, caFile: caFile, serverName: "example.com"}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, ".* certificate is valid for localhost, not example.com")
+
+	// Certificate valid for long enough
+	chk = &tcpChecker{port: port, tls: true, caFile: caFile, minValidity: 24 * time.Hour}
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Certificate expires within min-validity
+	chk = &tcpChecker{port: port, tls: true, caFile: caFile, minValidity: 30 * 24 * time.Hour}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `certificate expires in 4[78]h.*, less than min-validity 720h0m0s`)
+	detailsErr, ok := err.(*detailsError)
+	c.Assert(ok, Equals, true)
+	c.Check(detailsErr.Details(), Equals,
+		fmt.Sprintf("certificate %q expires at %s", "localhost", notAfter.UTC().Format(time.RFC3339)))
+	c.Check(chk.certExpiry().Equal(notAfter), Equals, true)
+}
+
+func (s *CheckersSuite) TestTCPTLSExpired(c *C) {
+	notAfter := time.Now().Add(-30 * time.Minute).Truncate(time.Second)
+	cert, certPEM := newServerCert(c, "localhost", notAfter)
+	listener, err := tls.Listen("tcp", "localhost:", &tls.Config{Certificates: []tls.Certificate{cert}})
+	c.Assert(err, IsNil)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+	caFile := filepath.Join(c.MkDir(), "ca.pem")
+	err = os.WriteFile(caFile, certPEM, 0o644)
+	c.Assert(err, IsNil)
+	details := fmt.Sprintf("certificate %q expires at %s", "localhost", notAfter.UTC().Format(time.RFC3339))
+
+	// The expiry is reported whether or not the certificate is verified.
+	for _, chk := range []*tcpChecker{
+		{port: port, tls: true, caFile: caFile},
+		{port: port, tls: true, insecureSkipVerify: true},
+	} {
+		err = chk.check(context.Background())
+		c.Assert(err, ErrorMatches, `certificate expired (29m|30m).* ago`)
+		detailsErr, ok := err.(*detailsError)
+		c.Assert(ok, Equals, true)
+		c.Check(detailsErr.Details(), Equals, details)
+	}
+}
+
+// newServerCert returns a self-signed server certificate for the given host
+// name that expires at notAfter, and the certificate in PEM format.
+func newServerCert(c *C, host string, notAfter time.Time) (tls.Certificate, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: host},
+		DNSNames:              []string{host},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	c.Assert(err, IsNil)
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
 func (s *CheckersSuite) TestExec(c *C) {
 	err := reaper.Start()
 	c.Assert(err, IsNil)
@@ -259,21 +502,320 @@ func (s *CheckersSuite) TestExec(c *C) {
 	c.Assert(detailsErr.Details(), Equals, currentUser.Username)
 }
 
+func (s *CheckersSuite) TestGRPC(c *C) {
+	var metadata http.Header
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		c.Check(r.URL.Path, Equals, "/grpc.health.v1.Health/Check")
+		c.Check(r.Header.Get("Content-Type"), Equals, "application/grpc")
+		metadata = r.Header
+		body, err := io.ReadAll(r.Body)
+		c.Assert(err, IsNil)
+		c.Assert(len(body) >= 5, Equals, true)
+		service := ""
+		if len(body) > 5 {
+			// Single "service" string field, short enough for a one-byte length.
+			service = string(body[7:])
+		}
+
+		w.Header().Set("Content-Type", "application/grpc")
+		var status byte
+		switch service {
+		case "", "ok":
+			status = 1 // SERVING
+		case "sick":
+			status = 2 // NOT_SERVING
+		case "unimplemented":
+			w.Header().Set("Grpc-Status", "12")
+			w.Header().Set("Grpc-Message", "unknown%20service")
+			return
+		default:
+			w.Header().Set(http.TrailerPrefix+"Grpc-Status", "5")
+			w.Header().Set(http.TrailerPrefix+"Grpc-Message", "unknown service")
+			return
+		}
+		w.Write([]byte{0, 0, 0, 0, 2, 1<<3 | 0, status})
+		w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+	})
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.Protocols = &http.Protocols{}
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+	address := server.Listener.Addr().String()
+
+	// Serving status works, for the overall server and for a named service
+	chk := &grpcChecker{address: address}
+	err := chk.check(context.Background())
+	c.Assert(err, IsNil)
+	chk = &grpcChecker{
+		address:  address,
+		service:  "ok",
+		metadata: map[string]string{"X-Name": "Bob Smith"},
+	}
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+	c.Assert(metadata.Get("X-Name"), Equals, "Bob Smith")
+
+	// Non-serving status returns error
+	chk = &grpcChecker{address: address, service: "sick"}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `service "sick" has status NOT_SERVING`)
+
+	// Non-OK gRPC status returns error with message in details
+	chk = &grpcChecker{address: address, service: "unknown"}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, "gRPC status 5")
+	detailsErr, ok := err.(*detailsError)
+	c.Assert(ok, Equals, true)
+	c.Assert(detailsErr.Details(), Equals, "unknown service")
+
+	// Trailers-only response is handled
+	chk = &grpcChecker{address: address, service: "unimplemented"}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, "server does not implement grpc.health.v1")
+	detailsErr, ok = err.(*detailsError)
+	c.Assert(ok, Equals, true)
+	c.Assert(detailsErr.Details(), Equals, "unknown service")
+
+	// Cancelled context returns error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	chk = &grpcChecker{address: address}
+	err = chk.check(ctx)
+	c.Assert(err, ErrorMatches, ".* context canceled")
+
+	// After server closed, should get a network dial error
+	server.Close()
+	chk = &grpcChecker{address: address}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, ".* connection refused")
+}
+
+func (s *CheckersSuite) TestGRPCTLS(c *C) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Write([]byte{0, 0, 0, 0, 2, 1<<3 | 0, 1})
+		w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	address := server.Listener.Addr().String()
+
+	// Self-signed server certificate is rejected by default
+	chk := &grpcChecker{address: address, tls: true}
+	err := chk.check(context.Background())
+	c.Assert(err, ErrorMatches, ".* certificate .*")
+
+	// Verification can be skipped
+	chk = &grpcChecker{address: address, tls: true, insecureSkipVerify: true}
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Or the server certificate can be trusted with a CA file
+	caFile := filepath.Join(c.MkDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	err = os.WriteFile(caFile, caPEM, 0o644)
+	c.Assert(err, IsNil)
+	chk = &grpcChecker{address: address, tls: true, caFile: caFile, serverName: "example.com"}
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Invalid CA file returns error
+	err = os.WriteFile(caFile, []byte("foo"), 0o644)
+	c.Assert(err, IsNil)
+	chk = &grpcChecker{address: address, tls: true, caFile: caFile}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, "cannot find any certificates in CA file .*")
+}
+
+func (s *CheckersSuite) TestLog(c *C) {
+	rb := servicelog.NewRingBuffer(4096)
+	defer rb.Close()
+	writer := servicelog.NewFormatWriter(rb, "svc")
+	serviceLogs := func(service string) *servicelog.RingBuffer {
+		c.Check(service, Equals, "svc")
+		return rb
+	}
+
+	// No logs yet is fine
+	chk := newLogChecker("chk", &plan.LogCheck{
+		Service: "svc",
+		Regex:   "panic:",
+	}, time.Hour, serviceLogs)
+	defer chk.Close()
+	err := chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Non-matching logs are fine
+	fmt.Fprintln(writer, "all good")
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// A matching log within the window fails
+	fmt.Fprintln(writer, "panic: oh no")
+	fmt.Fprintln(writer, "still going")
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `log matched regex "panic:" at .*`)
+	detailsErr, ok := err.(*detailsError)
+	c.Assert(ok, Equals, true)
+	c.Assert(detailsErr.Details(), Equals, "panic: oh no")
+
+	// A new checker reads the existing logs too
+	chk2 := newLogChecker("chk", &plan.LogCheck{
+		Service: "svc",
+		Regex:   "oh no$",
+	}, time.Hour, serviceLogs)
+	defer chk2.Close()
+	err = chk2.check(context.Background())
+	c.Assert(err, ErrorMatches, `log matched regex "oh no\$" at .*`)
+
+	// But a match outside the window doesn't fail
+	chk3 := newLogChecker("chk", &plan.LogCheck{
+		Service: "svc",
+		Regex:   "panic:",
+	}, time.Nanosecond, serviceLogs)
+	defer chk3.Close()
+	err = chk3.check(context.Background())
+	c.Assert(err, IsNil)
+}
+
+func (s *CheckersSuite) TestLogHeartbeat(c *C) {
+	rb := servicelog.NewRingBuffer(4096)
+	defer rb.Close()
+	writer := servicelog.NewFormatWriter(rb, "svc")
+	serviceLogs := func(service string) *servicelog.RingBuffer {
+		return rb
+	}
+
+	// Within the timeout of the check starting is fine
+	chk := newLogChecker("chk", &plan.LogCheck{
+		Service:          "svc",
+		HeartbeatRegex:   "^tick$",
+		HeartbeatTimeout: plan.OptionalDuration{Value: 100 * time.Millisecond},
+	}, 0, serviceLogs)
+	defer chk.Close()
+	err := chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Heartbeats keep the check up
+	for i := 0; i < 3; i++ {
+		time.Sleep(30 * time.Millisecond)
+		fmt.Fprintln(writer, "tick")
+		err = chk.check(context.Background())
+		c.Assert(err, IsNil)
+	}
+
+	// Other logs don't count as a heartbeat
+	for i := 0; i < 3; i++ {
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprintln(writer, "ticking")
+	}
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `no log matching heartbeat regex "\^tick\$" for .*`)
+
+	// A new checker counts from the first buffered log, not when it started
+	chk2 := newLogChecker("chk", &plan.LogCheck{
+		Service:          "svc",
+		HeartbeatRegex:   "^tock$",
+		HeartbeatTimeout: plan.OptionalDuration{Value: 100 * time.Millisecond},
+	}, 0, serviceLogs)
+	defer chk2.Close()
+	err = chk2.check(context.Background())
+	c.Assert(err, ErrorMatches, `no log matching heartbeat regex "\^tock\$" for .*`)
+
+	// Service without logs is down once the timeout elapses
+	noLogs := func(service string) *servicelog.RingBuffer {
+		return nil
+	}
+	chk3 := newLogChecker("chk", &plan.LogCheck{
+		Service:          "svc",
+		HeartbeatRegex:   "^tick$",
+		HeartbeatTimeout: plan.OptionalDuration{Value: 10 * time.Millisecond},
+	}, 0, noLogs)
+	defer chk3.Close()
+	time.Sleep(20 * time.Millisecond)
+	err = chk3.check(context.Background())
+	c.Assert(err, ErrorMatches, `no log matching heartbeat regex "\^tick\$" for .*`)
+}
+
+func (s *CheckersSuite) TestLogServiceRestarted(c *C) {
+	rb := servicelog.NewRingBuffer(4096)
+	writer := servicelog.NewFormatWriter(rb, "svc")
+	serviceLogs := func(service string) *servicelog.RingBuffer {
+		return rb
+	}
+
+	chk := newLogChecker("chk", &plan.LogCheck{
+		Service:          "svc",
+		Regex:            "panic:",
+		HeartbeatRegex:   "^tick$",
+		HeartbeatTimeout: plan.OptionalDuration{Value: time.Hour},
+	}, time.Hour, serviceLogs)
+	defer chk.Close()
+	fmt.Fprintln(writer, "tick")
+	err := chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// The service is pruned and started again with a new ring buffer; the
+	// old buffer is no longer written to.
+	rb.Close()
+	rb = servicelog.NewRingBuffer(4096)
+	defer rb.Close()
+	writer = servicelog.NewFormatWriter(rb, "svc")
+
+	// The checker follows the new buffer.
+	fmt.Fprintln(writer, "panic: after restart")
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `log matched regex "panic:" at .*`)
+	detailsErr, ok := err.(*detailsError)
+	c.Assert(ok, Equals, true)
+	c.Check(detailsErr.Details(), Equals, "panic: after restart")
+
+	// Heartbeats in the new buffer are seen too.
+	chk.heartbeatTimeout = 50 * time.Millisecond
+	chk.regex = nil
+	time.Sleep(60 * time.Millisecond)
+	fmt.Fprintln(writer, "tick")
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+}
+
 func (s *CheckersSuite) TestNewChecker(c *C) {
-	chk := newChecker(&plan.Check{
+	manager := &CheckManager{}
+	chk := manager.newChecker(&plan.Check{
 		Name: "http",
 		HTTP: &plan.HTTPCheck{
-			URL:     "https://example.com/foo",
-			Headers: map[string]string{"k": "v"},
+			URL:                "https://example.com/foo",
+			Method:             "HEAD",
+			Headers:            map[string]string{"k": "v"},
+			Body:               "body",
+			ExpectedStatus:     []int{200, 204},
+			BodyRegex:          "^ok$",
+			InsecureSkipVerify: true,
+			CAFile:             "/ca.pem",
+			CertFile:           "/client.crt",
+			KeyFile:            "/client.key",
 		},
 	})
 	http, ok := chk.(*httpChecker)
 	c.Assert(ok, Equals, true)
 	c.Check(http.name, Equals, "http")
 	c.Check(http.url, Equals, "https://example.com/foo")
+	c.Check(http.method, Equals, "HEAD")
 	c.Check(http.headers, DeepEquals, map[string]string{"k": "v"})
+	c.Check(http.body, Equals, "body")
+	c.Check(http.expectedStatus, DeepEquals, []int{200, 204})
+	c.Check(http.bodyRegex.String(), Equals, "^ok$")
+	c.Check(http.insecureSkipVerify, Equals, true)
+	c.Check(http.caFile, Equals, "/ca.pem")
+	c.Check(http.certFile, Equals, "/client.crt")
+	c.Check(http.keyFile, Equals, "/client.key")
 
-	chk = newChecker(&plan.Check{
+	chk = manager.newChecker(&plan.Check{
 		Name: "tcp",
 		TCP: &plan.TCPCheck{
 			Port: 80,
@@ -287,7 +829,7 @@ func (s *CheckersSuite) TestNewChecker(c *C) {
 	c.Check(tcp.host, Equals, "localhost")
 
 	userID, groupID := 100, 200
-	chk = newChecker(&plan.Check{
+	chk = manager.newChecker(&plan.Check{
 		Name: "exec",
 		Exec: &plan.ExecCheck{
 			Command:     "sleep 1",
@@ -308,6 +850,292 @@ func (s *CheckersSuite) TestNewChecker(c *C) {
 	c.Assert(exec.user, Equals, "user")
 	c.Assert(exec.groupID, Equals, &groupID)
 	c.Assert(exec.workingDir, Equals, "/working/dir")
+
+	chk = manager.newChecker(&plan.Check{
+		Name: "tls",
+		TCP: &plan.TCPCheck{
+			Port:               8443,
+			TLS:                true,
+			InsecureSkipVerify: true,
+			CAFile:             "/ca.pem",
+			ServerName:         "example.com",
+			MinValidity:        plan.OptionalDuration{Value: 7 * 24 * time.Hour, IsSet: true},
+		},
+	})
+	tcp, ok = chk.(*tcpChecker)
+	c.Assert(ok, Equals, true)
+	c.Check(tcp.name, Equals, "tls")
+	c.Check(tcp.port, Equals, 8443)
+	c.Check(tcp.tls, Equals, true)
+	c.Check(tcp.insecureSkipVerify, Equals, true)
+	c.Check(tcp.caFile, Equals, "/ca.pem")
+	c.Check(tcp.serverName, Equals, "example.com")
+	c.Check(tcp.minValidity, Equals, 7*24*time.Hour)
+
+	chk = manager.newChecker(&plan.Check{
+		Name: "grpc",
+		GRPC: &plan.GRPCCheck{
+			Address:            "localhost:50051",
+			Service:            "svc",
+			TLS:                true,
+			InsecureSkipVerify: true,
+			CAFile:             "/ca.pem",
+			ServerName:         "example.com",
+			Metadata:           map[string]string{"k": "v"},
+		},
+	})
+	grpc, ok := chk.(*grpcChecker)
+	c.Assert(ok, Equals, true)
+	c.Check(grpc.name, Equals, "grpc")
+	c.Check(grpc.address, Equals, "localhost:50051")
+	c.Check(grpc.service, Equals, "svc")
+	c.Check(grpc.tls, Equals, true)
+	c.Check(grpc.insecureSkipVerify, Equals, true)
+	c.Check(grpc.caFile, Equals, "/ca.pem")
+	c.Check(grpc.serverName, Equals, "example.com")
+	c.Check(grpc.metadata, DeepEquals, map[string]string{"k": "v"})
+
+	chk = manager.newChecker(&plan.Check{
+		Name:   "log",
+		Period: plan.OptionalDuration{Value: 10 * time.Second},
+		Log: &plan.LogCheck{
+			Service:          "svc",
+			Regex:            "ERROR",
+			HeartbeatRegex:   "tick",
+			HeartbeatTimeout: plan.OptionalDuration{Value: time.Minute, IsSet: true},
+		},
+	})
+	log, ok := chk.(*logChecker)
+	c.Assert(ok, Equals, true)
+	c.Check(log.name, Equals, "log")
+	c.Check(log.service, Equals, "svc")
+	c.Check(log.regex.String(), Equals, "ERROR")
+	c.Check(log.window, Equals, 10*time.Second) // defaults to period
+	c.Check(log.heartbeatRegex.String(), Equals, "tick")
+	c.Check(log.heartbeatTimeout, Equals, time.Minute)
+
+	chk = manager.newChecker(&plan.Check{
+		Name: "composite",
+		Composite: &plan.CompositeCheck{
+			Checks:  []string{"a", "b"},
+			Require: plan.CompositeRequireNOf,
+			N:       1,
+		},
+	})
+	composite, ok := chk.(*compositeChecker)
+	c.Assert(ok, Equals, true)
+	c.Check(composite.name, Equals, "composite")
+	c.Check(composite.checks, DeepEquals, []string{"a", "b"})
+	c.Check(composite.require, Equals, plan.CompositeRequireNOf)
+	c.Check(composite.n, Equals, 1)
+	c.Check(composite.status("a"), Equals, CheckStatusInactive)
+
+	chk = manager.newChecker(&plan.Check{
+		Name: "file",
+		File: &plan.FileCheck{
+			Path:   "/heartbeat",
+			MaxAge: plan.OptionalDuration{Value: time.Minute, IsSet: true},
+			Regex:  "OK",
+		},
+	})
+	file, ok := chk.(*fileChecker)
+	c.Assert(ok, Equals, true)
+	c.Check(file.name, Equals, "file")
+	c.Check(file.path, Equals, "/heartbeat")
+	c.Check(file.maxAge, Equals, time.Minute)
+	c.Check(file.regex.String(), Equals, "OK")
+
+	manager.SetServiceProcessGroup(func(service string) int { return 42 })
+	chk = manager.newChecker(&plan.Check{
+		Name: "process",
+		Process: &plan.ProcessCheck{
+			Service: "svc",
+			Name:    "worker",
+		},
+	})
+	process, ok := chk.(*processChecker)
+	c.Assert(ok, Equals, true)
+	c.Check(process.name, Equals, "process")
+	c.Check(process.pidFile, Equals, "")
+	c.Check(process.service, Equals, "svc")
+	c.Check(process.processName, Equals, "worker")
+	c.Check(process.processGroup("svc"), Equals, 42)
+}
+
+func (s *CheckersSuite) TestFile(c *C) {
+	path := filepath.Join(c.MkDir(), "heartbeat")
+
+	chk := &fileChecker{name: "file", path: path}
+	err := chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `file ".*/heartbeat" does not exist`)
+
+	err = os.WriteFile(path, []byte("status: OK\n"), 0644)
+	c.Assert(err, IsNil)
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Modified recently enough
+	chk.maxAge = time.Minute
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Modified too long ago
+	old := time.Now().Add(-2 * time.Minute)
+	err = os.Chtimes(path, old, old)
+	c.Assert(err, IsNil)
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `file ".*/heartbeat" last modified 2m0.* ago \(max-age 1m0s\)`)
+
+	// Content matches
+	chk.maxAge = 0
+	chk.regex = regexp.MustCompile(`status: OK`)
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Content doesn't match
+	err = os.WriteFile(path, []byte("status: FAILED\n"), 0644)
+	c.Assert(err, IsNil)
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `file ".*/heartbeat" content does not match regex "status: OK"`)
+	detailsErr, ok := err.(*detailsError)
+	c.Assert(ok, Equals, true)
+	c.Check(detailsErr.Details(), Equals, "status: FAILED")
+}
+
+func (s *CheckersSuite) TestProcessPIDFile(c *C) {
+	pidFile := filepath.Join(c.MkDir(), "daemon.pid")
+
+	chk := &processChecker{name: "process", pidFile: pidFile}
+	err := chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `cannot read PID file: .*`)
+
+	err = os.WriteFile(pidFile, []byte("foo\n"), 0644)
+	c.Assert(err, IsNil)
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `PID file ".*/daemon.pid" has invalid PID "foo"`)
+
+	// Our own process is certainly running.
+	err = os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644)
+	c.Assert(err, IsNil)
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	// Use a fake proc filesystem to simulate an exited and a zombie process.
+	restore := fakeProcRoot(c, map[int]string{
+		100: "100 (daemon) Z 1 100 100 0 -1",
+	})
+	defer restore()
+	err = os.WriteFile(pidFile, []byte("99"), 0644)
+	c.Assert(err, IsNil)
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `process 99 from PID file ".*/daemon.pid" is not running`)
+	err = os.WriteFile(pidFile, []byte("100"), 0644)
+	c.Assert(err, IsNil)
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `process 100 from PID file ".*/daemon.pid" is not running`)
+}
+
+func (s *CheckersSuite) TestProcessService(c *C) {
+	restore := fakeProcRoot(c, map[int]string{
+		100: "100 (sh) S 1 100 100 0 -1",
+		101: "101 (my worker) (x) S 100 100 100 0 -1",
+		102: "102 (zombie) Z 100 100 100 0 -1",
+		200: "200 (other) S 1 200 200 0 -1",
+	})
+	defer restore()
+
+	pgids := map[string]int{"svc": 100}
+	chk := &processChecker{
+		name:         "process",
+		service:      "svc",
+		processGroup: func(service string) int { return pgids[service] },
+	}
+	err := chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	chk.processName = "my worker) (x"
+	err = chk.check(context.Background())
+	c.Assert(err, IsNil)
+
+	chk.processName = "zombie"
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `no process named "zombie" in service "svc"`)
+
+	chk.processName = "other"
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `no process named "other" in service "svc"`)
+
+	chk.service = "nosvc"
+	err = chk.check(context.Background())
+	c.Assert(err, ErrorMatches, `service "nosvc" is not running`)
+}
+
+// fakeProcRoot points procRoot at a temporary directory containing a stat
+// file for each given process, and returns a function to restore it.
+func fakeProcRoot(c *C, stats map[int]string) (restore func()) {
+	dir := c.MkDir()
+	for pid, stat := range stats {
+		pidDir := filepath.Join(dir, strconv.Itoa(pid))
+		err := os.Mkdir(pidDir, 0755)
+		c.Assert(err, IsNil)
+		err = os.WriteFile(filepath.Join(pidDir, "stat"), []byte(stat+"\n"), 0644)
+		c.Assert(err, IsNil)
+	}
+	old := procRoot
+	procRoot = dir
+	return func() {
+		procRoot = old
+	}
+}
+
+func (s *CheckersSuite) TestComposite(c *C) {
+	statuses := map[string]CheckStatus{
+		"a": CheckStatusUp,
+		"b": CheckStatusDown,
+		"c": CheckStatusUp,
+		"d": CheckStatusInactive,
+	}
+	status := func(name string) CheckStatus {
+		return statuses[name]
+	}
+
+	tests := []struct {
+		checks  []string
+		require plan.CompositeRequire
+		n       int
+		err     string
+		details string
+	}{
+		{checks: []string{"a", "c"}},
+		{checks: []string{"a", "c"}, require: plan.CompositeRequireAll},
+		{checks: []string{"a", "b", "c"}, require: plan.CompositeRequireAll,
+			err: "2 of 3 checks up, need 3", details: "not up: b (down)"},
+		{checks: []string{"b", "c"}, require: plan.CompositeRequireAny},
+		{checks: []string{"b", "d"}, require: plan.CompositeRequireAny,
+			err: "0 of 2 checks up, need 1", details: "not up: b (down), d (inactive)"},
+		{checks: []string{"a", "b", "c"}, require: plan.CompositeRequireNOf, n: 2},
+		{checks: []string{"a", "b", "d"}, require: plan.CompositeRequireNOf, n: 2,
+			err: "1 of 3 checks up, need 2", details: "not up: b (down), d (inactive)"},
+	}
+	for _, test := range tests {
+		c.Logf("checks %v, require %q, n %d", test.checks, test.require, test.n)
+		chk := &compositeChecker{
+			name:    "composite",
+			checks:  test.checks,
+			require: test.require,
+			n:       test.n,
+			status:  status,
+		}
+		err := chk.check(context.Background())
+		if test.err == "" {
+			c.Check(err, IsNil)
+			continue
+		}
+		c.Check(err, ErrorMatches, test.err)
+		if detailsErr, ok := err.(*detailsError); c.Check(ok, Equals, true) {
+			c.Check(detailsErr.Details(), Equals, test.details)
+		}
+	}
 }
 
 func (s *CheckersSuite) TestExecContextNoOverride(c *C) {
@@ -329,7 +1157,8 @@ func (s *CheckersSuite) TestExecContextNoOverride(c *C) {
 			ServiceContext: "svc1",
 		},
 	})
-	chk := newChecker(config)
+	manager := &CheckManager{}
+	chk := manager.newChecker(config)
 	exec, ok := chk.(*execChecker)
 	c.Assert(ok, Equals, true)
 	c.Check(exec.name, Equals, "exec")
@@ -367,7 +1196,8 @@ func (s *CheckersSuite) TestExecContextOverride(c *C) {
 			WorkingDir:     "/working/dir",
 		},
 	})
-	chk := newChecker(config)
+	manager := &CheckManager{}
+	chk := manager.newChecker(config)
 	exec, ok := chk.(*execChecker)
 	c.Assert(ok, Equals, true)
 	c.Check(exec.name, Equals, "exec")
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package checkstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/canonical/pebble/internals/internal/checkext"
+	"github.com/canonical/pebble/internals/plan"
+)
+
+// ExtensionChecker is the interface implemented by the checkers of
+// extension-defined check types.
+type ExtensionChecker interface {
+	// Check performs a single run of the check, returning an error if it
+	// fails. It must return promptly when ctx is cancelled, which happens
+	// when the check's timeout is reached or the check is stopped. If the
+	// error has a "Details() string" method, the details are recorded
+	// along with the error (for example, in the check's history).
+	Check(ctx context.Context) error
+}
+
+// CheckExtension defines a custom check type: how its configuration is parsed
+// and merged (see plan.CheckExtension), and how it is performed.
+type CheckExtension interface {
+	plan.CheckExtension
+
+	// NewChecker returns a new checker for the named check, given the
+	// check's configuration for this extension, as returned by ParseCheck.
+	// If the returned checker implements io.Closer, it is closed when the
+	// check is stopped or its configuration changes.
+	NewChecker(name string, config plan.CheckConfig) ExtensionChecker
+}
+
+// checkExtensions keeps a map of registered check extensions.
+var checkExtensions = map[string]CheckExtension{}
+
+// RegisterCheckExtension adds a custom check type, configured with the given
+// field in a check's YAML. All registrations must be done before the plan
+// library is used, and before the check manager is started.
+func RegisterCheckExtension(field string, ext CheckExtension) {
+	checkext.Register(field, ext)
+	checkExtensions[field] = ext
+}
+
+// UnregisterCheckExtension removes a custom check type. This is only intended
+// for use by tests during cleanup.
+func UnregisterCheckExtension(field string) {
+	checkext.Unregister(field)
+	delete(checkExtensions, field)
+}
+
+// extensionField returns the field of the check's extension-defined type, or
+// "" if it's a built-in check type. Validation ensures there's at most one.
+func extensionField(config *plan.Check) string {
+	for field := range config.Extensions {
+		return field
+	}
+	return ""
+}
+
+// newExtensionChecker creates a checker for an extension-defined check type.
+func newExtensionChecker(config *plan.Check) checker {
+	field := extensionField(config)
+	ext, ok := checkExtensions[field]
+	if !ok {
+		panic(fmt.Sprintf("internal error: check extension %q not registered", field))
+	}
+	return &extensionAdapter{checker: ext.NewChecker(config.Name, config.Extensions[field])}
+}
+
+// extensionAdapter adapts an ExtensionChecker to the checker interface.
+type extensionAdapter struct {
+	checker ExtensionChecker
+}
+
+func (c *extensionAdapter) check(ctx context.Context) error {
+	err := c.checker.Check(ctx)
+	var detailer interface{ Details() string }
+	if errors.As(err, &detailer) {
+		return &detailsError{error: err, details: detailer.Details()}
+	}
+	return err
+}
+
+func (c *extensionAdapter) Close() error {
+	closer, ok := c.checker.(io.Closer)
+	if !ok {
+		return nil
+	}
+	return closer.Close()
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package checkstate_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	. "gopkg.in/check.v1"
+	"gopkg.in/yaml.v3"
+
+	"github.com/canonical/pebble/internals/overlord/checkstate"
+	"github.com/canonical/pebble/internals/plan"
+)
+
+func (s *ManagerSuite) TestCheckExtension(c *C) {
+	ext := &queueExtension{}
+	checkstate.RegisterCheckExtension("queue", ext)
+	defer checkstate.UnregisterCheckExtension("queue")
+
+	ext.depth.Store(5)
+	s.manager.PlanChanged(&plan.Plan{
+		Checks: map[string]*plan.Check{
+			"chk1": {
+				Name:      "chk1",
+				Override:  "replace",
+				Period:    plan.OptionalDuration{Value: 20 * time.Millisecond},
+				Timeout:   plan.OptionalDuration{Value: time.Second},
+				Threshold: 3,
+				Extensions: map[string]plan.CheckConfig{
+					"queue": &queueCheck{MaxDepth: 10},
+				},
+			},
+		},
+	})
+	waitCheck(c, s.manager, "chk1", func(check *checkstate.CheckInfo) bool {
+		return check.Successes > 0
+	})
+
+	// Queue too deep, check goes down and the error details are recorded.
+	ext.depth.Store(20)
+	check := waitCheck(c, s.manager, "chk1", func(check *checkstate.CheckInfo) bool {
+		return check.Status == checkstate.CheckStatusDown
+	})
+	c.Check(check.Failures, Equals, 3)
+	history := s.manager.CheckHistory("chk1", 1)
+	c.Assert(history, HasLen, 1)
+	c.Check(history[0].Error, Equals, "queue too deep")
+	c.Check(history[0].Details, Equals, "depth 20, max 10")
+
+	st := s.overlord.State()
+	st.Lock()
+	change := st.Change(check.ChangeID)
+	c.Assert(change, NotNil)
+	c.Check(change.Tasks()[0].Summary(), Equals, `Recover queue check "chk1"`)
+	st.Unlock()
+
+	// Checker is closed when the check is removed.
+	s.manager.PlanChanged(&plan.Plan{})
+	for i := 0; ext.closed.Load() == 0; i++ {
+		if i >= 1000 {
+			c.Fatalf("timed out waiting for checker to be closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// queueExtension implements the CheckExtension interface.
+type queueExtension struct {
+	depth  atomic.Int64
+	closed atomic.Int64
+}
+
+func (e *queueExtension) ParseCheck(data yaml.Node) (plan.CheckConfig, error) {
+	check := &queueCheck{}
+	err := plan.SectionDecode(&data, check)
+	if err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+func (e *queueExtension) NewChecker(name string, config plan.CheckConfig) checkstate.ExtensionChecker {
+	return &queueChecker{ext: e, maxDepth: config.(*queueCheck).MaxDepth}
+}
+
+// queueCheck is the backing type for queueExtension.
+type queueCheck struct {
+	MaxDepth int64 `yaml:"max-depth,omitempty"`
+}
+
+func (q *queueCheck) Validate() error {
+	return nil
+}
+
+func (q *queueCheck) Copy() plan.CheckConfig {
+	copied := *q
+	return &copied
+}
+
+func (q *queueCheck) Merge(other plan.CheckConfig) {
+	if o := other.(*queueCheck); o.MaxDepth != 0 {
+		q.MaxDepth = o.MaxDepth
+	}
+}
+
+type queueChecker struct {
+	ext      *queueExtension
+	maxDepth int64
+}
+
+func (q *queueChecker) Check(ctx context.Context) error {
+	depth := q.ext.depth.Load()
+	if depth > q.maxDepth {
+		return &queueError{depth: depth, maxDepth: q.maxDepth}
+	}
+	return nil
+}
+
+func (q *queueChecker) Close() error {
+	q.ext.closed.Add(1)
+	return nil
+}
+
+type queueError struct {
+	depth, maxDepth int64
+}
+
+func (e *queueError) Error() string {
+	return "queue too deep"
+}
+
+func (e *queueError) Details() string {
+	return fmt.Sprintf("depth %d, max %d", e.depth, e.maxDepth)
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	tombpkg "gopkg.in/tomb.v2"
@@ -42,18 +43,31 @@ func (m *CheckManager) doPerformCheck(task *state.Task, tomb *tombpkg.Tomb) erro
 	ticker := time.NewTicker(config.Period.Value)
 	defer ticker.Stop()
 
+	// Only delay the first check when the check is first started, not when
+	// it has switched back from recovering.
+	var initialDelay <-chan time.Time
+	if config.InitialDelay.Value > 0 && details.Successes == 0 && details.Failures == 0 {
+		ticker.Stop()
+		timer := time.NewTimer(config.InitialDelay.Value)
+		defer timer.Stop()
+		initialDelay = timer.C
+	}
+
 	m.checksLock.Lock()
 	data := m.ensureCheck(config.Name)
 	refresh := data.refresh
 	m.checksLock.Unlock()
 
-	chk := newChecker(config)
+	chk := m.newChecker(config)
+	defer closeChecker(chk)
 
 	performCheck := func() (shouldExit bool, err error) {
+		start := time.Now()
 		err = runCheck(tomb.Context(nil), chk, config.Timeout.Value)
 		if !tomb.Alive() {
 			return true, checkStopped(config.Name, task.Kind(), tomb.Err())
 		}
+		m.recordResult(config, chk, start, err)
 		if err != nil {
 			m.incFailureMetric(config)
 			// Record check failure and perform any action if the threshold
@@ -61,9 +75,21 @@ func (m *CheckManager) doPerformCheck(task *state.Task, tomb *tombpkg.Tomb) erro
 			details.Failures++
 			m.updateCheckData(config, changeID, details.Successes, details.Failures)
 
+			// While a startup check of the same service is pending, the
+			// service may still be booting, so don't act on failures yet.
+			startupPending := config.Level != plan.StartupLevel && m.startupPending(config.Name)
+
 			m.state.Lock()
-			atThreshold := details.Failures >= config.Threshold
+			atThreshold := details.Failures >= config.Threshold && !startupPending
 			if atThreshold {
+				// Only report the check as down once it's acted on, as a
+				// check that succeeds again while startup checks are
+				// pending never switches to recovering (and so never
+				// reports it's up again).
+				addStatusNotice(m.state, config.Name, CheckStatusDown, map[string]string{
+					"failures": strconv.Itoa(details.Failures),
+					"error":    err.Error(),
+				})
 				details.Proceed = true
 			} else {
 				// Add error to task log, but only if we haven't reached the
@@ -75,9 +101,15 @@ func (m *CheckManager) doPerformCheck(task *state.Task, tomb *tombpkg.Tomb) erro
 			m.state.Unlock()
 
 			logger.Noticef("Check %q failure %d/%d: %v", config.Name, details.Failures, config.Threshold, err)
+			if startupPending && details.Failures == config.Threshold {
+				logger.Noticef("Check %q threshold %d hit, but startup checks are pending; not triggering action", config.Name, config.Threshold)
+			}
 			if atThreshold {
 				logger.Noticef("Check %q threshold %d hit, triggering action and recovering", config.Name, config.Threshold)
 				m.callFailureHandlers(config.Name)
+				if config.OnDown != nil {
+					m.runHook(tomb.Context(nil), task, config, "on-down", config.OnDown)
+				}
 				// Returning the error means perform-check goes to Error status
 				// and logs the error to the task log.
 				return true, err
@@ -108,8 +140,16 @@ func (m *CheckManager) doPerformCheck(task *state.Task, tomb *tombpkg.Tomb) erro
 
 	for {
 		select {
+		case <-initialDelay:
+			initialDelay = nil
+			ticker.Reset(config.Period.Value)
+			shouldExit, err := performCheck()
+			if shouldExit {
+				return err
+			}
 		case info := <-refresh:
-			// Reset ticker on refresh.
+			// Reset ticker on refresh (this also ends any initial delay).
+			initialDelay = nil
 			ticker.Reset(config.Period.Value)
 			shouldExit, err := performCheck()
 			select {
@@ -161,13 +201,16 @@ func (m *CheckManager) doRecoverCheck(task *state.Task, tomb *tombpkg.Tomb) erro
 	refresh := data.refresh
 	m.checksLock.Unlock()
 
-	chk := newChecker(config)
+	chk := m.newChecker(config)
+	defer closeChecker(chk)
 
 	recoverCheck := func() (shouldExit bool, err error) {
+		start := time.Now()
 		err = runCheck(tomb.Context(nil), chk, config.Timeout.Value)
 		if !tomb.Alive() {
 			return true, checkStopped(config.Name, task.Kind(), tomb.Err())
 		}
+		m.recordResult(config, chk, start, err)
 		if err != nil {
 			m.incFailureMetric(config)
 			details.Failures++
@@ -184,12 +227,19 @@ func (m *CheckManager) doRecoverCheck(task *state.Task, tomb *tombpkg.Tomb) erro
 
 		// Check succeeded, switch to performing a succeeding check.
 		m.incSuccessMetric(config)
+		oldFailures := details.Failures
 		details.Successes = 1
 		details.Failures = 0
 		m.updateCheckData(config, changeID, details.Successes, details.Failures)
+		if config.OnUp != nil {
+			m.runHook(tomb.Context(nil), task, config, "on-up", config.OnUp)
+		}
 		details.Proceed = true
 		m.state.Lock()
 		task.Set(checkDetailsAttr, &details)
+		addStatusNotice(m.state, config.Name, CheckStatusUp, map[string]string{
+			"failures": strconv.Itoa(oldFailures),
+		})
 		m.state.Unlock()
 		return true, nil
 	}
@@ -218,6 +268,16 @@ func (m *CheckManager) doRecoverCheck(task *state.Task, tomb *tombpkg.Tomb) erro
 	}
 }
 
+// addStatusNotice records a check-status notice for the check going up or
+// down. The state lock must be held.
+func addStatusNotice(st *state.State, name string, status CheckStatus, data map[string]string) {
+	data["status"] = string(status)
+	_, err := st.AddNotice(nil, state.CheckStatusNotice, name, &state.AddNoticeOptions{Data: data})
+	if err != nil {
+		logger.Noticef("Cannot record check-status notice for check %q: %v", name, err)
+	}
+}
+
 func errorDetails(err error) string {
 	message := err.Error()
 	var detailsErr *detailsError
@@ -16,11 +16,16 @@ package checkstate
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/tomb.v2"
 
@@ -29,6 +34,7 @@ import (
 	"github.com/canonical/pebble/internals/overlord/planstate"
 	"github.com/canonical/pebble/internals/overlord/state"
 	"github.com/canonical/pebble/internals/plan"
+	"github.com/canonical/pebble/internals/servicelog"
 )
 
 const (
@@ -37,6 +43,14 @@ const (
 
 	noPruneAttr      = "check-no-prune"
 	checkDetailsAttr = "check-details"
+
+	// defaultHistoryLimit is the number of results kept in a check's
+	// history if the check doesn't set history-limit.
+	defaultHistoryLimit = 10
+
+	// defaultHookTimeout is how long on-down and on-up hooks may run if the
+	// hook doesn't set a timeout.
+	defaultHookTimeout = time.Minute
 )
 
 // CheckManager starts and manages the health checks.
@@ -44,15 +58,30 @@ type CheckManager struct {
 	state   *state.State
 	planMgr *planstate.PlanManager
 
-	failureHandlers []FailureFunc
+	failureHandlers     []FailureFunc
+	serviceLogs         ServiceLogsFunc
+	serviceProcessGroup ServiceProcessGroupFunc
 
 	checksLock sync.Mutex
 	checks     map[string]*checkData
+	// checkServices maps each check name to the services that list it in
+	// on-check-failure.
+	checkServices map[string][]string
 }
 
 // FailureFunc is the type of function called when a failure action is triggered.
 type FailureFunc func(name string)
 
+// ServiceLogsFunc is the type of function used to get the log buffer of a
+// service, as done by servstate's ServiceLogBuffer. It returns nil if the
+// service hasn't been started.
+type ServiceLogsFunc func(service string) *servicelog.RingBuffer
+
+// ServiceProcessGroupFunc is the type of function used to get the process
+// group ID of a running service (zero if it's not running), as done by
+// servstate's ServiceProcessGroup.
+type ServiceProcessGroupFunc func(service string) int
+
 // NewManager creates a new check manager.
 func NewManager(s *state.State, runner *state.TaskRunner, planMgr *planstate.PlanManager) *CheckManager {
 	manager := &CheckManager{
@@ -100,6 +129,17 @@ func (m *CheckManager) NotifyCheckFailed(f FailureFunc) {
 	m.failureHandlers = append(m.failureHandlers, f)
 }
 
+// SetServiceLogs sets the function used by log checks to read service logs.
+func (m *CheckManager) SetServiceLogs(f ServiceLogsFunc) {
+	m.serviceLogs = f
+}
+
+// SetServiceProcessGroup sets the function used by process checks to find
+// a service's process group.
+func (m *CheckManager) SetServiceProcessGroup(f ServiceProcessGroupFunc) {
+	m.serviceProcessGroup = f
+}
+
 // PlanChanged handles updates to the plan (server configuration),
 // stopping the previous checks and starting the new ones as required.
 func (m *CheckManager) PlanChanged(newPlan *plan.Plan) {
@@ -110,6 +150,16 @@ func (m *CheckManager) PlanChanged(newPlan *plan.Plan) {
 	newOrModified := make(map[string]bool)
 	existingChecks := make(map[string]bool)
 
+	checkServices := make(map[string][]string)
+	for _, service := range newPlan.Services {
+		for name := range service.OnCheckFailure {
+			checkServices[name] = append(checkServices[name], service.Name)
+		}
+	}
+	m.checksLock.Lock()
+	m.checkServices = checkServices
+	m.checksLock.Unlock()
+
 	// Abort all currently-running checks that have been removed or modified.
 	for _, change := range m.state.Changes() {
 		switch change.Kind() {
@@ -178,7 +228,7 @@ func (m *CheckManager) PlanChanged(newPlan *plan.Plan) {
 	for _, config := range newPlan.Checks {
 		if newOrModified[config.Name] {
 			merged := mergeServiceContext(newPlan, config)
-			changeID := performCheckChange(m.state, merged)
+			changeID := performCheckChange(m.state, merged, 0)
 			m.updateCheckData(config, changeID, 0, 0)
 			shouldEnsure = true
 		}
@@ -207,7 +257,7 @@ func (m *CheckManager) changeStatusChanged(change *state.Change, old, new state.
 			break
 		}
 		config := m.state.Cached(recoverConfigKey{change.ID()}).(*plan.Check) // panic if key not present (always should be)
-		changeID := performCheckChange(m.state, config)
+		changeID := performCheckChange(m.state, config, details.Successes)
 		m.updateCheckData(config, changeID, details.Successes, details.Failures)
 		shouldEnsure = true
 	}
@@ -245,6 +295,18 @@ func checkType(config *plan.Check) string {
 		return "TCP"
 	case config.Exec != nil:
 		return "exec"
+	case config.GRPC != nil:
+		return "gRPC"
+	case config.Log != nil:
+		return "log"
+	case config.Composite != nil:
+		return "composite"
+	case config.File != nil:
+		return "file"
+	case config.Process != nil:
+		return "process"
+	case len(config.Extensions) > 0:
+		return extensionField(config)
 	default:
 		return "<unknown>"
 	}
@@ -252,20 +314,38 @@ func checkType(config *plan.Check) string {
 
 // newChecker creates a new checker of the configured type. Assumes
 // mergeServiceContext has already been called.
-func newChecker(config *plan.Check) checker {
+func (m *CheckManager) newChecker(config *plan.Check) checker {
 	switch {
 	case config.HTTP != nil:
+		var bodyRegex *regexp.Regexp
+		if config.HTTP.BodyRegex != "" {
+			// The regex has already been checked when parsing the config.
+			bodyRegex = regexp.MustCompile(config.HTTP.BodyRegex)
+		}
 		return &httpChecker{
-			name:    config.Name,
-			url:     config.HTTP.URL,
-			headers: config.HTTP.Headers,
+			name:               config.Name,
+			url:                config.HTTP.URL,
+			method:             config.HTTP.Method,
+			headers:            config.HTTP.Headers,
+			body:               config.HTTP.Body,
+			expectedStatus:     config.HTTP.ExpectedStatus,
+			bodyRegex:          bodyRegex,
+			insecureSkipVerify: config.HTTP.InsecureSkipVerify,
+			caFile:             config.HTTP.CAFile,
+			certFile:           config.HTTP.CertFile,
+			keyFile:            config.HTTP.KeyFile,
 		}
 
 	case config.TCP != nil:
 		return &tcpChecker{
-			name: config.Name,
-			host: config.TCP.Host,
-			port: config.TCP.Port,
+			name:               config.Name,
+			host:               config.TCP.Host,
+			port:               config.TCP.Port,
+			tls:                config.TCP.TLS,
+			insecureSkipVerify: config.TCP.InsecureSkipVerify,
+			caFile:             config.TCP.CAFile,
+			serverName:         config.TCP.ServerName,
+			minValidity:        config.TCP.MinValidity.Value,
 		}
 
 	case config.Exec != nil:
@@ -280,6 +360,60 @@ func newChecker(config *plan.Check) checker {
 			workingDir:  config.Exec.WorkingDir,
 		}
 
+	case config.GRPC != nil:
+		return &grpcChecker{
+			name:               config.Name,
+			address:            config.GRPC.Address,
+			service:            config.GRPC.Service,
+			tls:                config.GRPC.TLS,
+			insecureSkipVerify: config.GRPC.InsecureSkipVerify,
+			caFile:             config.GRPC.CAFile,
+			serverName:         config.GRPC.ServerName,
+			metadata:           config.GRPC.Metadata,
+		}
+
+	case config.Log != nil:
+		// By default, look for matching logs since the previous check.
+		window := config.Period.Value
+		if config.Log.Window.IsSet {
+			window = config.Log.Window.Value
+		}
+		return newLogChecker(config.Name, config.Log, window, m.serviceLogs)
+
+	case config.Composite != nil:
+		return &compositeChecker{
+			name:    config.Name,
+			checks:  config.Composite.Checks,
+			require: config.Composite.Require,
+			n:       config.Composite.N,
+			status:  m.checkStatus,
+		}
+
+	case config.File != nil:
+		var regex *regexp.Regexp
+		if config.File.Regex != "" {
+			// The regex has already been checked when parsing the config.
+			regex = regexp.MustCompile(config.File.Regex)
+		}
+		return &fileChecker{
+			name:   config.Name,
+			path:   config.File.Path,
+			maxAge: config.File.MaxAge.Value,
+			regex:  regex,
+		}
+
+	case config.Process != nil:
+		return &processChecker{
+			name:         config.Name,
+			pidFile:      config.Process.PIDFile,
+			service:      config.Process.Service,
+			processName:  config.Process.Name,
+			processGroup: m.serviceProcessGroup,
+		}
+
+	case len(config.Extensions) > 0:
+		return newExtensionChecker(config)
+
 	default:
 		// This has already been checked when parsing the config.
 		panic("internal error: invalid check config")
@@ -287,33 +421,65 @@ func newChecker(config *plan.Check) checker {
 }
 
 // mergeServiceContext returns the final check configuration with service
-// context merged (for exec checks). The original config is copied if needed,
-// not modified.
+// context merged (for exec checks and on-down/on-up hooks). The original
+// config is copied if needed, not modified.
 func mergeServiceContext(p *plan.Plan, config *plan.Check) *plan.Check {
-	if config.Exec == nil || config.Exec.ServiceContext == "" {
+	hasContext := false
+	for _, exec := range execConfigs(config) {
+		if exec.ServiceContext != "" {
+			hasContext = true
+		}
+	}
+	if !hasContext {
 		return config
 	}
+	cpy := config.Copy()
+	for _, exec := range execConfigs(cpy) {
+		if exec.ServiceContext != "" {
+			mergeExecContext(p, exec)
+		}
+	}
+	return cpy
+}
+
+// execConfigs returns the check's exec configurations: the exec check itself
+// (if it's an exec check) and any on-down and on-up hooks.
+func execConfigs(config *plan.Check) []*plan.ExecCheck {
+	var execs []*plan.ExecCheck
+	if config.Exec != nil {
+		execs = append(execs, config.Exec)
+	}
+	if config.OnDown != nil {
+		execs = append(execs, &config.OnDown.ExecCheck)
+	}
+	if config.OnUp != nil {
+		execs = append(execs, &config.OnUp.ExecCheck)
+	}
+	return execs
+}
+
+// mergeExecContext merges the service context of the given exec
+// configuration into it, modifying it in place.
+func mergeExecContext(p *plan.Plan, exec *plan.ExecCheck) {
 	overrides := plan.ContextOptions{
-		Environment: config.Exec.Environment,
-		UserID:      config.Exec.UserID,
-		User:        config.Exec.User,
-		GroupID:     config.Exec.GroupID,
-		Group:       config.Exec.Group,
-		WorkingDir:  config.Exec.WorkingDir,
-	}
-	merged, err := plan.MergeServiceContext(p, config.Exec.ServiceContext, overrides)
+		Environment: exec.Environment,
+		UserID:      exec.UserID,
+		User:        exec.User,
+		GroupID:     exec.GroupID,
+		Group:       exec.Group,
+		WorkingDir:  exec.WorkingDir,
+	}
+	merged, err := plan.MergeServiceContext(p, exec.ServiceContext, overrides)
 	if err != nil {
 		// Context service name has already been checked when plan was loaded.
 		panic("internal error: " + err.Error())
 	}
-	cpy := config.Copy()
-	cpy.Exec.Environment = merged.Environment
-	cpy.Exec.UserID = merged.UserID
-	cpy.Exec.User = merged.User
-	cpy.Exec.Group = merged.Group
-	cpy.Exec.GroupID = merged.GroupID
-	cpy.Exec.WorkingDir = merged.WorkingDir
-	return cpy
+	exec.Environment = merged.Environment
+	exec.UserID = merged.UserID
+	exec.User = merged.User
+	exec.Group = merged.Group
+	exec.GroupID = merged.GroupID
+	exec.WorkingDir = merged.WorkingDir
 }
 
 // Checks returns the list of currently-configured checks and their status,
@@ -359,9 +525,15 @@ func (m *CheckManager) updateCheckData(config *plan.Check, changeID string, succ
 		startup = plan.CheckStartupEnabled
 	}
 
+	historyLimit := config.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = defaultHistoryLimit
+	}
+
 	check := m.ensureCheck(config.Name)
 	check.level = config.Level
 	check.startup = startup
+	check.historyLimit = historyLimit
 	check.status = status
 	check.successes = successes
 	check.failures = failures
@@ -369,6 +541,57 @@ func (m *CheckManager) updateCheckData(config *plan.Check, changeID string, succ
 	check.changeID = changeID
 }
 
+// recordResult adds the result of a check run which started at the given
+// time to the check's history, discarding the oldest results if the history
+// is full. It also records the certificate expiry time for TLS checks.
+func (m *CheckManager) recordResult(config *plan.Check, chk checker, start time.Time, err error) {
+	result := CheckResult{
+		Time:     start,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		result.Error = err.Error()
+		var detailsErr *detailsError
+		if errors.As(err, &detailsErr) {
+			result.Details = detailsErr.Details()
+		}
+	}
+
+	m.checksLock.Lock()
+	defer m.checksLock.Unlock()
+
+	check := m.ensureCheck(config.Name)
+	if certChk, ok := chk.(certChecker); ok {
+		check.certExpiry = certChk.certExpiry()
+	}
+	limit := check.historyLimit
+	if limit == 0 {
+		limit = defaultHistoryLimit
+	}
+	check.history = append(check.history, result)
+	if len(check.history) > limit {
+		// Copy so the backing array doesn't grow without bound.
+		check.history = append([]CheckResult(nil), check.history[len(check.history)-limit:]...)
+	}
+}
+
+// CheckHistory returns up to n of the most recent results of the named
+// check, oldest first. It returns nil if the check doesn't exist.
+func (m *CheckManager) CheckHistory(name string, n int) []CheckResult {
+	m.checksLock.Lock()
+	defer m.checksLock.Unlock()
+
+	check, ok := m.checks[name]
+	if !ok || n <= 0 {
+		return nil
+	}
+	history := check.history
+	if len(history) > n {
+		history = history[len(history)-n:]
+	}
+	return append([]CheckResult(nil), history...)
+}
+
 func (m *CheckManager) incSuccessMetric(config *plan.Check) {
 	m.checksLock.Lock()
 	defer m.checksLock.Unlock()
@@ -404,6 +627,25 @@ type CheckInfo struct {
 	ChangeID  string
 }
 
+// CheckResult holds the result of a single run of a check.
+type CheckResult struct {
+	// Time is when the check run started.
+	Time time.Time
+	// Duration is how long the check run took.
+	Duration time.Duration
+	// Error is the error message if the check failed, or empty on success.
+	Error string
+	// Details holds additional details about the error, if any (for
+	// example, the last few lines of output of an exec check).
+	Details string
+}
+
+// Starting reports whether the check is a startup check that is running but
+// has not yet succeeded.
+func (info *CheckInfo) Starting() bool {
+	return info.Level == plan.StartupLevel && info.Status != CheckStatusInactive && info.Successes == 0
+}
+
 type refreshInfo struct {
 	ctx    context.Context
 	result chan error
@@ -422,6 +664,9 @@ type checkData struct {
 	successMetric int64
 	failureMetric int64
 	refresh       chan refreshInfo
+	history       []CheckResult
+	historyLimit  int
+	certExpiry    time.Time
 }
 
 type CheckStatus string
@@ -436,6 +681,65 @@ type checker interface {
 	check(ctx context.Context) error
 }
 
+// certChecker is implemented by checkers that check a TLS certificate, to
+// report when the most recently seen certificate expires.
+type certChecker interface {
+	certExpiry() time.Time
+}
+
+// runHook runs an on-down or on-up hook, recording the outcome and the last
+// few lines of the command's output in the task log. The state lock must not
+// be held when calling this method.
+func (m *CheckManager) runHook(ctx context.Context, task *state.Task, config *plan.Check, kind string, hook *plan.CheckHook) {
+	chk := &execChecker{
+		name:        config.Name,
+		command:     hook.Command,
+		environment: hook.Environment,
+		userID:      hook.UserID,
+		user:        hook.User,
+		groupID:     hook.GroupID,
+		group:       hook.Group,
+		workingDir:  hook.WorkingDir,
+	}
+	timeout := defaultHookTimeout
+	if hook.Timeout.IsSet {
+		timeout = hook.Timeout.Value
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	logger.Noticef("Check %q running %s hook", config.Name, kind)
+	output, err := chk.run(ctx)
+
+	m.state.Lock()
+	defer m.state.Unlock()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		logger.Noticef("Check %q %s hook timed out after %v", config.Name, kind, timeout)
+		task.Errorf("%s hook timed out after %v", kind, timeout)
+	case err != nil:
+		logger.Noticef("Check %q %s hook failed: %v", config.Name, kind, err)
+		task.Errorf("%s hook failed: %s", kind, errorDetails(err))
+	case output != "":
+		task.Logf("%s hook succeeded; %s", kind, output)
+	default:
+		task.Logf("%s hook succeeded", kind)
+	}
+}
+
+// closeChecker releases any resources held by the checker, for checkers
+// that hold resources across runs (such as log iterators).
+func closeChecker(chk checker) {
+	closer, ok := chk.(io.Closer)
+	if !ok {
+		return
+	}
+	err := closer.Close()
+	if err != nil {
+		logger.Noticef("Cannot close checker: %v", err)
+	}
+}
+
 func (c *checkData) writeMetric(writer metrics.Writer) error {
 	// Don't list any inactive checks because they don't have an up or down status.
 	if c.status != CheckStatusInactive {
@@ -455,6 +759,19 @@ func (c *checkData) writeMetric(writer metrics.Writer) error {
 		}
 	}
 
+	if !c.certExpiry.IsZero() {
+		err := writer.Write(metrics.Metric{
+			Name:       "pebble_check_cert_expiry_timestamp_seconds",
+			Type:       metrics.TypeGaugeInt,
+			ValueInt64: c.certExpiry.Unix(),
+			Comment:    "When the check's TLS peer certificate expires, in seconds since the Unix epoch",
+			Labels:     []metrics.Label{metrics.NewLabel("check", c.name)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	err := writer.Write(metrics.Metric{
 		Name:       "pebble_check_success_count",
 		Type:       metrics.TypeCounterInt,
@@ -548,7 +865,7 @@ func (m *CheckManager) StartChecks(checks []string) (started []string, err error
 		if checkData.changeID != "" {
 			continue
 		}
-		changeID := performCheckChange(m.state, check)
+		changeID := performCheckChange(m.state, check, 0)
 		m.updateCheckData(check, changeID, 0, 0)
 		started = append(started, check.Name)
 	}
@@ -631,7 +948,7 @@ func (m *CheckManager) Replan() {
 		if checkData.changeID != "" {
 			continue
 		}
-		changeID := performCheckChange(m.state, check)
+		changeID := performCheckChange(m.state, check, 0)
 		m.updateCheckData(check, changeID, 0, 0)
 	}
 }
@@ -653,8 +970,11 @@ func (m *CheckManager) RefreshCheck(ctx context.Context, check *plan.Check) (*Ch
 
 	// If the check is stopped, run the check directly without using changes and tasks.
 	if changeID == "" {
-		chk := newChecker(check)
+		chk := m.newChecker(check)
+		defer closeChecker(chk)
+		start := time.Now()
 		err := runCheck(ctx, chk, check.Timeout.Value)
+		m.recordResult(check, chk, start, err)
 		if err != nil {
 			return getCheckInfo(), fmt.Errorf("%s", errorDetails(err))
 		}
@@ -681,6 +1001,40 @@ func (m *CheckManager) RefreshCheck(ctx context.Context, check *plan.Check) (*Ch
 	}
 }
 
+// checkStatus returns the current status of the named check, or
+// CheckStatusInactive if the check isn't known.
+func (m *CheckManager) checkStatus(name string) CheckStatus {
+	m.checksLock.Lock()
+	defer m.checksLock.Unlock()
+
+	check, ok := m.checks[name]
+	if !ok || check.status == "" {
+		return CheckStatusInactive
+	}
+	return check.status
+}
+
+// startupPending reports whether a startup check of one of the named check's
+// services is running but has not yet succeeded. A check's services are
+// those that list it in on-check-failure.
+func (m *CheckManager) startupPending(name string) bool {
+	m.checksLock.Lock()
+	defer m.checksLock.Unlock()
+
+	services := m.checkServices[name]
+	for _, data := range m.checks {
+		if data.name == name || !checkDataToInfo(data).Starting() {
+			continue
+		}
+		for _, service := range m.checkServices[data.name] {
+			if slices.Contains(services, service) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func checkDataToInfo(data *checkData) *CheckInfo {
 	return &CheckInfo{
 		Name:      data.name,
@@ -19,9 +19,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -278,6 +285,13 @@ func (s *ManagerSuite) TestFailures(c *C) {
 	c.Assert(check.Status, Equals, checkstate.CheckStatusDown)
 	c.Assert(notifies.Load(), Equals, int32(1))
 	recoverChangeID := check.ChangeID
+	notice := checkStatusNotice(c, s.overlord.State(), "chk1")
+	c.Assert(notice, NotNil)
+	c.Check(notice.LastData(), DeepEquals, map[string]string{
+		"status":   "down",
+		"failures": "3",
+		"error":    "exit status 1",
+	})
 
 	// Should log failures in recover-check mode
 	check = waitCheck(c, s.manager, "chk1", func(check *checkstate.CheckInfo) bool {
@@ -300,6 +314,26 @@ func (s *ManagerSuite) TestFailures(c *C) {
 	c.Assert(notifies.Load(), Equals, int32(1))
 	c.Assert(lastTaskLog(s.overlord.State(), check.ChangeID), Equals, "")
 	c.Assert(changeData(c, s.overlord.State(), check.ChangeID), DeepEquals, map[string]string{"check-name": "chk1"})
+	notice = checkStatusNotice(c, s.overlord.State(), "chk1")
+	c.Assert(notice, NotNil)
+	c.Check(notice.LastData()["status"], Equals, "up")
+	c.Check(notice.LastData()["failures"], Matches, `[1-9][0-9]*`)
+}
+
+// checkStatusNotice returns the check-status notice for the named check, or
+// nil if there isn't one.
+func checkStatusNotice(c *C, st *state.State, name string) *state.Notice {
+	st.Lock()
+	defer st.Unlock()
+	notices := st.Notices(&state.NoticeFilter{
+		Types: []state.NoticeType{state.CheckStatusNotice},
+		Keys:  []string{name},
+	})
+	if len(notices) == 0 {
+		return nil
+	}
+	c.Assert(notices, HasLen, 1)
+	return notices[0]
 }
 
 func (s *ManagerSuite) TestFailuresBelowThreshold(c *C) {
@@ -338,6 +372,343 @@ func (s *ManagerSuite) TestFailuresBelowThreshold(c *C) {
 	c.Assert(lastTaskLog(s.overlord.State(), check.ChangeID), Matches, ".* INFO succeeded after 1 failure")
 }
 
+func (s *ManagerSuite) TestStartupCheckPending(c *C) {
+	var mu sync.Mutex
+	var notified []string
+	s.manager.NotifyCheckFailed(func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, name)
+	})
+	tempDir := c.MkDir()
+	startedPath := filepath.Join(tempDir, "started")
+	s.manager.PlanChanged(&plan.Plan{
+		Services: map[string]*plan.Service{
+			"svc": {
+				Name: "svc",
+				OnCheckFailure: map[string]plan.ServiceAction{
+					"startup": plan.ActionRestart,
+					"alive":   plan.ActionRestart,
+				},
+			},
+			"other": {
+				Name:           "other",
+				OnCheckFailure: map[string]plan.ServiceAction{"other-alive": plan.ActionRestart},
+			},
+		},
+		Checks: map[string]*plan.Check{
+			"startup": {
+				Name:      "startup",
+				Override:  "replace",
+				Level:     plan.StartupLevel,
+				Period:    plan.OptionalDuration{Value: 20 * time.Millisecond},
+				Timeout:   plan.OptionalDuration{Value: time.Second},
+				Threshold: 1000,
+				Exec: &plan.ExecCheck{
+					Command: fmt.Sprintf(`/bin/sh -c '[ -f %s ]'`, startedPath),
+				},
+			},
+			"alive": {
+				Name:      "alive",
+				Override:  "replace",
+				Level:     plan.AliveLevel,
+				Period:    plan.OptionalDuration{Value: 20 * time.Millisecond},
+				Timeout:   plan.OptionalDuration{Value: time.Second},
+				Threshold: 1,
+				Exec:      &plan.ExecCheck{Command: "/bin/false"},
+			},
+			"other-alive": {
+				Name:      "other-alive",
+				Override:  "replace",
+				Level:     plan.AliveLevel,
+				Period:    plan.OptionalDuration{Value: 20 * time.Millisecond},
+				Timeout:   plan.OptionalDuration{Value: time.Second},
+				Threshold: 1,
+				Exec:      &plan.ExecCheck{Command: "/bin/false"},
+			},
+		},
+	})
+
+	// Failures past the threshold shouldn't trigger the failure handler
+	// while the startup check of the same service is pending.
+	check := waitCheck(c, s.manager, "alive", func(check *checkstate.CheckInfo) bool {
+		return check.Failures >= 2
+	})
+	c.Assert(check.Status, Equals, checkstate.CheckStatusDown)
+	check = waitCheck(c, s.manager, "startup", func(check *checkstate.CheckInfo) bool {
+		return true
+	})
+	c.Assert(check.Starting(), Equals, true)
+	// Nor should they record a check-status notice, as the check hasn't
+	// been acted on.
+	c.Check(checkStatusNotice(c, s.overlord.State(), "alive"), IsNil)
+	// Checks of other services aren't held back.
+	waitCheck(c, s.manager, "other-alive", func(check *checkstate.CheckInfo) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(notified) > 0
+	})
+	mu.Lock()
+	c.Assert(notified, DeepEquals, []string{"other-alive"})
+	notified = nil
+	mu.Unlock()
+
+	// Once the startup check succeeds, failures trigger the handler again.
+	err := os.WriteFile(startedPath, nil, 0o644)
+	c.Assert(err, IsNil)
+	check = waitCheck(c, s.manager, "startup", func(check *checkstate.CheckInfo) bool {
+		return check.Successes > 0
+	})
+	c.Assert(check.Starting(), Equals, false)
+	waitCheck(c, s.manager, "alive", func(check *checkstate.CheckInfo) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Contains(notified, "alive")
+	})
+	notice := checkStatusNotice(c, s.overlord.State(), "alive")
+	c.Assert(notice, NotNil)
+	c.Check(notice.LastData()["status"], Equals, "down")
+}
+
+func (s *ManagerSuite) TestStartupCheckRecovered(c *C) {
+	startedPath := filepath.Join(c.MkDir(), "started")
+	err := os.WriteFile(startedPath, nil, 0o644)
+	c.Assert(err, IsNil)
+	initialDelay := 500 * time.Millisecond
+	s.manager.PlanChanged(&plan.Plan{
+		Checks: map[string]*plan.Check{
+			"startup": {
+				Name:         "startup",
+				Override:     "replace",
+				Level:        plan.StartupLevel,
+				Period:       plan.OptionalDuration{Value: 20 * time.Millisecond},
+				Timeout:      plan.OptionalDuration{Value: time.Second},
+				Threshold:    1,
+				InitialDelay: plan.OptionalDuration{Value: initialDelay, IsSet: true},
+				Exec: &plan.ExecCheck{
+					Command: fmt.Sprintf(`/bin/sh -c '[ -f %s ]'`, startedPath),
+				},
+			},
+		},
+	})
+	check := waitCheck(c, s.manager, "startup", func(check *checkstate.CheckInfo) bool {
+		return check.Successes > 0
+	})
+	c.Assert(check.Starting(), Equals, false)
+
+	// Fail, so the check is recovering.
+	err = os.Remove(startedPath)
+	c.Assert(err, IsNil)
+	check = waitCheck(c, s.manager, "startup", func(check *checkstate.CheckInfo) bool {
+		return check.Status == checkstate.CheckStatusDown
+	})
+	c.Check(check.Starting(), Equals, false)
+	recoverChangeID := check.ChangeID
+
+	// Recover, so the check is performed again.
+	err = os.WriteFile(startedPath, nil, 0o644)
+	c.Assert(err, IsNil)
+	check = waitCheck(c, s.manager, "startup", func(check *checkstate.CheckInfo) bool {
+		return check.Status == checkstate.CheckStatusUp && check.ChangeID != recoverChangeID
+	})
+	c.Check(check.Starting(), Equals, false)
+
+	// Fail again: the check isn't starting, and isn't delayed again.
+	err = os.Remove(startedPath)
+	c.Assert(err, IsNil)
+	start := time.Now()
+	check = waitCheck(c, s.manager, "startup", func(check *checkstate.CheckInfo) bool {
+		return check.Failures > 0
+	})
+	c.Check(time.Since(start) < initialDelay, Equals, true)
+	c.Check(check.Successes > 0, Equals, true)
+	c.Check(check.Starting(), Equals, false)
+}
+
+func (s *ManagerSuite) TestInitialDelay(c *C) {
+	start := time.Now()
+	s.manager.PlanChanged(&plan.Plan{
+		Checks: map[string]*plan.Check{
+			"chk1": {
+				Name:         "chk1",
+				Override:     "replace",
+				Period:       plan.OptionalDuration{Value: 10 * time.Millisecond},
+				Timeout:      plan.OptionalDuration{Value: time.Second},
+				Threshold:    3,
+				InitialDelay: plan.OptionalDuration{Value: 200 * time.Millisecond, IsSet: true},
+				Exec:         &plan.ExecCheck{Command: "/bin/true"},
+			},
+		},
+	})
+
+	waitCheck(c, s.manager, "chk1", func(check *checkstate.CheckInfo) bool {
+		return check.Successes > 0
+	})
+	c.Assert(time.Since(start) >= 200*time.Millisecond, Equals, true)
+}
+
+func (s *ManagerSuite) TestCheckHistory(c *C) {
+	testPath := c.MkDir() + "/test"
+	err := os.WriteFile(testPath, nil, 0o644)
+	c.Assert(err, IsNil)
+	s.manager.PlanChanged(&plan.Plan{
+		Checks: map[string]*plan.Check{
+			"chk1": {
+				Name:         "chk1",
+				Override:     "replace",
+				Period:       plan.OptionalDuration{Value: 10 * time.Millisecond},
+				Timeout:      plan.OptionalDuration{Value: time.Second},
+				Threshold:    100,
+				HistoryLimit: 3,
+				Exec: &plan.ExecCheck{
+					Command: fmt.Sprintf(`/bin/sh -c 'echo details >/dev/stderr; [ ! -f %s ]'`, testPath),
+				},
+			},
+		},
+	})
+
+	waitCheck(c, s.manager, "chk1", func(check *checkstate.CheckInfo) bool {
+		return check.Failures >= 5
+	})
+	history := s.manager.CheckHistory("chk1", 10)
+	c.Assert(history, HasLen, 3)
+	for _, result := range history {
+		c.Check(result.Error, Equals, "exit status 1")
+		c.Check(result.Details, Equals, "details")
+		c.Check(result.Time.IsZero(), Equals, false)
+		c.Check(result.Duration > 0, Equals, true)
+	}
+	c.Check(history[0].Time.Before(history[2].Time), Equals, true)
+
+	// Only the requested number of most recent results are returned.
+	err = os.Remove(testPath)
+	c.Assert(err, IsNil)
+	waitCheck(c, s.manager, "chk1", func(check *checkstate.CheckInfo) bool {
+		return check.Successes >= 1
+	})
+	history = s.manager.CheckHistory("chk1", 1)
+	c.Assert(history, HasLen, 1)
+	c.Check(history[0].Error, Equals, "")
+
+	c.Check(s.manager.CheckHistory("chk1", 0), HasLen, 0)
+	c.Check(s.manager.CheckHistory("nonexistent", 10), IsNil)
+}
+
+func (s *ManagerSuite) TestHooks(c *C) {
+	tempDir := c.MkDir()
+	testPath := filepath.Join(tempDir, "test")
+	hookPath := filepath.Join(tempDir, "hooks")
+	err := os.WriteFile(testPath, nil, 0o644)
+	c.Assert(err, IsNil)
+	s.manager.PlanChanged(&plan.Plan{
+		Services: map[string]*plan.Service{
+			"svc1": {
+				Name:        "svc1",
+				Override:    "replace",
+				Command:     "dummy",
+				Environment: map[string]string{"FOO": "bar"},
+			},
+		},
+		Checks: map[string]*plan.Check{
+			"chk1": {
+				Name:      "chk1",
+				Override:  "replace",
+				Period:    plan.OptionalDuration{Value: 20 * time.Millisecond},
+				Timeout:   plan.OptionalDuration{Value: time.Second},
+				Threshold: 1,
+				Exec: &plan.ExecCheck{
+					Command: fmt.Sprintf(`/bin/sh -c '[ ! -f %s ]'`, testPath),
+				},
+				OnDown: &plan.CheckHook{ExecCheck: plan.ExecCheck{
+					Command:        fmt.Sprintf(`/bin/sh -c 'echo down $FOO; echo down >>%s'`, hookPath),
+					ServiceContext: "svc1",
+				}},
+				OnUp: &plan.CheckHook{ExecCheck: plan.ExecCheck{
+					Command: fmt.Sprintf(`/bin/sh -c 'echo up; echo up >>%s'`, hookPath),
+				}},
+			},
+		},
+	})
+
+	// The on-down hook runs once when the check goes down.
+	waitCheck(c, s.manager, "chk1", func(check *checkstate.CheckInfo) bool {
+		return check.Status == checkstate.CheckStatusDown && check.Failures >= 3
+	})
+	b, err := os.ReadFile(hookPath)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, "down\n")
+
+	// The on-up hook runs once when the check comes back up.
+	err = os.Remove(testPath)
+	c.Assert(err, IsNil)
+	waitCheck(c, s.manager, "chk1", func(check *checkstate.CheckInfo) bool {
+		return check.Status == checkstate.CheckStatusUp && check.Successes >= 2
+	})
+	b, err = os.ReadFile(hookPath)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, "down\nup\n")
+
+	// The hooks' output is recorded in the task logs.
+	st := s.overlord.State()
+	st.Lock()
+	var logs []string
+	for _, change := range st.Changes() {
+		for _, task := range change.Tasks() {
+			logs = append(logs, task.Log()...)
+		}
+	}
+	st.Unlock()
+	// Changes aren't returned in a defined order, so check each hook's log
+	// separately.
+	c.Check(strings.Join(logs, "\n"), Matches, `(?s).* INFO on-down hook succeeded; down bar(\n.*)?`)
+	c.Check(strings.Join(logs, "\n"), Matches, `(?s).* INFO on-up hook succeeded; up(\n.*)?`)
+}
+
+func (s *ManagerSuite) TestCompositeCheck(c *C) {
+	check := func(name, command string) *plan.Check {
+		return &plan.Check{
+			Name:      name,
+			Override:  "replace",
+			Period:    plan.OptionalDuration{Value: 10 * time.Millisecond},
+			Timeout:   plan.OptionalDuration{Value: time.Second},
+			Threshold: 1,
+			Exec:      &plan.ExecCheck{Command: command},
+		}
+	}
+	composite := func(name string, require plan.CompositeRequire) *plan.Check {
+		return &plan.Check{
+			Name:      name,
+			Override:  "replace",
+			Period:    plan.OptionalDuration{Value: 10 * time.Millisecond},
+			Timeout:   plan.OptionalDuration{Value: time.Second},
+			Threshold: 1,
+			Composite: &plan.CompositeCheck{
+				Checks:  []string{"good", "bad"},
+				Require: require,
+			},
+		}
+	}
+	s.manager.PlanChanged(&plan.Plan{
+		Checks: map[string]*plan.Check{
+			"good": check("good", "/bin/true"),
+			"bad":  check("bad", "/bin/false"),
+			"all":  composite("all", plan.CompositeRequireAll),
+			"any":  composite("any", plan.CompositeRequireAny),
+		},
+	})
+
+	waitCheck(c, s.manager, "bad", func(check *checkstate.CheckInfo) bool {
+		return check.Status == checkstate.CheckStatusDown
+	})
+	info := waitCheck(c, s.manager, "all", func(check *checkstate.CheckInfo) bool {
+		return check.Status == checkstate.CheckStatusDown && check.Failures >= 3
+	})
+	c.Check(lastTaskLog(s.overlord.State(), info.ChangeID), Matches, `.* ERROR 1 of 2 checks up, need 2; not up: bad \(down\)`)
+	waitCheck(c, s.manager, "any", func(check *checkstate.CheckInfo) bool {
+		return check.Status == checkstate.CheckStatusUp && check.Successes > 0
+	})
+}
+
 func (s *ManagerSuite) TestPlanChangedSmarts(c *C) {
 	s.manager.PlanChanged(&plan.Plan{
 		Checks: map[string]*plan.Check{
@@ -1007,6 +1378,57 @@ pebble_check_failure_count{check="chk1"} \d+
 	c.Assert(buf.String(), Matches, expectedRegex)
 }
 
+func (s *ManagerSuite) TestMetricsCertExpiry(c *C) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	defer server.Close()
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+	notAfter := server.Certificate().NotAfter
+
+	s.manager.PlanChanged(&plan.Plan{
+		Checks: map[string]*plan.Check{
+			"chk1": {
+				Name:      "chk1",
+				Override:  "replace",
+				Period:    plan.OptionalDuration{Value: 10 * time.Millisecond},
+				Timeout:   plan.OptionalDuration{Value: time.Second},
+				Threshold: 3,
+				TCP: &plan.TCPCheck{
+					Port:               port,
+					TLS:                true,
+					InsecureSkipVerify: true,
+				},
+			},
+		},
+	})
+	waitCheck(c, s.manager, "chk1", func(check *checkstate.CheckInfo) bool {
+		return check.Successes > 0
+	})
+
+	buf := new(bytes.Buffer)
+	writer := metrics.NewOpenTelemetryWriter(buf)
+	s.manager.WriteMetrics(writer)
+	expectedRegex := fmt.Sprintf(`
+# HELP pebble_check_up Whether the health check is up \(1\) or not \(0\)
+# TYPE pebble_check_up gauge
+pebble_check_up{check="chk1"} 1
+
+# HELP pebble_check_cert_expiry_timestamp_seconds When the check's TLS peer certificate expires, in seconds since the Unix epoch
+# TYPE pebble_check_cert_expiry_timestamp_seconds gauge
+pebble_check_cert_expiry_timestamp_seconds{check="chk1"} %d
+
+# HELP pebble_check_success_count Number of times the check has succeeded
+# TYPE pebble_check_success_count counter
+pebble_check_success_count{check="chk1"} \d+
+
+# HELP pebble_check_failure_count Number of times the check has failed
+# TYPE pebble_check_failure_count counter
+pebble_check_failure_count{check="chk1"} 0
+
+`[1:], notAfter.Unix())
+	c.Assert(buf.String(), Matches, expectedRegex)
+}
+
 func (s *ManagerSuite) TestMetricsInactiveCheck(c *C) {
 	tempDir := c.MkDir()
 	tempFile := filepath.Join(tempDir, "file.txt")
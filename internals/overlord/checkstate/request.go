@@ -33,10 +33,13 @@ type performConfigKey struct {
 	changeID string
 }
 
-func performCheckChange(st *state.State, config *plan.Check) (changeID string) {
+// performCheckChange creates a change to perform a check. The successes are
+// carried over from recovering the check, so that a recovered check isn't
+// considered to be starting again.
+func performCheckChange(st *state.State, config *plan.Check, successes int) (changeID string) {
 	summary := fmt.Sprintf("Perform %s check %q", checkType(config), config.Name)
 	task := st.NewTask(performCheckKind, summary)
-	task.Set(checkDetailsAttr, &checkDetails{Name: config.Name})
+	task.Set(checkDetailsAttr, &checkDetails{Name: config.Name, Successes: successes})
 
 	change := st.NewChangeWithNoticeData(performCheckKind, task.Summary(), map[string]string{
 		"check-name": config.Name,
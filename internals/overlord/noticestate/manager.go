@@ -0,0 +1,314 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package noticestate delivers notices to the HTTP subscribers configured in
+// the "notice-subscribers" plan section.
+package noticestate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/canonical/pebble/internals/logger"
+	"github.com/canonical/pebble/internals/overlord/state"
+	"github.com/canonical/pebble/internals/plan"
+)
+
+const (
+	// subscribersStateKey is the state key under which the delivery status
+	// of each subscriber is persisted.
+	subscribersStateKey = "notice-subscribers"
+
+	// maxBatchNotices is the maximum number of notices sent in one request.
+	maxBatchNotices = 100
+
+	deliveryTimeout = 30 * time.Second
+	maxErrorBytes   = 1024
+)
+
+var (
+	// Delay before retrying a failed delivery, doubling after each failure
+	// up to maxRetryDelay. These are variables so tests can override them.
+	initialRetryDelay = time.Second
+	maxRetryDelay     = 5 * time.Minute
+)
+
+// NoticeManager delivers notices to the subscribers in the plan. Delivery is
+// at-least-once: a subscriber's position is only advanced after a successful
+// delivery, and it's persisted in state so delivery resumes after a restart.
+type NoticeManager struct {
+	state *state.State
+
+	mu          sync.Mutex
+	subscribers map[string]*subscriber
+	client      *http.Client
+}
+
+// subscriber is a running delivery loop for a single subscriber.
+type subscriber struct {
+	config *Subscriber
+	tomb   tomb.Tomb
+}
+
+// SubscriberStatus is the delivery status of a notice subscriber.
+type SubscriberStatus struct {
+	// LastDelivered is the last-repeated time of the last notice delivered
+	// successfully. Only notices repeated after this time are delivered.
+	LastDelivered time.Time `json:"last-delivered"`
+
+	// LastAttempt is the time of the most recent delivery attempt.
+	LastAttempt time.Time `json:"last-attempt,omitempty"`
+
+	// Failures is the number of consecutive failed delivery attempts.
+	Failures int `json:"failures,omitempty"`
+
+	// LastError is the error from the most recent failed attempt, if
+	// Failures is non-zero.
+	LastError string `json:"last-error,omitempty"`
+}
+
+// NewManager creates a new notice manager.
+func NewManager(st *state.State) *NoticeManager {
+	return &NoticeManager{
+		state:       st,
+		subscribers: make(map[string]*subscriber),
+		client:      &http.Client{Timeout: deliveryTimeout},
+	}
+}
+
+// PlanChanged handles updates to the plan (server configuration), stopping
+// the delivery loops of removed or modified subscribers, and starting loops
+// for new or modified ones.
+func (m *NoticeManager) PlanChanged(p *plan.Plan) {
+	configs := planSubscribers(p)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, sub := range m.subscribers {
+		config, ok := configs[name]
+		if ok && reflect.DeepEqual(config, sub.config) {
+			continue
+		}
+		sub.tomb.Kill(nil)
+		sub.tomb.Wait()
+		delete(m.subscribers, name)
+		if !ok {
+			m.state.Lock()
+			m.deleteStatus(name)
+			m.state.Unlock()
+		}
+	}
+
+	for name, config := range configs {
+		if _, ok := m.subscribers[name]; ok {
+			continue
+		}
+		sub := &subscriber{config: config.copy()}
+		m.subscribers[name] = sub
+		sub.tomb.Go(func() error {
+			m.deliverLoop(sub)
+			return nil
+		})
+	}
+}
+
+// Ensure implements StateManager.Ensure.
+func (m *NoticeManager) Ensure() error {
+	return nil
+}
+
+// Stop implements StateStopper. It stops all the delivery loops.
+func (m *NoticeManager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, sub := range m.subscribers {
+		sub.tomb.Kill(nil)
+	}
+	for name, sub := range m.subscribers {
+		sub.tomb.Wait()
+		delete(m.subscribers, name)
+	}
+}
+
+// SubscriberStatus returns the delivery status of the named subscriber, and
+// whether the subscriber has a status.
+//
+// The state lock must be held when calling this method.
+func (m *NoticeManager) SubscriberStatus(name string) (SubscriberStatus, bool) {
+	statuses := m.statuses()
+	status, ok := statuses[name]
+	if !ok {
+		return SubscriberStatus{}, false
+	}
+	return *status, true
+}
+
+func (m *NoticeManager) statuses() map[string]*SubscriberStatus {
+	var statuses map[string]*SubscriberStatus
+	err := m.state.Get(subscribersStateKey, &statuses)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		logger.Noticef("Cannot read notice subscriber status: %v", err)
+	}
+	if statuses == nil {
+		statuses = make(map[string]*SubscriberStatus)
+	}
+	return statuses
+}
+
+func (m *NoticeManager) setStatus(name string, status *SubscriberStatus) {
+	statuses := m.statuses()
+	statuses[name] = status
+	m.state.Set(subscribersStateKey, statuses)
+}
+
+func (m *NoticeManager) deleteStatus(name string) {
+	statuses := m.statuses()
+	if _, ok := statuses[name]; !ok {
+		return
+	}
+	delete(statuses, name)
+	m.state.Set(subscribersStateKey, statuses)
+}
+
+// deliverLoop waits for notices matching the subscriber's filters and
+// delivers them, retrying with backoff on failure, until the subscriber's
+// tomb is killed.
+func (m *NoticeManager) deliverLoop(sub *subscriber) {
+	name := sub.config.Name
+	ctx := sub.tomb.Context(nil)
+
+	m.state.Lock()
+	status, ok := m.statuses()[name]
+	if !ok {
+		// A new subscriber only receives notices that occur from now on.
+		status = &SubscriberStatus{LastDelivered: time.Now().UTC()}
+		m.setStatus(name, status)
+	}
+	m.state.Unlock()
+
+	for {
+		m.state.Lock()
+		notices, err := m.state.WaitNotices(ctx, &state.NoticeFilter{
+			Types:      sub.config.Types,
+			Keys:       sub.config.Keys,
+			After:      status.LastDelivered,
+			PublicOnly: !sub.config.IncludePrivate,
+		})
+		if err != nil {
+			m.state.Unlock()
+			return // tomb killed
+		}
+		if len(notices) > maxBatchNotices {
+			notices = notices[:maxBatchNotices]
+		}
+		body, err := json.Marshal(deliveryBody{Subscriber: name, Notices: notices})
+		last := notices[len(notices)-1].LastRepeated()
+		m.state.Unlock()
+		if err != nil {
+			logger.Noticef("Internal error: cannot marshal notices for subscriber %q: %v", name, err)
+			return
+		}
+
+		err = m.deliver(ctx, sub.config, body)
+		if ctx.Err() != nil {
+			return
+		}
+
+		m.state.Lock()
+		status.LastAttempt = time.Now().UTC()
+		if err != nil {
+			status.Failures++
+			status.LastError = err.Error()
+		} else {
+			status.LastDelivered = last
+			status.Failures = 0
+			status.LastError = ""
+		}
+		m.setStatus(name, status)
+		failures := status.Failures
+		m.state.Unlock()
+
+		if err == nil {
+			continue
+		}
+		delay := retryDelay(failures)
+		logger.Noticef("Cannot deliver notices to subscriber %q (attempt %d, retrying in %v): %v",
+			name, failures, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// retryDelay returns the delay before the next attempt after the given
+// number of consecutive failures.
+func retryDelay(failures int) time.Duration {
+	delay := initialRetryDelay
+	for i := 1; i < failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// deliveryBody is the JSON body POSTed to subscribers.
+type deliveryBody struct {
+	Subscriber string          `json:"subscriber"`
+	Notices    []*state.Notice `json:"notices"`
+}
+
+// deliver POSTs the given body to the subscriber's URL, returning an error if
+// the request fails or the response has a non-2xx status code.
+func (m *NoticeManager) deliver(ctx context.Context, config *Subscriber, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, "POST", config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot build request: %w", err)
+	}
+	for k, v := range config.Headers {
+		request.Header.Set(k, v)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := m.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBytes))
+		message := strings.TrimSpace(string(data))
+		if message == "" {
+			return fmt.Errorf("non-2xx status code %d", response.StatusCode)
+		}
+		return fmt.Errorf("non-2xx status code %d: %s", response.StatusCode, message)
+	}
+	// Drain the body so the connection can be reused.
+	io.Copy(io.Discard, response.Body)
+	return nil
+}
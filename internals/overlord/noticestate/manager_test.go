@@ -0,0 +1,270 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package noticestate_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/canonical/pebble/internals/overlord/noticestate"
+	"github.com/canonical/pebble/internals/overlord/state"
+	"github.com/canonical/pebble/internals/plan"
+)
+
+type managerSuite struct {
+	state   *state.State
+	manager *noticestate.NoticeManager
+	server  *httptest.Server
+
+	mu       sync.Mutex
+	received []receivedRequest
+	status   int
+}
+
+var _ = Suite(&managerSuite{})
+
+type receivedRequest struct {
+	header http.Header
+	body   deliveryBody
+}
+
+type deliveryBody struct {
+	Subscriber string `json:"subscriber"`
+	Notices    []struct {
+		Type string `json:"type"`
+		Key  string `json:"key"`
+	} `json:"notices"`
+}
+
+func (s *managerSuite) SetUpTest(c *C) {
+	s.state = state.New(nil)
+	s.manager = noticestate.NewManager(s.state)
+	s.received = nil
+	s.status = http.StatusOK
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body deliveryBody
+		err := json.NewDecoder(r.Body).Decode(&body)
+		c.Check(err, IsNil)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.received = append(s.received, receivedRequest{header: r.Header, body: body})
+		w.WriteHeader(s.status)
+	}))
+}
+
+func (s *managerSuite) TearDownTest(c *C) {
+	s.manager.Stop()
+	s.server.Close()
+}
+
+func (s *managerSuite) planWith(subscribers ...*noticestate.Subscriber) *plan.Plan {
+	section := &noticestate.SubscribersSection{Entries: map[string]*noticestate.Subscriber{}}
+	for _, sub := range subscribers {
+		section.Entries[sub.Name] = sub
+	}
+	return &plan.Plan{Sections: map[string]plan.Section{noticestate.SubscribersField: section}}
+}
+
+func (s *managerSuite) addNotice(c *C, noticeType state.NoticeType, key string) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	_, err := s.state.AddNotice(nil, noticeType, key, nil)
+	c.Assert(err, IsNil)
+}
+
+func (s *managerSuite) setStatus(code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = code
+}
+
+// waitReceived waits until at least n requests have been received, and
+// returns the received requests.
+func (s *managerSuite) waitReceived(c *C, n int) []receivedRequest {
+	for i := 0; i < 1000; i++ {
+		s.mu.Lock()
+		received := append([]receivedRequest(nil), s.received...)
+		s.mu.Unlock()
+		if len(received) >= n {
+			return received
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	c.Fatalf("timed out waiting for %d requests", n)
+	return nil
+}
+
+func (s *managerSuite) waitStatus(c *C, name string, f func(status noticestate.SubscriberStatus) bool) noticestate.SubscriberStatus {
+	for i := 0; i < 1000; i++ {
+		s.state.Lock()
+		status, ok := s.manager.SubscriberStatus(name)
+		s.state.Unlock()
+		if ok && f(status) {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	c.Fatalf("timed out waiting for subscriber %q status", name)
+	return noticestate.SubscriberStatus{}
+}
+
+func (s *managerSuite) TestDeliver(c *C) {
+	// Notices from before the subscriber was added aren't delivered.
+	s.addNotice(c, state.CustomNotice, "example.com/old")
+
+	s.manager.PlanChanged(s.planWith(&noticestate.Subscriber{
+		Name:    "controller",
+		URL:     s.server.URL,
+		Types:   []state.NoticeType{state.CustomNotice},
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}))
+	s.waitStatus(c, "controller", func(status noticestate.SubscriberStatus) bool { return true })
+
+	s.addNotice(c, state.WarningNotice, "not delivered")
+	s.addNotice(c, state.CustomNotice, "example.com/new")
+
+	received := s.waitReceived(c, 1)
+	c.Assert(received, HasLen, 1)
+	c.Check(received[0].header.Get("Authorization"), Equals, "Bearer token")
+	c.Check(received[0].header.Get("Content-Type"), Equals, "application/json")
+	c.Check(received[0].body.Subscriber, Equals, "controller")
+	c.Assert(received[0].body.Notices, HasLen, 1)
+	c.Check(received[0].body.Notices[0].Type, Equals, "custom")
+	c.Check(received[0].body.Notices[0].Key, Equals, "example.com/new")
+
+	status := s.waitStatus(c, "controller", func(status noticestate.SubscriberStatus) bool {
+		return !status.LastAttempt.IsZero()
+	})
+	c.Check(status.Failures, Equals, 0)
+	c.Check(status.LastError, Equals, "")
+}
+
+func (s *managerSuite) TestDeliverPrivate(c *C) {
+	s.manager.PlanChanged(s.planWith(&noticestate.Subscriber{
+		Name: "public",
+		URL:  s.server.URL + "/public",
+	}, &noticestate.Subscriber{
+		Name:           "private",
+		URL:            s.server.URL + "/private",
+		IncludePrivate: true,
+	}))
+	s.waitStatus(c, "public", func(status noticestate.SubscriberStatus) bool { return true })
+	s.waitStatus(c, "private", func(status noticestate.SubscriberStatus) bool { return true })
+
+	// Notices with a user ID are only delivered to subscribers that opt in.
+	uid := uint32(1000)
+	s.state.Lock()
+	_, err := s.state.AddNotice(&uid, state.CustomNotice, "example.com/private", nil)
+	s.state.Unlock()
+	c.Assert(err, IsNil)
+	s.waitStatus(c, "private", func(status noticestate.SubscriberStatus) bool {
+		return !status.LastAttempt.IsZero()
+	})
+	s.addNotice(c, state.CustomNotice, "example.com/public")
+	received := s.waitReceived(c, 3)
+
+	keys := map[string][]string{}
+	for _, r := range received {
+		for _, n := range r.body.Notices {
+			keys[r.body.Subscriber] = append(keys[r.body.Subscriber], n.Key)
+		}
+	}
+	c.Check(keys, DeepEquals, map[string][]string{
+		"public":  {"example.com/public"},
+		"private": {"example.com/private", "example.com/public"},
+	})
+}
+
+func (s *managerSuite) TestRetry(c *C) {
+	restore := noticestate.FakeRetryDelays(10*time.Millisecond, 20*time.Millisecond)
+	defer restore()
+
+	s.setStatus(http.StatusServiceUnavailable)
+	s.manager.PlanChanged(s.planWith(&noticestate.Subscriber{
+		Name: "controller",
+		URL:  s.server.URL,
+	}))
+	s.waitStatus(c, "controller", func(status noticestate.SubscriberStatus) bool { return true })
+	s.addNotice(c, state.CustomNotice, "example.com/a")
+
+	status := s.waitStatus(c, "controller", func(status noticestate.SubscriberStatus) bool {
+		return status.Failures >= 2
+	})
+	c.Check(status.LastError, Equals, "non-2xx status code 503")
+
+	// The same notice is delivered again once the subscriber recovers.
+	s.setStatus(http.StatusOK)
+	s.waitStatus(c, "controller", func(status noticestate.SubscriberStatus) bool {
+		return status.Failures == 0
+	})
+	received := s.waitReceived(c, 3)
+	for _, r := range received {
+		c.Assert(r.body.Notices, HasLen, 1)
+		c.Check(r.body.Notices[0].Key, Equals, "example.com/a")
+	}
+}
+
+func (s *managerSuite) TestResumeFromState(c *C) {
+	s.manager.PlanChanged(s.planWith(&noticestate.Subscriber{
+		Name: "controller",
+		URL:  s.server.URL,
+	}))
+	s.waitStatus(c, "controller", func(status noticestate.SubscriberStatus) bool { return true })
+	s.addNotice(c, state.CustomNotice, "example.com/a")
+	s.waitReceived(c, 1)
+	s.waitStatus(c, "controller", func(status noticestate.SubscriberStatus) bool {
+		return !status.LastAttempt.IsZero()
+	})
+	s.manager.Stop()
+
+	// Notices that occur while stopped are delivered by a new manager using
+	// the status persisted in state, but already-delivered ones are not.
+	s.addNotice(c, state.CustomNotice, "example.com/b")
+	s.manager = noticestate.NewManager(s.state)
+	s.manager.PlanChanged(s.planWith(&noticestate.Subscriber{
+		Name: "controller",
+		URL:  s.server.URL,
+	}))
+	received := s.waitReceived(c, 2)
+	c.Assert(received, HasLen, 2)
+	c.Assert(received[1].body.Notices, HasLen, 1)
+	c.Check(received[1].body.Notices[0].Key, Equals, "example.com/b")
+}
+
+func (s *managerSuite) TestRemoveSubscriber(c *C) {
+	s.manager.PlanChanged(s.planWith(&noticestate.Subscriber{
+		Name: "controller",
+		URL:  s.server.URL,
+	}))
+	s.waitStatus(c, "controller", func(status noticestate.SubscriberStatus) bool { return true })
+
+	s.manager.PlanChanged(s.planWith())
+	s.state.Lock()
+	_, ok := s.manager.SubscriberStatus("controller")
+	s.state.Unlock()
+	c.Check(ok, Equals, false)
+}
+
+func (s *managerSuite) TestRetryDelay(c *C) {
+	c.Check(noticestate.RetryDelay(1), Equals, time.Second)
+	c.Check(noticestate.RetryDelay(2), Equals, 2*time.Second)
+	c.Check(noticestate.RetryDelay(4), Equals, 8*time.Second)
+	c.Check(noticestate.RetryDelay(100), Equals, 5*time.Minute)
+}
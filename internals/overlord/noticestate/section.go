@@ -0,0 +1,211 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package noticestate
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/canonical/pebble/internals/overlord/state"
+	"github.com/canonical/pebble/internals/plan"
+)
+
+// SubscribersField is the name of the plan section holding notice subscribers.
+const SubscribersField = "notice-subscribers"
+
+// Subscriber is the plan configuration of a notice subscriber: a URL to
+// which notices matching the subscriber's filters are POSTed.
+type Subscriber struct {
+	// Basic details
+	Name     string        `yaml:"-"`
+	Override plan.Override `yaml:"override,omitempty"`
+
+	// URL to POST notices to
+	URL string `yaml:"url,omitempty"`
+
+	// Filters (empty means all notices)
+	Types []state.NoticeType `yaml:"types,omitempty"`
+	Keys  []string           `yaml:"keys,omitempty"`
+
+	// Include notices that are only visible to a specific user, as well as
+	// public notices
+	IncludePrivate bool `yaml:"include-private,omitempty"`
+
+	// Extra HTTP headers to send with each request
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+func (s *Subscriber) copy() *Subscriber {
+	copied := *s
+	copied.Types = slices.Clone(s.Types)
+	copied.Keys = slices.Clone(s.Keys)
+	copied.Headers = maps.Clone(s.Headers)
+	return &copied
+}
+
+func (s *Subscriber) merge(other *Subscriber) {
+	if other.URL != "" {
+		s.URL = other.URL
+	}
+	s.Types = append(s.Types, other.Types...)
+	s.Keys = append(s.Keys, other.Keys...)
+	if other.IncludePrivate {
+		s.IncludePrivate = true
+	}
+	if len(other.Headers) > 0 {
+		s.Headers = makeMapIfNil(s.Headers)
+		maps.Copy(s.Headers, other.Headers)
+	}
+}
+
+func (s *Subscriber) validate() error {
+	if s.URL != "" {
+		u, err := url.Parse(s.URL)
+		if err != nil {
+			return fmt.Errorf("invalid URL: %v", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid URL %q: must be an absolute http or https URL", s.URL)
+		}
+	}
+	for _, noticeType := range s.Types {
+		if !noticeType.Valid() {
+			return fmt.Errorf("invalid notice type %q", noticeType)
+		}
+	}
+	return nil
+}
+
+var _ plan.Section = (*SubscribersSection)(nil)
+
+// SubscribersSection is the plan section holding notice subscribers.
+type SubscribersSection struct {
+	Entries map[string]*Subscriber `yaml:",inline"`
+}
+
+func (ss *SubscribersSection) IsZero() bool {
+	return len(ss.Entries) == 0
+}
+
+func (ss *SubscribersSection) Validate() error {
+	for name, subscriber := range ss.Entries {
+		if subscriber == nil {
+			return &plan.FormatError{
+				Message: fmt.Sprintf("notice subscriber %q cannot have a null value", name),
+			}
+		}
+		if err := subscriber.validate(); err != nil {
+			return &plan.FormatError{
+				Message: fmt.Sprintf("notice subscriber %q %v", name, err),
+			}
+		}
+	}
+	return nil
+}
+
+func (ss *SubscribersSection) combine(other *SubscribersSection) error {
+	for name, subscriber := range other.Entries {
+		ss.Entries = makeMapIfNil(ss.Entries)
+		switch subscriber.Override {
+		case plan.MergeOverride:
+			if current, ok := ss.Entries[name]; ok {
+				current.merge(subscriber)
+			} else {
+				ss.Entries[name] = subscriber.copy()
+			}
+		case plan.ReplaceOverride:
+			ss.Entries[name] = subscriber.copy()
+		case plan.UnknownOverride:
+			return &plan.FormatError{
+				Message: fmt.Sprintf(`notice subscriber %q must define "override" policy`, name),
+			}
+		default:
+			return &plan.FormatError{
+				Message: fmt.Sprintf(`notice subscriber %q has invalid "override" policy: %q`, name, subscriber.Override),
+			}
+		}
+	}
+	return nil
+}
+
+var _ plan.SectionExtension = (*SubscribersSectionExtension)(nil)
+
+// SubscribersSectionExtension is the plan extension for the
+// "notice-subscribers" section.
+type SubscribersSectionExtension struct{}
+
+func (*SubscribersSectionExtension) ParseSection(data yaml.Node) (plan.Section, error) {
+	subscribers := &SubscribersSection{}
+	if err := plan.SectionDecode(&data, subscribers); err != nil {
+		return nil, &plan.FormatError{
+			Message: fmt.Sprintf(`cannot parse the "notice-subscribers" section: %v`, err),
+		}
+	}
+	for name, subscriber := range subscribers.Entries {
+		if subscriber != nil {
+			subscriber.Name = name
+		}
+	}
+	return subscribers, nil
+}
+
+func (*SubscribersSectionExtension) CombineSections(sections ...plan.Section) (plan.Section, error) {
+	subscribers := &SubscribersSection{}
+	for _, section := range sections {
+		// The following will panic if any of the supplied sections is not a
+		// SubscribersSection.
+		layer := section.(*SubscribersSection)
+		if err := subscribers.combine(layer); err != nil {
+			return nil, err
+		}
+	}
+	return subscribers, nil
+}
+
+func (*SubscribersSectionExtension) ValidatePlan(p *plan.Plan) error {
+	ss, ok := p.Sections[SubscribersField].(*SubscribersSection)
+	if !ok {
+		return nil
+	}
+	for name, subscriber := range ss.Entries {
+		if subscriber.URL == "" {
+			return &plan.FormatError{
+				Message: fmt.Sprintf(`plan must set "url" for notice subscriber %q`, name),
+			}
+		}
+	}
+	return nil
+}
+
+// planSubscribers returns the notice subscribers in the plan, or nil if the
+// section extension isn't registered.
+func planSubscribers(p *plan.Plan) map[string]*Subscriber {
+	ss, ok := p.Sections[SubscribersField].(*SubscribersSection)
+	if !ok {
+		return nil
+	}
+	return ss.Entries
+}
+
+func makeMapIfNil[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		m = make(map[K]V)
+	}
+	return m
+}
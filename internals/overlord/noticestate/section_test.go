@@ -0,0 +1,203 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package noticestate_test
+
+import (
+	"fmt"
+	"strings"
+
+	. "gopkg.in/check.v1"
+	"gopkg.in/yaml.v3"
+
+	"github.com/canonical/pebble/internals/overlord/noticestate"
+	"github.com/canonical/pebble/internals/overlord/state"
+	"github.com/canonical/pebble/internals/plan"
+)
+
+type sectionSuite struct{}
+
+var _ = Suite(&sectionSuite{})
+
+var schemaTests = []struct {
+	summary         string
+	layers          []string
+	combinedSection *noticestate.SubscribersSection
+	combinedYAML    string
+	error           string
+}{{
+	summary:         "Empty section",
+	combinedSection: &noticestate.SubscribersSection{},
+	combinedYAML:    `notice-subscribers: {}`,
+}, {
+	summary: "Null subscriber",
+	layers: []string{`
+notice-subscribers:
+    controller:
+`},
+	error: `notice subscriber "controller" cannot have a null value`,
+}, {
+	summary: "No override policy",
+	layers: []string{`
+notice-subscribers:
+    controller:
+        url: http://localhost:8080/notices
+`},
+	error: `notice subscriber "controller" must define "override" policy`,
+}, {
+	summary: "Invalid override policy",
+	layers: []string{`
+notice-subscribers:
+    controller:
+        override: foo
+        url: http://localhost:8080/notices
+`},
+	error: `notice subscriber "controller" has invalid "override" policy: "foo"`,
+}, {
+	summary: "Invalid URL",
+	layers: []string{`
+notice-subscribers:
+    controller:
+        override: replace
+        url: /notices
+`},
+	error: `notice subscriber "controller" invalid URL "/notices": must be an absolute http or https URL`,
+}, {
+	summary: "Invalid notice type",
+	layers: []string{`
+notice-subscribers:
+    controller:
+        override: replace
+        url: http://localhost:8080/notices
+        types: [foo]
+`},
+	error: `notice subscriber "controller" invalid notice type "foo"`,
+}, {
+	summary: "Unknown field",
+	layers: []string{`
+notice-subscribers:
+    controller:
+        override: replace
+        uri: http://localhost:8080/notices
+`},
+	error: `(?s)cannot parse the "notice-subscribers" section: .*field uri not found.*`,
+}, {
+	summary: "Merged subscribers",
+	layers: []string{`
+notice-subscribers:
+    controller:
+        override: replace
+        url: http://localhost:8080/notices
+        types: [custom]
+        keys: [example.com/a]
+        headers:
+            Authorization: Bearer abc
+`, `
+notice-subscribers:
+    controller:
+        override: merge
+        types: [warning]
+        include-private: true
+        headers:
+            X-Source: pebble
+    other:
+        override: merge
+        url: https://example.com/hook
+`},
+	combinedSection: &noticestate.SubscribersSection{
+		Entries: map[string]*noticestate.Subscriber{
+			"controller": {
+				Name:           "controller",
+				Override:       plan.ReplaceOverride,
+				URL:            "http://localhost:8080/notices",
+				Types:          []state.NoticeType{state.CustomNotice, state.WarningNotice},
+				Keys:           []string{"example.com/a"},
+				IncludePrivate: true,
+				Headers: map[string]string{
+					"Authorization": "Bearer abc",
+					"X-Source":      "pebble",
+				},
+			},
+			"other": {
+				Name:     "other",
+				Override: plan.MergeOverride,
+				URL:      "https://example.com/hook",
+			},
+		},
+	},
+	combinedYAML: `
+notice-subscribers:
+    controller:
+        override: replace
+        url: http://localhost:8080/notices
+        types:
+            - custom
+            - warning
+        keys:
+            - example.com/a
+        include-private: true
+        headers:
+            Authorization: Bearer abc
+            X-Source: pebble
+    other:
+        override: merge
+        url: https://example.com/hook
+`,
+}, {
+	summary: "URL required in combined plan",
+	layers: []string{`
+notice-subscribers:
+    controller:
+        override: merge
+        types: [custom]
+`},
+	error: `plan must set "url" for notice subscriber "controller"`,
+}}
+
+func (s *sectionSuite) TestSchema(c *C) {
+	plan.RegisterSectionExtension(noticestate.SubscribersField, &noticestate.SubscribersSectionExtension{})
+	defer plan.UnregisterSectionExtension(noticestate.SubscribersField)
+
+	for _, t := range schemaTests {
+		c.Logf("Summary: %s", t.summary)
+		combined, err := parseCombineLayers(t.layers)
+		if err == nil {
+			p := &plan.Plan{Sections: combined.Sections}
+			err = p.Validate()
+		}
+		if t.error != "" {
+			c.Check(err, ErrorMatches, t.error)
+			continue
+		}
+		c.Assert(err, IsNil)
+		section, ok := combined.Sections[noticestate.SubscribersField].(*noticestate.SubscribersSection)
+		c.Assert(ok, Equals, true)
+		c.Check(section, DeepEquals, t.combinedSection)
+		data, err := yaml.Marshal(combined)
+		c.Assert(err, IsNil)
+		c.Check(strings.TrimSpace(string(data)), Equals, strings.TrimSpace(t.combinedYAML))
+	}
+}
+
+func parseCombineLayers(yamls []string) (*plan.Layer, error) {
+	var layers []*plan.Layer
+	for i, yaml := range yamls {
+		layer, err := plan.ParseLayer(i, fmt.Sprintf("layer-%d", i), []byte(yaml))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	return plan.CombineLayers(layers...)
+}
@@ -16,6 +16,7 @@
 package overlord
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -29,10 +30,12 @@ import (
 	"gopkg.in/tomb.v2"
 
 	"github.com/canonical/pebble/cmd"
+	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/osutil"
 	"github.com/canonical/pebble/internals/overlord/checkstate"
 	"github.com/canonical/pebble/internals/overlord/cmdstate"
 	"github.com/canonical/pebble/internals/overlord/logstate"
+	"github.com/canonical/pebble/internals/overlord/noticestate"
 	"github.com/canonical/pebble/internals/overlord/patch"
 	"github.com/canonical/pebble/internals/overlord/planstate"
 	"github.com/canonical/pebble/internals/overlord/restart"
@@ -92,6 +95,12 @@ type Options struct {
 	IDSigner tlsstate.IDSigner
 	// Persist specifies whether the state should be persisted to disk.
 	Persist PersistMode
+	// EncryptState specifies whether the persisted state is encrypted with
+	// a key derived from IDSigner, which must expose its private key seed
+	// with a "Seed() []byte" method, as idkey.IDKey does. Encrypted state is
+	// always decrypted when it's loaded, so turning this off decrypts it
+	// again.
+	EncryptState bool
 }
 
 type PersistMode int
@@ -128,6 +137,7 @@ type Overlord struct {
 	checkMgr   *checkstate.CheckManager
 	logMgr     *logstate.LogManager
 	tlsMgr     *tlsstate.TLSManager
+	noticeMgr  *noticestate.NoticeManager
 
 	extension Extension
 }
@@ -160,10 +170,16 @@ func New(opts *Options) (*Overlord, error) {
 	var s *state.State
 	var restartMgr *restart.RestartManager
 	if opts.Persist == PersistDefault {
+		idKey, _ := opts.IDSigner.(seeder)
+		if opts.EncryptState && idKey == nil {
+			return nil, errors.New("cannot encrypt state without an identity key")
+		}
 		statePath := filepath.Join(o.pebbleDir, cmd.StateFile)
 		backend := &overlordStateBackend{
 			path:         statePath,
 			ensureBefore: o.ensureBefore,
+			idKey:        idKey,
+			encrypt:      opts.EncryptState,
 		}
 		s, restartMgr, err = loadState(curBootID, statePath, opts.RestartHandler, backend)
 		if err != nil {
@@ -206,7 +222,7 @@ func New(opts *Options) (*Overlord, error) {
 	if tlsDir == "" {
 		tlsDir = filepath.Join(opts.PebbleDir, "tls")
 	}
-	o.tlsMgr = tlsstate.NewManager(tlsDir, opts.IDSigner)
+	o.tlsMgr = tlsstate.NewManager(s, tlsDir, opts.IDSigner)
 	o.stateEng.AddManager(o.tlsMgr)
 
 	o.logMgr = logstate.NewLogManager()
@@ -242,9 +258,19 @@ func New(opts *Options) (*Overlord, error) {
 	// Tell log manager about plan updates.
 	o.planMgr.AddChangeListener(o.logMgr.PlanChanged)
 
+	o.noticeMgr = noticestate.NewManager(s)
+	o.stateEng.AddManager(o.noticeMgr)
+
+	// Tell notice manager about plan updates.
+	o.planMgr.AddChangeListener(o.noticeMgr.PlanChanged)
+
 	// Tell service manager about check failures.
 	o.checkMgr.NotifyCheckFailed(o.serviceMgr.CheckFailed)
 
+	// Let log checks read service logs.
+	o.checkMgr.SetServiceLogs(o.serviceMgr.ServiceLogBuffer)
+	o.checkMgr.SetServiceProcessGroup(o.serviceMgr.ServiceProcessGroup)
+
 	if o.extension != nil {
 		extraManagers, err := o.extension.ExtraManagers(o)
 		if err != nil {
@@ -295,7 +321,7 @@ func getCurrentBootID() (string, error) {
 	return curBootID, nil
 }
 
-func loadState(curBootID, statePath string, restartHandler restart.Handler, backend state.Backend) (*state.State, *restart.RestartManager, error) {
+func loadState(curBootID, statePath string, restartHandler restart.Handler, backend *overlordStateBackend) (*state.State, *restart.RestartManager, error) {
 	timings := timing.Start("", "", map[string]string{"startup": "load-state"})
 
 	if !osutil.CanStat(statePath) {
@@ -310,17 +336,21 @@ func loadState(curBootID, statePath string, restartHandler restart.Handler, back
 			return nil, nil, err
 		}
 		patch.Init(s)
+		updateStateEncryption(s, backend.encrypt)
 		return s, restartMgr, nil
 	}
-	r, err := os.Open(statePath)
+	data, err := os.ReadFile(statePath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot read the state file: %s", err)
 	}
-	defer r.Close()
+	data, _, err = backend.open(data)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	var s *state.State
 	span := timings.StartNested("read-state", "read state from disk")
-	s, err = state.ReadState(backend, r)
+	s, err = state.ReadState(backend, bytes.NewReader(data))
 	span.Stop()
 	if err != nil {
 		return nil, nil, err
@@ -342,9 +372,33 @@ func loadState(curBootID, statePath string, restartHandler restart.Handler, back
 	if err != nil {
 		return nil, nil, err
 	}
+	updateStateEncryption(s, backend.encrypt)
 	return s, restartMgr, nil
 }
 
+// updateStateEncryption records whether the state is encrypted, which
+// rewrites the state file if it has changed: encrypting plain text state
+// when encryption is turned on, and decrypting it when it's turned off.
+func updateStateEncryption(s *state.State, encrypt bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	var encrypted bool
+	err := s.Get("state-encrypted", &encrypted)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		logger.Noticef("Cannot get state encryption status: %v", err)
+	}
+	if encrypted == encrypt {
+		return
+	}
+	if encrypt {
+		logger.Noticef("Encrypting state.")
+	} else {
+		logger.Noticef("Decrypting state.")
+	}
+	s.Set("state-encrypted", encrypt)
+}
+
 func setupState(curBootID string, restartHandler restart.Handler, backend state.Backend) (*state.State, *restart.RestartManager, error) {
 	// Create a new state for in-memory backend.
 	s := state.New(backend)
@@ -642,6 +696,12 @@ func (o *Overlord) TLSManager() *tlsstate.TLSManager {
 	return o.tlsMgr
 }
 
+// NoticeManager returns the notice manager responsible for delivering
+// notices to subscribers.
+func (o *Overlord) NoticeManager() *noticestate.NoticeManager {
+	return o.noticeMgr
+}
+
 // Fake creates an Overlord without any managers and with a backend
 // not using disk. Managers can be added with AddManager. For testing.
 func Fake() *Overlord {
@@ -21,6 +21,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -29,11 +30,13 @@ import (
 	"gopkg.in/tomb.v2"
 
 	"github.com/canonical/pebble/cmd"
+	"github.com/canonical/pebble/internals/idkey"
 	"github.com/canonical/pebble/internals/osutil"
 	"github.com/canonical/pebble/internals/overlord"
 	"github.com/canonical/pebble/internals/overlord/patch"
 	"github.com/canonical/pebble/internals/overlord/restart"
 	"github.com/canonical/pebble/internals/overlord/state"
+	"github.com/canonical/pebble/internals/overlord/tlsstate"
 	"github.com/canonical/pebble/internals/testutil"
 )
 
@@ -239,6 +242,92 @@ func (wm *witnessManager) Ensure() error {
 	return nil
 }
 
+func (ovs *overlordSuite) TestNewEncryptState(c *C) {
+	signer, err := idkey.Generate(filepath.Join(c.MkDir(), "identity"))
+	c.Assert(err, IsNil)
+
+	fakeState := []byte(fmt.Sprintf(`{"data": {"patch-level": %d, "patch-sublevel": %d, "secret": "hunter2"}}`,
+		patch.Level, patch.Sublevel))
+	err = os.WriteFile(ovs.statePath, fakeState, 0600)
+	c.Assert(err, IsNil)
+
+	// Plain text state is encrypted when it's loaded with encryption on.
+	_, err = overlord.New(&overlord.Options{PebbleDir: ovs.dir, IDSigner: signer, EncryptState: true})
+	c.Assert(err, IsNil)
+	data, err := os.ReadFile(ovs.statePath)
+	c.Assert(err, IsNil)
+	c.Check(strings.HasPrefix(string(data), "pebble-encrypted-state-v1\n"), Equals, true)
+	c.Check(strings.Contains(string(data), "hunter2"), Equals, false)
+
+	// Encrypted state is loaded transparently.
+	o, err := overlord.New(&overlord.Options{PebbleDir: ovs.dir, IDSigner: signer, EncryptState: true})
+	c.Assert(err, IsNil)
+	st := o.State()
+	st.Lock()
+	var secret string
+	err = st.Get("secret", &secret)
+	st.Unlock()
+	c.Assert(err, IsNil)
+	c.Check(secret, Equals, "hunter2")
+
+	// Encrypted state is decrypted when it's loaded with encryption off.
+	o, err = overlord.New(&overlord.Options{PebbleDir: ovs.dir, IDSigner: signer})
+	c.Assert(err, IsNil)
+	st = o.State()
+	st.Lock()
+	err = st.Get("secret", &secret)
+	st.Unlock()
+	c.Assert(err, IsNil)
+	c.Check(secret, Equals, "hunter2")
+	data, err = os.ReadFile(ovs.statePath)
+	c.Assert(err, IsNil)
+	c.Check(strings.Contains(string(data), "hunter2"), Equals, true)
+}
+
+func (ovs *overlordSuite) TestNewEncryptStateNew(c *C) {
+	signer, err := idkey.Generate(filepath.Join(c.MkDir(), "identity"))
+	c.Assert(err, IsNil)
+
+	o, err := overlord.New(&overlord.Options{PebbleDir: ovs.dir, IDSigner: signer, EncryptState: true})
+	c.Assert(err, IsNil)
+	st := o.State()
+	st.Lock()
+	st.Set("secret", "hunter2")
+	st.Unlock()
+
+	data, err := os.ReadFile(ovs.statePath)
+	c.Assert(err, IsNil)
+	c.Check(strings.HasPrefix(string(data), "pebble-encrypted-state-v1\n"), Equals, true)
+	c.Check(strings.Contains(string(data), "hunter2"), Equals, false)
+}
+
+func (ovs *overlordSuite) TestNewEncryptStateNoIdentityKey(c *C) {
+	_, err := overlord.New(&overlord.Options{PebbleDir: ovs.dir, EncryptState: true})
+	c.Assert(err, ErrorMatches, "cannot encrypt state without an identity key")
+
+	// The identity key must expose its seed to derive the encryption key.
+	key, err := idkey.Generate(filepath.Join(c.MkDir(), "identity"))
+	c.Assert(err, IsNil)
+	signer := struct{ tlsstate.IDSigner }{key}
+	_, err = overlord.New(&overlord.Options{PebbleDir: ovs.dir, IDSigner: signer, EncryptState: true})
+	c.Assert(err, ErrorMatches, "cannot encrypt state without an identity key")
+}
+
+func (ovs *overlordSuite) TestNewEncryptedStateErrors(c *C) {
+	signer, err := idkey.Generate(filepath.Join(c.MkDir(), "identity"))
+	c.Assert(err, IsNil)
+	_, err = overlord.New(&overlord.Options{PebbleDir: ovs.dir, IDSigner: signer, EncryptState: true})
+	c.Assert(err, IsNil)
+
+	_, err = overlord.New(&overlord.Options{PebbleDir: ovs.dir})
+	c.Assert(err, ErrorMatches, "cannot decrypt state: no identity key")
+
+	other, err := idkey.Generate(filepath.Join(c.MkDir(), "identity"))
+	c.Assert(err, IsNil)
+	_, err = overlord.New(&overlord.Options{PebbleDir: ovs.dir, IDSigner: other, EncryptState: true})
+	c.Assert(err, ErrorMatches, `cannot decrypt state \(was it encrypted with another identity key\?\): .*`)
+}
+
 func (ovs *overlordSuite) TestTrivialRunAndStop(c *C) {
 	o, err := overlord.New(&overlord.Options{PebbleDir: ovs.dir})
 	c.Assert(err, IsNil)
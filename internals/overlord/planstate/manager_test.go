@@ -468,7 +468,7 @@ checks:
         tcp:
             port: 8080
 `))
-	c.Check(err, ErrorMatches, `(?s).*plan check.*must be "alive" or "ready".*`)
+	c.Check(err, ErrorMatches, `(?s).*plan check.*must be "alive", "ready", or "startup".*`)
 
 	// Make sure that layer validation is happening for extensions.
 	_, err = plan.ParseLayer(0, "label4", []byte(`
@@ -110,6 +110,11 @@ type serviceData struct {
 	restarting   bool
 	currentSince time.Time
 	startCount   atomic.Int64
+
+	// Status reported in the last service-status notice, and the exit code
+	// to report in the next one (only set while handling an exit).
+	notifiedStatus ServiceStatus
+	exitCode       *int
 }
 
 func (m *ServiceManager) doStart(task *state.Task, tomb *tomb.Tomb) error {
@@ -313,6 +318,11 @@ func (s *serviceData) transitionRestarting(state serviceState, restarting bool)
 	if oldStatus != newStatus {
 		s.currentSince = time.Now()
 	}
+	// The initial state is only passed through when (re)starting, so don't
+	// report it as an error.
+	if state != stateInitial && newStatus != s.notifiedStatus {
+		s.manager.queueStatusNotice(s, newStatus)
+	}
 
 	s.state = state
 	s.restarting = restarting
@@ -525,6 +535,9 @@ func (s *serviceData) exited(exitCode int) error {
 	s.manager.servicesLock.Lock()
 	defer s.manager.servicesLock.Unlock()
 
+	s.exitCode = &exitCode
+	defer func() { s.exitCode = nil }()
+
 	if s.resetTimer != nil {
 		s.resetTimer.Stop()
 	}
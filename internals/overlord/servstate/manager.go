@@ -37,6 +37,10 @@ type ServiceManager struct {
 	rand     *rand.Rand
 
 	logMgr LogManager
+
+	noticesLock    sync.Mutex
+	pendingNotices []statusNotice
+	addingNotices  bool
 }
 
 type LogManager interface {
@@ -256,6 +260,39 @@ func (m *ServiceManager) ServiceLogs(services []string, last int) (map[string]se
 	return iterators, nil
 }
 
+// ServiceLogBuffer returns the log ring buffer of the named service, or nil
+// if the service hasn't been started. The buffer is replaced with a new one
+// if the service is started again after being pruned.
+func (m *ServiceManager) ServiceLogBuffer(name string) *servicelog.RingBuffer {
+	m.servicesLock.Lock()
+	defer m.servicesLock.Unlock()
+
+	service := m.services[name]
+	if service == nil {
+		return nil
+	}
+	return service.logs
+}
+
+// ServiceProcessGroup returns the process group ID of the named service, or
+// zero if the service isn't running. Each service runs in its own process
+// group, whose ID is the PID of the service's main process.
+func (m *ServiceManager) ServiceProcessGroup(name string) int {
+	m.servicesLock.Lock()
+	defer m.servicesLock.Unlock()
+
+	service := m.services[name]
+	if service == nil || service.cmd == nil || service.cmd.Process == nil {
+		return 0
+	}
+	switch service.state {
+	case stateStarting, stateRunning:
+		return service.cmd.Process.Pid
+	default:
+		return 0
+	}
+}
+
 // Replan returns a list of services in lanes to stop and services to start
 // because their plans had changed between when they started and this call.
 func (m *ServiceManager) Replan() ([][]string, [][]string, error) {
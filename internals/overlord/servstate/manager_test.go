@@ -252,6 +252,26 @@ func (s *S) TestStartStopServices(c *C) {
 	s.stopTestServices(c)
 }
 
+func (s *S) TestServiceProcessGroup(c *C) {
+	s.newServiceManager(c)
+	s.planAddLayer(c, testPlanLayer)
+	s.planChanged(c)
+
+	c.Check(s.manager.ServiceProcessGroup("test1"), Equals, 0)
+	c.Check(s.manager.ServiceProcessGroup("nosvc"), Equals, 0)
+
+	s.startTestServices(c, true)
+	if c.Failed() {
+		return
+	}
+	pgid := s.manager.ServiceProcessGroup("test1")
+	c.Check(pgid, Not(Equals), 0)
+	c.Check(pgid, Equals, s.manager.RunningCmds()["test1"].Process.Pid)
+
+	s.stopTestServices(c)
+	c.Check(s.manager.ServiceProcessGroup("test1"), Equals, 0)
+}
+
 func (s *S) TestStartStopServicesIdempotency(c *C) {
 	s.newServiceManager(c)
 	s.planAddLayer(c, testPlanLayer)
@@ -562,6 +582,33 @@ func (s *S) TestServiceLogs(c *C) {
 	s.testServiceLogs(c, outputs)
 }
 
+func (s *S) TestServiceLogBuffer(c *C) {
+	s.newServiceManager(c)
+	s.planAddLayer(c, testPlanLayer)
+	s.planChanged(c)
+
+	// No buffer until the service has been started.
+	c.Check(s.manager.ServiceLogBuffer("test2"), IsNil)
+
+	s.startTestServices(c, true)
+	buffer := s.manager.ServiceLogBuffer("test2")
+	c.Assert(buffer, NotNil)
+
+	// Restarting the service keeps the same buffer.
+	s.stopTestServices(c)
+	s.startTestServices(c, true)
+	c.Check(s.manager.ServiceLogBuffer("test2"), Equals, buffer)
+
+	// Starting the service after it has been pruned creates a new buffer.
+	s.stopTestServices(c)
+	s.manager.Prune(0, 0)
+	c.Check(s.manager.ServiceLogBuffer("test2"), IsNil)
+	s.startTestServices(c, true)
+	newBuffer := s.manager.ServiceLogBuffer("test2")
+	c.Assert(newBuffer, NotNil)
+	c.Check(newBuffer, Not(Equals), buffer)
+}
+
 func (s *S) TestStartBadCommand(c *C) {
 	s.newServiceManager(c)
 	s.planAddLayer(c, testPlanLayer)
@@ -973,6 +1020,53 @@ services:
 	})
 }
 
+func (s *S) TestStatusNotices(c *C) {
+	s.newServiceManager(c)
+	s.planAddLayer(c, testPlanLayer)
+	s.planAddLayer(c, `
+services:
+    test2:
+        override: merge
+        command: /bin/sh -c "sleep 0.1; exit 3"
+`)
+	s.planChanged(c)
+
+	s.startServices(c, [][]string{{"test2"}})
+	notice := s.waitStatusNotice(c, "test2", servstate.StatusBackoff)
+	c.Check(notice.LastData(), DeepEquals, map[string]string{
+		"status":          "backoff",
+		"previous-status": "active",
+		"exit-code":       "3",
+		"backoff-count":   "1",
+	})
+
+	s.stopServices(c, [][]string{{"test2"}})
+	notice = s.waitStatusNotice(c, "test2", servstate.StatusInactive)
+	c.Check(notice.LastData(), DeepEquals, map[string]string{
+		"status":          "inactive",
+		"previous-status": "backoff",
+	})
+}
+
+// waitStatusNotice waits for the service-status notice of the given service
+// to report the given status, and returns the notice.
+func (s *S) waitStatusNotice(c *C, service string, status servstate.ServiceStatus) *state.Notice {
+	for i := 0; i < 500; i++ {
+		s.st.Lock()
+		notices := s.st.Notices(&state.NoticeFilter{
+			Types: []state.NoticeType{state.ServiceStatusNotice},
+			Keys:  []string{service},
+		})
+		s.st.Unlock()
+		if len(notices) == 1 && notices[0].LastData()["status"] == string(status) {
+			return notices[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Fatalf("timed out waiting for %q service-status notice %q", service, status)
+	return nil
+}
+
 // The aim of this test is to make sure that the actioned check
 // failure terminates the service, after which it will first go
 // to back-off state and then finally starts again (only once).
@@ -0,0 +1,71 @@
+package servstate
+
+import (
+	"strconv"
+
+	"github.com/canonical/pebble/internals/logger"
+	"github.com/canonical/pebble/internals/overlord/state"
+)
+
+// statusNotice is a pending service-status notice.
+type statusNotice struct {
+	name string
+	data map[string]string
+}
+
+// queueStatusNotice records a service-status notice for the service's new
+// status. It's called with servicesLock held, and the state lock can't be
+// acquired while holding that (as it's ordered the other way elsewhere), so
+// notices are queued and added to state in order by a separate goroutine.
+func (m *ServiceManager) queueStatusNotice(s *serviceData, status ServiceStatus) {
+	previous := s.notifiedStatus
+	if previous == "" {
+		previous = StatusInactive
+	}
+	data := map[string]string{
+		"status":          string(status),
+		"previous-status": string(previous),
+	}
+	if s.exitCode != nil {
+		data["exit-code"] = strconv.Itoa(*s.exitCode)
+	}
+	if status == StatusBackoff {
+		data["backoff-count"] = strconv.Itoa(s.backoffNum)
+	}
+	s.notifiedStatus = status
+
+	m.noticesLock.Lock()
+	defer m.noticesLock.Unlock()
+	m.pendingNotices = append(m.pendingNotices, statusNotice{name: s.config.Name, data: data})
+	if !m.addingNotices {
+		m.addingNotices = true
+		go m.addPendingNotices()
+	}
+}
+
+// addPendingNotices adds queued service-status notices to state until the
+// queue is empty.
+func (m *ServiceManager) addPendingNotices() {
+	for {
+		m.noticesLock.Lock()
+		notices := m.pendingNotices
+		m.pendingNotices = nil
+		if len(notices) == 0 {
+			m.addingNotices = false
+			m.noticesLock.Unlock()
+			return
+		}
+		m.noticesLock.Unlock()
+
+		m.state.Lock()
+		for _, notice := range notices {
+			_, err := m.state.AddNotice(nil, state.ServiceStatusNotice, notice.name, &state.AddNoticeOptions{
+				Data: notice.data,
+			})
+			if err != nil {
+				logger.Noticef("Cannot record service-status notice for service %q: %v", notice.name, err)
+			}
+		}
+		m.state.Unlock()
+	}
+}
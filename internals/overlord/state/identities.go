@@ -15,12 +15,19 @@
 package state
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/GehirnInc/crypt/sha512_crypt"
 )
@@ -34,6 +41,26 @@ type Identity struct {
 	// non-nil.
 	Local *LocalIdentity
 	Basic *BasicIdentity
+	Token *TokenIdentity
+	Cert  *CertIdentity
+
+	// Permissions optionally governs the identity's access to services,
+	// checks, files and exec with fine-grained permissions. If nil, the
+	// access level alone determines what the identity can do.
+	Permissions []Permission
+
+	// ExpiresAt is the time the identity expires, after which it can no
+	// longer be used and is removed. It is zero if the identity never expires.
+	ExpiresAt time.Time
+}
+
+// Expired reports whether the identity has expired.
+func (d *Identity) Expired() bool {
+	return d.expiredAt(timeNow())
+}
+
+func (d *Identity) expiredAt(now time.Time) bool {
+	return !d.ExpiresAt.IsZero() && !now.Before(d.ExpiresAt)
 }
 
 // IdentityAccess defines the access level for an identity.
@@ -57,6 +84,61 @@ type LocalIdentity struct {
 type BasicIdentity struct {
 	// Password holds the user's sha512-crypt-hashed password.
 	Password string
+
+	// PreviousPassword holds the hashed password replaced by the last
+	// rotation, which remains valid until PreviousExpiresAt.
+	PreviousPassword  string
+	PreviousExpiresAt time.Time
+}
+
+// TokenIdentity holds identity configuration specific to the "token" type
+// (for HTTP bearer token authentication).
+type TokenIdentity struct {
+	// Hash holds the hex-encoded SHA-256 hash of the user's token. Tokens
+	// are long random strings, so unlike passwords they don't need a slow
+	// (salted) hash.
+	Hash string
+
+	// PreviousHash holds the token hash replaced by the last rotation, which
+	// remains valid until PreviousExpiresAt.
+	PreviousHash      string
+	PreviousExpiresAt time.Time
+}
+
+// HashToken returns the hex-encoded SHA-256 hash of the given token, as
+// stored in a TokenIdentity.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+var tokenHashRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// CertIdentity holds identity configuration specific to the "cert" type
+// (for mutual TLS authentication using a client certificate).
+type CertIdentity struct {
+	X509 *x509.Certificate
+}
+
+// parseCertPEM parses a single PEM-encoded X.509 certificate.
+func parseCertPEM(data string) (*x509.Certificate, error) {
+	block, rest := pem.Decode([]byte(data))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("cert identity must include a PEM-encoded certificate")
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, errors.New("cert identity must include only one PEM-encoded certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse certificate from cert identity: %w", err)
+	}
+	return cert, nil
+}
+
+// encodeCertPEM returns the PEM encoding of the given certificate.
+func encodeCertPEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
 }
 
 // This is used to ensure we send a well-formed identity Name.
@@ -72,6 +154,10 @@ func (d *Identity) validate(name string) error {
 		return fmt.Errorf("identity name %q invalid: must start with an alphabetic character and only contain alphanumeric characters, underscore, and hyphen", d.Name)
 	}
 
+	if d.Expired() {
+		return fmt.Errorf("expires-at %s must be in the future", d.ExpiresAt.Format(time.RFC3339))
+	}
+
 	return d.validateAccess()
 }
 
@@ -101,8 +187,35 @@ func (d *Identity) validateAccess() error {
 		}
 		gotType = true
 	}
+	if d.Token != nil {
+		if !tokenHashRegexp.MatchString(d.Token.Hash) {
+			return errors.New("token identity must specify hash (hex-encoded SHA-256)")
+		}
+		gotType = true
+	}
+	if d.Cert != nil {
+		if d.Cert.X509 == nil {
+			return errors.New("cert identity must include an X.509 certificate")
+		}
+		gotType = true
+	}
 	if !gotType {
-		return errors.New(`identity must have at least one type ("local" or "basic")`)
+		return errors.New(`identity must have at least one type ("local", "basic", "token" or "cert")`)
+	}
+
+	if d.Permissions != nil {
+		if d.Access != AdminAccess && d.Access != ReadAccess {
+			return fmt.Errorf("permissions can only be used with %q or %q access", ReadAccess, AdminAccess)
+		}
+		if len(d.Permissions) == 0 {
+			return errors.New("permissions must not be empty if specified")
+		}
+		for i := range d.Permissions {
+			err := d.Permissions[i].validate()
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -115,6 +228,11 @@ type apiIdentity struct {
 	Access string            `json:"access"`
 	Local  *apiLocalIdentity `json:"local,omitempty"`
 	Basic  *apiBasicIdentity `json:"basic,omitempty"`
+	Token  *apiTokenIdentity `json:"token,omitempty"`
+	Cert   *apiCertIdentity  `json:"cert,omitempty"`
+
+	Permissions []apiPermission `json:"permissions,omitempty"`
+	ExpiresAt   *time.Time      `json:"expires-at,omitempty"`
 }
 
 type apiLocalIdentity struct {
@@ -123,6 +241,25 @@ type apiLocalIdentity struct {
 
 type apiBasicIdentity struct {
 	Password string `json:"password"`
+
+	// Only used in responses, to show when the previous password expires.
+	PreviousExpiresAt *time.Time `json:"previous-expires-at,omitempty"`
+}
+
+type apiTokenIdentity struct {
+	Hash string `json:"hash"`
+
+	// Only used in responses, to show when the previous token expires.
+	PreviousExpiresAt *time.Time `json:"previous-expires-at,omitempty"`
+}
+
+type apiCertIdentity struct {
+	PEM string `json:"pem"`
+}
+
+type apiPermission struct {
+	Action    string   `json:"action"`
+	Resources []string `json:"resources"`
 }
 
 // IMPORTANT NOTE: be sure to exclude secrets when adding to this!
@@ -135,6 +272,28 @@ func (d *Identity) MarshalJSON() ([]byte, error) {
 	}
 	if d.Basic != nil {
 		ai.Basic = &apiBasicIdentity{Password: "*****"}
+		if d.Basic.PreviousPassword != "" {
+			ai.Basic.PreviousExpiresAt = &d.Basic.PreviousExpiresAt
+		}
+	}
+	if d.Token != nil {
+		ai.Token = &apiTokenIdentity{Hash: "*****"}
+		if d.Token.PreviousHash != "" {
+			ai.Token.PreviousExpiresAt = &d.Token.PreviousExpiresAt
+		}
+	}
+	if d.Cert != nil {
+		// A certificate is public, so it's fine to include it here.
+		ai.Cert = &apiCertIdentity{PEM: encodeCertPEM(d.Cert.X509)}
+	}
+	for _, permission := range d.Permissions {
+		ai.Permissions = append(ai.Permissions, apiPermission{
+			Action:    string(permission.Action),
+			Resources: permission.Resources,
+		})
+	}
+	if !d.ExpiresAt.IsZero() {
+		ai.ExpiresAt = &d.ExpiresAt
 	}
 	return json.Marshal(ai)
 }
@@ -159,6 +318,28 @@ func (d *Identity) UnmarshalJSON(data []byte) error {
 	if ai.Basic != nil {
 		identity.Basic = &BasicIdentity{Password: ai.Basic.Password}
 	}
+	if ai.Token != nil {
+		identity.Token = &TokenIdentity{Hash: ai.Token.Hash}
+	}
+	if ai.Cert != nil {
+		cert, err := parseCertPEM(ai.Cert.PEM)
+		if err != nil {
+			return err
+		}
+		identity.Cert = &CertIdentity{X509: cert}
+	}
+	if ai.Permissions != nil {
+		identity.Permissions = make([]Permission, len(ai.Permissions))
+		for i, permission := range ai.Permissions {
+			identity.Permissions[i] = Permission{
+				Action:    PermissionAction(permission.Action),
+				Resources: permission.Resources,
+			}
+		}
+	}
+	if ai.ExpiresAt != nil {
+		identity.ExpiresAt = *ai.ExpiresAt
+	}
 
 	// Perform additional validation using the local Identity type.
 	err = identity.validateAccess()
@@ -303,6 +484,113 @@ func (s *State) RemoveIdentities(identities map[string]struct{}) error {
 	return nil
 }
 
+// IdentityCredentials holds new credentials for an identity, used when
+// rotating them.
+type IdentityCredentials struct {
+	Basic *BasicIdentity
+	Token *TokenIdentity
+}
+
+// RotateIdentities replaces the basic password and/or token of the named
+// identities with the given credentials. The previous password or token
+// remains valid for the grace period, so that clients can be updated. It's
+// an error if any of the named identities do not exist, or don't have the
+// type of credential being rotated.
+func (s *State) RotateIdentities(credentials map[string]*IdentityCredentials, gracePeriod time.Duration) error {
+	s.reading()
+
+	if gracePeriod < 0 {
+		return errors.New("grace period must not be negative")
+	}
+
+	// If any of the named identities don't exist, return an error.
+	var missing []string
+	for name := range credentials {
+		if _, ok := s.identities[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("identities do not exist: %s", strings.Join(missing, ", "))
+	}
+
+	now := timeNow()
+	newIdentities := s.cloneIdentities()
+	for name, creds := range credentials {
+		if creds == nil || (creds.Basic == nil && creds.Token == nil) {
+			return fmt.Errorf(`identity %q must specify "basic" or "token" credentials to rotate`, name)
+		}
+		existing := s.identities[name]
+		identity := *existing
+		if creds.Basic != nil {
+			if existing.Basic == nil {
+				return fmt.Errorf("identity %q has no basic credentials to rotate", name)
+			}
+			identity.Basic = &BasicIdentity{Password: creds.Basic.Password}
+			if gracePeriod > 0 {
+				identity.Basic.PreviousPassword = existing.Basic.Password
+				identity.Basic.PreviousExpiresAt = now.Add(gracePeriod)
+			}
+		}
+		if creds.Token != nil {
+			if existing.Token == nil {
+				return fmt.Errorf("identity %q has no token credentials to rotate", name)
+			}
+			identity.Token = &TokenIdentity{Hash: creds.Token.Hash}
+			if gracePeriod > 0 {
+				identity.Token.PreviousHash = existing.Token.Hash
+				identity.Token.PreviousExpiresAt = now.Add(gracePeriod)
+			}
+		}
+		err := identity.validate(name)
+		if err != nil {
+			return fmt.Errorf("identity %q invalid: %w", name, err)
+		}
+		newIdentities[name] = &identity
+	}
+
+	err := verifyUniqueTokens(newIdentities)
+	if err != nil {
+		return err
+	}
+
+	s.writing()
+	s.identities = newIdentities
+	return nil
+}
+
+// pruneIdentities removes expired identities, recording a warning for each
+// one removed, and forgets previous credentials whose grace period is over.
+func (s *State) pruneIdentities(now time.Time) {
+	for name, identity := range s.identities {
+		if identity.expiredAt(now) {
+			s.writing()
+			delete(s.identities, name)
+			s.Warnf("Identity %q expired at %s and was removed",
+				name, identity.ExpiresAt.Format(time.RFC3339))
+			continue
+		}
+		basicOver := identity.Basic != nil && identity.Basic.PreviousPassword != "" &&
+			!now.Before(identity.Basic.PreviousExpiresAt)
+		tokenOver := identity.Token != nil && identity.Token.PreviousHash != "" &&
+			!now.Before(identity.Token.PreviousExpiresAt)
+		if !basicOver && !tokenOver {
+			continue
+		}
+		// Identities may be shared with callers of Identities, so update a copy.
+		updated := *identity
+		if basicOver {
+			updated.Basic = &BasicIdentity{Password: identity.Basic.Password}
+		}
+		if tokenOver {
+			updated.Token = &TokenIdentity{Hash: identity.Token.Hash}
+		}
+		s.writing()
+		s.identities[name] = &updated
+	}
+}
+
 // Identities returns all the identities in the system. The returned map is a
 // shallow clone, so map mutations won't affect state.
 func (s *State) Identities() map[string]*Identity {
@@ -345,6 +633,12 @@ func (s *State) IdentityFromInputs(userID *uint32, username, password string) *I
 			if err == nil {
 				return identity
 			}
+			if previousValid(identity.Basic.PreviousPassword, identity.Basic.PreviousExpiresAt) {
+				err = crypt.Verify(identity.Basic.PreviousPassword, passwordBytes)
+				if err == nil {
+					return identity
+				}
+			}
 			return nil
 		}
 	case userID != nil:
@@ -358,6 +652,57 @@ func (s *State) IdentityFromInputs(userID *uint32, username, password string) *I
 	return nil
 }
 
+// IdentityFromToken returns the "token" type identity whose token hash
+// matches the given bearer token, or nil if there is none.
+func (s *State) IdentityFromToken(token string) *Identity {
+	s.reading()
+
+	if token == "" {
+		return nil
+	}
+	hash := []byte(HashToken(token))
+	for _, identity := range s.identities {
+		if identity.Token == nil {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(identity.Token.Hash), hash) == 1 {
+			return identity
+		}
+		if previousValid(identity.Token.PreviousHash, identity.Token.PreviousExpiresAt) &&
+			subtle.ConstantTimeCompare([]byte(identity.Token.PreviousHash), hash) == 1 {
+			return identity
+		}
+	}
+	return nil
+}
+
+// previousValid reports whether a previous (rotated) credential is set and
+// still within its grace period.
+func previousValid(previous string, expiresAt time.Time) bool {
+	return previous != "" && timeNow().Before(expiresAt)
+}
+
+// IdentityFromCert returns the "cert" type identity whose certificate
+// matches the given client certificate, or nil if there is none. The
+// certificate must also be within its validity period.
+func (s *State) IdentityFromCert(cert *x509.Certificate) *Identity {
+	s.reading()
+
+	if cert == nil {
+		return nil
+	}
+	now := timeNow()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil
+	}
+	for _, identity := range s.identities {
+		if identity.Cert != nil && identity.Cert.X509.Equal(cert) {
+			return identity
+		}
+	}
+	return nil
+}
+
 func (s *State) cloneIdentities() map[string]*Identity {
 	newIdentities := make(map[string]*Identity, len(s.identities))
 	for name, identity := range s.identities {
@@ -382,5 +727,54 @@ func verifyUniqueUserIDs(identities map[string]*Identity) error {
 				userID, strings.Join(names, ", "))
 		}
 	}
+	err := verifyUniqueCerts(identities)
+	if err != nil {
+		return err
+	}
+	return verifyUniqueTokens(identities)
+}
+
+func verifyUniqueCerts(identities map[string]*Identity) error {
+	certs := make(map[string][]string) // maps certificate fingerprint to identity names
+	for name, identity := range identities {
+		if identity.Cert != nil {
+			sum := sha256.Sum256(identity.Cert.X509.Raw)
+			fingerprint := hex.EncodeToString(sum[:])
+			certs[fingerprint] = append(certs[fingerprint], name)
+		}
+	}
+	for fingerprint, names := range certs {
+		if len(names) > 1 {
+			sort.Strings(names) // ensure error message is stable
+			return fmt.Errorf("cannot have multiple identities with the same certificate (SHA-256 %s: %s)",
+				fingerprint, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// verifyUniqueTokens checks that no two identities accept the same token,
+// including previous tokens that are still within their grace period, so
+// that a token always maps to a single identity.
+func verifyUniqueTokens(identities map[string]*Identity) error {
+	tokens := make(map[string][]string) // maps token hash to identity names
+	for name, identity := range identities {
+		if identity.Token == nil {
+			continue
+		}
+		hash := identity.Token.Hash
+		tokens[hash] = append(tokens[hash], name)
+		previous := identity.Token.PreviousHash
+		if previous != hash && previousValid(previous, identity.Token.PreviousExpiresAt) {
+			tokens[previous] = append(tokens[previous], name)
+		}
+	}
+	for _, names := range tokens {
+		if len(names) > 1 {
+			sort.Strings(names) // ensure error message is stable
+			return fmt.Errorf("cannot have multiple identities with the same token (%s)",
+				strings.Join(names, ", "))
+		}
+	}
 	return nil
 }
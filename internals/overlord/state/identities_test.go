@@ -15,7 +15,15 @@
 package state_test
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -45,6 +53,10 @@ func (s *identitiesSuite) TestMarshalAPI(c *C) {
 			Access: state.MetricsAccess,
 			Basic:  &state.BasicIdentity{Password: "hash"},
 		},
+		"olivia": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: "930bbdc51b6aed5c2a5678fd6e28dee7a05e8a4b643cfc0b4427c3efb86c0d94"},
+		},
 	})
 	c.Assert(err, IsNil)
 
@@ -70,6 +82,12 @@ func (s *identitiesSuite) TestMarshalAPI(c *C) {
         "basic": {
             "password": "*****"
         }
+    },
+    "olivia": {
+        "access": "read",
+        "token": {
+            "hash": "*****"
+        }
     }
 }`[1:])
 }
@@ -94,6 +112,12 @@ func (s *identitiesSuite) TestUnmarshalAPI(c *C) {
         "basic": {
             "password": "hash"
         }
+    },
+    "olivia": {
+        "access": "read",
+        "token": {
+            "hash": "930bbdc51b6aed5c2a5678fd6e28dee7a05e8a4b643cfc0b4427c3efb86c0d94"
+        }
     }
 }`)
 	var identities map[string]*state.Identity
@@ -112,6 +136,10 @@ func (s *identitiesSuite) TestUnmarshalAPI(c *C) {
 			Access: state.MetricsAccess,
 			Basic:  &state.BasicIdentity{Password: "hash"},
 		},
+		"olivia": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: "930bbdc51b6aed5c2a5678fd6e28dee7a05e8a4b643cfc0b4427c3efb86c0d94"},
+		},
 	})
 }
 
@@ -121,13 +149,43 @@ func (s *identitiesSuite) TestUnmarshalAPIErrors(c *C) {
 		error string
 	}{{
 		data:  `{"no-type": {"access": "admin"}}`,
-		error: `identity must have at least one type \("local" or "basic"\)`,
+		error: `identity must have at least one type \("local", "basic", "token" or "cert"\)`,
 	}, {
 		data:  `{"invalid-access": {"access": "admin", "local": {}}}`,
 		error: `local identity must specify user-id`,
 	}, {
 		data:  `{"invalid-access": {"access": "metrics", "basic": {}}}`,
 		error: `basic identity must specify password \(hashed\)`,
+	}, {
+		data:  `{"invalid-token": {"access": "read", "token": {}}}`,
+		error: `token identity must specify hash \(hex-encoded SHA-256\)`,
+	}, {
+		data:  `{"invalid-token": {"access": "read", "token": {"hash": "secret-token"}}}`,
+		error: `token identity must specify hash \(hex-encoded SHA-256\)`,
+	}, {
+		data:  `{"invalid-cert": {"access": "read", "cert": {}}}`,
+		error: `cert identity must include a PEM-encoded certificate`,
+	}, {
+		data:  `{"invalid-cert": {"access": "read", "cert": {"pem": "-----BEGIN CERTIFICATE-----\nZm9v\n-----END CERTIFICATE-----\n"}}}`,
+		error: `cannot parse certificate from cert identity: .*`,
+	}, {
+		data:  `{"invalid-permissions": {"access": "metrics", "local": {"user-id": 42}, "permissions": [{"action": "read-logs", "resources": ["*"]}]}}`,
+		error: `permissions can only be used with "read" or "admin" access`,
+	}, {
+		data:  `{"invalid-permissions": {"access": "read", "local": {"user-id": 42}, "permissions": []}}`,
+		error: `permissions must not be empty if specified`,
+	}, {
+		data:  `{"invalid-permissions": {"access": "read", "local": {"user-id": 42}, "permissions": [{"action": "reboot", "resources": ["*"]}]}}`,
+		error: `invalid permission action "reboot", must be one of "read-services", .*, "exec"`,
+	}, {
+		data:  `{"invalid-permissions": {"access": "read", "local": {"user-id": 42}, "permissions": [{"action": "read-logs"}]}}`,
+		error: `"read-logs" permission must specify at least one resource`,
+	}, {
+		data:  `{"invalid-permissions": {"access": "read", "local": {"user-id": 42}, "permissions": [{"action": "read-files", "resources": ["etc/*"]}]}}`,
+		error: `"read-files" permission resource "etc/\*" must be an absolute path`,
+	}, {
+		data:  `{"invalid-permissions": {"access": "read", "local": {"user-id": 42}, "permissions": [{"action": "read-logs", "resources": ["web-["]}]}}`,
+		error: `"read-logs" permission resource "web-\[" is not a valid pattern`,
 	}, {
 		data:  `{"invalid-access": {"access": "foo", "local": {"user-id": 42}}}`,
 		error: `invalid access value "foo", must be "admin", "read", "metrics", or "untrusted"`,
@@ -157,6 +215,10 @@ func (s *identitiesSuite) TestMarshalState(c *C) {
 			Access: state.AdminAccess,
 			Local:  &state.LocalIdentity{UserID: 1000},
 		},
+		"olivia": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: "930bbdc51b6aed5c2a5678fd6e28dee7a05e8a4b643cfc0b4427c3efb86c0d94"},
+		},
 	})
 	c.Assert(err, IsNil)
 
@@ -181,6 +243,12 @@ func (s *identitiesSuite) TestMarshalState(c *C) {
         "local": {
             "user-id": 1000
         }
+    },
+    "olivia": {
+        "access": "read",
+        "token": {
+            "hash": "930bbdc51b6aed5c2a5678fd6e28dee7a05e8a4b643cfc0b4427c3efb86c0d94"
+        }
     }
 }`[1:])
 }
@@ -204,6 +272,12 @@ func (s *identitiesSuite) TestUnmarshalState(c *C) {
             "local": {
                 "user-id": 1000
             }
+        },
+        "olivia": {
+            "access": "read",
+            "token": {
+                "hash": "930bbdc51b6aed5c2a5678fd6e28dee7a05e8a4b643cfc0b4427c3efb86c0d94"
+            }
         }
     }
 }`)
@@ -220,6 +294,11 @@ func (s *identitiesSuite) TestUnmarshalState(c *C) {
 			Access: state.AdminAccess,
 			Local:  &state.LocalIdentity{UserID: 1000},
 		},
+		"olivia": {
+			Name:   "olivia",
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: "930bbdc51b6aed5c2a5678fd6e28dee7a05e8a4b643cfc0b4427c3efb86c0d94"},
+		},
 	})
 }
 
@@ -303,7 +382,7 @@ func (s *identitiesSuite) TestAddIdentities(c *C) {
 			Access: "admin",
 		},
 	})
-	c.Assert(err, ErrorMatches, `identity "bill" invalid: identity must have at least one type \(\"local\" or \"basic\"\)`)
+	c.Assert(err, ErrorMatches, `identity "bill" invalid: identity must have at least one type \(\"local\", \"basic\", \"token\" or \"cert\"\)`)
 
 	// May have two types.
 	err = st.AddIdentities(map[string]*state.Identity{
@@ -485,7 +564,7 @@ func (s *identitiesSuite) TestReplaceIdentities(c *C) {
 			Access: "admin",
 		},
 	})
-	c.Assert(err, ErrorMatches, `identity "bill" invalid: identity must have at least one type \("local" or "basic"\)`)
+	c.Assert(err, ErrorMatches, `identity "bill" invalid: identity must have at least one type \("local", "basic", "token" or "cert"\)`)
 
 	// Ensure unique user ID testing is being done (full testing done in AddIdentity).
 	err = st.ReplaceIdentities(map[string]*state.Identity{
@@ -702,3 +781,479 @@ func (s *identitiesSuite) TestIdentityFromInputs(c *C) {
 	identity = st.IdentityFromInputs(&userID, "nancy-wrong-username", "wrong-password")
 	c.Assert(identity, IsNil)
 }
+
+func (s *identitiesSuite) TestIdentityFromToken(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	err := st.AddIdentities(map[string]*state.Identity{
+		"bob": {
+			Access: state.ReadAccess,
+			Local:  &state.LocalIdentity{UserID: 42},
+		},
+		"olivia": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("secret-token")},
+		},
+		"paul": {
+			Access: state.AdminAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("other-token")},
+		},
+	})
+	c.Assert(err, IsNil)
+
+	identity := st.IdentityFromToken("secret-token")
+	c.Assert(identity, NotNil)
+	c.Check(identity.Name, Equals, "olivia")
+
+	identity = st.IdentityFromToken("other-token")
+	c.Assert(identity, NotNil)
+	c.Check(identity.Name, Equals, "paul")
+
+	c.Check(st.IdentityFromToken("wrong-token"), IsNil)
+	c.Check(st.IdentityFromToken(""), IsNil)
+
+	// The token's hash itself isn't accepted as a token.
+	c.Check(st.IdentityFromToken(state.HashToken("secret-token")), IsNil)
+}
+
+func (s *identitiesSuite) TestUniqueTokens(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	err := st.AddIdentities(map[string]*state.Identity{
+		"olivia": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("secret-token")},
+		},
+		"paul": {
+			Access: state.AdminAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("other-token")},
+		},
+	})
+	c.Assert(err, IsNil)
+
+	// Another identity can't use the same token.
+	err = st.AddIdentities(map[string]*state.Identity{
+		"mary": {
+			Access: state.AdminAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("secret-token")},
+		},
+	})
+	c.Assert(err, ErrorMatches, `cannot have multiple identities with the same token \(mary, olivia\)`)
+
+	err = st.UpdateIdentities(map[string]*state.Identity{
+		"paul": {
+			Access: state.AdminAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("secret-token")},
+		},
+	})
+	c.Assert(err, ErrorMatches, `cannot have multiple identities with the same token \(olivia, paul\)`)
+
+	err = st.ReplaceIdentities(map[string]*state.Identity{
+		"mary": {
+			Access: state.AdminAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("other-token")},
+		},
+	})
+	c.Assert(err, ErrorMatches, `cannot have multiple identities with the same token \(mary, paul\)`)
+
+	err = st.RotateIdentities(map[string]*state.IdentityCredentials{
+		"paul": {Token: &state.TokenIdentity{Hash: state.HashToken("secret-token")}},
+	}, 0)
+	c.Assert(err, ErrorMatches, `cannot have multiple identities with the same token \(olivia, paul\)`)
+
+	// A previous token is still accepted during its grace period, so it
+	// can't be used by another identity either.
+	err = st.RotateIdentities(map[string]*state.IdentityCredentials{
+		"olivia": {Token: &state.TokenIdentity{Hash: state.HashToken("new-token")}},
+	}, time.Hour)
+	c.Assert(err, IsNil)
+	err = st.AddIdentities(map[string]*state.Identity{
+		"mary": {
+			Access: state.AdminAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("secret-token")},
+		},
+	})
+	c.Assert(err, ErrorMatches, `cannot have multiple identities with the same token \(mary, olivia\)`)
+
+	// Rotating an identity to its own previous token is fine.
+	err = st.RotateIdentities(map[string]*state.IdentityCredentials{
+		"olivia": {Token: &state.TokenIdentity{Hash: state.HashToken("secret-token")}},
+	}, time.Hour)
+	c.Assert(err, IsNil)
+
+	c.Check(st.IdentityFromToken("secret-token").Name, Equals, "olivia")
+	c.Check(st.IdentityFromToken("other-token").Name, Equals, "paul")
+}
+
+func (s *identitiesSuite) TestHashToken(c *C) {
+	c.Check(state.HashToken("secret-token"), Equals, "930bbdc51b6aed5c2a5678fd6e28dee7a05e8a4b643cfc0b4427c3efb86c0d94")
+}
+
+func (s *identitiesSuite) TestCertIdentity(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	cert := newTestCert(c, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+
+	// Unmarshal from the API format.
+	var identities map[string]*state.Identity
+	data, err := json.Marshal(map[string]any{
+		"controller": map[string]any{
+			"access": "admin",
+			"cert":   map[string]any{"pem": certPEM},
+		},
+	})
+	c.Assert(err, IsNil)
+	err = json.Unmarshal(data, &identities)
+	c.Assert(err, IsNil)
+	c.Assert(identities["controller"].Cert, NotNil)
+	c.Assert(identities["controller"].Cert.X509.Equal(cert), Equals, true)
+
+	err = st.AddIdentities(identities)
+	c.Assert(err, IsNil)
+
+	// The certificate is included in API responses.
+	data, err = json.Marshal(st.Identities())
+	c.Assert(err, IsNil)
+	var apiIdentities map[string]map[string]any
+	err = json.Unmarshal(data, &apiIdentities)
+	c.Assert(err, IsNil)
+	c.Check(apiIdentities["controller"]["cert"], DeepEquals, map[string]any{"pem": certPEM})
+
+	// The certificate is persisted in state.
+	data, err = json.Marshal(st)
+	c.Assert(err, IsNil)
+	st2 := state.New(nil)
+	st2.Lock()
+	err = json.Unmarshal(data, &st2)
+	st2.Unlock()
+	c.Assert(err, IsNil)
+	st2.Lock()
+	identity := st2.IdentityFromCert(cert)
+	st2.Unlock()
+	c.Assert(identity, NotNil)
+	c.Check(identity.Name, Equals, "controller")
+	c.Check(identity.Access, Equals, state.AdminAccess)
+
+	// Another identity can't use the same certificate.
+	err = st.AddIdentities(map[string]*state.Identity{
+		"other": {
+			Access: state.ReadAccess,
+			Cert:   &state.CertIdentity{X509: cert},
+		},
+	})
+	c.Assert(err, ErrorMatches, `cannot have multiple identities with the same certificate \(SHA-256 [0-9a-f]{64}: controller, other\)`)
+}
+
+func (s *identitiesSuite) TestIdentityFromCert(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	now := time.Now()
+	cert := newTestCert(c, now.Add(-time.Hour), now.Add(time.Hour))
+	expired := newTestCert(c, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	unknown := newTestCert(c, now.Add(-time.Hour), now.Add(time.Hour))
+
+	err := st.AddIdentities(map[string]*state.Identity{
+		"controller": {
+			Access: state.AdminAccess,
+			Cert:   &state.CertIdentity{X509: cert},
+		},
+		"old": {
+			Access: state.ReadAccess,
+			Cert:   &state.CertIdentity{X509: expired},
+		},
+	})
+	c.Assert(err, IsNil)
+
+	identity := st.IdentityFromCert(cert)
+	c.Assert(identity, NotNil)
+	c.Check(identity.Name, Equals, "controller")
+
+	c.Check(st.IdentityFromCert(expired), IsNil)
+	c.Check(st.IdentityFromCert(unknown), IsNil)
+	c.Check(st.IdentityFromCert(nil), IsNil)
+}
+
+// newTestCert returns a new self-signed client certificate valid between the
+// given times.
+func (s *identitiesSuite) TestExpiry(c *C) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	restore := state.FakeTime(now)
+	defer restore()
+
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	// Identities can't be added already expired.
+	err := st.AddIdentities(map[string]*state.Identity{
+		"bob": {
+			Access:    state.ReadAccess,
+			Local:     &state.LocalIdentity{UserID: 42},
+			ExpiresAt: now,
+		},
+	})
+	c.Assert(err, ErrorMatches, `identity "bob" invalid: expires-at 2026-01-02T03:04:05Z must be in the future`)
+
+	err = st.AddIdentities(map[string]*state.Identity{
+		"bob": {
+			Access:    state.ReadAccess,
+			Local:     &state.LocalIdentity{UserID: 42},
+			ExpiresAt: now.Add(time.Hour),
+		},
+	})
+	c.Assert(err, IsNil)
+	bob := st.Identities()["bob"]
+	c.Check(bob.Expired(), Equals, false)
+
+	data, err := json.Marshal(bob)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, `{"access":"read","local":{"user-id":42},"expires-at":"2026-01-02T04:04:05Z"}`)
+	var unmarshalled state.Identity
+	err = json.Unmarshal(data, &unmarshalled)
+	c.Assert(err, IsNil)
+	c.Check(unmarshalled.ExpiresAt.Equal(bob.ExpiresAt), Equals, true)
+
+	restore = state.FakeTime(now.Add(time.Hour))
+	defer restore()
+	c.Check(bob.Expired(), Equals, true)
+}
+
+func (s *identitiesSuite) TestRotateIdentities(c *C) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	restore := state.FakeTime(now)
+	defer restore()
+
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	err := st.AddIdentities(map[string]*state.Identity{
+		"bob": {
+			Access: state.ReadAccess,
+			Local:  &state.LocalIdentity{UserID: 42},
+		},
+		"nancy": {
+			Access: state.MetricsAccess,
+			Basic: &state.BasicIdentity{
+				// password: test
+				Password: "$6$F9cFSVEKyO4gB1Wh$8S1BSKsNkF.jBAixGc4W7l80OpfCNk65LZBDHBng3NAmbcHuMj4RIm7992rrJ8YA.SJ0hvm.vGk2z483am4Ym1",
+			},
+		},
+		"olivia": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("old-token")},
+		},
+	})
+	c.Assert(err, IsNil)
+
+	err = st.RotateIdentities(map[string]*state.IdentityCredentials{
+		"nancy": {Basic: &state.BasicIdentity{
+			// password: test2
+			Password: "$6$abcdefgh12345678$IVIDPdrJcDp6reNLgSebLCRpEm/3eTf8V2mfLFwncYb3JcxJOvUuG0AaJb6aBg3Lb1Y1fepbUzBG6LN1UKw6r/",
+		}},
+		"olivia": {Token: &state.TokenIdentity{Hash: state.HashToken("new-token")}},
+	}, time.Hour)
+	c.Assert(err, IsNil)
+
+	// Both the old and new credentials are valid during the grace period.
+	c.Check(st.IdentityFromInputs(nil, "nancy", "test"), NotNil)
+	c.Check(st.IdentityFromInputs(nil, "nancy", "test2"), NotNil)
+	c.Check(st.IdentityFromInputs(nil, "nancy", "wrong"), IsNil)
+	c.Check(st.IdentityFromToken("old-token"), NotNil)
+	c.Check(st.IdentityFromToken("new-token"), NotNil)
+
+	data, err := json.Marshal(st.Identities()["olivia"])
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, `{"access":"read","token":{"hash":"*****","previous-expires-at":"2026-01-02T04:04:05Z"}}`)
+
+	// Only the new credentials are valid after the grace period.
+	restore = state.FakeTime(now.Add(time.Hour))
+	defer restore()
+	c.Check(st.IdentityFromInputs(nil, "nancy", "test"), IsNil)
+	c.Check(st.IdentityFromInputs(nil, "nancy", "test2"), NotNil)
+	c.Check(st.IdentityFromToken("old-token"), IsNil)
+	c.Check(st.IdentityFromToken("new-token"), NotNil)
+
+	// Without a grace period, the old credentials are invalid immediately.
+	err = st.RotateIdentities(map[string]*state.IdentityCredentials{
+		"olivia": {Token: &state.TokenIdentity{Hash: state.HashToken("newer-token")}},
+	}, 0)
+	c.Assert(err, IsNil)
+	c.Check(st.IdentityFromToken("new-token"), IsNil)
+	c.Check(st.IdentityFromToken("newer-token"), NotNil)
+}
+
+func (s *identitiesSuite) TestRotateIdentitiesErrors(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	err := st.AddIdentities(map[string]*state.Identity{
+		"bob": {
+			Access: state.ReadAccess,
+			Local:  &state.LocalIdentity{UserID: 42},
+		},
+		"olivia": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("old-token")},
+		},
+	})
+	c.Assert(err, IsNil)
+
+	tests := []struct {
+		credentials map[string]*state.IdentityCredentials
+		gracePeriod time.Duration
+		error       string
+	}{{
+		credentials: map[string]*state.IdentityCredentials{
+			"olivia": {Token: &state.TokenIdentity{Hash: state.HashToken("new-token")}},
+		},
+		gracePeriod: -time.Second,
+		error:       "grace period must not be negative",
+	}, {
+		credentials: map[string]*state.IdentityCredentials{
+			"mary": {Token: &state.TokenIdentity{Hash: state.HashToken("new-token")}},
+			"nick": {Token: &state.TokenIdentity{Hash: state.HashToken("new-token")}},
+		},
+		error: "identities do not exist: mary, nick",
+	}, {
+		credentials: map[string]*state.IdentityCredentials{
+			"olivia": {},
+		},
+		error: `identity "olivia" must specify "basic" or "token" credentials to rotate`,
+	}, {
+		credentials: map[string]*state.IdentityCredentials{
+			"bob": {Token: &state.TokenIdentity{Hash: state.HashToken("new-token")}},
+		},
+		error: `identity "bob" has no token credentials to rotate`,
+	}, {
+		credentials: map[string]*state.IdentityCredentials{
+			"olivia": {Basic: &state.BasicIdentity{Password: "hash"}},
+		},
+		error: `identity "olivia" has no basic credentials to rotate`,
+	}, {
+		credentials: map[string]*state.IdentityCredentials{
+			"olivia": {Token: &state.TokenIdentity{Hash: "bad"}},
+		},
+		error: `identity "olivia" invalid: token identity must specify hash \(hex-encoded SHA-256\)`,
+	}}
+	for _, test := range tests {
+		err := st.RotateIdentities(test.credentials, test.gracePeriod)
+		c.Check(err, ErrorMatches, test.error)
+	}
+
+	// Failed rotations don't change anything.
+	c.Check(st.IdentityFromToken("old-token"), NotNil)
+	c.Check(st.Identities()["olivia"].Token.PreviousHash, Equals, "")
+}
+
+func (s *identitiesSuite) TestPruneIdentities(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	// Add identities that expire (or whose previous token expires) an hour
+	// before the real time Prune uses.
+	past := time.Now().Add(-time.Hour)
+	restore := state.FakeTime(past.Add(-time.Hour))
+	defer restore()
+	err := st.AddIdentities(map[string]*state.Identity{
+		"bob": {
+			Access:    state.ReadAccess,
+			Local:     &state.LocalIdentity{UserID: 42},
+			ExpiresAt: past,
+		},
+		"mary": {
+			Access:    state.AdminAccess,
+			Local:     &state.LocalIdentity{UserID: 1000},
+			ExpiresAt: past.Add(24 * time.Hour),
+		},
+		"olivia": {
+			Access: state.ReadAccess,
+			Token:  &state.TokenIdentity{Hash: state.HashToken("old-token")},
+		},
+	})
+	c.Assert(err, IsNil)
+	err = st.RotateIdentities(map[string]*state.IdentityCredentials{
+		"olivia": {Token: &state.TokenIdentity{Hash: state.HashToken("new-token")}},
+	}, time.Hour)
+	c.Assert(err, IsNil)
+	restore()
+
+	st.Prune(time.Now(), time.Hour, time.Hour, 100, 100)
+
+	identities := st.Identities()
+	c.Check(identities["bob"], IsNil)
+	c.Check(identities["mary"], NotNil)
+	c.Assert(identities["olivia"], NotNil)
+	c.Check(identities["olivia"].Token.PreviousHash, Equals, "")
+
+	notices := st.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.WarningNotice}})
+	c.Assert(notices, HasLen, 1)
+	n := noticeToMap(c, notices[0])
+	c.Check(n["key"], Matches, `Identity "bob" expired at .* and was removed`)
+}
+
+func (s *identitiesSuite) TestMarshalStateExpiry(c *C) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	restore := state.FakeTime(now)
+	defer restore()
+
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	err := st.AddIdentities(map[string]*state.Identity{
+		"olivia": {
+			Access:    state.ReadAccess,
+			Token:     &state.TokenIdentity{Hash: state.HashToken("old-token")},
+			ExpiresAt: now.Add(24 * time.Hour),
+		},
+	})
+	c.Assert(err, IsNil)
+	err = st.RotateIdentities(map[string]*state.IdentityCredentials{
+		"olivia": {Token: &state.TokenIdentity{Hash: state.HashToken("new-token")}},
+	}, time.Hour)
+	c.Assert(err, IsNil)
+
+	data, err := json.Marshal(st)
+	c.Assert(err, IsNil)
+	st2, err := state.ReadState(nil, bytes.NewReader(data))
+	c.Assert(err, IsNil)
+	st2.Lock()
+	defer st2.Unlock()
+	olivia := st2.Identities()["olivia"]
+	c.Check(olivia.ExpiresAt.Equal(now.Add(24*time.Hour)), Equals, true)
+	c.Check(olivia.Token.Hash, Equals, state.HashToken("new-token"))
+	c.Check(olivia.Token.PreviousHash, Equals, state.HashToken("old-token"))
+	c.Check(olivia.Token.PreviousExpiresAt.Equal(now.Add(time.Hour)), Equals, true)
+}
+
+func newTestCert(c *C, notBefore, notAfter time.Time) *x509.Certificate {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	c.Assert(err, IsNil)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+	c.Assert(err, IsNil)
+	cert, err := x509.ParseCertificate(der)
+	c.Assert(err, IsNil)
+	return cert
+}
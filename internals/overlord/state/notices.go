@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"sort"
 	"strconv"
 	"time"
@@ -84,6 +85,12 @@ type Notice struct {
 	// The repeatAfter duration must be less than this, because the notice
 	// won't be tracked after it expires.
 	expireAfter time.Duration
+
+	// The last-repeated time of the notice when each user acknowledged it,
+	// keyed by user ID. A notice is acknowledged by a user until it repeats.
+	// This is persisted in state separately from the notice itself, so it's
+	// not exposed when the notice is marshalled for the API.
+	acknowledged map[uint32]time.Time
 }
 
 func (n *Notice) String() string {
@@ -102,6 +109,23 @@ func (n *Notice) UserID() (userID uint32, isSet bool) {
 	return flattenUserID(n.userID)
 }
 
+// Type returns the notice's type.
+func (n *Notice) Type() NoticeType {
+	return n.noticeType
+}
+
+// LastRepeated returns the time the notice was last repeated. Notices and
+// WaitNotices return notices ordered by this time, and the "after" filter
+// compares against it.
+func (n *Notice) LastRepeated() time.Time {
+	return n.lastRepeated
+}
+
+// LastData returns a copy of the data from the notice's last occurrence.
+func (n *Notice) LastData() map[string]string {
+	return maps.Clone(n.lastData)
+}
+
 func flattenUserID(userID *uint32) (uid uint32, isSet bool) {
 	if userID == nil {
 		return 0, false
@@ -197,11 +221,20 @@ const (
 	// Warnings are a subset of notices where the key is a human-readable
 	// warning message.
 	WarningNotice NoticeType = "warning"
+
+	// Recorded whenever a service's status changes, for example when it
+	// becomes active, enters backoff, or exits. The key for service-status
+	// notices is the service name.
+	ServiceStatusNotice NoticeType = "service-status"
+
+	// Recorded whenever a check goes up or down. The key for check-status
+	// notices is the check name.
+	CheckStatusNotice NoticeType = "check-status"
 )
 
 func (t NoticeType) Valid() bool {
 	switch t {
-	case ChangeUpdateNotice, CustomNotice, WarningNotice:
+	case ChangeUpdateNotice, CustomNotice, WarningNotice, ServiceStatusNotice, CheckStatusNotice:
 		return true
 	}
 	return false
@@ -324,6 +357,9 @@ type NoticeFilter struct {
 	// UserID, if set, includes only notices that have this user ID or are public.
 	UserID *uint32
 
+	// PublicOnly, if true, includes only public notices (those without a user ID).
+	PublicOnly bool
+
 	// Types, if not empty, includes only notices whose type is one of these.
 	Types []NoticeType
 
@@ -332,6 +368,13 @@ type NoticeFilter struct {
 
 	// After, if set, includes only notices that were last repeated after this time.
 	After time.Time
+
+	// Before, if set, includes only notices that were last repeated before this time.
+	Before time.Time
+
+	// UnacknowledgedBy, if set, includes only notices that this user hasn't
+	// acknowledged since they were last repeated.
+	UnacknowledgedBy *uint32
 }
 
 // matches reports whether the notice n matches this filter
@@ -342,6 +385,9 @@ func (f *NoticeFilter) matches(n *Notice) bool {
 	if f.UserID != nil && !(n.userID == nil || *f.UserID == *n.userID) {
 		return false
 	}
+	if f.PublicOnly && n.userID != nil {
+		return false
+	}
 	// Can't use strutil.ListContains as Types is []NoticeType, not []string
 	if len(f.Types) > 0 && !sliceContains(f.Types, n.noticeType) {
 		return false
@@ -352,9 +398,22 @@ func (f *NoticeFilter) matches(n *Notice) bool {
 	if !f.After.IsZero() && !n.lastRepeated.After(f.After) {
 		return false
 	}
+	if !f.Before.IsZero() && !n.lastRepeated.Before(f.Before) {
+		return false
+	}
+	if f.UnacknowledgedBy != nil && n.acknowledgedBy(*f.UnacknowledgedBy) {
+		return false
+	}
 	return true
 }
 
+// acknowledgedBy reports whether the given user has acknowledged this notice
+// since it was last repeated.
+func (n *Notice) acknowledgedBy(userID uint32) bool {
+	acknowledged, ok := n.acknowledged[userID]
+	return ok && !n.lastRepeated.After(acknowledged)
+}
+
 func sliceContains[T comparable](haystack []T, needle T) bool {
 	for _, v := range haystack {
 		if v == needle {
@@ -403,6 +462,36 @@ func (s *State) Notice(id string) *Notice {
 	return nil
 }
 
+// AcknowledgeNotices records that the given user has acknowledged the notices
+// that match the filter, so they're excluded by a filter's UnacknowledgedBy
+// field until they repeat. It returns the number of notices acknowledged.
+func (s *State) AcknowledgeNotices(userID uint32, filter *NoticeFilter) int {
+	s.writing()
+
+	notices := s.flattenNotices(filter)
+	for _, n := range notices {
+		if n.acknowledged == nil {
+			n.acknowledged = make(map[uint32]time.Time)
+		}
+		n.acknowledged[userID] = n.lastRepeated
+	}
+	return len(notices)
+}
+
+// DeleteNotice deletes the notice with the given ID, reporting whether it
+// was found.
+func (s *State) DeleteNotice(id string) bool {
+	s.writing()
+
+	for k, n := range s.notices {
+		if n.id == id {
+			delete(s.notices, k)
+			return true
+		}
+	}
+	return false
+}
+
 func (s *State) flattenNotices(filter *NoticeFilter) []*Notice {
 	now := time.Now()
 	var notices []*Notice
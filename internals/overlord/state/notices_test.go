@@ -253,6 +253,26 @@ func (s *noticesSuite) TestNoticesFilterUserID(c *C) {
 	c.Check(n["key"], Equals, "Warning 2!")
 }
 
+func (s *noticesSuite) TestNoticesFilterPublicOnly(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	uid := uint32(1000)
+	addNotice(c, st, &uid, state.CustomNotice, "foo.com/private", nil)
+	time.Sleep(time.Microsecond)
+	addNotice(c, st, nil, state.CustomNotice, "foo.com/public", nil)
+
+	notices := st.Notices(&state.NoticeFilter{PublicOnly: true})
+	c.Assert(notices, HasLen, 1)
+	n := noticeToMap(c, notices[0])
+	c.Check(n["user-id"], IsNil)
+	c.Check(n["key"], Equals, "foo.com/public")
+
+	// Combined with a user ID, only public notices are included.
+	notices = st.Notices(&state.NoticeFilter{UserID: &uid, PublicOnly: true})
+	c.Assert(notices, HasLen, 1)
+}
 func (s *noticesSuite) TestNoticesFilterType(c *C) {
 	st := state.New(nil)
 	st.Lock()
@@ -373,6 +393,108 @@ func (s *noticesSuite) TestNoticesFilterAfter(c *C) {
 	c.Check(n["key"], Equals, "foo.com/y")
 }
 
+func (s *noticesSuite) TestNoticesFilterBefore(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	addNotice(c, st, nil, state.CustomNotice, "foo.com/x", nil)
+	time.Sleep(time.Microsecond)
+	addNotice(c, st, nil, state.CustomNotice, "foo.com/y", nil)
+	notices := st.Notices(nil)
+	c.Assert(notices, HasLen, 2)
+
+	notices = st.Notices(&state.NoticeFilter{Before: notices[1].LastRepeated()})
+	c.Assert(notices, HasLen, 1)
+	n := noticeToMap(c, notices[0])
+	c.Check(n["key"], Equals, "foo.com/x")
+}
+
+func (s *noticesSuite) TestAcknowledgeNotices(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	uid1 := uint32(1000)
+	uid2 := uint32(1001)
+	addNotice(c, st, nil, state.CustomNotice, "foo.com/x", nil)
+	time.Sleep(time.Microsecond)
+	addNotice(c, st, nil, state.WarningNotice, "danger", nil)
+	time.Sleep(time.Microsecond)
+	addNotice(c, st, &uid1, state.CustomNotice, "foo.com/y", nil)
+
+	// Acknowledging is restricted by the filter.
+	count := st.AcknowledgeNotices(uid1, &state.NoticeFilter{
+		UserID: &uid1,
+		Types:  []state.NoticeType{state.CustomNotice},
+	})
+	c.Check(count, Equals, 2)
+	c.Check(noticeKeys(st.Notices(&state.NoticeFilter{UnacknowledgedBy: &uid1})), DeepEquals, []string{"danger"})
+
+	// Acknowledgements are per user.
+	c.Check(noticeKeys(st.Notices(&state.NoticeFilter{UnacknowledgedBy: &uid2})), DeepEquals,
+		[]string{"foo.com/x", "danger", "foo.com/y"})
+
+	// Acknowledging again is idempotent.
+	count = st.AcknowledgeNotices(uid1, &state.NoticeFilter{UserID: &uid1, Keys: []string{"foo.com/x"}})
+	c.Check(count, Equals, 1)
+
+	// A notice that repeats is unacknowledged again.
+	time.Sleep(time.Microsecond)
+	addNotice(c, st, nil, state.CustomNotice, "foo.com/x", nil)
+	c.Check(noticeKeys(st.Notices(&state.NoticeFilter{UnacknowledgedBy: &uid1})), DeepEquals,
+		[]string{"danger", "foo.com/x"})
+
+	// Acknowledgements aren't included in the notice's JSON.
+	notices := st.Notices(&state.NoticeFilter{Keys: []string{"foo.com/y"}})
+	c.Assert(notices, HasLen, 1)
+	c.Check(noticeToMap(c, notices[0])["acknowledged"], IsNil)
+}
+
+func (s *noticesSuite) TestAcknowledgeNoticesPersisted(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	uid := uint32(1000)
+	addNotice(c, st, nil, state.CustomNotice, "foo.com/x", nil)
+	addNotice(c, st, nil, state.CustomNotice, "foo.com/y", nil)
+	st.AcknowledgeNotices(uid, &state.NoticeFilter{Keys: []string{"foo.com/x"}})
+
+	data, err := json.Marshal(st)
+	c.Assert(err, IsNil)
+	st2, err := state.ReadState(nil, bytes.NewReader(data))
+	c.Assert(err, IsNil)
+	st2.Lock()
+	defer st2.Unlock()
+	c.Check(noticeKeys(st2.Notices(&state.NoticeFilter{UnacknowledgedBy: &uid})), DeepEquals, []string{"foo.com/y"})
+}
+
+func (s *noticesSuite) TestDeleteNotice(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	addNotice(c, st, nil, state.CustomNotice, "foo.com/x", nil)
+	addNotice(c, st, nil, state.CustomNotice, "foo.com/y", nil)
+	notices := st.Notices(&state.NoticeFilter{Keys: []string{"foo.com/x"}})
+	c.Assert(notices, HasLen, 1)
+	id := noticeToMap(c, notices[0])["id"].(string)
+
+	c.Check(st.DeleteNotice(id), Equals, true)
+	c.Check(st.Notice(id), IsNil)
+	c.Check(noticeKeys(st.Notices(nil)), DeepEquals, []string{"foo.com/y"})
+	c.Check(st.DeleteNotice(id), Equals, false)
+
+	// A new occurrence after deletion creates a new notice.
+	addNotice(c, st, nil, state.CustomNotice, "foo.com/x", nil)
+	notices = st.Notices(&state.NoticeFilter{Keys: []string{"foo.com/x"}})
+	c.Assert(notices, HasLen, 1)
+	n := noticeToMap(c, notices[0])
+	c.Check(n["id"], Not(Equals), id)
+	c.Check(n["occurrences"], Equals, 1.0)
+}
+
 func (s *noticesSuite) TestNotice(c *C) {
 	st := state.New(nil)
 	st.Lock()
@@ -672,3 +794,16 @@ func addNotice(c *C, st *state.State, userID *uint32, noticeType state.NoticeTyp
 	_, err := st.AddNotice(userID, noticeType, key, options)
 	c.Assert(err, IsNil)
 }
+
+func noticeKeys(notices []*state.Notice) []string {
+	keys := make([]string, len(notices))
+	for i, n := range notices {
+		var jn struct {
+			Key string `json:"key"`
+		}
+		data, _ := json.Marshal(n)
+		json.Unmarshal(data, &jn)
+		keys[i] = jn.Key
+	}
+	return keys
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Permission grants an identity fine-grained access to perform an action on
+// the resources that match any of its resource patterns.
+type Permission struct {
+	Action    PermissionAction
+	Resources []string
+}
+
+// PermissionAction is an action that a Permission can grant.
+type PermissionAction string
+
+const (
+	// ReadServicesPermission allows viewing the status of services.
+	ReadServicesPermission PermissionAction = "read-services"
+	// ManageServicesPermission allows starting, stopping, restarting and
+	// replanning services, and sending signals to them.
+	ManageServicesPermission PermissionAction = "manage-services"
+	// ReadLogsPermission allows reading the logs of services.
+	ReadLogsPermission PermissionAction = "read-logs"
+	// ReadChecksPermission allows viewing the status of health checks.
+	ReadChecksPermission PermissionAction = "read-checks"
+	// ManageChecksPermission allows starting, stopping and refreshing
+	// health checks.
+	ManageChecksPermission PermissionAction = "manage-checks"
+	// ReadFilesPermission allows listing and reading files.
+	ReadFilesPermission PermissionAction = "read-files"
+	// WriteFilesPermission allows writing files, making directories and
+	// removing paths.
+	WriteFilesPermission PermissionAction = "write-files"
+	// ExecPermission allows executing commands.
+	ExecPermission PermissionAction = "exec"
+)
+
+var permissionActions = []PermissionAction{
+	ReadServicesPermission,
+	ManageServicesPermission,
+	ReadLogsPermission,
+	ReadChecksPermission,
+	ManageChecksPermission,
+	ReadFilesPermission,
+	WriteFilesPermission,
+	ExecPermission,
+}
+
+// isPathAction reports whether the action's resources are absolute paths
+// (file paths, or executable paths for exec) rather than names.
+func (a PermissionAction) isPathAction() bool {
+	switch a {
+	case ReadFilesPermission, WriteFilesPermission, ExecPermission:
+		return true
+	}
+	return false
+}
+
+// validate checks that the permission's action and resource patterns are
+// valid, returning an error if not.
+func (p *Permission) validate() error {
+	if !slices.Contains(permissionActions, p.Action) {
+		actions := make([]string, len(permissionActions))
+		for i, action := range permissionActions {
+			actions[i] = fmt.Sprintf("%q", action)
+		}
+		return fmt.Errorf("invalid permission action %q, must be one of %s",
+			p.Action, strings.Join(actions, ", "))
+	}
+	if len(p.Resources) == 0 {
+		return fmt.Errorf("%q permission must specify at least one resource", p.Action)
+	}
+	for _, pattern := range p.Resources {
+		if pattern == "" {
+			return fmt.Errorf("%q permission resource must not be empty", p.Action)
+		}
+		if p.Action.isPathAction() && !path.IsAbs(pattern) {
+			return fmt.Errorf("%q permission resource %q must be an absolute path", p.Action, pattern)
+		}
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+			return fmt.Errorf("%q permission resource %q is not a valid pattern", p.Action, pattern)
+		}
+	}
+	return nil
+}
+
+// Allows reports whether the permission allows the given action on the
+// given resource.
+//
+// Resource patterns use path.Match syntax, so "*" matches all service or
+// check names. For file and exec permissions, a pattern ending in "/**"
+// matches the directory and everything under it, at any depth. Paths should
+// be cleaned (with path.Clean) before calling Allows.
+func (p *Permission) Allows(action PermissionAction, resource string) bool {
+	if p.Action != action {
+		return false
+	}
+	for _, pattern := range p.Resources {
+		if matchResource(pattern, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchResource(pattern, resource string) bool {
+	dir, recursive := strings.CutSuffix(pattern, "/**")
+	if !recursive {
+		matched, _ := path.Match(pattern, resource)
+		return matched
+	}
+	if dir == "" {
+		// Pattern is "/**", which matches every absolute path.
+		return path.IsAbs(resource)
+	}
+	for p := resource; p != "/" && p != "."; p = path.Dir(p) {
+		if matched, _ := path.Match(dir, p); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Canonical Ltd
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3 as
+// published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package state_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/canonical/pebble/internals/overlord/state"
+)
+
+type permissionsSuite struct{}
+
+var _ = Suite(&permissionsSuite{})
+
+func (s *permissionsSuite) TestAllows(c *C) {
+	tests := []struct {
+		permission state.Permission
+		action     state.PermissionAction
+		resource   string
+		allowed    bool
+	}{
+		// Names use glob patterns.
+		{state.Permission{Action: state.ReadLogsPermission, Resources: []string{"*"}}, state.ReadLogsPermission, "web", true},
+		{state.Permission{Action: state.ReadLogsPermission, Resources: []string{"web-*"}}, state.ReadLogsPermission, "web-1", true},
+		{state.Permission{Action: state.ReadLogsPermission, Resources: []string{"web-*"}}, state.ReadLogsPermission, "db", false},
+		{state.Permission{Action: state.ReadLogsPermission, Resources: []string{"db", "web"}}, state.ReadLogsPermission, "web", true},
+		// The action must match.
+		{state.Permission{Action: state.ReadLogsPermission, Resources: []string{"*"}}, state.ManageServicesPermission, "web", false},
+		// Paths use glob patterns, with "/**" matching a whole tree.
+		{state.Permission{Action: state.ReadFilesPermission, Resources: []string{"/etc/*.conf"}}, state.ReadFilesPermission, "/etc/app.conf", true},
+		{state.Permission{Action: state.ReadFilesPermission, Resources: []string{"/etc/*.conf"}}, state.ReadFilesPermission, "/etc/app/app.conf", false},
+		{state.Permission{Action: state.ReadFilesPermission, Resources: []string{"/srv/**"}}, state.ReadFilesPermission, "/srv", true},
+		{state.Permission{Action: state.ReadFilesPermission, Resources: []string{"/srv/**"}}, state.ReadFilesPermission, "/srv/app/data/x", true},
+		{state.Permission{Action: state.ReadFilesPermission, Resources: []string{"/srv/**"}}, state.ReadFilesPermission, "/srvx", false},
+		{state.Permission{Action: state.ReadFilesPermission, Resources: []string{"/srv/*/logs/**"}}, state.ReadFilesPermission, "/srv/app/logs/x.log", true},
+		{state.Permission{Action: state.ReadFilesPermission, Resources: []string{"/srv/*/logs/**"}}, state.ReadFilesPermission, "/srv/app/data/x", false},
+		{state.Permission{Action: state.WriteFilesPermission, Resources: []string{"/**"}}, state.WriteFilesPermission, "/", true},
+		{state.Permission{Action: state.WriteFilesPermission, Resources: []string{"/**"}}, state.WriteFilesPermission, "/a/b", true},
+		{state.Permission{Action: state.ExecPermission, Resources: []string{"/usr/bin/ls"}}, state.ExecPermission, "/usr/bin/ls", true},
+		{state.Permission{Action: state.ExecPermission, Resources: []string{"/usr/bin/ls"}}, state.ExecPermission, "/usr/bin/rm", false},
+	}
+	for _, test := range tests {
+		c.Logf("Permission %v, action %q, resource %q", test.permission, test.action, test.resource)
+		c.Check(test.permission.Allows(test.action, test.resource), Equals, test.allowed)
+	}
+}
+
+func (s *permissionsSuite) TestMarshalAPI(c *C) {
+	identity := &state.Identity{
+		Access: state.ReadAccess,
+		Local:  &state.LocalIdentity{UserID: 42},
+		Permissions: []state.Permission{
+			{Action: state.ManageServicesPermission, Resources: []string{"web"}},
+			{Action: state.ReadLogsPermission, Resources: []string{"web", "db-*"}},
+		},
+	}
+	data, err := json.Marshal(identity)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, `{"access":"read","local":{"user-id":42},"permissions":[{"action":"manage-services","resources":["web"]},{"action":"read-logs","resources":["web","db-*"]}]}`)
+
+	var unmarshalled state.Identity
+	err = json.Unmarshal(data, &unmarshalled)
+	c.Assert(err, IsNil)
+	c.Check(unmarshalled.Permissions, DeepEquals, identity.Permissions)
+}
+
+func (s *permissionsSuite) TestMarshalState(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	permissions := []state.Permission{
+		{Action: state.ReadFilesPermission, Resources: []string{"/srv/**"}},
+		{Action: state.ExecPermission, Resources: []string{"/usr/bin/ls"}},
+	}
+	err := st.AddIdentities(map[string]*state.Identity{
+		"bob": {
+			Access:      state.AdminAccess,
+			Local:       &state.LocalIdentity{UserID: 42},
+			Permissions: permissions,
+		},
+	})
+	c.Assert(err, IsNil)
+
+	data, err := json.Marshal(st)
+	c.Assert(err, IsNil)
+	st2, err := state.ReadState(nil, bytes.NewReader(data))
+	c.Assert(err, IsNil)
+	st2.Lock()
+	defer st2.Unlock()
+	c.Check(st2.Identities()["bob"].Permissions, DeepEquals, permissions)
+}
@@ -159,11 +159,12 @@ func (s *State) unlock() {
 }
 
 type marshalledState struct {
-	Data       map[string]*json.RawMessage    `json:"data"`
-	Changes    map[string]*Change             `json:"changes"`
-	Tasks      map[string]*Task               `json:"tasks"`
-	Notices    []*Notice                      `json:"notices,omitempty"`
-	Identities map[string]*marshalledIdentity `json:"identities,omitempty"`
+	Data       map[string]*json.RawMessage     `json:"data"`
+	Changes    map[string]*Change              `json:"changes"`
+	Tasks      map[string]*Task                `json:"tasks"`
+	Notices    []*Notice                       `json:"notices,omitempty"`
+	NoticeAcks map[string]map[uint32]time.Time `json:"notice-acks,omitempty"`
+	Identities map[string]*marshalledIdentity  `json:"identities,omitempty"`
 
 	LastChangeId int `json:"last-change-id"`
 	LastTaskId   int `json:"last-task-id"`
@@ -177,6 +178,11 @@ type marshalledIdentity struct {
 	Access string                   `json:"access"`
 	Local  *marshalledLocalIdentity `json:"local,omitempty"`
 	Basic  *marshalledBasicIdentity `json:"basic,omitempty"`
+	Token  *marshalledTokenIdentity `json:"token,omitempty"`
+	Cert   *marshalledCertIdentity  `json:"cert,omitempty"`
+
+	Permissions []marshalledPermission `json:"permissions,omitempty"`
+	ExpiresAt   *time.Time             `json:"expires-at,omitempty"`
 }
 
 type marshalledLocalIdentity struct {
@@ -184,7 +190,24 @@ type marshalledLocalIdentity struct {
 }
 
 type marshalledBasicIdentity struct {
-	Password string `json:"password"`
+	Password          string     `json:"password"`
+	PreviousPassword  string     `json:"previous-password,omitempty"`
+	PreviousExpiresAt *time.Time `json:"previous-expires-at,omitempty"`
+}
+
+type marshalledTokenIdentity struct {
+	Hash              string     `json:"hash"`
+	PreviousHash      string     `json:"previous-hash,omitempty"`
+	PreviousExpiresAt *time.Time `json:"previous-expires-at,omitempty"`
+}
+
+type marshalledCertIdentity struct {
+	PEM string `json:"pem"`
+}
+
+type marshalledPermission struct {
+	Action    string   `json:"action"`
+	Resources []string `json:"resources"`
 }
 
 // MarshalJSON makes State a json.Marshaller
@@ -195,6 +218,7 @@ func (s *State) MarshalJSON() ([]byte, error) {
 		Changes:    s.changes,
 		Tasks:      s.tasks,
 		Notices:    s.flattenNotices(nil),
+		NoticeAcks: s.noticeAcks(),
 		Identities: s.marshalledIdentities(),
 
 		LastTaskId:   s.lastTaskId,
@@ -204,6 +228,31 @@ func (s *State) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// noticeAcks returns the notice acknowledgements to persist, keyed by notice
+// ID and then user ID.
+func (s *State) noticeAcks() map[string]map[uint32]time.Time {
+	var acks map[string]map[uint32]time.Time
+	for _, n := range s.notices {
+		if len(n.acknowledged) == 0 {
+			continue
+		}
+		if acks == nil {
+			acks = make(map[string]map[uint32]time.Time)
+		}
+		acks[n.id] = n.acknowledged
+	}
+	return acks
+}
+
+func (s *State) restoreNoticeAcks(acks map[string]map[uint32]time.Time) {
+	if len(acks) == 0 {
+		return
+	}
+	for _, n := range s.notices {
+		n.acknowledged = acks[n.id]
+	}
+}
+
 func (s *State) marshalledIdentities() map[string]*marshalledIdentity {
 	marshalled := make(map[string]*marshalledIdentity, len(s.identities))
 	for name, identity := range s.identities {
@@ -215,6 +264,29 @@ func (s *State) marshalledIdentities() map[string]*marshalledIdentity {
 		}
 		if identity.Basic != nil {
 			marshalled[name].Basic = &marshalledBasicIdentity{Password: identity.Basic.Password}
+			if identity.Basic.PreviousPassword != "" {
+				marshalled[name].Basic.PreviousPassword = identity.Basic.PreviousPassword
+				marshalled[name].Basic.PreviousExpiresAt = &identity.Basic.PreviousExpiresAt
+			}
+		}
+		if identity.Token != nil {
+			marshalled[name].Token = &marshalledTokenIdentity{Hash: identity.Token.Hash}
+			if identity.Token.PreviousHash != "" {
+				marshalled[name].Token.PreviousHash = identity.Token.PreviousHash
+				marshalled[name].Token.PreviousExpiresAt = &identity.Token.PreviousExpiresAt
+			}
+		}
+		if identity.Cert != nil {
+			marshalled[name].Cert = &marshalledCertIdentity{PEM: encodeCertPEM(identity.Cert.X509)}
+		}
+		for _, permission := range identity.Permissions {
+			marshalled[name].Permissions = append(marshalled[name].Permissions, marshalledPermission{
+				Action:    string(permission.Action),
+				Resources: permission.Resources,
+			})
+		}
+		if !identity.ExpiresAt.IsZero() {
+			marshalled[name].ExpiresAt = &identity.ExpiresAt
 		}
 	}
 	return marshalled
@@ -232,6 +304,7 @@ func (s *State) UnmarshalJSON(data []byte) error {
 	s.changes = unmarshalled.Changes
 	s.tasks = unmarshalled.Tasks
 	s.unflattenNotices(unmarshalled.Notices)
+	s.restoreNoticeAcks(unmarshalled.NoticeAcks)
 	s.unmarshalIdentities(unmarshalled.Identities)
 	s.lastChangeId = unmarshalled.LastChangeId
 	s.lastTaskId = unmarshalled.LastTaskId
@@ -259,7 +332,41 @@ func (s *State) unmarshalIdentities(marshalled map[string]*marshalledIdentity) {
 			s.identities[name].Local = &LocalIdentity{UserID: mi.Local.UserID}
 		}
 		if mi.Basic != nil {
-			s.identities[name].Basic = &BasicIdentity{Password: mi.Basic.Password}
+			s.identities[name].Basic = &BasicIdentity{
+				Password:         mi.Basic.Password,
+				PreviousPassword: mi.Basic.PreviousPassword,
+			}
+			if mi.Basic.PreviousExpiresAt != nil {
+				s.identities[name].Basic.PreviousExpiresAt = *mi.Basic.PreviousExpiresAt
+			}
+		}
+		if mi.Token != nil {
+			s.identities[name].Token = &TokenIdentity{
+				Hash:         mi.Token.Hash,
+				PreviousHash: mi.Token.PreviousHash,
+			}
+			if mi.Token.PreviousExpiresAt != nil {
+				s.identities[name].Token.PreviousExpiresAt = *mi.Token.PreviousExpiresAt
+			}
+		}
+		if mi.Cert != nil {
+			cert, err := parseCertPEM(mi.Cert.PEM)
+			if err != nil {
+				// Don't fail to load state; the identity just can't be
+				// used for certificate authentication.
+				logger.Noticef("Cannot load certificate for identity %q: %v", name, err)
+			} else {
+				s.identities[name].Cert = &CertIdentity{X509: cert}
+			}
+		}
+		for _, mp := range mi.Permissions {
+			s.identities[name].Permissions = append(s.identities[name].Permissions, Permission{
+				Action:    PermissionAction(mp.Action),
+				Resources: mp.Resources,
+			})
+		}
+		if mi.ExpiresAt != nil {
+			s.identities[name].ExpiresAt = *mi.ExpiresAt
 		}
 	}
 }
@@ -574,6 +681,8 @@ NextChange:
 			delete(s.tasks, tid)
 		}
 	}
+	s.pruneIdentities(now)
+
 	// Prune expired notices, and update the latest warning time cache.
 	var latestWarningTime time.Time
 	for k, n := range s.notices {
@@ -58,3 +58,13 @@ func FakeTLSCertRenewWindow(d time.Duration) (restore func()) {
 }
 
 var DefaultCertSubject = defaultCertSubject
+
+// FakeCertCheckInterval fakes how often the user-supplied certificate files
+// are checked for changes.
+func FakeCertCheckInterval(d time.Duration) (restore func()) {
+	old := certCheckInterval
+	certCheckInterval = d
+	return func() {
+		certCheckInterval = old
+	}
+}
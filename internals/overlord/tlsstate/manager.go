@@ -36,6 +36,8 @@ import (
 	"time"
 
 	"github.com/canonical/pebble/cmd"
+	"github.com/canonical/pebble/internals/logger"
+	"github.com/canonical/pebble/internals/overlord/state"
 )
 
 var (
@@ -70,6 +72,31 @@ var (
 	// idCertFile is the public x509 certificate, which holds the
 	// identity public key, and self-signed with the identity key.
 	idCertFile = "identity.pem"
+
+	// certFile and keyFile optionally hold a user-supplied TLS certificate
+	// (followed by any intermediate certificates) and its private key. If
+	// present and valid, they're used instead of the generated certificate.
+	certFile = "cert.pem"
+	keyFile  = "key.pem"
+
+	// certCheckInterval defines how often the user-supplied certificate
+	// files are checked for changes, so that a renewed certificate is used
+	// without restarting the daemon.
+	certCheckInterval = 5 * time.Second
+
+	// certExpiryWarning defines how long before a user-supplied certificate
+	// expires that a warning is recorded.
+	certExpiryWarning = 14 * 24 * time.Hour
+)
+
+const (
+	// CertSourceGenerated means the TLS certificate is generated by the
+	// manager and signed by the identity key.
+	CertSourceGenerated = "generated"
+
+	// CertSourceUser means the TLS certificate was supplied by the user in
+	// the TLS directory.
+	CertSourceUser = "user"
 )
 
 // IDSigner includes a crypto.Signer, and expects the provided signer
@@ -83,6 +110,8 @@ type IDSigner interface {
 }
 
 type TLSManager struct {
+	state *state.State
+
 	// tlsDir is the location of the PEM keypair files.
 	tlsDir string
 	mu     sync.RWMutex
@@ -98,6 +127,21 @@ type TLSManager struct {
 	// supplied X509 templates (see SetX509Templates).
 	idTemplate  *x509.Certificate
 	tlsTemplate *x509.Certificate
+
+	// The user-supplied TLS certificate, if one has been loaded. It's kept
+	// when a changed certificate fails to load (for example, while it's
+	// being replaced), and userCertErr records the error.
+	userCert    *tls.Certificate
+	userCertErr error
+	// The modification times and sizes of the user-supplied certificate
+	// files when they were last loaded, and when they were last checked.
+	userCertStamp [2]fileStamp
+	lastCertCheck time.Time
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
 }
 
 // NewManager creates a new TLS keypair manager. The tlsDir must be a
@@ -106,14 +150,24 @@ type TLSManager struct {
 // represents the identity key of the machine, container or device. The
 // signer will be used to sign TLS keypairs and the identity certificate.
 // The identity certificate acts as the root CA.
-func NewManager(tlsDir string, signer IDSigner) *TLSManager {
+//
+// If the TLS directory contains a certificate file "cert.pem" and private
+// key file "key.pem", they're used instead, and reloaded when they change.
+func NewManager(st *state.State, tlsDir string, signer IDSigner) *TLSManager {
 	m := &TLSManager{
+		state:  st,
 		tlsDir: tlsDir,
 		signer: signer,
 	}
 	return m
 }
 
+// Fingerprint returns the fingerprint of the identity key, which clients use
+// to verify the server's identity.
+func (m *TLSManager) Fingerprint() string {
+	return m.signer.Fingerprint()
+}
+
 // SetX509Templates allows select fields of the certificates to be externally
 // supplied. This function must be called before any call to GetCertificate
 // otherwise templates will not be applied consistently. If this function
@@ -140,6 +194,12 @@ func (m *TLSManager) ListenConfig() *tls.Config {
 		NextProtos:     []string{"h2", "http/1.1"},
 		MinVersion:     tls.VersionTLS13,
 		GetCertificate: m.GetCertificate,
+		// Ask clients for a certificate, but don't require one or verify it
+		// against a CA: client certificates are typically self-signed, and
+		// the daemon authenticates them by matching them against "cert"
+		// identities. The handshake still proves the client holds the
+		// certificate's private key.
+		ClientAuth: tls.RequestClientCert,
 	}
 	return tlsConf
 }
@@ -149,14 +209,35 @@ func (m *TLSManager) ListenConfig() *tls.Config {
 // either the identity or TLS certificate nears expiry, this functions creates new
 // certificates on demand. Note that even if the identity certificate is re-created, this
 // does not mean that the identity key changed (the key itself has no expiry).
+//
+// If a valid user-supplied certificate is present in the TLS directory, it's
+// returned instead.
 func (m *TLSManager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
-	// Fast path: concurrent sessions while the ID and TLS certificate is valid.
+	// Fast path: concurrent sessions while the certificate is valid, and the
+	// user-supplied certificate files don't need checking for changes.
 	m.mu.RLock()
+	checkDue := timeNow().Sub(m.lastCertCheck) >= certCheckInterval
+	userCert := m.userCert
 	tlsCert := m.tlsCert
 	idCert := m.idCert
 	m.mu.RUnlock()
-	if idCert != nil && tlsCert != nil && isCertActive(tlsCert.Leaf) {
-		return tlsCert, nil
+	if !checkDue {
+		if userCert != nil && isCertActive(userCert.Leaf) {
+			return userCert, nil
+		}
+		if idCert != nil && tlsCert != nil && isCertActive(tlsCert.Leaf) {
+			return tlsCert, nil
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkUserCert()
+	if m.userCert != nil && isCertActive(m.userCert.Leaf) {
+		return m.userCert, nil
+	}
+	if m.idCert != nil && m.tlsCert != nil && isCertActive(m.tlsCert.Leaf) {
+		return m.tlsCert, nil
 	}
 
 	// Slow path: generate a new in-memory identity signed TLS certificate.
@@ -164,8 +245,9 @@ func (m *TLSManager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, err
 	// If we got here then it means we need to generate a new in-memory TLS
 	// keypair, and potentially an identity certificate (only the first time
 	// or when the identity key changed).
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	if m.signer == nil {
+		return nil, errors.New("cannot create TLS certificate without an identity key")
+	}
 	if err := m.createDir(); err != nil {
 		return nil, fmt.Errorf("cannot create TLS directory: %w", err)
 	}
@@ -178,11 +260,153 @@ func (m *TLSManager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, err
 	return m.tlsCert, nil
 }
 
-// Ensure implements StateManager.Ensure.
+// CertInfo describes the TLS certificate presented to clients.
+type CertInfo struct {
+	// Source is CertSourceGenerated or CertSourceUser.
+	Source    string
+	Subject   string
+	SANs      []string
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// CertInfo returns details of the TLS certificate currently presented to
+// clients, generating the certificate if necessary.
+func (m *TLSManager) CertInfo() (*CertInfo, error) {
+	cert, err := m.GetCertificate(nil)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	source := CertSourceGenerated
+	if cert == m.userCert {
+		source = CertSourceUser
+	}
+	m.mu.RUnlock()
+
+	leaf := cert.Leaf
+	var sans []string
+	sans = append(sans, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, leaf.EmailAddresses...)
+	for _, uri := range leaf.URIs {
+		sans = append(sans, uri.String())
+	}
+	return &CertInfo{
+		Source:    source,
+		Subject:   leaf.Subject.String(),
+		SANs:      sans,
+		NotBefore: leaf.NotBefore,
+		NotAfter:  leaf.NotAfter,
+	}, nil
+}
+
+// Ensure implements StateManager.Ensure. It reloads the user-supplied
+// certificate if it changed, and records a warning if it can't be loaded,
+// or if it expires soon.
 func (m *TLSManager) Ensure() error {
+	m.mu.Lock()
+	m.lastCertCheck = time.Time{}
+	m.checkUserCert()
+	userCert := m.userCert
+	userCertErr := m.userCertErr
+	m.mu.Unlock()
+
+	m.state.Lock()
+	defer m.state.Unlock()
+	certPath := filepath.Join(m.tlsDir, certFile)
+	if userCertErr != nil {
+		m.state.Warnf("Cannot load TLS certificate %q: %v", certPath, userCertErr)
+	}
+	if userCert != nil {
+		notAfter := userCert.Leaf.NotAfter
+		remaining := notAfter.Sub(timeNow())
+		switch {
+		case remaining <= 0:
+			m.state.Warnf("TLS certificate %q expired at %s, using generated certificate instead",
+				certPath, notAfter.UTC().Format(time.RFC3339))
+		case remaining <= certExpiryWarning:
+			m.state.Warnf("TLS certificate %q expires at %s",
+				certPath, notAfter.UTC().Format(time.RFC3339))
+		}
+	}
 	return nil
 }
 
+// checkUserCert loads the user-supplied certificate if its files have
+// changed since they were last loaded. It must be called with the lock held.
+func (m *TLSManager) checkUserCert() {
+	now := timeNow()
+	if now.Sub(m.lastCertCheck) < certCheckInterval {
+		return
+	}
+	m.lastCertCheck = now
+
+	certPath := filepath.Join(m.tlsDir, certFile)
+	keyPath := filepath.Join(m.tlsDir, keyFile)
+	var stamps [2]fileStamp
+	for i, path := range []string{certPath, keyPath} {
+		info, err := os.Stat(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			m.userCertErr = err
+			return
+		}
+		if err == nil {
+			stamps[i] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	if stamps == m.userCertStamp {
+		return
+	}
+	m.userCertStamp = stamps
+
+	if stamps == [2]fileStamp{} {
+		if m.userCert != nil {
+			logger.Noticef("TLS certificate %q removed, using generated certificate.", certPath)
+		}
+		m.userCert = nil
+		m.userCertErr = nil
+		return
+	}
+	cert, err := loadUserCert(certPath, keyPath)
+	if err != nil {
+		// Keep using the previous certificate (if any): the files may be
+		// part-way through being replaced.
+		logger.Noticef("Cannot load TLS certificate: %v", err)
+		m.userCertErr = err
+		return
+	}
+	m.userCert = cert
+	m.userCertErr = nil
+	logger.Noticef("Loaded TLS certificate %q (subject %q, expires %s).",
+		certPath, cert.Leaf.Subject, cert.Leaf.NotAfter.UTC().Format(time.RFC3339))
+}
+
+// loadUserCert loads a user-supplied TLS certificate chain and its private
+// key, which must not be accessible to other users.
+func loadUserCert(certPath, keyPath string) (*tls.Certificate, error) {
+	info, err := os.Stat(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("expected no group or other permissions (got 0o%o) for %q", info.Mode().Perm(), keyPath)
+	}
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Leaf == nil {
+		cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &cert, nil
+}
+
 func (m *TLSManager) createTLSCert() error {
 	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
 	if err != nil {
@@ -15,8 +15,16 @@
 package tlsstate_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
 	"os"
 	"path/filepath"
 	"slices"
@@ -25,6 +33,7 @@ import (
 	. "gopkg.in/check.v1"
 
 	"github.com/canonical/pebble/cmd"
+	"github.com/canonical/pebble/internals/overlord/state"
 	"github.com/canonical/pebble/internals/overlord/tlsstate"
 )
 
@@ -35,7 +44,7 @@ func (ts *tlsSuite) TestNoDirectory(c *C) {
 	tlsDir := filepath.Join(c.MkDir(), "tls")
 
 	key := newIDKey(c)
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 	_, err := mgr.GetCertificate(nil)
 	c.Assert(err, IsNil)
 }
@@ -48,7 +57,7 @@ func (ts *tlsSuite) TestDirectoryInvalidPerm(c *C) {
 	c.Assert(err, IsNil)
 
 	key := newIDKey(c)
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 	_, err = mgr.GetCertificate(nil)
 	c.Assert(err, ErrorMatches, ".* expected permission 0o700 .*")
 }
@@ -59,7 +68,7 @@ func (ts *tlsSuite) TestKeypairDirNoParent(c *C) {
 	tlsDir := filepath.Join(c.MkDir(), "something/tls")
 
 	key := newIDKey(c)
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 	_, err := mgr.GetCertificate(nil)
 	c.Assert(err, ErrorMatches, "cannot create TLS directory.*")
 }
@@ -72,7 +81,7 @@ func (ts *tlsSuite) TestInvalidIDCertContent(c *C) {
 	c.Assert(err, IsNil)
 
 	key := newIDKey(c)
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 
 	// Empty the file.
 	f, err := os.OpenFile(filepath.Join(tlsDir, "identity.pem"), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
@@ -92,7 +101,7 @@ func (ts *tlsSuite) TestIDCertExtraBytes(c *C) {
 	c.Assert(err, IsNil)
 
 	key := newIDKey(c)
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 
 	// Generate certificates on demand.
 	_, err = mgr.GetCertificate(nil)
@@ -107,18 +116,26 @@ func (ts *tlsSuite) TestIDCertExtraBytes(c *C) {
 	c.Assert(err, IsNil)
 
 	// Simulate a process restart by creating a new manager.
-	mgr = tlsstate.NewManager(tlsDir, key)
+	mgr = tlsstate.NewManager(state.New(nil), tlsDir, key)
 	_, err = mgr.GetCertificate(nil)
 	c.Assert(err, ErrorMatches, ".*unexpected bytes.*")
 }
 
+// TestFingerprint checks that the manager reports the fingerprint of its
+// identity key.
+func (ts *tlsSuite) TestFingerprint(c *C) {
+	key := newIDKey(c)
+	mgr := tlsstate.NewManager(state.New(nil), filepath.Join(c.MkDir(), "tls"), key)
+	c.Assert(mgr.Fingerprint(), Equals, key.Fingerprint())
+}
+
 // TestInvalidIDCertPerm checks if we detect an invalid permission on
 // the identity certificate.
 func (ts *tlsSuite) TestInvalidIDCertPerm(c *C) {
 	tlsDir := filepath.Join(c.MkDir(), "tls")
 
 	key := newIDKey(c)
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 
 	// Generate certificates on demand.
 	_, err := mgr.GetCertificate(nil)
@@ -129,7 +146,7 @@ func (ts *tlsSuite) TestInvalidIDCertPerm(c *C) {
 	c.Assert(err, IsNil)
 
 	// Simulate a process restart by creating a new manager.
-	mgr = tlsstate.NewManager(tlsDir, key)
+	mgr = tlsstate.NewManager(state.New(nil), tlsDir, key)
 	_, err = mgr.GetCertificate(nil)
 	c.Assert(err, ErrorMatches, ".*expected permission.*")
 }
@@ -142,7 +159,7 @@ func (ts *tlsSuite) TestTLSServerClient(c *C) {
 
 	tlsDir := filepath.Join(c.MkDir(), "tls")
 	key := newIDKey(c)
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 
 	// Start the HTTPS server.
 	shutdownHTTPSServer := ts.testTLSServer(c, mgr.GetCertificate)
@@ -187,7 +204,7 @@ func (ts *tlsSuite) TestTLSServerClientTLSReuse(c *C) {
 
 	tlsDir := filepath.Join(c.MkDir(), "tls")
 	key := newIDKey(c)
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 
 	// Start the HTTPS server.
 	shutdownHTTPSServer := ts.testTLSServer(c, mgr.GetCertificate)
@@ -231,7 +248,7 @@ func (ts *tlsSuite) TestTLSServerClientRenewWindow(c *C) {
 
 	key := newIDKey(c)
 	tlsDir := filepath.Join(c.MkDir(), "tls")
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 
 	// Start the HTTPS server.
 	shutdownHTTPSServer := ts.testTLSServer(c, mgr.GetCertificate)
@@ -282,7 +299,7 @@ func (ts *tlsSuite) TestTLSServerClientIDExpires(c *C) {
 
 	key := newIDKey(c)
 	tlsDir := filepath.Join(c.MkDir(), "tls")
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 
 	// Start the HTTPS server.
 	shutdownHTTPSServer := ts.testTLSServer(c, mgr.GetCertificate)
@@ -343,7 +360,7 @@ func (ts *tlsSuite) TestTLSServerClientIDKeyChange(c *C) {
 
 	key := newIDKey(c)
 	tlsDir := filepath.Join(c.MkDir(), "tls")
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 
 	// Start the HTTPS server.
 	shutdownHTTPSServer := ts.testTLSServer(c, mgr.GetCertificate)
@@ -370,7 +387,7 @@ func (ts *tlsSuite) TestTLSServerClientIDKeyChange(c *C) {
 	// This simulates a process restart, after which we should detect
 	// the crypto.Signer no longer gives us the same private key.
 	key = newIDKey(c)
-	mgr = tlsstate.NewManager(tlsDir, key)
+	mgr = tlsstate.NewManager(state.New(nil), tlsDir, key)
 
 	// Start the HTTPS server.
 	shutdownHTTPSServer = ts.testTLSServer(c, mgr.GetCertificate)
@@ -400,7 +417,7 @@ func (ts *tlsSuite) BenchmarkIDTLSCertGen(c *C) {
 		// New unique temporary directory (so identity cert must be re-created).
 		tlsDir := filepath.Join(c.MkDir(), "tls")
 
-		mgr := tlsstate.NewManager(tlsDir, key)
+		mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 
 		// Create identity and TLS certificates on demand.
 		_, err := mgr.GetCertificate(nil)
@@ -465,7 +482,7 @@ func (ts *tlsSuite) TestTLSServerClientCustomTemplates(c *C) {
 
 	key := newIDKey(c)
 	tlsDir := filepath.Join(c.MkDir(), "tls")
-	mgr := tlsstate.NewManager(tlsDir, key)
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, key)
 
 	// For the identity certificate.
 	idTemplate := &x509.Certificate{
@@ -542,3 +559,185 @@ func (ts *tlsSuite) TestTLSServerClientCustomTemplates(c *C) {
 		c.Fail()
 	}
 }
+
+// writeUserCert writes a user-supplied certificate chain and private key to
+// the TLS directory. The leaf certificate is signed by a new CA certificate,
+// which is included in the chain.
+func writeUserCert(c *C, tlsDir string, commonName string, notAfter time.Time) *x509.Certificate {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Internal CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	c.Assert(err, IsNil)
+	caCert, err := x509.ParseCertificate(caDER)
+	c.Assert(err, IsNil)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"pebble.example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("10.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, key.Public(), caKey)
+	c.Assert(err, IsNil)
+	cert, err := x509.ParseCertificate(der)
+	c.Assert(err, IsNil)
+
+	var certPEM []byte
+	certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})...)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	c.Assert(err, IsNil)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	err = os.MkdirAll(tlsDir, 0o700)
+	c.Assert(err, IsNil)
+	err = os.WriteFile(filepath.Join(tlsDir, "cert.pem"), certPEM, 0o600)
+	c.Assert(err, IsNil)
+	err = os.WriteFile(filepath.Join(tlsDir, "key.pem"), keyPEM, 0o600)
+	c.Assert(err, IsNil)
+	return cert
+}
+
+// warnings returns the messages of the warnings recorded in state.
+func warnings(c *C, st *state.State) []string {
+	st.Lock()
+	defer st.Unlock()
+	var messages []string
+	for _, notice := range st.Notices(&state.NoticeFilter{Types: []state.NoticeType{state.WarningNotice}}) {
+		data, err := json.Marshal(notice)
+		c.Assert(err, IsNil)
+		var n struct {
+			Key string `json:"key"`
+		}
+		err = json.Unmarshal(data, &n)
+		c.Assert(err, IsNil)
+		messages = append(messages, n.Key)
+	}
+	return messages
+}
+
+// TestUserCert checks that a user-supplied certificate is used instead of
+// the generated one, and that its details are reported.
+func (ts *tlsSuite) TestUserCert(c *C) {
+	tlsDir := filepath.Join(c.MkDir(), "tls")
+	notAfter := time.Now().Add(90 * 24 * time.Hour).Truncate(time.Second)
+	leaf := writeUserCert(c, tlsDir, "pebble.example.com", notAfter)
+
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, newIDKey(c))
+	cert, err := mgr.GetCertificate(nil)
+	c.Assert(err, IsNil)
+	c.Assert(cert.Leaf.Equal(leaf), Equals, true)
+	c.Assert(cert.Certificate, HasLen, 2)
+
+	info, err := mgr.CertInfo()
+	c.Assert(err, IsNil)
+	c.Assert(info.Source, Equals, tlsstate.CertSourceUser)
+	c.Assert(info.Subject, Equals, "CN=pebble.example.com")
+	c.Assert(info.SANs, DeepEquals, []string{"pebble.example.com", "10.0.0.1"})
+	c.Assert(info.NotAfter.Equal(notAfter), Equals, true)
+
+	// The generated identity certificate isn't needed.
+	_, err = os.Stat(filepath.Join(tlsDir, "identity.pem"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+// TestUserCertReload checks that a changed user-supplied certificate is
+// reloaded, and that removing it falls back to the generated certificate.
+func (ts *tlsSuite) TestUserCertReload(c *C) {
+	restore := tlsstate.FakeCertCheckInterval(0)
+	defer restore()
+
+	tlsDir := filepath.Join(c.MkDir(), "tls")
+	mgr := tlsstate.NewManager(state.New(nil), tlsDir, newIDKey(c))
+	info, err := mgr.CertInfo()
+	c.Assert(err, IsNil)
+	c.Assert(info.Source, Equals, tlsstate.CertSourceGenerated)
+
+	writeUserCert(c, tlsDir, "first", time.Now().Add(24*time.Hour))
+	info, err = mgr.CertInfo()
+	c.Assert(err, IsNil)
+	c.Assert(info.Source, Equals, tlsstate.CertSourceUser)
+	c.Assert(info.Subject, Equals, "CN=first")
+
+	second := writeUserCert(c, tlsDir, "second", time.Now().Add(48*time.Hour))
+	// Make sure the change is noticed even on file systems with coarse
+	// modification times.
+	later := time.Now().Add(time.Minute)
+	for _, name := range []string{"cert.pem", "key.pem"} {
+		err = os.Chtimes(filepath.Join(tlsDir, name), later, later)
+		c.Assert(err, IsNil)
+	}
+	cert, err := mgr.GetCertificate(nil)
+	c.Assert(err, IsNil)
+	c.Assert(cert.Leaf.Equal(second), Equals, true)
+
+	for _, name := range []string{"cert.pem", "key.pem"} {
+		err = os.Remove(filepath.Join(tlsDir, name))
+		c.Assert(err, IsNil)
+	}
+	info, err = mgr.CertInfo()
+	c.Assert(err, IsNil)
+	c.Assert(info.Source, Equals, tlsstate.CertSourceGenerated)
+}
+
+// TestUserCertInvalid checks that an invalid user-supplied certificate is
+// ignored in favour of the generated one, and that a warning is recorded.
+func (ts *tlsSuite) TestUserCertInvalid(c *C) {
+	tlsDir := filepath.Join(c.MkDir(), "tls")
+	writeUserCert(c, tlsDir, "pebble", time.Now().Add(24*time.Hour))
+	err := os.Chmod(filepath.Join(tlsDir, "key.pem"), 0o644)
+	c.Assert(err, IsNil)
+
+	st := state.New(nil)
+	mgr := tlsstate.NewManager(st, tlsDir, newIDKey(c))
+	info, err := mgr.CertInfo()
+	c.Assert(err, IsNil)
+	c.Assert(info.Source, Equals, tlsstate.CertSourceGenerated)
+
+	err = mgr.Ensure()
+	c.Assert(err, IsNil)
+	c.Assert(warnings(c, st), HasLen, 1)
+	c.Assert(warnings(c, st)[0], Matches, `Cannot load TLS certificate ".*/cert.pem": expected no group or other permissions \(got 0o644\) for ".*/key.pem"`)
+}
+
+// TestUserCertExpiry checks that a warning is recorded when a user-supplied
+// certificate expires soon, and that an expired certificate isn't used.
+func (ts *tlsSuite) TestUserCertExpiry(c *C) {
+	tlsDir := filepath.Join(c.MkDir(), "tls")
+	notAfter := time.Now().Add(7 * 24 * time.Hour)
+	writeUserCert(c, tlsDir, "pebble", notAfter)
+
+	st := state.New(nil)
+	mgr := tlsstate.NewManager(st, tlsDir, newIDKey(c))
+	err := mgr.Ensure()
+	c.Assert(err, IsNil)
+	c.Assert(warnings(c, st), DeepEquals, []string{
+		fmt.Sprintf(`TLS certificate %q expires at %s`, filepath.Join(tlsDir, "cert.pem"), notAfter.UTC().Format(time.RFC3339)),
+	})
+
+	// Once it has expired, the generated certificate is used instead.
+	restore := tlsstate.FakeTimeNow(notAfter.Add(time.Hour))
+	defer restore()
+	info, err := mgr.CertInfo()
+	c.Assert(err, IsNil)
+	c.Assert(info.Source, Equals, tlsstate.CertSourceGenerated)
+	err = mgr.Ensure()
+	c.Assert(err, IsNil)
+	c.Assert(warnings(c, st), HasLen, 2)
+	c.Assert(warnings(c, st)[1], Matches, `TLS certificate ".*" expired at .*, using generated certificate instead`)
+}
@@ -15,3 +15,9 @@
 package plan
 
 var BuiltinSections = builtinSections
+var BuiltinCheckFields = builtinCheckFields
+
+var (
+	RegisterCheckExtension   = registerCheckExtension
+	UnregisterCheckExtension = unregisterCheckExtension
+)
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"slices"
 	"strings"
 
@@ -768,3 +769,173 @@ func makeMapIfNil[K comparable, V any](m map[K]V) map[K]V {
 	}
 	return m
 }
+
+func (s *S) TestBuiltinCheckFields(c *C) {
+	var fields []string
+	typ := reflect.TypeOf(plan.Check{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	c.Assert(plan.BuiltinCheckFields, DeepEquals, fields)
+}
+
+func (s *S) TestCheckExtensionBuiltinField(c *C) {
+	c.Assert(func() { plan.RegisterCheckExtension("exec", pingExtension{}) },
+		PanicMatches, `internal error: check extension "exec" already used as built-in field`)
+}
+
+func (s *S) TestCheckExtension(c *C) {
+	plan.RegisterCheckExtension(pingField, pingExtension{})
+	defer plan.UnregisterCheckExtension(pingField)
+
+	layer1, err := plan.ParseLayer(1, "base", reindent(`
+		checks:
+			chk1:
+				override: replace
+				threshold: 5
+				ping:
+					host: db.local
+					count: 1
+			chk2:
+				override: replace
+				exec:
+					command: true`))
+	c.Assert(err, IsNil)
+	c.Assert(layer1.Checks["chk1"].Extensions, DeepEquals, map[string]plan.CheckConfig{
+		pingField: &pingCheck{Host: "db.local", Count: 1},
+	})
+	c.Assert(layer1.Checks["chk2"].Extensions, IsNil)
+
+	layer2, err := plan.ParseLayer(2, "override", reindent(`
+		checks:
+			chk1:
+				override: merge
+				ping:
+					count: 3`))
+	c.Assert(err, IsNil)
+
+	combined, err := plan.CombineLayers(layer1, layer2)
+	c.Assert(err, IsNil)
+	c.Assert(combined.Checks["chk1"].Extensions, DeepEquals, map[string]plan.CheckConfig{
+		pingField: &pingCheck{Host: "db.local", Count: 3},
+	})
+	// The layers themselves are not modified by the merge.
+	c.Assert(layer1.Checks["chk1"].Extensions[pingField], DeepEquals, &pingCheck{Host: "db.local", Count: 1})
+
+	p := &plan.Plan{Checks: combined.Checks}
+	err = p.Validate()
+	c.Assert(err, IsNil)
+	data, err := yaml.Marshal(p)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, string(reindent(`
+		checks:
+			chk1:
+				override: replace
+				threshold: 5
+				ping:
+					host: db.local
+					count: 3
+			chk2:
+				override: replace
+				threshold: 3
+				exec:
+					command: "true"`)))
+}
+
+func (s *S) TestCheckExtensionErrors(c *C) {
+	plan.RegisterCheckExtension(pingField, pingExtension{})
+	defer plan.UnregisterCheckExtension(pingField)
+
+	tests := []struct {
+		yaml  string
+		error string
+	}{{
+		yaml: `
+			checks:
+				chk1:
+					override: replace
+					ping:
+						hots: db.local`,
+		error: `(?s)cannot parse layer "label" check "chk1" field "ping": .*field hots not found.*`,
+	}, {
+		yaml: `
+			checks:
+				chk1:
+					override: replace
+					pong: {}`,
+		error: `(?s)cannot parse layer "label" section "checks": .*field pong not found.*`,
+	}, {
+		yaml: `
+			checks:
+				chk1:
+					override: replace
+					ping:
+						count: 1`,
+		error: `plan check "chk1" ping invalid: host must be set`,
+	}, {
+		yaml: `
+			checks:
+				chk1:
+					override: replace
+					exec:
+						command: "true"
+					ping:
+						host: db.local`,
+		error: `plan must specify one of "http", "tcp", "exec", "grpc", "log", "composite", "file", "process", or "ping" for check "chk1"`,
+	}}
+	for _, test := range tests {
+		layer, err := plan.ParseLayer(1, "label", reindent(test.yaml))
+		if err == nil {
+			var combined *plan.Layer
+			combined, err = plan.CombineLayers(layer)
+			c.Assert(err, IsNil)
+			err = (&plan.Plan{Checks: combined.Checks}).Validate()
+		}
+		c.Check(err, ErrorMatches, test.error)
+	}
+}
+
+const pingField string = "ping"
+
+// pingExtension implements the CheckExtension interface.
+type pingExtension struct{}
+
+func (pingExtension) ParseCheck(data yaml.Node) (plan.CheckConfig, error) {
+	check := &pingCheck{}
+	err := plan.SectionDecode(&data, check)
+	if err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// pingCheck is the backing type for pingExtension.
+type pingCheck struct {
+	Host  string `yaml:"host,omitempty"`
+	Count int    `yaml:"count,omitempty"`
+}
+
+func (p *pingCheck) Validate() error {
+	if p.Host == "" {
+		return fmt.Errorf("host must be set")
+	}
+	return nil
+}
+
+func (p *pingCheck) Copy() plan.CheckConfig {
+	copied := *p
+	return &copied
+}
+
+func (p *pingCheck) Merge(other plan.CheckConfig) {
+	o := other.(*pingCheck)
+	if o.Host != "" {
+		p.Host = o.Host
+	}
+	if o.Count != 0 {
+		p.Count = o.Count
+	}
+}
@@ -17,6 +17,7 @@ package plan
 import (
 	"bytes"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -29,6 +30,7 @@ import (
 	"github.com/canonical/x-go/strutil/shlex"
 	"gopkg.in/yaml.v3"
 
+	"github.com/canonical/pebble/internals/internal/checkext"
 	"github.com/canonical/pebble/internals/logger"
 	"github.com/canonical/pebble/internals/osutil"
 )
@@ -58,6 +60,29 @@ type Section interface {
 	IsZero() bool
 }
 
+// CheckExtension allows custom check types to be defined without adding
+// centralised schema knowledge to the plan library.
+type CheckExtension interface {
+	// ParseCheck returns a newly allocated concrete type containing the
+	// unmarshalled check configuration.
+	ParseCheck(data yaml.Node) (CheckConfig, error)
+}
+
+// CheckConfig holds the configuration of an extension-defined check type.
+type CheckConfig interface {
+	// Validate checks whether the configuration is valid, returning an
+	// error if not. It is called on the configuration of the combined plan,
+	// so required fields may be missing from individual layers.
+	Validate() error
+
+	// Copy returns a deep copy of the configuration.
+	Copy() CheckConfig
+
+	// Merge merges the fields set in other, which is always of the same
+	// concrete type, into the configuration.
+	Merge(other CheckConfig)
+}
+
 const (
 	defaultBackoffDelay  = 500 * time.Millisecond
 	defaultBackoffFactor = 2.0
@@ -68,12 +93,23 @@ const (
 	defaultCheckThreshold = 3
 )
 
+// MaxCheckHistoryLimit is the maximum number of results that may be kept in
+// a check's result history.
+const MaxCheckHistoryLimit = 1000
+
 var (
 	// sectionExtensions keeps a map of registered extensions.
 	sectionExtensions = map[string]SectionExtension{}
 
 	// sectionExtensionsOrder records the order in which the extensions were registered.
 	sectionExtensionsOrder = []string{}
+
+	// checkExtensions keeps a map of registered check extensions.
+	checkExtensions = map[string]CheckExtension{}
+
+	// checkExtensionsOrder records the order in which the check extensions
+	// were registered.
+	checkExtensionsOrder = []string{}
 )
 
 // builtinSections represents all the built-in layer sections. This list is used
@@ -105,6 +141,44 @@ func UnregisterSectionExtension(field string) {
 	})
 }
 
+// builtinCheckFields represents all the built-in check fields. It is unit
+// tested to match the YAML fields exposed in the Check type.
+var builtinCheckFields = []string{
+	"override", "level", "startup", "period", "timeout", "threshold",
+	"initial-delay", "history-limit", "http", "tcp", "exec", "grpc", "log",
+	"composite", "file", "process", "on-down", "on-up",
+}
+
+func init() {
+	checkext.Register = func(field string, ext any) {
+		registerCheckExtension(field, ext.(CheckExtension))
+	}
+	checkext.Unregister = unregisterCheckExtension
+}
+
+// registerCheckExtension adds a check type extension, configured with the
+// given field in a check's YAML. Check extensions are registered with
+// checkstate.RegisterCheckExtension, which also registers how the check is
+// performed.
+func registerCheckExtension(field string, ext CheckExtension) {
+	if slices.Contains(builtinCheckFields, field) {
+		panic(fmt.Sprintf("internal error: check extension %q already used as built-in field", field))
+	}
+	if _, ok := checkExtensions[field]; ok {
+		panic(fmt.Sprintf("internal error: check extension %q already registered", field))
+	}
+	checkExtensions[field] = ext
+	checkExtensionsOrder = append(checkExtensionsOrder, field)
+}
+
+// unregisterCheckExtension removes a check type extension.
+func unregisterCheckExtension(field string) {
+	delete(checkExtensions, field)
+	checkExtensionsOrder = slices.DeleteFunc(checkExtensionsOrder, func(n string) bool {
+		return n == field
+	})
+}
+
 type Plan struct {
 	Layers     []*Layer              `yaml:"-"`
 	Services   map[string]*Service   `yaml:"services,omitempty"`
@@ -445,14 +519,57 @@ type Check struct {
 	Startup  CheckStartup `yaml:"startup,omitempty"`
 
 	// Common check settings
-	Period    OptionalDuration `yaml:"period,omitempty"`
-	Timeout   OptionalDuration `yaml:"timeout,omitempty"`
-	Threshold int              `yaml:"threshold,omitempty"`
+	Period       OptionalDuration `yaml:"period,omitempty"`
+	Timeout      OptionalDuration `yaml:"timeout,omitempty"`
+	Threshold    int              `yaml:"threshold,omitempty"`
+	InitialDelay OptionalDuration `yaml:"initial-delay,omitempty"`
+	HistoryLimit int              `yaml:"history-limit,omitempty"`
 
 	// Type-specific check settings (only one of these can be set)
-	HTTP *HTTPCheck `yaml:"http,omitempty"`
-	TCP  *TCPCheck  `yaml:"tcp,omitempty"`
-	Exec *ExecCheck `yaml:"exec,omitempty"`
+	HTTP      *HTTPCheck      `yaml:"http,omitempty"`
+	TCP       *TCPCheck       `yaml:"tcp,omitempty"`
+	Exec      *ExecCheck      `yaml:"exec,omitempty"`
+	GRPC      *GRPCCheck      `yaml:"grpc,omitempty"`
+	Log       *LogCheck       `yaml:"log,omitempty"`
+	Composite *CompositeCheck `yaml:"composite,omitempty"`
+	File      *FileCheck      `yaml:"file,omitempty"`
+	Process   *ProcessCheck   `yaml:"process,omitempty"`
+
+	// Hooks run when the check goes down or comes back up
+	OnDown *CheckHook `yaml:"on-down,omitempty"`
+	OnUp   *CheckHook `yaml:"on-up,omitempty"`
+
+	// Extension-defined check settings, keyed by the registered field name
+	// (only one type-specific setting can be set, including these)
+	Extensions map[string]CheckConfig `yaml:"-"`
+}
+
+// MarshalYAML adds the extension-defined check settings, which have no
+// concrete type in Check, to the check's YAML.
+func (c *Check) MarshalYAML() (any, error) {
+	type plainCheck Check
+	if len(c.Extensions) == 0 {
+		return (*plainCheck)(c), nil
+	}
+	node := &yaml.Node{}
+	err := node.Encode((*plainCheck)(c))
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range checkExtensionsOrder {
+		config, ok := c.Extensions[field]
+		if !ok {
+			continue
+		}
+		value := &yaml.Node{}
+		err := value.Encode(config)
+		if err != nil {
+			return nil, err
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: field}
+		node.Content = append(node.Content, key, value)
+	}
+	return node, nil
 }
 
 // Copy returns a deep copy of the check configuration.
@@ -467,6 +584,33 @@ func (c *Check) Copy() *Check {
 	if c.Exec != nil {
 		copied.Exec = c.Exec.Copy()
 	}
+	if c.GRPC != nil {
+		copied.GRPC = c.GRPC.Copy()
+	}
+	if c.Log != nil {
+		copied.Log = c.Log.Copy()
+	}
+	if c.Composite != nil {
+		copied.Composite = c.Composite.Copy()
+	}
+	if c.File != nil {
+		copied.File = c.File.Copy()
+	}
+	if c.Process != nil {
+		copied.Process = c.Process.Copy()
+	}
+	if c.OnDown != nil {
+		copied.OnDown = c.OnDown.Copy()
+	}
+	if c.OnUp != nil {
+		copied.OnUp = c.OnUp.Copy()
+	}
+	if c.Extensions != nil {
+		copied.Extensions = make(map[string]CheckConfig, len(c.Extensions))
+		for field, config := range c.Extensions {
+			copied.Extensions[field] = config.Copy()
+		}
+	}
 	return &copied
 }
 
@@ -487,6 +631,12 @@ func (c *Check) Merge(other *Check) {
 	if other.Threshold != 0 {
 		c.Threshold = other.Threshold
 	}
+	if other.InitialDelay.IsSet {
+		c.InitialDelay = other.InitialDelay
+	}
+	if other.HistoryLimit != 0 {
+		c.HistoryLimit = other.HistoryLimit
+	}
 	if other.HTTP != nil {
 		if c.HTTP == nil {
 			c.HTTP = &HTTPCheck{}
@@ -505,15 +655,68 @@ func (c *Check) Merge(other *Check) {
 		}
 		c.Exec.Merge(other.Exec)
 	}
+	if other.GRPC != nil {
+		if c.GRPC == nil {
+			c.GRPC = &GRPCCheck{}
+		}
+		c.GRPC.Merge(other.GRPC)
+	}
+	if other.Log != nil {
+		if c.Log == nil {
+			c.Log = &LogCheck{}
+		}
+		c.Log.Merge(other.Log)
+	}
+	if other.Composite != nil {
+		if c.Composite == nil {
+			c.Composite = &CompositeCheck{}
+		}
+		c.Composite.Merge(other.Composite)
+	}
+	if other.File != nil {
+		if c.File == nil {
+			c.File = &FileCheck{}
+		}
+		c.File.Merge(other.File)
+	}
+	if other.Process != nil {
+		if c.Process == nil {
+			c.Process = &ProcessCheck{}
+		}
+		c.Process.Merge(other.Process)
+	}
+	if other.OnDown != nil {
+		if c.OnDown == nil {
+			c.OnDown = &CheckHook{}
+		}
+		c.OnDown.Merge(other.OnDown)
+	}
+	if other.OnUp != nil {
+		if c.OnUp == nil {
+			c.OnUp = &CheckHook{}
+		}
+		c.OnUp.Merge(other.OnUp)
+	}
+	for field, config := range other.Extensions {
+		if c.Extensions == nil {
+			c.Extensions = make(map[string]CheckConfig)
+		}
+		if existing, ok := c.Extensions[field]; ok {
+			existing.Merge(config)
+		} else {
+			c.Extensions[field] = config.Copy()
+		}
+	}
 }
 
 // CheckLevel specifies the optional check level.
 type CheckLevel string
 
 const (
-	UnsetLevel CheckLevel = ""
-	AliveLevel CheckLevel = "alive"
-	ReadyLevel CheckLevel = "ready"
+	UnsetLevel   CheckLevel = ""
+	AliveLevel   CheckLevel = "alive"
+	ReadyLevel   CheckLevel = "ready"
+	StartupLevel CheckLevel = "startup"
 )
 
 // CheckStartup defines the different startup modes for a check.
@@ -527,8 +730,16 @@ const (
 
 // HTTPCheck holds the configuration for an HTTP health check.
 type HTTPCheck struct {
-	URL     string            `yaml:"url,omitempty"`
-	Headers map[string]string `yaml:"headers,omitempty"`
+	URL                string            `yaml:"url,omitempty"`
+	Method             string            `yaml:"method,omitempty"`
+	Headers            map[string]string `yaml:"headers,omitempty"`
+	Body               string            `yaml:"body,omitempty"`
+	ExpectedStatus     []int             `yaml:"expected-status,omitempty"`
+	BodyRegex          string            `yaml:"body-regex,omitempty"`
+	InsecureSkipVerify bool              `yaml:"insecure-skip-verify,omitempty"`
+	CAFile             string            `yaml:"ca-file,omitempty"`
+	CertFile           string            `yaml:"cert-file,omitempty"`
+	KeyFile            string            `yaml:"key-file,omitempty"`
 }
 
 // Copy returns a deep copy of the HTTP check configuration.
@@ -540,6 +751,7 @@ func (c *HTTPCheck) Copy() *HTTPCheck {
 			copied.Headers[k] = v
 		}
 	}
+	copied.ExpectedStatus = append([]int(nil), c.ExpectedStatus...)
 	return &copied
 }
 
@@ -548,18 +760,50 @@ func (c *HTTPCheck) Merge(other *HTTPCheck) {
 	if other.URL != "" {
 		c.URL = other.URL
 	}
+	if other.Method != "" {
+		c.Method = other.Method
+	}
 	for k, v := range other.Headers {
 		if c.Headers == nil {
 			c.Headers = make(map[string]string)
 		}
 		c.Headers[k] = v
 	}
+	if other.Body != "" {
+		c.Body = other.Body
+	}
+	if len(other.ExpectedStatus) > 0 {
+		// The expected status codes are a set, so replace rather than append.
+		c.ExpectedStatus = append([]int(nil), other.ExpectedStatus...)
+	}
+	if other.BodyRegex != "" {
+		c.BodyRegex = other.BodyRegex
+	}
+	if other.InsecureSkipVerify {
+		c.InsecureSkipVerify = true
+	}
+	if other.CAFile != "" {
+		c.CAFile = other.CAFile
+	}
+	if other.CertFile != "" {
+		c.CertFile = other.CertFile
+	}
+	if other.KeyFile != "" {
+		c.KeyFile = other.KeyFile
+	}
 }
 
-// TCPCheck holds the configuration for an HTTP health check.
+// TCPCheck holds the configuration for a TCP health check, which can
+// optionally perform a TLS handshake and check the peer's certificate.
 type TCPCheck struct {
 	Port int    `yaml:"port,omitempty"`
 	Host string `yaml:"host,omitempty"`
+
+	TLS                bool             `yaml:"tls,omitempty"`
+	InsecureSkipVerify bool             `yaml:"insecure-skip-verify,omitempty"`
+	CAFile             string           `yaml:"ca-file,omitempty"`
+	ServerName         string           `yaml:"server-name,omitempty"`
+	MinValidity        OptionalDuration `yaml:"min-validity,omitempty"`
 }
 
 // Copy returns a deep copy of the TCP check configuration.
@@ -576,6 +820,21 @@ func (c *TCPCheck) Merge(other *TCPCheck) {
 	if other.Host != "" {
 		c.Host = other.Host
 	}
+	if other.TLS {
+		c.TLS = true
+	}
+	if other.InsecureSkipVerify {
+		c.InsecureSkipVerify = true
+	}
+	if other.CAFile != "" {
+		c.CAFile = other.CAFile
+	}
+	if other.ServerName != "" {
+		c.ServerName = other.ServerName
+	}
+	if other.MinValidity.IsSet {
+		c.MinValidity = other.MinValidity
+	}
 }
 
 // ExecCheck holds the configuration for an exec health check.
@@ -639,6 +898,215 @@ func (c *ExecCheck) Merge(other *ExecCheck) {
 	}
 }
 
+// CheckHook holds the configuration for a command that is run when a check
+// goes down (on-down) or comes back up (on-up). The command is run in the
+// same way as an exec check's command.
+type CheckHook struct {
+	ExecCheck `yaml:",inline"`
+
+	Timeout OptionalDuration `yaml:"timeout,omitempty"`
+}
+
+// Copy returns a deep copy of the check hook configuration.
+func (h *CheckHook) Copy() *CheckHook {
+	copied := *h
+	copied.ExecCheck = *h.ExecCheck.Copy()
+	return &copied
+}
+
+// Merge merges the fields set in other into h.
+func (h *CheckHook) Merge(other *CheckHook) {
+	h.ExecCheck.Merge(&other.ExecCheck)
+	if other.Timeout.IsSet {
+		h.Timeout = other.Timeout
+	}
+}
+
+// GRPCCheck holds the configuration for a gRPC health check, which uses the
+// standard grpc.health.v1 health checking protocol.
+type GRPCCheck struct {
+	Address            string            `yaml:"address,omitempty"`
+	Service            string            `yaml:"service,omitempty"`
+	TLS                bool              `yaml:"tls,omitempty"`
+	InsecureSkipVerify bool              `yaml:"insecure-skip-verify,omitempty"`
+	CAFile             string            `yaml:"ca-file,omitempty"`
+	ServerName         string            `yaml:"server-name,omitempty"`
+	Metadata           map[string]string `yaml:"metadata,omitempty"`
+}
+
+// Copy returns a deep copy of the gRPC check configuration.
+func (c *GRPCCheck) Copy() *GRPCCheck {
+	copied := *c
+	if c.Metadata != nil {
+		copied.Metadata = make(map[string]string, len(c.Metadata))
+		for k, v := range c.Metadata {
+			copied.Metadata[k] = v
+		}
+	}
+	return &copied
+}
+
+// Merge merges the fields set in other into c.
+func (c *GRPCCheck) Merge(other *GRPCCheck) {
+	if other.Address != "" {
+		c.Address = other.Address
+	}
+	if other.Service != "" {
+		c.Service = other.Service
+	}
+	if other.TLS {
+		c.TLS = true
+	}
+	if other.InsecureSkipVerify {
+		c.InsecureSkipVerify = true
+	}
+	if other.CAFile != "" {
+		c.CAFile = other.CAFile
+	}
+	if other.ServerName != "" {
+		c.ServerName = other.ServerName
+	}
+	for k, v := range other.Metadata {
+		if c.Metadata == nil {
+			c.Metadata = make(map[string]string)
+		}
+		c.Metadata[k] = v
+	}
+}
+
+// LogCheck holds the configuration for a log check, which looks for
+// patterns in a service's log output.
+type LogCheck struct {
+	Service          string           `yaml:"service,omitempty"`
+	Regex            string           `yaml:"regex,omitempty"`
+	Window           OptionalDuration `yaml:"window,omitempty"`
+	HeartbeatRegex   string           `yaml:"heartbeat-regex,omitempty"`
+	HeartbeatTimeout OptionalDuration `yaml:"heartbeat-timeout,omitempty"`
+}
+
+// Copy returns a deep copy of the log check configuration.
+func (c *LogCheck) Copy() *LogCheck {
+	copied := *c
+	return &copied
+}
+
+// Merge merges the fields set in other into c.
+func (c *LogCheck) Merge(other *LogCheck) {
+	if other.Service != "" {
+		c.Service = other.Service
+	}
+	if other.Regex != "" {
+		c.Regex = other.Regex
+	}
+	if other.Window.IsSet {
+		c.Window = other.Window
+	}
+	if other.HeartbeatRegex != "" {
+		c.HeartbeatRegex = other.HeartbeatRegex
+	}
+	if other.HeartbeatTimeout.IsSet {
+		c.HeartbeatTimeout = other.HeartbeatTimeout
+	}
+}
+
+// FileCheck holds the configuration for a file check, which is healthy if
+// a file exists and, optionally, was modified recently or has matching
+// content.
+type FileCheck struct {
+	Path   string           `yaml:"path,omitempty"`
+	MaxAge OptionalDuration `yaml:"max-age,omitempty"`
+	Regex  string           `yaml:"regex,omitempty"`
+}
+
+// Copy returns a deep copy of the file check configuration.
+func (c *FileCheck) Copy() *FileCheck {
+	copied := *c
+	return &copied
+}
+
+// Merge merges the fields set in other into c.
+func (c *FileCheck) Merge(other *FileCheck) {
+	if other.Path != "" {
+		c.Path = other.Path
+	}
+	if other.MaxAge.IsSet {
+		c.MaxAge = other.MaxAge
+	}
+	if other.Regex != "" {
+		c.Regex = other.Regex
+	}
+}
+
+// ProcessCheck holds the configuration for a process check, which is
+// healthy if the process in a PID file is running, or if a service's
+// process group contains a running process (optionally with a given name).
+type ProcessCheck struct {
+	PIDFile string `yaml:"pid-file,omitempty"`
+	Service string `yaml:"service,omitempty"`
+	Name    string `yaml:"name,omitempty"`
+}
+
+// Copy returns a deep copy of the process check configuration.
+func (c *ProcessCheck) Copy() *ProcessCheck {
+	copied := *c
+	return &copied
+}
+
+// Merge merges the fields set in other into c.
+func (c *ProcessCheck) Merge(other *ProcessCheck) {
+	if other.PIDFile != "" {
+		c.PIDFile = other.PIDFile
+	}
+	if other.Service != "" {
+		c.Service = other.Service
+	}
+	if other.Name != "" {
+		c.Name = other.Name
+	}
+}
+
+// CompositeCheck holds the configuration for a composite check, which is
+// healthy based on the current status of other checks.
+type CompositeCheck struct {
+	Checks  []string         `yaml:"checks,omitempty"`
+	Require CompositeRequire `yaml:"require,omitempty"`
+	N       int              `yaml:"n,omitempty"`
+}
+
+// Copy returns a deep copy of the composite check configuration.
+func (c *CompositeCheck) Copy() *CompositeCheck {
+	copied := *c
+	copied.Checks = append([]string(nil), c.Checks...)
+	return &copied
+}
+
+// Merge merges the fields set in other into c. Checks in other that c
+// already depends on aren't added again.
+func (c *CompositeCheck) Merge(other *CompositeCheck) {
+	for _, name := range other.Checks {
+		if !slices.Contains(c.Checks, name) {
+			c.Checks = append(c.Checks, name)
+		}
+	}
+	if other.Require != "" {
+		c.Require = other.Require
+	}
+	if other.N != 0 {
+		c.N = other.N
+	}
+}
+
+// CompositeRequire specifies how many of a composite check's checks must be
+// up for the composite check to succeed.
+type CompositeRequire string
+
+const (
+	CompositeRequireUnset CompositeRequire = ""
+	CompositeRequireAll   CompositeRequire = "all"
+	CompositeRequireAny   CompositeRequire = "any"
+	CompositeRequireNOf   CompositeRequire = "n-of"
+)
+
 // LogTarget specifies a remote server to forward logs to.
 type LogTarget struct {
 	Name     string            `yaml:"-"`
@@ -929,9 +1397,9 @@ func (layer *Layer) Validate() error {
 				Message: "cannot use empty string as log target name",
 			}
 		}
-		if check.Level != UnsetLevel && check.Level != AliveLevel && check.Level != ReadyLevel {
+		if check.Level != UnsetLevel && check.Level != AliveLevel && check.Level != ReadyLevel && check.Level != StartupLevel {
 			return &FormatError{
-				Message: fmt.Sprintf(`plan check %q level must be "alive" or "ready"`, name),
+				Message: fmt.Sprintf(`plan check %q level must be "alive", "ready", or "startup"`, name),
 			}
 		}
 		if check.Startup != CheckStartupUnknown && check.Startup != CheckStartupEnabled && check.Startup != CheckStartupDisabled {
@@ -949,6 +1417,16 @@ func (layer *Layer) Validate() error {
 				Message: fmt.Sprintf("plan check %q timeout must not be zero", name),
 			}
 		}
+		if check.InitialDelay.Value < 0 {
+			return &FormatError{
+				Message: fmt.Sprintf("plan check %q initial-delay must not be negative", name),
+			}
+		}
+		if check.HistoryLimit < 0 || check.HistoryLimit > MaxCheckHistoryLimit {
+			return &FormatError{
+				Message: fmt.Sprintf("plan check %q history-limit must be between 0 and %d", name, MaxCheckHistoryLimit),
+			}
+		}
 
 		if check.Exec != nil {
 			_, err := shlex.Split(check.Exec.Command)
@@ -964,6 +1442,147 @@ func (layer *Layer) Validate() error {
 				}
 			}
 		}
+
+		if check.Composite != nil {
+			switch check.Composite.Require {
+			case CompositeRequireUnset, CompositeRequireAll, CompositeRequireAny:
+				if check.Composite.N != 0 {
+					return &FormatError{
+						Message: fmt.Sprintf(`plan check %q must only set "n" with require "n-of"`, name),
+					}
+				}
+			case CompositeRequireNOf:
+				if check.Composite.N < 1 {
+					return &FormatError{
+						Message: fmt.Sprintf(`plan check %q must set "n" to at least 1 with require "n-of"`, name),
+					}
+				}
+			default:
+				return &FormatError{
+					Message: fmt.Sprintf(`plan check %q require must be "all", "any", or "n-of"`, name),
+				}
+			}
+		}
+
+		for _, h := range []struct {
+			field string
+			hook  *CheckHook
+		}{{"on-down", check.OnDown}, {"on-up", check.OnUp}} {
+			if h.hook == nil {
+				continue
+			}
+			_, err := shlex.Split(h.hook.Command)
+			if err != nil {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q %s command invalid: %v", name, h.field, err),
+				}
+			}
+			_, _, err = osutil.NormalizeUidGid(h.hook.UserID, h.hook.GroupID, h.hook.User, h.hook.Group)
+			if err != nil {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q %s has invalid user/group: %v", name, h.field, err),
+				}
+			}
+			if h.hook.Timeout.IsSet && h.hook.Timeout.Value <= 0 {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q %s timeout must be greater than zero", name, h.field),
+				}
+			}
+		}
+
+		if check.HTTP != nil {
+			if check.HTTP.Method != "" && !httpMethodRegexp.MatchString(check.HTTP.Method) {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q method %q invalid", name, check.HTTP.Method),
+				}
+			}
+			for _, status := range check.HTTP.ExpectedStatus {
+				if status < 100 || status > 599 {
+					return &FormatError{
+						Message: fmt.Sprintf("plan check %q expected-status %d invalid", name, status),
+					}
+				}
+			}
+			if check.HTTP.BodyRegex != "" {
+				_, err := regexp.Compile(check.HTTP.BodyRegex)
+				if err != nil {
+					return &FormatError{
+						Message: fmt.Sprintf("plan check %q body-regex invalid: %v", name, err),
+					}
+				}
+			}
+		}
+
+		if check.Log != nil {
+			if check.Log.Regex != "" {
+				_, err := regexp.Compile(check.Log.Regex)
+				if err != nil {
+					return &FormatError{
+						Message: fmt.Sprintf("plan check %q regex invalid: %v", name, err),
+					}
+				}
+			}
+			if check.Log.HeartbeatRegex != "" {
+				_, err := regexp.Compile(check.Log.HeartbeatRegex)
+				if err != nil {
+					return &FormatError{
+						Message: fmt.Sprintf("plan check %q heartbeat-regex invalid: %v", name, err),
+					}
+				}
+			}
+			if check.Log.Window.IsSet && check.Log.Window.Value <= 0 {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q window must be greater than zero", name),
+				}
+			}
+			if check.Log.HeartbeatTimeout.IsSet && check.Log.HeartbeatTimeout.Value <= 0 {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q heartbeat-timeout must be greater than zero", name),
+				}
+			}
+		}
+
+		if check.File != nil {
+			if check.File.Path != "" && !filepath.IsAbs(check.File.Path) {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q path must be an absolute path", name),
+				}
+			}
+			if check.File.MaxAge.IsSet && check.File.MaxAge.Value <= 0 {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q max-age must be greater than zero", name),
+				}
+			}
+			if check.File.Regex != "" {
+				_, err := regexp.Compile(check.File.Regex)
+				if err != nil {
+					return &FormatError{
+						Message: fmt.Sprintf("plan check %q regex invalid: %v", name, err),
+					}
+				}
+			}
+		}
+
+		if check.Process != nil && check.Process.PIDFile != "" && !filepath.IsAbs(check.Process.PIDFile) {
+			return &FormatError{
+				Message: fmt.Sprintf("plan check %q pid-file must be an absolute path", name),
+			}
+		}
+
+		if check.TCP != nil && check.TCP.MinValidity.IsSet && check.TCP.MinValidity.Value <= 0 {
+			return &FormatError{
+				Message: fmt.Sprintf("plan check %q min-validity must be greater than zero", name),
+			}
+		}
+
+		if check.GRPC != nil && check.GRPC.Address != "" {
+			_, _, err := net.SplitHostPort(check.GRPC.Address)
+			if err != nil {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q address invalid: %v", name, err),
+				}
+			}
+		}
 	}
 
 	for name, target := range layer.LogTargets {
@@ -1022,6 +1641,11 @@ func (p *Plan) Validate() error {
 					Message: fmt.Sprintf(`plan must set "url" for http check %q`, name),
 				}
 			}
+			if (check.HTTP.CertFile == "") != (check.HTTP.KeyFile == "") {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan must set both "cert-file" and "key-file" for http check %q`, name),
+				}
+			}
 			numTypes++
 		}
 		if check.TCP != nil {
@@ -1030,6 +1654,11 @@ func (p *Plan) Validate() error {
 					Message: fmt.Sprintf(`plan must set "port" for tcp check %q`, name),
 				}
 			}
+			if !check.TCP.TLS && (check.TCP.InsecureSkipVerify || check.TCP.CAFile != "" || check.TCP.ServerName != "" || check.TCP.MinValidity.IsSet) {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan tcp check %q must set "tls" to use TLS options`, name),
+				}
+			}
 			numTypes++
 		}
 		if check.Exec != nil {
@@ -1047,9 +1676,122 @@ func (p *Plan) Validate() error {
 			}
 			numTypes++
 		}
+		if check.GRPC != nil {
+			if check.GRPC.Address == "" {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan must set "address" for grpc check %q`, name),
+				}
+			}
+			if !check.GRPC.TLS && (check.GRPC.InsecureSkipVerify || check.GRPC.CAFile != "" || check.GRPC.ServerName != "") {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan grpc check %q must set "tls" to use TLS options`, name),
+				}
+			}
+			numTypes++
+		}
+		if check.Log != nil {
+			if check.Log.Service == "" {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan must set "service" for log check %q`, name),
+				}
+			}
+			if _, ok := p.Services[check.Log.Service]; !ok {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q specifies non-existent service %q",
+						name, check.Log.Service),
+				}
+			}
+			if check.Log.Regex == "" && check.Log.HeartbeatRegex == "" {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan must set "regex" or "heartbeat-regex" for log check %q`, name),
+				}
+			}
+			if check.Log.HeartbeatRegex != "" && !check.Log.HeartbeatTimeout.IsSet {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan must set "heartbeat-timeout" with "heartbeat-regex" for log check %q`, name),
+				}
+			}
+			numTypes++
+		}
+		if check.File != nil {
+			if check.File.Path == "" {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan must set "path" for file check %q`, name),
+				}
+			}
+			numTypes++
+		}
+		if check.Process != nil {
+			if (check.Process.PIDFile == "") == (check.Process.Service == "") {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan must set one of "pid-file" or "service" for process check %q`, name),
+				}
+			}
+			if check.Process.Name != "" && check.Process.Service == "" {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan must set "service" with "name" for process check %q`, name),
+				}
+			}
+			if _, ok := p.Services[check.Process.Service]; check.Process.Service != "" && !ok {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q specifies non-existent service %q",
+						name, check.Process.Service),
+				}
+			}
+			numTypes++
+		}
+		if check.Composite != nil {
+			if len(check.Composite.Checks) == 0 {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan must set "checks" for composite check %q`, name),
+				}
+			}
+			for _, ref := range check.Composite.Checks {
+				if _, ok := p.Checks[ref]; !ok {
+					return &FormatError{
+						Message: fmt.Sprintf("plan composite check %q refers to non-existent check %q", name, ref),
+					}
+				}
+			}
+			if check.Composite.N > len(check.Composite.Checks) {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan composite check %q "n" must not be greater than the number of checks`, name),
+				}
+			}
+			numTypes++
+		}
+		for field, config := range check.Extensions {
+			err := config.Validate()
+			if err != nil {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q %s invalid: %v", name, field, err),
+				}
+			}
+			numTypes++
+		}
 		if numTypes != 1 {
 			return &FormatError{
-				Message: fmt.Sprintf(`plan must specify one of "http", "tcp", or "exec" for check %q`, name),
+				Message: fmt.Sprintf(`plan must specify one of %s for check %q`, checkTypeFields(), name),
+			}
+		}
+		for _, h := range []struct {
+			field string
+			hook  *CheckHook
+		}{{"on-down", check.OnDown}, {"on-up", check.OnUp}} {
+			if h.hook == nil {
+				continue
+			}
+			if h.hook.Command == "" {
+				return &FormatError{
+					Message: fmt.Sprintf(`plan must set "command" for check %q %s hook`, name, h.field),
+				}
+			}
+			_, contextExists := p.Services[h.hook.ServiceContext]
+			if h.hook.ServiceContext != "" && !contextExists {
+				return &FormatError{
+					Message: fmt.Sprintf("plan check %q %s service context specifies non-existent service %q",
+						name, h.field, h.hook.ServiceContext),
+				}
 			}
 		}
 	}
@@ -1092,6 +1834,10 @@ func (p *Plan) Validate() error {
 	if err != nil {
 		return err
 	}
+	err = p.checkCompositeCycles()
+	if err != nil {
+		return err
+	}
 
 	// Each section extension must validate the combined plan.
 	for _, extension := range sectionExtensions {
@@ -1272,6 +2018,48 @@ func (p *Plan) checkCycles() error {
 	return err
 }
 
+// checkCompositeCycles ensures composite checks don't refer to themselves,
+// directly or indirectly.
+func (p *Plan) checkCompositeCycles() error {
+	successors := make(map[string][]string)
+	for name, check := range p.Checks {
+		if check.Composite == nil {
+			continue
+		}
+		var succs []string
+		for _, ref := range check.Composite.Checks {
+			if ref == name {
+				return &FormatError{
+					Message: fmt.Sprintf("composite check %q refers to itself", name),
+				}
+			}
+			// Only composite checks can be part of a loop.
+			if refCheck, ok := p.Checks[ref]; ok && refCheck.Composite != nil {
+				succs = append(succs, ref)
+			}
+		}
+		successors[name] = succs
+	}
+	for _, names := range tarjanSort(successors) {
+		if len(names) > 1 {
+			return &FormatError{
+				Message: fmt.Sprintf("composite checks in loop: %s", strings.Join(names, ", ")),
+			}
+		}
+	}
+	return nil
+}
+
+// checkTypeFields returns the quoted names of all the type-specific check
+// fields, including extension-defined ones, for use in error messages.
+func checkTypeFields() string {
+	fields := []string{`"http"`, `"tcp"`, `"exec"`, `"grpc"`, `"log"`, `"composite"`, `"file"`, `"process"`}
+	for _, field := range checkExtensionsOrder {
+		fields = append(fields, strconv.Quote(field))
+	}
+	return strings.Join(fields[:len(fields)-1], ", ") + ", or " + fields[len(fields)-1]
+}
+
 func ParseLayer(order int, label string, data []byte) (*Layer, error) {
 	layer := &Layer{
 		Services:   make(map[string]*Service),
@@ -1314,6 +2102,11 @@ func ParseLayer(order int, label string, data []byte) (*Layer, error) {
 		}
 	}
 
+	// Extension check fields do not have a concrete type in Check, so remove
+	// them from the YAML before decoding the checks, to honor KnownFields =
+	// true behaviour for the built-in check fields.
+	checkExtensionNodes := extractCheckExtensions(sections["checks"])
+
 	for field, section := range sections {
 		if slices.Contains(builtinSections, field) {
 			if err := SectionDecode(&section, builtins[field]); err != nil {
@@ -1340,6 +2133,23 @@ func ParseLayer(order int, label string, data []byte) (*Layer, error) {
 		}
 	}
 
+	for name, nodes := range checkExtensionNodes {
+		check := layer.Checks[name]
+		if check == nil {
+			continue
+		}
+		check.Extensions = make(map[string]CheckConfig, len(nodes))
+		for field, node := range nodes {
+			// Check unmarshal rules are defined by the extension itself.
+			check.Extensions[field], err = checkExtensions[field].ParseCheck(*node)
+			if err != nil {
+				return nil, &FormatError{
+					Message: fmt.Sprintf("cannot parse layer %q check %q field %q: %v", label, name, field, err),
+				}
+			}
+		}
+	}
+
 	layer.Order = order
 	layer.Label = label
 
@@ -1473,6 +2283,10 @@ func loadConfigLayer(layersDir string, l1Entry *configEntry, l2Entry *configEntr
 	return layer, nil
 }
 
+// httpMethodRegexp matches a valid HTTP method name, which we restrict to
+// upper-case letters (this covers all standard and common extension methods).
+var httpMethodRegexp = regexp.MustCompile(`^[A-Z]+$`)
+
 // configEntryRegexp matches either a valid config layer YAML file name or a
 // valid config layer directory. Match[1] is the 3-digit order and match[2]
 // is the label.
@@ -1649,6 +2463,39 @@ func MergeServiceContext(p *Plan, serviceName string, overrides ContextOptions)
 	return merged, nil
 }
 
+// extractCheckExtensions removes the fields of registered check extensions
+// from each check in the given "checks" section node, and returns the removed
+// value nodes, keyed by check name and then by field.
+func extractCheckExtensions(checks yaml.Node) map[string]map[string]*yaml.Node {
+	if len(checkExtensions) == 0 || checks.Kind != yaml.MappingNode {
+		return nil
+	}
+	var extracted map[string]map[string]*yaml.Node
+	for i := 0; i+1 < len(checks.Content); i += 2 {
+		name, check := checks.Content[i].Value, checks.Content[i+1]
+		if check.Kind != yaml.MappingNode {
+			continue
+		}
+		var content []*yaml.Node
+		for j := 0; j+1 < len(check.Content); j += 2 {
+			key, value := check.Content[j], check.Content[j+1]
+			if _, ok := checkExtensions[key.Value]; !ok {
+				content = append(content, key, value)
+				continue
+			}
+			if extracted == nil {
+				extracted = make(map[string]map[string]*yaml.Node)
+			}
+			if extracted[name] == nil {
+				extracted[name] = make(map[string]*yaml.Node)
+			}
+			extracted[name][key.Value] = value
+		}
+		check.Content = content
+	}
+	return extracted
+}
+
 // ContextOptions holds service context config fields.
 type ContextOptions struct {
 	Environment map[string]string
@@ -890,7 +890,7 @@ var planTests = []planTest{{
 	},
 }, {
 	summary: "One of http, tcp, or exec must be present for check",
-	error:   `plan must specify one of "http", "tcp", or "exec" for check "chk1"`,
+	error:   `plan must specify one of "http", "tcp", "exec", "grpc", "log", "composite", "file", or "process" for check "chk1"`,
 	input: []string{`
 		checks:
 			chk1:
@@ -944,6 +944,285 @@ var planTests = []planTest{{
 					command: foo
 					service-context: nosvc
 	`},
+}, {
+	summary: "HTTP check options override merge works correctly",
+	input: []string{`
+		checks:
+			chk-http:
+				override: replace
+				http:
+					url: https://example.com/foo
+					method: POST
+					body: '{"ping": true}'
+					expected-status: [200, 204]
+					ca-file: /etc/ssl/ca.pem
+`, `
+		checks:
+			chk-http:
+				override: merge
+				http:
+					expected-status: [401]
+					body-regex: ^ok$
+					insecure-skip-verify: true
+					cert-file: /etc/ssl/client.crt
+					key-file: /etc/ssl/client.key
+`},
+	result: &plan.Layer{
+		Services: map[string]*plan.Service{},
+		Checks: map[string]*plan.Check{
+			"chk-http": {
+				Name:      "chk-http",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				HTTP: &plan.HTTPCheck{
+					URL:                "https://example.com/foo",
+					Method:             "POST",
+					Body:               `{"ping": true}`,
+					ExpectedStatus:     []int{401},
+					BodyRegex:          "^ok$",
+					InsecureSkipVerify: true,
+					CAFile:             "/etc/ssl/ca.pem",
+					CertFile:           "/etc/ssl/client.crt",
+					KeyFile:            "/etc/ssl/client.key",
+				},
+			},
+		},
+		LogTargets: map[string]*plan.LogTarget{},
+		Sections:   map[string]plan.Section{},
+	},
+}, {
+	summary: "Invalid HTTP check method",
+	error:   `plan check "chk1" method "get it" invalid`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				http:
+					url: https://example.com/foo
+					method: get it
+`},
+}, {
+	summary: "Invalid HTTP check expected status",
+	error:   `plan check "chk1" expected-status 999 invalid`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				http:
+					url: https://example.com/foo
+					expected-status: [200, 999]
+`},
+}, {
+	summary: "Invalid HTTP check body regex",
+	error:   `plan check "chk1" body-regex invalid: .*`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				http:
+					url: https://example.com/foo
+					body-regex: "(foo"
+`},
+}, {
+	summary: "HTTP check client certificate requires key",
+	error:   `plan must set both "cert-file" and "key-file" for http check "chk1"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				http:
+					url: https://example.com/foo
+					cert-file: /etc/ssl/client.crt
+`},
+}, {
+	summary: "Log check override merge works correctly",
+	input: []string{`
+		services:
+			svc1:
+				override: replace
+				command: foo
+		checks:
+			chk-log:
+				override: replace
+				log:
+					service: svc1
+					regex: "panic:"
+`, `
+		checks:
+			chk-log:
+				override: merge
+				log:
+					window: 5m
+					heartbeat-regex: ^tick$
+					heartbeat-timeout: 1m
+`},
+	result: &plan.Layer{
+		Services: map[string]*plan.Service{
+			"svc1": {
+				Name:          "svc1",
+				Override:      plan.ReplaceOverride,
+				Command:       "foo",
+				BackoffDelay:  plan.OptionalDuration{Value: defaultBackoffDelay},
+				BackoffFactor: plan.OptionalFloat{Value: defaultBackoffFactor},
+				BackoffLimit:  plan.OptionalDuration{Value: defaultBackoffLimit},
+			},
+		},
+		Checks: map[string]*plan.Check{
+			"chk-log": {
+				Name:      "chk-log",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				Log: &plan.LogCheck{
+					Service:          "svc1",
+					Regex:            "panic:",
+					Window:           plan.OptionalDuration{Value: 5 * time.Minute, IsSet: true},
+					HeartbeatRegex:   "^tick$",
+					HeartbeatTimeout: plan.OptionalDuration{Value: time.Minute, IsSet: true},
+				},
+			},
+		},
+		LogTargets: map[string]*plan.LogTarget{},
+		Sections:   map[string]plan.Section{},
+	},
+}, {
+	summary: "Log check requires service field",
+	error:   `plan must set "service" for log check "chk1"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				log:
+					regex: foo
+`},
+}, {
+	summary: "Log check requires existing service",
+	error:   `plan check "chk1" specifies non-existent service "nosvc"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				log:
+					service: nosvc
+					regex: foo
+`},
+}, {
+	summary: "Log check requires regex or heartbeat-regex",
+	error:   `plan must set "regex" or "heartbeat-regex" for log check "chk1"`,
+	input: []string{`
+		services:
+			svc1:
+				override: replace
+				command: foo
+		checks:
+			chk1:
+				override: replace
+				log:
+					service: svc1
+`},
+}, {
+	summary: "Log check heartbeat-regex requires heartbeat-timeout",
+	error:   `plan must set "heartbeat-timeout" with "heartbeat-regex" for log check "chk1"`,
+	input: []string{`
+		services:
+			svc1:
+				override: replace
+				command: foo
+		checks:
+			chk1:
+				override: replace
+				log:
+					service: svc1
+					heartbeat-regex: tick
+`},
+}, {
+	summary: "Invalid log check regex",
+	error:   `plan check "chk1" regex invalid: .*`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				log:
+					service: svc1
+					regex: "(foo"
+`},
+}, {
+	summary: "gRPC check override merge works correctly",
+	input: []string{`
+		checks:
+			chk-grpc:
+				override: replace
+				grpc:
+					address: localhost:50051
+					metadata:
+						foo: bar
+`, `
+		checks:
+			chk-grpc:
+				override: merge
+				grpc:
+					service: my.Service
+					tls: true
+					ca-file: /etc/ssl/ca.pem
+					server-name: example.com
+					metadata:
+						baz: qux
+`},
+	result: &plan.Layer{
+		Services: map[string]*plan.Service{},
+		Checks: map[string]*plan.Check{
+			"chk-grpc": {
+				Name:      "chk-grpc",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				GRPC: &plan.GRPCCheck{
+					Address:    "localhost:50051",
+					Service:    "my.Service",
+					TLS:        true,
+					CAFile:     "/etc/ssl/ca.pem",
+					ServerName: "example.com",
+					Metadata:   map[string]string{"foo": "bar", "baz": "qux"},
+				},
+			},
+		},
+		LogTargets: map[string]*plan.LogTarget{},
+		Sections:   map[string]plan.Section{},
+	},
+}, {
+	summary: "gRPC check requires address field",
+	error:   `plan must set "address" for grpc check "chk1"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				grpc: {}
+`},
+}, {
+	summary: "Invalid gRPC check address",
+	error:   `plan check "chk1" address invalid: address localhost: missing port in address`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				grpc:
+					address: localhost
+`},
+}, {
+	summary: "gRPC check TLS options require TLS",
+	error:   `plan grpc check "chk1" must set "tls" to use TLS options`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				grpc:
+					address: localhost:50051
+					insecure-skip-verify: true
+`},
 }, {
 	summary: `Invalid check startup value`,
 	error:   `plan check "chk1" startup must be "enabled" or "disabled"`,
@@ -955,6 +1234,659 @@ var planTests = []planTest{{
 					exec:
 						command: foo
 		`},
+}, {
+	summary: "Invalid check level",
+	error:   `plan check "chk1" level must be "alive", "ready", or "startup"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				level: started
+				exec:
+					command: foo
+`},
+}, {
+	summary: "Negative check initial-delay",
+	error:   `plan check "chk1" initial-delay must not be negative`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				initial-delay: -1s
+				exec:
+					command: foo
+`},
+}, {
+	summary: "Check history-limit out of range",
+	error:   `plan check "chk1" history-limit must be between 0 and 1000`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				history-limit: 1001
+				exec:
+					command: foo
+`},
+}, {
+	summary: "Check history-limit negative",
+	error:   `plan check "chk1" history-limit must be between 0 and 1000`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				history-limit: -1
+				exec:
+					command: foo
+`},
+}, {
+	summary: "Check hooks merge correctly",
+	input: []string{`
+		services:
+			svc1:
+				override: replace
+				command: foo
+		checks:
+			chk1:
+				override: replace
+				exec:
+					command: foo
+				on-down:
+					command: dump-diagnostics
+					service-context: svc1
+					environment:
+						A: a
+`, `
+		checks:
+			chk1:
+				override: merge
+				on-down:
+					timeout: 30s
+					environment:
+						B: b
+				on-up:
+					command: flush-cache --all
+					user: nobody
+`},
+	result: &plan.Layer{
+		Services: map[string]*plan.Service{
+			"svc1": {
+				Name:          "svc1",
+				Override:      plan.ReplaceOverride,
+				Command:       "foo",
+				BackoffDelay:  plan.OptionalDuration{Value: defaultBackoffDelay},
+				BackoffFactor: plan.OptionalFloat{Value: defaultBackoffFactor},
+				BackoffLimit:  plan.OptionalDuration{Value: defaultBackoffLimit},
+			},
+		},
+		Checks: map[string]*plan.Check{
+			"chk1": {
+				Name:      "chk1",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				Exec: &plan.ExecCheck{
+					Command: "foo",
+				},
+				OnDown: &plan.CheckHook{
+					ExecCheck: plan.ExecCheck{
+						Command:        "dump-diagnostics",
+						ServiceContext: "svc1",
+						Environment:    map[string]string{"A": "a", "B": "b"},
+					},
+					Timeout: plan.OptionalDuration{Value: 30 * time.Second, IsSet: true},
+				},
+				OnUp: &plan.CheckHook{
+					ExecCheck: plan.ExecCheck{
+						Command: "flush-cache --all",
+						User:    "nobody",
+					},
+				},
+			},
+		},
+		LogTargets: map[string]*plan.LogTarget{},
+		Sections:   map[string]plan.Section{},
+	},
+}, {
+	summary: "Check hook requires command",
+	error:   `plan must set "command" for check "chk1" on-up hook`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				exec:
+					command: foo
+				on-up:
+					environment:
+						A: a
+`},
+}, {
+	summary: "Check hook requires existing service context",
+	error:   `plan check "chk1" on-down service context specifies non-existent service "nosvc"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				exec:
+					command: foo
+				on-down:
+					command: bar
+					service-context: nosvc
+`},
+}, {
+	summary: "Invalid check hook command",
+	error:   `plan check "chk1" on-down command invalid: .*`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				exec:
+					command: foo
+				on-down:
+					command: "bar '"
+`},
+}, {
+	summary: "Invalid check hook timeout",
+	error:   `plan check "chk1" on-up timeout must be greater than zero`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				exec:
+					command: foo
+				on-up:
+					command: bar
+					timeout: 0s
+`},
+}, {
+	summary: "Composite check merge works correctly",
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				tcp:
+					port: 5432
+			chk2:
+				override: replace
+				tcp:
+					port: 6379
+			chk3:
+				override: replace
+				tcp:
+					port: 8080
+			ready:
+				override: replace
+				level: ready
+				composite:
+					checks: [chk1, chk2]
+`, `
+		checks:
+			ready:
+				override: merge
+				composite:
+					checks: [chk3]
+					require: n-of
+					n: 2
+`},
+	result: &plan.Layer{
+		Services: map[string]*plan.Service{},
+		Checks: map[string]*plan.Check{
+			"chk1": {
+				Name:      "chk1",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				TCP:       &plan.TCPCheck{Port: 5432},
+			},
+			"chk2": {
+				Name:      "chk2",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				TCP:       &plan.TCPCheck{Port: 6379},
+			},
+			"chk3": {
+				Name:      "chk3",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				TCP:       &plan.TCPCheck{Port: 8080},
+			},
+			"ready": {
+				Name:      "ready",
+				Override:  plan.ReplaceOverride,
+				Level:     plan.ReadyLevel,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				Composite: &plan.CompositeCheck{
+					Checks:  []string{"chk1", "chk2", "chk3"},
+					Require: plan.CompositeRequireNOf,
+					N:       2,
+				},
+			},
+		},
+		LogTargets: map[string]*plan.LogTarget{},
+		Sections:   map[string]plan.Section{},
+	},
+}, {
+	summary: "Composite check merge doesn't duplicate checks",
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				tcp:
+					port: 5432
+			chk2:
+				override: replace
+				tcp:
+					port: 6379
+			ready:
+				override: replace
+				level: ready
+				composite:
+					checks: [chk1, chk2]
+`, `
+		checks:
+			ready:
+				override: merge
+				composite:
+					checks: [chk2, chk1]
+					require: n-of
+					n: 2
+`},
+	result: &plan.Layer{
+		Services: map[string]*plan.Service{},
+		Checks: map[string]*plan.Check{
+			"chk1": {
+				Name:      "chk1",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				TCP:       &plan.TCPCheck{Port: 5432},
+			},
+			"chk2": {
+				Name:      "chk2",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				TCP:       &plan.TCPCheck{Port: 6379},
+			},
+			"ready": {
+				Name:      "ready",
+				Override:  plan.ReplaceOverride,
+				Level:     plan.ReadyLevel,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				Composite: &plan.CompositeCheck{
+					Checks:  []string{"chk1", "chk2"},
+					Require: plan.CompositeRequireNOf,
+					N:       2,
+				},
+			},
+		},
+		LogTargets: map[string]*plan.LogTarget{},
+		Sections:   map[string]plan.Section{},
+	},
+}, {
+	summary: "Composite check requires checks",
+	error:   `plan must set "checks" for composite check "chk1"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				composite:
+					require: any
+`},
+}, {
+	summary: "Composite check refers to non-existent check",
+	error:   `plan composite check "chk1" refers to non-existent check "nochk"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				composite:
+					checks: [nochk]
+`},
+}, {
+	summary: "Composite check invalid require",
+	error:   `plan check "chk1" require must be "all", "any", or "n-of"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				composite:
+					checks: [chk2]
+					require: most
+`},
+}, {
+	summary: "Composite check n-of requires n",
+	error:   `plan check "chk1" must set "n" to at least 1 with require "n-of"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				composite:
+					checks: [chk2]
+					require: n-of
+`},
+}, {
+	summary: "Composite check n only with n-of",
+	error:   `plan check "chk1" must only set "n" with require "n-of"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				composite:
+					checks: [chk2]
+					n: 1
+`},
+}, {
+	summary: "Composite check n greater than number of checks",
+	error:   `plan composite check "chk1" "n" must not be greater than the number of checks`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				composite:
+					checks: [chk2]
+					require: n-of
+					n: 2
+			chk2:
+				override: replace
+				tcp:
+					port: 8080
+`},
+}, {
+	summary: "Composite check refers to itself",
+	error:   `composite check "chk1" refers to itself`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				composite:
+					checks: [chk1]
+`},
+}, {
+	summary: "Composite checks in loop",
+	error:   `composite checks in loop: chk1, chk2, chk3`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				composite:
+					checks: [chk2, chk4]
+			chk2:
+				override: replace
+				composite:
+					checks: [chk3]
+			chk3:
+				override: replace
+				composite:
+					checks: [chk1]
+			chk4:
+				override: replace
+				tcp:
+					port: 8080
+`},
+}, {
+	summary: "File and process checks merge correctly",
+	input: []string{`
+		services:
+			srv1:
+				override: replace
+				command: cmd
+		checks:
+			chk1:
+				override: replace
+				file:
+					path: /var/run/heartbeat
+					max-age: 1m
+			chk2:
+				override: replace
+				process:
+					pid-file: /var/run/daemon.pid
+`, `
+		checks:
+			chk1:
+				override: merge
+				file:
+					regex: OK
+			chk2:
+				override: replace
+				process:
+					service: srv1
+					name: worker
+`},
+	result: &plan.Layer{
+		Services: map[string]*plan.Service{
+			"srv1": {
+				Name:          "srv1",
+				Override:      plan.ReplaceOverride,
+				Command:       "cmd",
+				BackoffDelay:  plan.OptionalDuration{Value: defaultBackoffDelay},
+				BackoffFactor: plan.OptionalFloat{Value: defaultBackoffFactor},
+				BackoffLimit:  plan.OptionalDuration{Value: defaultBackoffLimit},
+			},
+		},
+		Checks: map[string]*plan.Check{
+			"chk1": {
+				Name:      "chk1",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				File: &plan.FileCheck{
+					Path:   "/var/run/heartbeat",
+					MaxAge: plan.OptionalDuration{Value: time.Minute, IsSet: true},
+					Regex:  "OK",
+				},
+			},
+			"chk2": {
+				Name:      "chk2",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				Process: &plan.ProcessCheck{
+					Service: "srv1",
+					Name:    "worker",
+				},
+			},
+		},
+		LogTargets: map[string]*plan.LogTarget{},
+		Sections:   map[string]plan.Section{},
+	},
+}, {
+	summary: "File check requires path",
+	error:   `plan must set "path" for file check "chk1"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				file:
+					max-age: 1m
+`},
+}, {
+	summary: "File check path must be absolute",
+	error:   `plan check "chk1" path must be an absolute path`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				file:
+					path: heartbeat
+`},
+}, {
+	summary: "File check invalid max-age",
+	error:   `plan check "chk1" max-age must be greater than zero`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				file:
+					path: /heartbeat
+					max-age: 0s
+`},
+}, {
+	summary: "File check invalid regex",
+	error:   `plan check "chk1" regex invalid: .*`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				file:
+					path: /heartbeat
+					regex: "("
+`},
+}, {
+	summary: "Process check requires pid-file or service",
+	error:   `plan must set one of "pid-file" or "service" for process check "chk1"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				process:
+					name: worker
+`},
+}, {
+	summary: "Process check pid-file must be absolute",
+	error:   `plan check "chk1" pid-file must be an absolute path`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				process:
+					pid-file: daemon.pid
+`},
+}, {
+	summary: "Process check name requires service",
+	error:   `plan must set "service" with "name" for process check "chk1"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				process:
+					pid-file: /daemon.pid
+					name: worker
+`},
+}, {
+	summary: "Process check non-existent service",
+	error:   `plan check "chk1" specifies non-existent service "nosrv"`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				process:
+					service: nosrv
+`},
+}, {
+	summary: "TCP check with TLS merges correctly",
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				tcp:
+					port: 443
+					host: example.com
+					tls: true
+`, `
+		checks:
+			chk1:
+				override: merge
+				tcp:
+					server-name: www.example.com
+					ca-file: /etc/ssl/ca.pem
+					min-validity: 168h
+`},
+	result: &plan.Layer{
+		Services: map[string]*plan.Service{},
+		Checks: map[string]*plan.Check{
+			"chk1": {
+				Name:      "chk1",
+				Override:  plan.ReplaceOverride,
+				Period:    plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:   plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold: defaultCheckThreshold,
+				TCP: &plan.TCPCheck{
+					Port:        443,
+					Host:        "example.com",
+					TLS:         true,
+					ServerName:  "www.example.com",
+					CAFile:      "/etc/ssl/ca.pem",
+					MinValidity: plan.OptionalDuration{Value: 168 * time.Hour, IsSet: true},
+				},
+			},
+		},
+		LogTargets: map[string]*plan.LogTarget{},
+		Sections:   map[string]plan.Section{},
+	},
+}, {
+	summary: "TCP check TLS options require tls",
+	error:   `plan tcp check "chk1" must set "tls" to use TLS options`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				tcp:
+					port: 443
+					min-validity: 24h
+`},
+}, {
+	summary: "TCP check invalid min-validity",
+	error:   `plan check "chk1" min-validity must be greater than zero`,
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				tcp:
+					port: 443
+					tls: true
+					min-validity: 0s
+`},
+}, {
+	summary: "Startup check with initial-delay merges correctly",
+	input: []string{`
+		checks:
+			chk1:
+				override: replace
+				level: startup
+				threshold: 30
+				exec:
+					command: foo
+`, `
+		checks:
+			chk1:
+				override: merge
+				initial-delay: 20s
+				history-limit: 50
+`},
+	result: &plan.Layer{
+		Services: map[string]*plan.Service{},
+		Checks: map[string]*plan.Check{
+			"chk1": {
+				Name:         "chk1",
+				Override:     plan.ReplaceOverride,
+				Level:        plan.StartupLevel,
+				Period:       plan.OptionalDuration{Value: defaultCheckPeriod},
+				Timeout:      plan.OptionalDuration{Value: defaultCheckTimeout},
+				Threshold:    30,
+				InitialDelay: plan.OptionalDuration{Value: 20 * time.Second, IsSet: true},
+				HistoryLimit: 50,
+				Exec: &plan.ExecCheck{
+					Command: "foo",
+				},
+			},
+		},
+		LogTargets: map[string]*plan.LogTarget{},
+		Sections:   map[string]plan.Section{},
+	},
 }, {}, {
 	summary: "Simple layer with log targets",
 	input: []string{`
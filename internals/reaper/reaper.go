@@ -51,8 +51,15 @@ func Start() error {
 		return err
 	}
 
+	// Register for SIGCHLD before returning, so that a child started right
+	// after Start returns can't exit before the reaper is listening.
+	sigChld := make(chan os.Signal, 1)
+	signal.Notify(sigChld, unix.SIGCHLD)
+
 	started = true
-	reaperTomb.Go(reapChildren)
+	reaperTomb.Go(func() error {
+		return reapChildren(sigChld)
+	})
 	return nil
 }
 
@@ -100,10 +107,8 @@ func setChildSubreaper(set int) error {
 
 // reapChildren "reaps" (waits for) child processes whose parents didn't
 // wait() for them. It stops when the reaper tomb is killed.
-func reapChildren() error {
+func reapChildren(sigChld chan os.Signal) error {
 	logger.Debugf("Reaper started, waiting for SIGCHLD.")
-	sigChld := make(chan os.Signal, 1)
-	signal.Notify(sigChld, unix.SIGCHLD)
 	for {
 		select {
 		case <-sigChld: